@@ -0,0 +1,469 @@
+package tempuscache
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+/*
+aof.go implements append-only file (AOF) persistence.
+
+================================================================================
+WHY AN AOF?
+================================================================================
+
+Snapshots only capture the state at the moment they are taken;
+everything written since the last snapshot is lost on a crash.
+The AOF records every mutation as it happens and replays the log
+at startup, closing that window.
+
+================================================================================
+LOG FORMAT
+================================================================================
+
+The log is a single gob stream of aofRecord values:
+
+- aofOpSet    → Key now holds Value with absolute deadline Expiration.
+- aofOpDelete → Key was explicitly removed.
+- aofOpFlush  → Every entry was removed by Flush().
+
+Sets record the ABSOLUTE deadline (UnixNano), so replay reproduces
+the exact original expiration regardless of when it runs.
+
+Capacity evictions and expirations are NOT logged: replaying the
+same sets under the same capacity reproduces evictions, and expired
+deadlines are skipped on replay.
+
+================================================================================
+LIFECYCLE
+================================================================================
+
+OpenAOF:
+    1. Replays the existing log (if any) into the cache.
+       A torn record at the tail (crash mid-append) is ignored.
+    2. Rewrites the log to contain only the current live state,
+       via temp file + fsync + atomic rename.
+    3. Keeps the rewritten file open and appends every subsequent
+       mutation to it.
+
+A gob stream cannot be resumed by a second encoder, which is why the
+log is always rewritten at open rather than appended to directly.
+
+================================================================================
+COMPACTION
+================================================================================
+
+Overwrite-heavy workloads make the log grow without bound even though
+the live state stays small. CompactNow() rewrites the log to the live
+state in the background of normal traffic, and WithAOFRewriteSize()
+triggers the same rewrite automatically once the log has both reached
+a size threshold and doubled since its last rewrite.
+
+================================================================================
+DURABILITY
+================================================================================
+
+Each record is handed to the OS with a single write() while the cache
+lock is held. This survives process crashes; surviving power loss
+additionally requires the OS to have flushed its page cache. Stop()
+fsyncs and closes the log.
+
+================================================================================
+CONCURRENCY
+================================================================================
+
+All appends happen under the cache's exclusive lock, so the log
+needs no synchronization of its own and records appear in exactly
+the order the mutations were applied.
+*/
+
+// ErrAOFDisabled is returned by CompactNow when no append-only log is open.
+var ErrAOFDisabled = errors.New("tempuscache: append-only log is not enabled")
+
+const (
+	aofOpSet uint8 = iota + 1
+	aofOpDelete
+	aofOpFlush
+)
+
+type aofRecord struct {
+	Op         uint8
+	Key        string
+	Value      interface{}
+	Expiration int64
+}
+
+/*
+appendLog is an open append-only log.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+path     -> Final location of the log
+f        -> Open file handle (survives the atomic rename)
+enc      -> gob encoder bound to f
+size     -> Bytes written to f so far
+baseSize -> Size of the log right after its last rewrite
+tail     -> Records appended while a background rewrite is running
+            (nil when no rewrite is in progress)
+*/
+
+type appendLog struct {
+	path     string
+	f        *os.File
+	enc      *gob.Encoder
+	size     int64
+	baseSize int64
+	tail     []aofRecord
+}
+
+// Write counts bytes so size-triggered compaction needs no stat() calls.
+func (l *appendLog) Write(p []byte) (int, error) {
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+/*
+aofAppend records a mutation in the append-only log, if enabled.
+
+Write errors are logged but otherwise ignored: the in-memory cache
+stays authoritative and the next rewrite restores a consistent log.
+
+If a background rewrite is running, the record is also captured in
+the tail buffer so it can be replayed onto the new log at swap time.
+If the log has outgrown its compaction threshold, a background
+rewrite is started.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) aofAppend(op uint8, key string, value interface{}, exp int64) {
+	log := c.aof
+	if log == nil {
+		return
+	}
+
+	rec := aofRecord{Op: op, Key: key, Value: value, Expiration: exp}
+	if err := log.enc.Encode(&rec); err != nil {
+		c.logger().Warn("tempuscache: append-only log write failed", "path", log.path, "key", key, "err", err)
+	}
+
+	if log.tail != nil {
+		log.tail = append(log.tail, rec)
+		return
+	}
+
+	if c.aofRewriteSize > 0 && log.size >= c.aofRewriteSize && log.size >= 2*log.baseSize {
+		log.tail = []aofRecord{}
+		c.aofWorkers.Add(1)
+		go func() {
+			defer c.aofWorkers.Done()
+			if err := c.compactAOF(log); err != nil && err != ErrAOFDisabled {
+				c.logger().Warn("tempuscache: append-only log rewrite failed", "path", log.path, "err", err)
+			}
+		}()
+	}
+}
+
+/*
+OpenAOF enables append-only persistence backed by the file at path.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- An existing log is replayed into the cache first.
+- The log is then compacted to the current live state.
+- Every subsequent Set/Delete (and every other write path) is
+  appended to the log until Stop() is called.
+
+Calling OpenAOF while a log is already open switches to the new path.
+
+================================================================================
+ERRORS
+================================================================================
+
+Returns ErrClosed after Stop(), or an error if the existing log is
+corrupt (other than a torn final record), contains unregistered gob
+types, or the rewritten log cannot be created.
+*/
+
+func (c *Cache) OpenAOF(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return ErrClosed
+	}
+
+	c.closeAOFLocked()
+
+	if err := c.replayAOF(path); err != nil {
+		return err
+	}
+
+	log, err := c.rewriteAOF(path)
+	if err != nil {
+		return err
+	}
+
+	c.aof = log
+	return nil
+}
+
+/*
+replayAOF applies every record of the log at path to the cache.
+A missing file is not an error.
+
+NOTE:
+The caller must hold the exclusive lock, and c.aof must be nil so
+replayed records are not appended again.
+*/
+
+func (c *Cache) replayAOF(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c.restoring = true
+	defer func() { c.restoring = false }()
+
+	now := c.now()
+	dec := gob.NewDecoder(f)
+	for {
+		var rec aofRecord
+		err := dec.Decode(&rec)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch rec.Op {
+		case aofOpSet:
+			if rec.Expiration != 0 && now > rec.Expiration {
+				c.delete(rec.Key)
+				continue
+			}
+			c.put(rec.Key, rec.Value, rec.Expiration)
+		case aofOpDelete:
+			c.delete(rec.Key)
+		case aofOpFlush:
+			c.flush()
+		}
+	}
+}
+
+/*
+liveRecords returns the entries of v as set records, ordered from
+least to most recently used.
+*/
+
+func (c *Cache) liveRecords(v *View) []aofRecord {
+	recs := make([]aofRecord, len(v.entries))
+	for i, e := range v.entries {
+		recs[i] = aofRecord{Op: aofOpSet, Key: e.key, Value: c.unpack(e.value), Expiration: e.expiration}
+	}
+	return recs
+}
+
+/*
+createAOF writes recs to a temporary file next to path and
+returns it as an open, not yet renamed, appendLog.
+*/
+
+func createAOF(path string, recs []aofRecord) (*appendLog, string, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, base+".tmp-*")
+	if err != nil {
+		return nil, "", err
+	}
+
+	log := &appendLog{path: path, f: tmp}
+	log.enc = gob.NewEncoder(log)
+	for i := range recs {
+		if err := log.enc.Encode(&recs[i]); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return nil, "", err
+		}
+	}
+	return log, tmp.Name(), nil
+}
+
+/*
+installAOF fsyncs a freshly written log and atomically renames it
+over its final path.
+*/
+
+func installAOF(log *appendLog, tmpName string) error {
+	if err := log.f.Sync(); err != nil {
+		log.f.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, log.path); err != nil {
+		log.f.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	log.baseSize = log.size
+	return nil
+}
+
+/*
+rewriteAOF writes the current live state to a fresh log that
+atomically replaces the file at path, and returns it open for
+further appends.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) rewriteAOF(path string) (*appendLog, error) {
+	log, tmpName, err := createAOF(path, c.liveRecords(c.view()))
+	if err != nil {
+		return nil, err
+	}
+	if err := installAOF(log, tmpName); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+/*
+compactAOF rewrites the log to the current live state without
+blocking writers for the duration of the I/O.
+
+================================================================================
+ALGORITHM
+================================================================================
+
+1. Under the lock:
+   - Capture the live state as a View (see view.go).
+   - Start buffering new records in old.tail.
+2. Without the lock:
+   - Decode the View and write it to a temporary file.
+3. Under the lock again:
+   - Append the buffered tail records to the new file.
+   - fsync + atomically rename it over the old log.
+   - Swap the cache over to the new log and close the old one.
+
+The old log keeps receiving every write until the swap, so a crash
+at any point leaves a complete, replayable log on disk.
+
+If the log was closed or replaced while the rewrite was running
+(Stop, OpenAOF), the new file is discarded.
+
+The caller must have set old.tail to a non-nil slice; this marks
+the rewrite as in progress and prevents concurrent rewrites.
+*/
+
+func (c *Cache) compactAOF(old *appendLog) error {
+	c.mu.Lock()
+	v := c.view()
+	c.mu.Unlock()
+
+	log, tmpName, err := createAOF(old.path, c.liveRecords(v))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tail := old.tail
+	old.tail = nil
+
+	if err != nil {
+		return err
+	}
+	if c.aof != old {
+		log.f.Close()
+		os.Remove(tmpName)
+		return ErrAOFDisabled
+	}
+
+	for i := range tail {
+		if err := log.enc.Encode(&tail[i]); err != nil {
+			log.f.Close()
+			os.Remove(tmpName)
+			return err
+		}
+	}
+	if err := installAOF(log, tmpName); err != nil {
+		return err
+	}
+
+	old.f.Close()
+	c.aof = log
+	return nil
+}
+
+/*
+CompactNow rewrites the append-only log so that it contains only
+the current live state, discarding overwritten and deleted history.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Blocks until the rewrite completes.
+- Writers are only blocked while the state is captured and while
+  the new log is swapped in, not during the bulk I/O.
+- If a size-triggered rewrite is already running, CompactNow
+  returns nil without starting another one.
+
+================================================================================
+ERRORS
+================================================================================
+
+Returns ErrAOFDisabled if no log is open, or any I/O or encoding
+error from writing the new log. On error, the old log remains in use.
+*/
+
+func (c *Cache) CompactNow() error {
+	c.mu.Lock()
+	log := c.aof
+	if log == nil {
+		c.mu.Unlock()
+		return ErrAOFDisabled
+	}
+	if log.tail != nil {
+		c.mu.Unlock()
+		return nil
+	}
+	log.tail = []aofRecord{}
+	c.mu.Unlock()
+
+	return c.compactAOF(log)
+}
+
+/*
+closeAOFLocked fsyncs and closes the append-only log, if open.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) closeAOFLocked() {
+	if c.aof == nil {
+		return
+	}
+	c.aof.f.Sync()
+	c.aof.f.Close()
+	c.aof = nil
+}
@@ -0,0 +1,106 @@
+package tempuscache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	cache := New(WithAOF(path))
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, time.Hour)
+	cache.Set("c", 3, 0)
+	cache.Delete("c")
+	cache.IncrementBy("n", 5, 0)
+	cache.Stop()
+
+	restored := New(WithAOF(path))
+
+	got := restored.GetMany([]string{"a", "b", "c", "n"})
+	if len(got) != 3 || got["a"] != 1 || got["b"] != 2 || got["n"] != int64(5) {
+		t.Fatalf("unexpected replayed state: %v", got)
+	}
+
+	// Appends continue to work after the startup rewrite.
+	restored.Set("d", 4, 0)
+	restored.Stop()
+
+	again := New(WithAOF(path))
+	defer again.Stop()
+	if val, found := again.Get("d"); !found || val != 4 {
+		t.Fatalf("expected 'd' after second replay, got %v", val)
+	}
+}
+
+func TestAOFCompactNow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	cache := New(WithAOF(path))
+	for i := 0; i < 1000; i++ {
+		cache.Set("counter", i, 0)
+	}
+
+	before, _ := os.Stat(path)
+	if err := cache.CompactNow(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+
+	if after.Size() >= before.Size() {
+		t.Fatalf("expected compaction to shrink the log (%d -> %d)", before.Size(), after.Size())
+	}
+
+	cache.Set("extra", 1, 0)
+	cache.Stop()
+
+	restored := New(WithAOF(path))
+	defer restored.Stop()
+
+	got := restored.GetMany([]string{"counter", "extra"})
+	if got["counter"] != 999 || got["extra"] != 1 {
+		t.Fatalf("unexpected state after compaction: %v", got)
+	}
+}
+
+func TestAOFRewriteSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	cache := New(WithAOF(path), WithAOFRewriteSize(4096))
+	for i := 0; i < 5000; i++ {
+		cache.Set("counter", i, 0)
+		if i%100 == 0 {
+			time.Sleep(time.Millisecond) // let background rewrites run
+		}
+	}
+	cache.Stop()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() >= 4096*4 {
+		t.Fatalf("expected automatic compaction to bound the log, got %d bytes", info.Size())
+	}
+
+	if err := New().CompactNow(); err != ErrAOFDisabled {
+		t.Fatalf("expected ErrAOFDisabled, got %v", err)
+	}
+}
+
+func TestOpenAOFAfterStop(t *testing.T) {
+	cache := New()
+	cache.Stop()
+
+	path := filepath.Join(t.TempDir(), "cache.aof")
+	if err := cache.OpenAOF(path); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected no log to be created")
+	}
+}
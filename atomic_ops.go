@@ -0,0 +1,353 @@
+package tempuscache
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrNotNumeric is returned by the Increment/Decrement family when the
+// stored value is not of the numeric type the operation expects.
+var ErrNotNumeric = errors.New("tempuscache: value is not numeric")
+
+/*
+CompareAndSwap replaces the value stored under key with new,
+but only if the current value equals old.
+
+================================================================================
+RETURN VALUE
+================================================================================
+
+true  -> The key existed, was unexpired, held old, and now holds new.
+false -> The key was missing, expired, or held a different value.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Equality uses Go's == operator on the stored interface{} values.
+- Values whose dynamic type is not comparable (maps, slices, funcs)
+  never match; CompareAndSwap returns false instead of panicking.
+- On success:
+    → Expiration is recalculated (if ttl > 0).
+    → The item moves to the front of the LRU list.
+- Expired entries are removed (lazy expiration) and treated as missing.
+- The swap is cache-only: it bypasses WithInterceptor and is not
+  written through or behind to a Store (see interceptor.go and
+  writethrough.go).
+
+================================================================================
+OPTIMISTIC CONCURRENCY
+================================================================================
+
+CompareAndSwap lets concurrent writers perform optimistic updates:
+
+    for {
+        old, _ := cache.Get("k")
+        if cache.CompareAndSwap("k", old, next(old), ttl) {
+            break
+        }
+    }
+
+The comparison and the write happen under the same exclusive lock,
+so no other writer can slip in between.
+
+TIME COMPLEXITY:
+O(1) average case
+*/
+
+func (c *Cache) CompareAndSwap(key string, old, new interface{}, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+
+	item := elem.Value.(*Item)
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		return false
+	}
+
+	if !valuesEqual(c.unpack(item.value), old) {
+		return false
+	}
+
+	return c.set(key, new, ttl) == nil
+}
+
+/*
+valuesEqual reports whether a and b are equal under ==,
+returning false (rather than panicking) when the dynamic
+type is not comparable.
+*/
+
+func valuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+/*
+Update performs an atomic read-modify-write on a single key.
+
+================================================================================
+PARAMETERS
+================================================================================
+
+fn(old, exists) (new, ttl):
+    - old    : Current value (nil if missing or expired)
+    - exists : Whether a valid entry was present
+    - new    : Value to store
+    - ttl    : TTL to apply, with the same semantics as Set()
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- fn is invoked while the cache holds its exclusive lock.
+- Its result is stored exactly as Set() would store it:
+    → Existing entries keep their expiration unless ttl > 0.
+    → New entries may trigger LRU eviction at capacity.
+- The stored value is returned to the caller.
+- Unlike Set, no interceptor runs and the result is not saved to a
+  write-through or write-behind Store.
+
+================================================================================
+WHY THIS MATTERS
+================================================================================
+
+Counters, sets-within-values, and "append to cached slice" patterns
+are racy when implemented as separate Get() and Set() calls.
+Update closes that window.
+
+================================================================================
+USAGE CONTRACT
+================================================================================
+
+fn must be fast and must NOT call back into the cache —
+doing so would deadlock, since the lock is already held.
+*/
+
+func (c *Cache) Update(key string, fn func(old interface{}, exists bool) (interface{}, time.Duration)) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var old interface{}
+	exists := false
+	if elem, found := c.data[key]; found {
+		item := elem.Value.(*Item)
+		if c.expired(item) {
+			c.expireElement(elem, false)
+		} else {
+			old, exists = c.read(item.value), true
+		}
+	}
+
+	value, ttl := fn(old, exists)
+	c.set(key, value, ttl)
+	return value
+}
+
+/*
+Compute is Update with the option of not writing at all: when fn
+returns write = false, the entry is left exactly as it was (no Set
+is counted, the LRU order is unchanged, and nothing is logged,
+published, or saved).
+
+RETURNS:
+The value now stored under key (fn's result if it was written, the
+old value otherwise) and whether fn's result was written. A write
+refused by a key validator or WithMaxValueBytes is not written.
+
+The same USAGE CONTRACT as Update applies to fn, and, as with
+Update, a written result bypasses interceptors and Store writes.
+*/
+
+func (c *Cache) Compute(key string, fn func(old interface{}, exists bool) (new interface{}, ttl time.Duration, write bool)) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var old interface{}
+	exists := false
+	if elem, found := c.data[key]; found {
+		item := elem.Value.(*Item)
+		if c.expired(item) {
+			c.expireElement(elem, false)
+		} else {
+			old, exists = c.read(item.value), true
+		}
+	}
+
+	value, ttl, write := fn(old, exists)
+	if !write || c.set(key, value, ttl) != nil {
+		return old, false
+	}
+	return value, true
+}
+
+/*
+IncrementBy atomically adds delta to an int64 value and returns the result.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Missing or expired key:
+    → The key is created with value delta.
+    → ttl is applied exactly as Set() would (ttl <= 0 means no expiry).
+- Existing key holding an int64:
+    → delta is added in place.
+    → The existing expiration is preserved; ttl is ignored.
+- Existing key holding any other type:
+    → The value is left untouched and ErrNotNumeric is returned.
+- Only the cache is updated: interceptors are skipped, and
+  write-through and write-behind Stores never see the counter.
+
+================================================================================
+TTL SEMANTICS
+================================================================================
+
+TTL only applies on creation. This is what per-key rate counters need:
+the counting window starts with the first increment and is not pushed
+forward by later ones.
+
+TIME COMPLEXITY:
+O(1) average case
+*/
+
+func (c *Cache) IncrementBy(key string, delta int64, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, err := c.numericItem(key, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	if item == nil {
+		return delta, nil
+	}
+
+	n, ok := c.unpack(item.value).(int64)
+	if !ok {
+		return 0, ErrNotNumeric
+	}
+	n += delta
+	if err := c.put(key, n, item.expiration); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// DecrementBy is IncrementBy with a negated delta.
+func (c *Cache) DecrementBy(key string, delta int64, ttl time.Duration) (int64, error) {
+	return c.IncrementBy(key, -delta, ttl)
+}
+
+/*
+IncrementFloatBy is the float64 counterpart of IncrementBy.
+The same creation, TTL, and type-mismatch rules apply.
+*/
+
+func (c *Cache) IncrementFloatBy(key string, delta float64, ttl time.Duration) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, err := c.numericItem(key, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	if item == nil {
+		return delta, nil
+	}
+
+	f, ok := c.unpack(item.value).(float64)
+	if !ok {
+		return 0, ErrNotNumeric
+	}
+	f += delta
+	if err := c.put(key, f, item.expiration); err != nil {
+		return 0, err
+	}
+	return f, nil
+}
+
+// DecrementFloatBy is IncrementFloatBy with a negated delta.
+func (c *Cache) DecrementFloatBy(key string, delta float64, ttl time.Duration) (float64, error) {
+	return c.IncrementFloatBy(key, -delta, ttl)
+}
+
+/*
+numericItem resolves the item targeted by an increment.
+
+If the key is missing or expired, it stores initial with ttl and
+returns a nil item, signalling that the caller is done, along with
+the error of a rejected write.
+Otherwise the live item is returned so the caller can store the
+new total via put(), keeping its existing expiration.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) numericItem(key string, initial interface{}, ttl time.Duration) (*Item, error) {
+	if elem, found := c.data[key]; found {
+		item := elem.Value.(*Item)
+		if !c.expired(item) {
+			return item, nil
+		}
+		c.expireElement(elem, false)
+	}
+
+	return nil, c.set(key, initial, ttl)
+}
+
+/*
+GetOrSet returns the existing value for key if present and unexpired.
+Otherwise it stores value and returns it.
+
+================================================================================
+RETURN VALUE
+================================================================================
+
+(actual, true)  -> The key was present; actual is the cached value.
+(value, false)  -> The key was missing or expired; value was stored.
+
+These semantics match sync.Map.LoadOrStore.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- A loaded value is promoted in the LRU list and counted as a hit.
+- A stored value is inserted exactly as Set() would insert it
+  and counted as a miss, except that WithInterceptor is bypassed
+  and the value is not written through or behind to a Store.
+
+================================================================================
+WHY THIS MATTERS
+================================================================================
+
+The naive Get-then-Set pattern lets two goroutines both observe a
+miss and both insert, with the slower one clobbering a newer value.
+GetOrSet makes the check and the insert a single atomic step.
+*/
+
+func (c *Cache) GetOrSet(key string, value interface{}, ttl time.Duration) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if actual, found := c.get(key); found {
+		return actual, true
+	}
+
+	c.set(key, value, ttl)
+	return value, false
+}
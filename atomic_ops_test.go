@@ -0,0 +1,144 @@
+package tempuscache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	cache := New()
+
+	if cache.CompareAndSwap("a", nil, 1, 0) {
+		t.Fatal("expected CAS on missing key to fail")
+	}
+
+	cache.Set("a", 1, 0)
+
+	if cache.CompareAndSwap("a", 2, 3, 0) {
+		t.Fatal("expected CAS with stale old value to fail")
+	}
+	if !cache.CompareAndSwap("a", 1, 3, 0) {
+		t.Fatal("expected CAS with current value to succeed")
+	}
+
+	val, _ := cache.Get("a")
+	if val != 3 {
+		t.Fatalf("expected 3, got %v", val)
+	}
+
+	cache.Set("m", map[string]int{}, 0)
+	if cache.CompareAndSwap("m", map[string]int{}, 1, 0) {
+		t.Fatal("expected CAS on uncomparable value to fail")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cache := New()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Update("counter", func(old interface{}, exists bool) (interface{}, time.Duration) {
+				if !exists {
+					return 1, 0
+				}
+				return old.(int) + 1, 0
+			})
+		}()
+	}
+	wg.Wait()
+
+	val, _ := cache.Get("counter")
+	if val != 100 {
+		t.Fatalf("expected 100, got %v", val)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	cache := New()
+	cache.Set("k", 1, time.Hour)
+
+	if v, written := cache.Compute("k", func(old interface{}, exists bool) (interface{}, time.Duration, bool) {
+		return old.(int) + 1, 0, old.(int) < 1
+	}); written || v != 1 {
+		t.Fatalf("expected a skipped write to keep the old value, got %v (%v)", v, written)
+	}
+	if n := cache.Stats().Sets; n != 1 {
+		t.Fatalf("expected a skipped write not to count as a Set, got %d Sets", n)
+	}
+
+	if v, written := cache.Compute("k", func(old interface{}, exists bool) (interface{}, time.Duration, bool) {
+		return old.(int) + 1, 0, true
+	}); !written || v != 2 {
+		t.Fatalf("expected the new value to be written, got %v (%v)", v, written)
+	}
+	if ttl, _ := cache.TTL("k"); ttl <= 0 {
+		t.Fatalf("expected the expiration to be kept as by Set, got %v", ttl)
+	}
+}
+
+func TestIncrementBy(t *testing.T) {
+	cache := New()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.IncrementBy("hits", 2, 0)
+		}()
+	}
+	wg.Wait()
+
+	n, err := cache.DecrementBy("hits", 50, 0)
+	if err != nil || n != 150 {
+		t.Fatalf("expected 150, got %d (%v)", n, err)
+	}
+
+	f, err := cache.IncrementFloatBy("ratio", 0.5, 0)
+	if err != nil || f != 0.5 {
+		t.Fatalf("expected 0.5, got %v (%v)", f, err)
+	}
+
+	cache.Set("s", "text", 0)
+	if _, err := cache.IncrementBy("s", 1, 0); err != ErrNotNumeric {
+		t.Fatalf("expected ErrNotNumeric, got %v", err)
+	}
+
+	strict := New(WithKeyValidator(func(string) error { return ErrInvalidKey }))
+	if n, err := strict.IncrementBy("k", 5, 0); err == nil || n != 0 {
+		t.Fatalf("expected (0, error) for a rejected key, got %d (%v)", n, err)
+	}
+	if f, err := strict.IncrementFloatBy("k", 0.5, 0); err == nil || f != 0 {
+		t.Fatalf("expected (0, error) for a rejected key, got %v (%v)", f, err)
+	}
+}
+
+func TestIncrementByKeepsExpiration(t *testing.T) {
+	cache := New()
+
+	cache.IncrementBy("window", 1, 5*time.Millisecond)
+	cache.IncrementBy("window", 1, time.Hour)
+	time.Sleep(10 * time.Millisecond)
+
+	if _, found := cache.Get("window"); found {
+		t.Fatal("expected ttl from creation to be preserved")
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	cache := New()
+
+	actual, loaded := cache.GetOrSet("a", 1, 0)
+	if loaded || actual != 1 {
+		t.Fatalf("expected store of 1, got %v (loaded=%v)", actual, loaded)
+	}
+
+	actual, loaded = cache.GetOrSet("a", 2, 0)
+	if !loaded || actual != 1 {
+		t.Fatalf("expected load of 1, got %v (loaded=%v)", actual, loaded)
+	}
+}
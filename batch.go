@@ -0,0 +1,188 @@
+package tempuscache
+
+import "time"
+
+/*
+Entry describes a single value to be written by a batch operation.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Value -> Arbitrary data to store
+TTL   -> Time-To-Live, with the same semantics as Set()
+*/
+
+type Entry struct {
+	Value interface{}
+	TTL   time.Duration
+}
+
+/*
+SetMany stores every entry of the given map under a single
+lock acquisition.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Each entry is written exactly as Set() would write it:
+
+- Existing keys are updated and moved to the front of the LRU list.
+- New keys are inserted, evicting the LRU entry when at capacity.
+
+Map iteration order is random in Go, so the relative LRU order
+of the written keys is unspecified.
+
+================================================================================
+PERFORMANCE
+================================================================================
+
+Warming thousands of keys one Set() at a time spends most of its
+time acquiring and releasing the mutex. SetMany pays that cost once.
+
+The lock is held for the entire batch; very large batches delay
+concurrent readers accordingly.
+
+TIME COMPLEXITY:
+O(n) for n entries
+*/
+
+func (c *Cache) SetMany(entries map[string]Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, e := range entries {
+		c.set(key, e.Value, e.TTL)
+	}
+}
+
+/*
+GetMany looks up several keys in one pass.
+
+================================================================================
+RETURN VALUE
+================================================================================
+
+A map containing only the keys that were present and unexpired.
+Missing and expired keys are simply absent from the result.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Every key is resolved exactly as Get() would resolve it:
+
+- Hits move to the front of the LRU list and increment Hits.
+- Missing keys increment Misses.
+- Expired keys are removed (lazy expiration) and increment Misses.
+
+The lock is acquired once for the whole batch, which removes the
+per-call locking overhead for handlers that read tens of keys
+per request.
+
+TIME COMPLEXITY:
+O(n) for n keys
+*/
+
+func (c *Cache) GetMany(keys []string) map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if val, found := c.get(key); found {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+/*
+DeleteMany removes every given key under a single lock acquisition.
+
+================================================================================
+RETURN VALUE
+================================================================================
+
+The number of live entries that were removed. Missing keys are
+ignored, and expired entries are cleaned up without being counted,
+since they were already logically gone.
+
+================================================================================
+USE CASE
+================================================================================
+
+Event-driven invalidation often delivers hundreds of keys at once.
+Removing them in one critical section avoids lock churn and makes
+the batch appear atomic to concurrent readers.
+
+TIME COMPLEXITY:
+O(n) for n keys
+*/
+
+func (c *Cache) DeleteMany(keys ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		if c.delete(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+/*
+Warm bulk-loads entries, typically before the cache is exposed
+to traffic.
+
+================================================================================
+USAGE
+================================================================================
+
+    cache.Warm(func(add func(key string, value interface{}, ttl time.Duration)) {
+        for _, k := range hotKeysColdestFirst {
+            add(k.Name, k.Value, 10*time.Minute)
+        }
+    })
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- fill is called once; every add() call is buffered.
+- The buffered entries are then written under a single lock
+  acquisition, in the order they were added.
+- Each entry goes to the LRU front, so the LAST key added becomes
+  the most recently used. Add keys from coldest to hottest.
+- If more entries are added than the capacity allows, the earliest
+  added entries are evicted first.
+
+Unlike SetMany(), which takes an unordered map, Warm gives
+deterministic LRU order.
+
+fill runs WITHOUT the cache lock held, so it may perform slow work
+(database queries, file reads) without blocking cache traffic.
+*/
+
+func (c *Cache) Warm(fill func(add func(key string, value interface{}, ttl time.Duration))) {
+	type warmEntry struct {
+		key   string
+		value interface{}
+		ttl   time.Duration
+	}
+
+	var entries []warmEntry
+	fill(func(key string, value interface{}, ttl time.Duration) {
+		entries = append(entries, warmEntry{key, value, ttl})
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range entries {
+		c.set(e.key, e.value, e.ttl)
+	}
+}
@@ -0,0 +1,79 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetMany(t *testing.T) {
+	cache := New()
+
+	cache.SetMany(map[string]Entry{
+		"a": {Value: 1},
+		"b": {Value: 2},
+	})
+
+	for key, want := range map[string]int{"a": 1, "b": 2} {
+		val, found := cache.Get(key)
+		if !found || val != want {
+			t.Fatalf("expected %s=%d, got %v", key, want, val)
+		}
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	got := cache.GetMany([]string{"a", "b", "c"})
+	if len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+		t.Fatalf("unexpected result: %v", got)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("expected 2 hits and 1 miss, got %+v", stats)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Set("c", 3, 0)
+
+	if n := cache.DeleteMany("a", "b", "missing"); n != 2 {
+		t.Fatalf("expected 2 removals, got %d", n)
+	}
+
+	got := cache.GetMany([]string{"a", "b", "c"})
+	if len(got) != 1 || got["c"] != 3 {
+		t.Fatalf("unexpected remaining entries: %v", got)
+	}
+}
+
+func TestWarmPreservesOrder(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+
+	cache.Warm(func(add func(key string, value interface{}, ttl time.Duration)) {
+		add("cold", 1, 0)
+		add("warm", 2, 0)
+		add("hot", 3, 0)
+	})
+
+	if _, found := cache.Get("cold"); found {
+		t.Fatal("expected earliest warmed key to be evicted")
+	}
+
+	// "warm" is now least recently used and must be evicted next.
+	cache.Set("new", 4, 0)
+	if _, found := cache.Get("warm"); found {
+		t.Fatal("expected LRU order to follow insertion order")
+	}
+	if _, found := cache.Get("hot"); !found {
+		t.Fatal("expected last warmed key to survive")
+	}
+}
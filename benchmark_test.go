@@ -222,3 +222,35 @@ func BenchmarkEviction(b *testing.B) {
 		cache.Set(fmt.Sprintf("key%d", i), i, 0)
 	}
 }
+
+/*
+BenchmarkGetAllocs guards the zero-allocation hit path.
+
+================================================================================
+OBJECTIVE
+================================================================================
+
+A hit must not allocate: no boxed timestamps, no event or stats
+values escaping to the heap. The benchmark fails outright if a hit
+allocates, so a regression shows up as a failure rather than as a
+number nobody reads.
+
+Run with:
+
+    go test -bench=GetAllocs -benchmem
+*/
+
+func BenchmarkGetAllocs(b *testing.B) {
+	cache := New()
+	cache.Set("key", "value", time.Hour)
+
+	if n := testing.AllocsPerRun(100, func() { cache.Get("key") }); n != 0 {
+		b.Fatalf("Get hit: %v allocs/op, want 0", n)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get("key")
+	}
+}
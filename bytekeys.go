@@ -0,0 +1,77 @@
+package tempuscache
+
+import (
+	"container/list"
+	"time"
+	"unsafe"
+)
+
+/*
+bytekeys.go implements GetBytes and SetBytes, for callers holding
+keys as []byte, e.g. parsed from network buffers.
+
+================================================================================
+WHY?
+================================================================================
+
+Converting a []byte key with string(key) copies it into a new
+allocation on every call, only for the cache to look it up and drop
+it again on a hit, or find that an identical string is already
+stored on an overwrite.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+GetBytes serves hits on the lock-free path (see readpath.go) through
+a string that aliases key, without copying it. That string is only
+compared and hashed, never retained. Everything else (misses,
+expired entries, and caches where the lock-free path is disabled)
+goes through Get with a copy of key, since those paths may keep the
+key: in loads, events, and traces.
+
+SetBytes reuses the string already stored for key when key is
+cached, so overwrites do not copy it; new keys are copied once, as
+the cache must own them.
+
+Callers may reuse key's buffer as soon as either call returns.
+*/
+
+/*
+GetBytes is Get for a []byte key, without allocating on a hit (see
+bytekeys.go).
+*/
+
+func (c *Cache) GetBytes(key []byte) (interface{}, bool) {
+	if c.chain != nil {
+		return c.Get(string(key))
+	}
+	if value, found := c.fastGet(unsafe.String(unsafe.SliceData(key), len(key))); found {
+		return value, true
+	}
+	return c.Get(string(key))
+}
+
+/*
+SetBytes is Set for a []byte key, copying key only if it is not yet
+cached (see bytekeys.go).
+*/
+
+func (c *Cache) SetBytes(key []byte, value interface{}, ttl time.Duration) {
+	c.Set(c.internKey(key), value, ttl)
+}
+
+/*
+internKey returns the string stored for key if key is cached, or a
+copy of key otherwise.
+*/
+
+func (c *Cache) internKey(key []byte) string {
+	if v, ok := c.index.Load(unsafe.String(unsafe.SliceData(key), len(key))); ok {
+		view := v.(*list.Element).Value.(*Item).view.Load()
+		if view.key == string(key) {
+			return view.key
+		}
+	}
+	return string(key)
+}
@@ -0,0 +1,37 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetBytesSetBytes(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	buf := []byte("user:42")
+	cache.SetBytes(buf, "alice", time.Hour)
+	copy(buf, "xxxx") // the caller may reuse its buffer
+	if v, ok := cache.Get("user:42"); !ok || v != "alice" {
+		t.Fatalf("expected SetBytes to copy a new key, got %v", v)
+	}
+
+	key := []byte("user:42")
+	if v, ok := cache.GetBytes(key); !ok || v != "alice" {
+		t.Fatalf("expected a hit, got %v", v)
+	}
+	if _, ok := cache.GetBytes([]byte("user:43")); ok {
+		t.Fatal("expected a miss")
+	}
+	if s := cache.Stats(); s.Hits != 2 || s.Misses != 1 {
+		t.Fatalf("expected GetBytes to be counted like Get, got %+v", s)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { cache.GetBytes(key) }); n != 0 {
+		t.Fatalf("GetBytes hit: %v allocs, want 0", n)
+	}
+	set := testing.AllocsPerRun(100, func() { cache.Set("user:42", "bob", time.Hour) })
+	if n := testing.AllocsPerRun(100, func() { cache.SetBytes(key, "bob", time.Hour) }); n > set {
+		t.Fatalf("SetBytes overwrite: %v allocs, want at most Set's %v", n, set)
+	}
+}
@@ -2,7 +2,10 @@ package tempuscache
 
 import (
 	"container/list"
+	"context"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -65,8 +68,74 @@ lru        -> Doubly linked list maintaining LRU ordering
 mu         -> Read-write mutex for concurrency control
 maxEntries -> Maximum allowed entries before LRU eviction
 interval   -> Background cleanup interval
+defaultTTL -> TTL of new entries written without one (0 = never expire)
+onEvict    -> Callback for evicted and expired entries (nil unless WithOnEvict is used)
+janitorStop -> Stops the current janitor (nil when none is running)
 stopChan   -> Graceful shutdown signal for janitor goroutine
-stats      -> Cache performance metrics (hits/misses)
+workers    -> Tracks background goroutines so Stop() can wait for them
+stopOnce   -> Makes Stop()/Close() idempotent
+counters   -> Live statistics counters, updated atomically (see stats.go)
+bytes      -> Estimated memory held by all entries
+created    -> Construction time, used to report uptime
+window     -> Per-second hit/miss ring for rolling hit ratios
+topK       -> Hot-key tracker (nil unless WithTopK is used)
+frequency  -> Access frequency sketch (nil unless WithFrequencySketch is used)
+log        -> Structured logger for background events (nil = silent)
+storm      -> Per-second eviction counter for eviction-storm warnings
+subs       -> Event subscriber channels (see Subscribe)
+stopped    -> Set by Stop(); later subscribers get a closed channel
+nodeID     -> Random identifier used as the Origin of invalidations
+inval      -> Cross-node invalidation state (nil unless WithInvalidation is used)
+restoring  -> Set while loading persisted state or backfilling a Tiered L1;
+              such changes are not re-published
+backfills  -> In-flight Tiered backfills into this cache, by key
+store      -> Read-through backing source (nil unless WithStore is used)
+loadMu     -> Protects loads
+loads      -> In-flight Store loads, shared by concurrent misses
+writeThrough -> Write-through state (nil unless WithWriteThrough is used)
+writeBehind  -> Write-behind queue (nil unless WithWriteBehind is used)
+staleWindow  -> How long expired entries may be served while reloading
+refreshAhead -> Fraction of an entry's lifetime in which reads refresh it
+earlyBeta    -> Eagerness of probabilistic early expiration (0 = off)
+fills        -> Per-key locks behind LockedGet
+ctx          -> Context whose end closes the cache (nil unless WithContext is used)
+clock        -> Source of time for deadlines, timestamps, and tickers (see clock.go)
+tracer       -> Operation trace recorder (nil unless WithTrace is used)
+index        -> Concurrent mirror of data for the lock-free hit path (see readpath.go)
+fastReads    -> Whether the configured features allow lock-free hits
+promotions   -> LRU promotions of lock-free hits, applied under the lock
+subscribers  -> Number of event subscribers, readable without the lock
+compressor   -> Value compression (nil unless WithCompression is used)
+serializer   -> Value encoding (nil unless WithSerializer is used)
+clone        -> Copy made of values on read (nil unless WithCopyOnRead is used)
+keyValidators -> Checks run on every written key (see WithKeyValidator)
+maxValueBytes  -> Largest value cached, in stored bytes (0 = no limit)
+oversizePolicy -> What happens to larger values (see oversize.go)
+compressedBytes   -> Stored size of the values kept compressed
+uncompressedBytes -> Original size of the values kept compressed
+pinned            -> Number of pinned entries (see pin.go)
+priorities        -> Number of unpinned entries per Priority (see priority.go)
+cost              -> Total cost of the entries (see cost.go)
+maxCost           -> Largest total cost kept (0 = no limit; see WithMaxCost)
+evictionSamples   -> Entries sampled per eviction (0 = strict LRU; see sampled.go)
+doorkeeper        -> Admission filter for new keys (nil unless WithDoorkeeper is used)
+ghosts            -> Recently evicted key hashes (nil unless WithGhostHistory is used)
+expiry            -> Entries by deadline for the janitor (nil unless WithTTLBuckets is used)
+interceptors      -> Middleware around Get, Set, and Delete (see interceptor.go)
+chain             -> The interceptors composed, built by configure (nil without any)
+hasher            -> Key hash of the internal indexes (nil = hashKey; see hash.go)
+frozen            -> FreezePolicy while read-only, 0 otherwise (see freeze.go)
+opts              -> Options given to New, replayed by Clone (see fork.go)
+
+codec            -> Snapshot serialization format (nil = gob)
+snapshotPath     -> Destination file for automatic snapshots
+snapshotInterval -> Frequency of automatic snapshots
+snapshotLoad     -> Whether New loads snapshotPath (set by WithSnapshotPath)
+aofPath          -> Append-only log file configured via WithAOF
+aof              -> Append-only log (nil when AOF persistence is disabled)
+aofRewriteSize   -> Log size that triggers background compaction (0 = never)
+aofWorkers       -> Tracks background log rewrites so Stop() can wait for them
+expvarName       -> expvar name under which stats are published
 
 The design prioritizes:
 - Predictable performance
@@ -75,14 +144,81 @@ The design prioritizes:
 */
 
 type Cache struct {
-	data       map[string]*list.Element
-	lru        *list.List //where each element stores an Item.
-	mu         sync.RWMutex
-	maxEntries int
-	interval   time.Duration
-	stopChan   chan struct{}
-	stats      Stats
+	data         map[string]*list.Element
+	lru          *list.List //where each element stores an Item.
+	mu           sync.RWMutex
+	maxEntries   int
+	interval     time.Duration
+	defaultTTL   time.Duration
+	onEvict      func(key string, value interface{}, reason EventType)
+	janitorStop  chan struct{}
+	stopChan     chan struct{}
+	workers      sync.WaitGroup
+	stopOnce     sync.Once
+	counters     counters
+	bytes        int64
+	created      time.Time
+	window       hitWindow
+	topK         *topKTracker
+	frequency    *frequencySketch
+	log          *slog.Logger
+	storm        evictionStorm
+	subs         []chan Event
+	stopped      bool
+	nodeID       string
+	inval        *invalidator
+	restoring    bool
+	backfills    map[string][]*backfill
+	store        Store
+	loadMu       sync.Mutex
+	loads        map[string]*loadCall
+	writeThrough *writeThrough
+	writeBehind  *writeBehind
+	staleWindow  time.Duration
+	refreshAhead float64
+	earlyBeta    float64
+	fills        KeyLock
+	ctx          context.Context
+	clock        Clock
+	tracer       *tracer
+	index        sync.Map
+	fastReads    bool
+	promotions   chan *list.Element
+	subscribers  atomic.Int32
+	compressor   Compressor
+	serializer   Serializer
+	clone        func(interface{}) interface{}
+
+	keyValidators  []func(key string) error
+	maxValueBytes  int64
+	oversizePolicy OversizePolicy
+
+	compressedBytes   int64
+	uncompressedBytes int64
+	pinned            int
+	priorities        [numPriorities]int
+	cost              int64
+	maxCost           int64
+	evictionSamples   int
+	doorkeeper        *doorkeeper
+	ghosts            *ghostList
+	expiry            *expiryBuckets
+	interceptors      []Interceptor
+	chain             Invoker
+	hasher            func(key string) uint64
+	frozen            atomic.Int32
+	opts              []Option
 	// graceful shutdown pattern, and struct{} uses zero memory.
+
+	codec            Codec
+	snapshotPath     string
+	snapshotInterval time.Duration
+	snapshotLoad     bool
+	aofPath          string
+	aof              *appendLog
+	aofRewriteSize   int64
+	aofWorkers       sync.WaitGroup
+	expvarName       string
 }
 
 /*
@@ -97,7 +233,16 @@ INITIALIZATION STEPS:
 2. Initialize LRU list.
 3. Create stop channel for graceful shutdown.
 4. Apply user-provided options.
-5. Start background janitor (if cleanup interval is set).
+5. Log suspicious configuration (see WithLogger), and enable the
+   lock-free hit path if the configuration allows it.
+6. Load the snapshot (if configured by WithSnapshotPath).
+7. Open the append-only log (if configured).
+8. Publish expvar statistics (if configured).
+9. Start background janitor (if cleanup interval is set).
+10. Start auto-snapshot worker (if configured).
+11. Start cross-node invalidation (if configured).
+12. Start the write-behind worker (if configured).
+13. Close the cache when its context ends (if configured).
 
 If no cleanup interval is configured, the janitor will not run.
 
@@ -105,19 +250,92 @@ This pattern ensures forward compatibility and API stability.
 */
 
 func New(opts ...Option) *Cache {
+	c := configure(opts)
+	c.validateConfig()
+	c.initReadPath()
+
+	if err := c.loadSnapshot(); err != nil {
+		c.logger().Error("tempuscache: snapshot not loaded", "path", c.snapshotPath, "err", err)
+	}
+	if c.aofPath != "" {
+		if err := c.OpenAOF(c.aofPath); err != nil {
+			c.logger().Error("tempuscache: append-only log disabled", "path", c.aofPath, "err", err)
+		}
+	}
+
+	c.start()
+	return c
+}
+
+/*
+NewWithError is New for callers that want a bad configuration to
+fail construction rather than be logged and worked around.
+
+ERRORS:
+- ErrInvalidConfig (wrapped, listing every problem) for any
+  configuration New would log a warning about: negative capacity or
+  cleanup interval, options missing their prerequisite (WithStore,
+  WithAOF, a WritableStore, ...), conflicting policies, or a
+  WithExpvar name already published.
+- The error of loading the snapshot configured by WithSnapshotPath,
+  unless the file does not exist.
+- The error of opening the append-only log configured by WithAOF.
+
+On error no cache is returned and no background worker is started.
+*/
+
+func NewWithError(opts ...Option) (*Cache, error) {
+	c := configure(opts)
+	if err := c.checkConfig(); err != nil {
+		return nil, err
+	}
+	c.initReadPath()
+
+	if err := c.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if c.aofPath != "" {
+		if err := c.OpenAOF(c.aofPath); err != nil {
+			return nil, err
+		}
+	}
+
+	c.start()
+	return c, nil
+}
+
+// configure allocates a cache and applies opts (steps 1-4 of New).
+func configure(opts []Option) *Cache {
 	c := &Cache{
 		data:     make(map[string]*list.Element),
 		lru:      list.New(),
 		stopChan: make(chan struct{}),
+		nodeID:   newNodeID(),
+		clock:    SystemClock,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.opts = opts
+	c.buildChain()
+	c.created = c.clock.Now()
+	return c
+}
 
-	c.startJanitor()
+// start publishes expvar statistics and starts every configured
+// background worker (steps 8-13 of New).
+func (c *Cache) start() {
+	if c.expvarName != "" {
+		// A name already taken is reported by configIssues.
+		c.PublishExpvar(c.expvarName)
+	}
 
-	return c
+	c.startJanitor()
+	c.startAutoSnapshot()
+	c.startInvalidation()
+	c.startWriteBehind()
+	c.watchContext()
 }
 
 /*
@@ -132,15 +350,29 @@ BEHAVIOR:
 
 1. If key already exists:
    - Update its value.
-   - Recalculate expiration (if ttl > 0).
+   - Recalculate expiration (if ttl > 0), drop it (if ttl is
+     NoExpiration), or keep the current one (otherwise).
    - Move item to front of LRU list.
 
 2. If key does not exist:
    - If maxEntries limit is reached → evict oldest entry (LRU policy).
-   - Create new Item with optional expiration timestamp.
+   - Create new Item with optional expiration timestamp (the
+     WithDefaultTTL one, if any, when ttl <= 0 and ttl is not
+     NoExpiration).
    - Insert at front of LRU list.
    - Store reference in map.
 
+KEY AND VALUE LIMITS:
+With WithKeyValidator and WithMaxValueBytes, writes of invalid keys
+and oversized values are dropped and counted in Stats().Rejected;
+use SetContext to receive the error.
+
+WRITE-THROUGH:
+With WithWriteThrough, Set also persists to the Store (see
+writethrough.go). Save failures are logged; use SetContext to
+receive them. With WithWriteBehind, the value is queued and saved
+in the background (see writebehind.go).
+
 TTL IMPLEMENTATION:
 Expiration time is stored as UnixNano (int64) for:
 - Fast numeric comparison
@@ -153,36 +385,193 @@ This operation is fully protected by exclusive locking to ensure consistency.
 */
 
 func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.chain != nil {
+		c.chain(Op{Kind: OpSet, Key: key, Value: value, TTL: ttl})
+		return
+	}
+	c.setValue(key, value, ttl)
+}
 
-	if elem, found := c.data[key]; found {
-		item := elem.Value.(*Item)
-		item.value = value
-		if ttl > 0 {
-			item.expiration = time.Now().Add(ttl).UnixNano()
+// setValue is Set without interceptors (see interceptor.go).
+func (c *Cache) setValue(key string, value interface{}, ttl time.Duration) {
+	if c.writeThrough != nil || c.writeBehind != nil {
+		if err := c.SetContext(context.Background(), key, value, ttl); err != nil {
+			c.logger().Warn("tempuscache: store save failed", "key", key, "err", err)
 		}
-		c.lru.MoveToFront(elem)
 		return
 	}
 
-	if c.maxEntries > 0 && c.lru.Len() >= c.maxEntries {
-		c.evictOldest()
-	}
+	c.setLocal(key, value, ttl)
+}
+
+/*
+set is the unlocked core of Set().
 
+It is shared by every write path (Set, Update, batch writes, ...)
+so insertion, TTL calculation, and capacity eviction behave
+identically everywhere.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) set(key string, value interface{}, ttl time.Duration) error {
 	var exp int64
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixNano()
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	} else if ttl == NoExpiration {
+		exp = 0
+	} else if elem, found := c.data[key]; found {
+		exp = elem.Value.(*Item).expiration
+	} else if c.defaultTTL > 0 {
+		exp = c.clock.Now().Add(c.defaultTTL).UnixNano()
+	}
+	return c.put(key, value, exp)
+}
+
+/*
+put stores value under key with an absolute expiration deadline
+(UnixNano, 0 = never).
+
+It is the single point through which every entry is created or
+modified, which keeps key validation, LRU promotion, capacity
+eviction, and the append-only log consistent across all write paths.
+
+Writes refused by a key validator or WithMaxValueBytes return
+their error (see keys.go and oversize.go).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) put(key string, value interface{}, exp int64) error {
+	return c.write(key, value, exp, true)
+}
+
+/*
+write is put, with publish controlling whether the change is queued
+as an invalidation for other nodes. Writes that merely mirror a
+shared source, such as caching a Store load, are not published.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) write(key string, value interface{}, exp int64, publish bool) error {
+	if c.Frozen() {
+		return c.frozenWrite()
+	}
+	if err := c.admit(key); err != nil {
+		return err
+	}
+
+	stored := c.pack(value)
+	if c.maxValueBytes > 0 {
+		if n := valueSize(stored); n > c.maxValueBytes {
+			return c.oversize(key, n)
+		}
+	}
+	if c.doorkeeper != nil {
+		if _, found := c.data[key]; !found && !c.admitNew(key) {
+			return nil
+		}
+	}
+	size := estimateSize(key, stored)
+	now := c.now()
+	c.applyPromotions()
+	c.trackCompressed(stored, 1)
+
+	elem, found := c.data[key]
+	if found {
+		item := elem.Value.(*Item)
+		c.bytes += size - item.size
+		c.trackCompressed(item.value, -1)
+		item.value = stored
+		item.expiration = exp
+		item.size = size
+		item.written = now
+		c.publish(item)
+		c.schedule(item)
+		c.lru.MoveToFront(elem)
+	} else {
+		if c.maxEntries > 0 && c.lru.Len() >= c.maxEntries {
+			elem = c.evictOldest()
+		}
+
+		if elem != nil {
+			// Reuse the evicted entry in place (see pool.go).
+			item := elem.Value.(*Item)
+			item.reset(key, stored, exp, size, now)
+			c.publish(item)
+			c.lru.MoveToFront(elem)
+		} else {
+			item := newItem(key, stored, exp, size, now)
+			c.publish(item)
+			elem = c.lru.PushFront(item)
+		}
+		c.data[key] = elem
+		c.index.Store(key, elem)
+		c.schedule(elem.Value.(*Item))
+		c.bytes += size
+		c.priorities[PriorityNormal.slot()]++
+		c.cost++
 	}
 
-	item := &Item{
-		key:        key,
-		value:      value,
-		expiration: exp,
+	c.counters.sets.Add(1)
+	c.aofAppend(aofOpSet, key, value, exp)
+	if publish {
+		c.invalidate(key, false)
 	}
+	c.emit(EventSet, key)
+	c.shed()
+	return nil
+}
+
+/*
+Replace updates a key only if it is already present in the cache.
+
+RETURNS:
+- true  -> The key existed, was unexpired, and has been updated
+- false -> The key was missing or expired; nothing was stored
+
+BEHAVIOR:
+
+1. If key does not exist:
+   - Return false without inserting.
+
+2. If key exists but is expired:
+   - Remove it (lazy expiration).
+   - Return false without inserting.
 
-	elem := c.lru.PushFront(item)
-	c.data[key] = elem
+3. If key exists and is valid:
+   - Update its value.
+   - Recalculate expiration (if ttl > 0).
+   - Move item to front of LRU list, exactly as Set() does.
+
+WHY THIS MATTERS:
+A Get-then-Set sequence in caller code can resurrect a key that another
+goroutine invalidated in between. Replace performs the existence check
+and the update under a single exclusive lock.
+
+TIME COMPLEXITY:
+O(1) average case
+*/
+
+func (c *Cache) Replace(key string, value interface{}, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+
+	if c.expired(elem.Value.(*Item)) {
+		c.expireElement(elem, false)
+		return false
+	}
+
+	return c.set(key, value, ttl) == nil
 }
 
 /*
@@ -215,43 +604,175 @@ LRU UPDATE:
 Every successful access updates recency ordering,
 ensuring accurate eviction decisions.
 
+READ-THROUGH:
+With WithStore, a miss loads the value from the Store (see
+store.go). Load failures are logged and reported as a miss; use
+GetContext to receive them. With WithStaleWhileRevalidate, recently
+expired entries are served while being reloaded (see stale.go);
+with WithRefreshAhead, entries close to expiry are reloaded before
+they expire (see refresh.go).
+
 TIME COMPLEXITY:
 O(1) average case
 
-This method acquires exclusive Lock() because it may:
+ALLOCATIONS:
+A hit performs no heap allocations, on either path below
+(guarded by TestGetHitAllocs).
+
+Hits are normally served without the lock (see readpath.go).
+Otherwise this method acquires exclusive Lock() because it may:
 - Modify LRU ordering
 - Remove expired entries
 - Update statistics
 */
 
 func (c *Cache) Get(key string) (interface{}, bool) {
+	if c.chain != nil {
+		return c.chain(Op{Kind: OpGet, Key: key})
+	}
+	return c.getValue(key)
+}
+
+// getValue is Get without interceptors (see interceptor.go).
+func (c *Cache) getValue(key string) (interface{}, bool) {
+	if value, found := c.fastGet(key); found {
+		return value, true
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	value, found, refresh := c.lookup(key)
+	c.mu.Unlock()
+
+	if refresh {
+		c.revalidate(key)
+	}
+	if found || c.store == nil {
+		return value, found
+	}
+
+	value, found, err := c.load(context.Background(), key)
+	if err != nil {
+		c.logger().Warn("tempuscache: store load failed", "key", key, "err", err)
+	}
+	return value, found
+}
+
+/*
+GetE is Get with the reason for a miss reported as an error, for
+callers that handle or meter the cases differently.
+
+RETURNS:
+- (value, nil)       -> Cache hit, or successfully loaded
+- (nil, ErrNotFound) -> Key is not in the cache (nor in the Store)
+- (nil, ErrExpired)  -> Key was in the cache but its TTL had elapsed;
+                        it is removed, as by Get
+- (nil, ErrClosed)   -> The cache has been stopped
+- (nil, err)         -> The Store configured with WithStore failed
+
+Statistics, events, and read-through behave exactly as for Get.
+*/
+
+func (c *Cache) GetE(key string) (interface{}, error) {
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	if value, found := c.fastGet(key); found {
+		return value, nil
+	}
+
+	c.mu.Lock()
+	elem, expired := c.data[key]
+	expired = expired && c.expired(elem.Value.(*Item))
+	value, found, refresh := c.lookup(key)
+	c.mu.Unlock()
+
+	if refresh {
+		c.revalidate(key)
+	}
+	if found {
+		return value, nil
+	}
+	if c.store != nil {
+		value, found, err := c.load(context.Background(), key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return value, nil
+		}
+	}
+	if expired {
+		return nil, ErrExpired
+	}
+	return nil, ErrNotFound
+}
+
+// closed reports whether Stop has been called.
+func (c *Cache) closed() bool {
+	select {
+	case <-c.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+get is the unlocked core of Get(), shared with batch reads.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) get(key string) (interface{}, bool) {
+	c.observe(key)
 
 	elem, found := c.data[key]
 	if !found {
-		c.stats.Misses++
+		c.missedGhost(key)
+		c.recordLookup(false)
+		c.emit(EventMiss, key)
 		return nil, false
 	}
 
 	item := elem.Value.(*Item)
 
-	if item.Expired() {
-		c.removeElement(elem)
-		c.stats.Misses++
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		c.recordLookup(false)
+		c.emit(EventMiss, key)
 		return nil, false
 	}
 
-	c.lru.MoveToFront(elem)
-	c.stats.Hits++
-	return item.value, true
+	c.hit(elem)
+	return c.read(item.value), true
+}
+
+/*
+hit records a successful read of elem: LRU promotion, per-entry
+access metadata, statistics, and the EventHit event.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) hit(elem *list.Element) {
+	item := elem.Value.(*Item)
+	if c.evictionSamples == 0 && !c.Frozen() {
+		c.applyPromotions()
+		c.lru.MoveToFront(elem)
+	}
+	item.hits.Add(1)
+	item.accessed.Store(c.now())
+	c.recordLookup(true)
+	c.emit(EventHit, item.key)
 }
 
 /*
 Delete removes a key from the cache.
 
 BEHAVIOR:
-- If key exists → remove from map.
+- If key exists → remove from map and LRU list.
 - If key does not exist → operation is safely ignored.
 
 This operation does not panic on missing keys.
@@ -264,15 +785,283 @@ O(1) average case
 */
 
 func (c *Cache) Delete(key string) {
+	if c.chain != nil {
+		c.chain(Op{Kind: OpDelete, Key: key})
+		return
+	}
+	c.deleteKey(key)
+}
+
+// deleteKey is Delete without interceptors (see interceptor.go).
+func (c *Cache) deleteKey(key string) bool {
 	c.mu.Lock()
-	delete(c.data, key)
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+	return c.delete(key)
 }
 
+/*
+DeleteContext is Delete that does nothing and returns ctx.Err() if
+ctx has already ended. It completes the context-aware API (see
+GetContext and SetContext), so *Cache can serve as a ContextTier.
+*/
+
+func (c *Cache) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Delete(key)
+	return nil
+}
+
+/*
+delete is the unlocked core of Delete(), shared with batch removal.
+
+The element is removed from both the map and the LRU list via
+removeElement(), so no orphaned list node is left behind that a
+later eviction could mistake for a live entry.
+
+RETURNS:
+true if a live (unexpired) entry was removed.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) delete(key string) bool {
+	if c.Frozen() {
+		return false
+	}
+	c.supersede(key)
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+	live := !c.expired(elem.Value.(*Item))
+	if live {
+		c.removeElement(elem)
+		c.counters.deletes.Add(1)
+		c.emit(EventDeleted, key)
+	} else {
+		c.expireElement(elem, false)
+	}
+	c.aofAppend(aofOpDelete, key, nil, 0)
+	c.invalidate(key, false)
+	return live
+}
+
+/*
+Flush removes every entry from the cache.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- All entries are dropped in O(1); the old map and list are left
+  to the garbage collector.
+- Removed entries are not counted as Deletes, Evictions, or
+  Expirations; subscribers receive a single EventFlushed instead.
+- The flush is recorded in the append-only log (if enabled), so a
+  replay does not resurrect the flushed entries.
+- Counters and configuration are untouched.
+*/
+
+func (c *Cache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Frozen() {
+		return
+	}
+	c.flush()
+	c.aofAppend(aofOpFlush, "", nil, 0)
+	c.invalidate("", true)
+	c.emit(EventFlushed, "")
+}
+
+/*
+flush is the unlocked core of Flush(), shared with log replay.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) flush() {
+	c.supersedeAll()
+	c.data = make(map[string]*list.Element)
+	c.index.Clear()
+	c.lru.Init()
+	c.bytes = 0
+	c.compressedBytes = 0
+	c.uncompressedBytes = 0
+	c.pinned = 0
+	c.priorities = [numPriorities]int{}
+	c.cost = 0
+	if c.expiry != nil {
+		c.expiry.reset()
+	}
+}
+
+/*
+Stats returns a point-in-time copy of the cache's statistics.
+
+Counters are cumulative since construction. Entries, EstimatedBytes,
+and Uptime are computed at the moment of the call.
+*/
+
 func (c *Cache) Stats() Stats {
+	s := c.counters.snapshot(false)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s.Entries = c.lru.Len()
+	s.Pinned = c.pinned
+	s.Cost = c.cost
+	s.EstimatedBytes = c.bytes
+	s.CompressedBytes = c.compressedBytes
+	s.UncompressedBytes = c.uncompressedBytes
+	s.Uptime = c.clock.Now().Sub(c.created)
+	if c.topK != nil {
+		s.HotKeys = c.topK.top()
+	}
+	if c.writeBehind != nil {
+		s.WriteBehindPending = c.writeBehind.queued()
+	}
+	return s
+}
+
+/*
+ResetStats zeroes all counters and returns the snapshot taken
+immediately before the reset.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Hits, Misses, Sets, Deletes, Evictions, and Expirations restart
+  from zero.
+- Gauges (Entries, EstimatedBytes, Uptime) reflect cache state and
+  are not affected.
+- Each counter is read and zeroed in one atomic step, so no event
+  is counted in neither or both windows.
+
+================================================================================
+USE CASE
+================================================================================
+
+Polling agents can call ResetStats() on every scrape and treat the
+result as the delta since the previous scrape, without tracking
+previous values themselves.
+
+Note that exporters relying on cumulative counters (Prometheus,
+OpenTelemetry, StatsD) will observe the reset as a counter restart.
+*/
+
+func (c *Cache) ResetStats() Stats {
+	s := c.counters.snapshot(true)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.stats
+
+	s.Entries = c.lru.Len()
+	s.Pinned = c.pinned
+	s.Cost = c.cost
+	s.EstimatedBytes = c.bytes
+	s.CompressedBytes = c.compressedBytes
+	s.UncompressedBytes = c.uncompressedBytes
+	s.Uptime = c.clock.Now().Sub(c.created)
+	return s
+}
+
+/*
+Len returns the number of entries currently stored.
+
+Entries that have expired but have not yet been removed by lazy
+expiration or the janitor are included in the count.
+*/
+
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lru.Len()
+}
+
+/*
+Keys returns the keys of all unexpired entries, ordered from the
+most to the least recently used (written, with WithSampledEviction).
+
+The result is a snapshot: keys added or removed afterwards are not
+reflected. Keys does not count as a lookup and does not affect LRU
+order.
+
+TIME COMPLEXITY:
+O(n)
+*/
+
+func (c *Cache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.applyPromotions()
+	keys := make([]string, 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		item := elem.Value.(*Item)
+		if !c.expired(item) {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys
+}
+
+/*
+Capacity returns the configured maximum number of entries.
+A value <= 0 means the cache is unbounded.
+*/
+
+func (c *Cache) Capacity() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxEntries
+}
+
+/*
+Resize changes the maximum number of entries of a running cache.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- n <= 0 makes the cache unbounded.
+- Growing, or shrinking to a limit the cache is already within,
+  only changes the limit.
+- Shrinking below the current size evicts least recently used
+  entries until the cache fits, exactly as Set would: each counts
+  in Stats().Evictions and emits EventEvicted. Pinned entries are
+  kept, even if they alone exceed n (see pin.go).
+
+RETURNS:
+The number of entries evicted.
+
+TIME COMPLEXITY:
+O(k) for k evicted entries, under the exclusive lock.
+*/
+
+func (c *Cache) Resize(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = n
+	evicted := 0
+	for n > 0 && c.lru.Len() > n {
+		elem := c.evictOldest()
+		if elem == nil {
+			break
+		}
+		c.lru.Remove(elem)
+		recycle(elem)
+		evicted++
+	}
+	return evicted
 }
 
 /*
@@ -284,29 +1073,41 @@ This method is invoked by the background janitor at configured intervals.
 ALGORITHM:
 - Iterate from the back (oldest entries).
 - Check expiration status.
-- Remove expired elements using removeElement().
+- Remove expired elements using expireElement().
 
 TIME COMPLEXITY:
-O(n) — full scan of entries.
+O(n) — full scan of entries; with WithTTLBuckets, only due buckets
+are visited instead (see expiry.go).
 
 CONCURRENCY:
 Acquires exclusive Lock() since it mutates internal structures.
 
+RETURNS:
+The number of entries removed.
+
 DESIGN RATIONALE:
 Active expiration prevents memory accumulation from expired keys
 that are not accessed frequently enough to trigger lazy deletion.
 */
 
-func (c *Cache) deleteExpired() {
+func (c *Cache) deleteExpired() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.Frozen() {
+		return 0
+	}
+	if c.expiry != nil {
+		return c.expireBuckets()
+	}
+	removed := 0
 	for elem := c.lru.Back(); elem != nil; {
 		prev := elem.Prev()
-		item := elem.Value.(*Item)
-		if item.Expired() {
-			c.removeElement(elem)
+		if c.pastStale(elem.Value.(*Item)) {
+			c.expireElement(elem, true)
+			removed++
 		}
 		elem = prev
 	}
+	return removed
 }
@@ -1,6 +1,9 @@
 package tempuscache
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -99,6 +102,20 @@ func TestNoExpiration(t *testing.T) {
 	}
 }
 
+func TestSetNoExpirationClearsTTL(t *testing.T) {
+	cache := New(WithDefaultTTL(time.Minute))
+
+	cache.Set("a", "b", time.Hour)
+	cache.Set("a", "c", NoExpiration)
+	cache.Set("n", "d", NoExpiration)
+
+	for _, key := range []string{"a", "n"} {
+		if ttl, found := cache.TTL(key); !found || ttl != 0 {
+			t.Fatalf("expected %q to never expire, got %v %v", key, ttl, found)
+		}
+	}
+}
+
 func TestDelete(t *testing.T) {
 	cache := New()
 
@@ -202,3 +219,302 @@ func TestStatsTracking(t *testing.T) {
 		t.Fatalf("expected 1 miss, got %d", stats.Misses)
 	}
 }
+
+func TestReplace(t *testing.T) {
+	cache := New()
+
+	if cache.Replace("a", "b", 0) {
+		t.Fatal("expected replace of missing key to fail")
+	}
+	if _, found := cache.Get("a"); found {
+		t.Fatal("expected replace not to insert missing key")
+	}
+
+	cache.Set("a", "b", 0)
+	if !cache.Replace("a", "c", 0) {
+		t.Fatal("expected replace of existing key to succeed")
+	}
+
+	val, found := cache.Get("a")
+	if !found || val != "c" {
+		t.Fatalf("expected 'c', got %v", val)
+	}
+
+	cache.Set("x", 1, 1*time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if cache.Replace("x", 2, 0) {
+		t.Fatal("expected replace of expired key to fail")
+	}
+}
+
+/*
+TestDeleteThenEvict guards against Delete leaving an orphaned node
+in the LRU list: a later eviction of that node must not remove a
+re-inserted entry with the same key.
+*/
+
+func TestDeleteThenEvict(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+
+	cache.Set("a", 1, 0)
+	cache.Delete("a")
+	cache.Set("b", 2, 0)
+	cache.Set("a", 3, 0)
+	cache.Set("c", 4, 0)
+
+	if _, found := cache.Get("b"); found {
+		t.Fatal("expected 'b' to be evicted as least recently used")
+	}
+	if val, found := cache.Get("a"); !found || val != 3 {
+		t.Fatalf("expected re-inserted 'a' to survive, got %v", val)
+	}
+}
+
+func TestExpirationStats(t *testing.T) {
+	cache := New(WithMaxEntries(10))
+
+	cache.Set("a", 1, time.Millisecond)
+	cache.Set("b", 2, 0)
+	time.Sleep(2 * time.Millisecond)
+
+	if n := cache.Len(); n != 2 {
+		t.Fatalf("expected 2 stored entries, got %d", n)
+	}
+
+	cache.Get("a")
+
+	if s := cache.Stats(); s.Expirations != 1 || s.Misses != 1 {
+		t.Fatalf("expected 1 expiration and 1 miss, got %+v", s)
+	}
+	if n := cache.Len(); n != 1 {
+		t.Fatalf("expected 1 stored entry, got %d", n)
+	}
+	if c := cache.Capacity(); c != 10 {
+		t.Fatalf("expected capacity 10, got %d", c)
+	}
+}
+
+func TestRicherStats(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", "12345", 0)
+	cache.Set("a", "1234567890", 0)
+	cache.Set("b", []byte("xyz"), 0)
+	cache.Delete("b")
+	cache.Delete("missing")
+
+	s := cache.Stats()
+	if s.Sets != 3 || s.Deletes != 1 || s.Entries != 1 {
+		t.Fatalf("unexpected counters: %+v", s)
+	}
+	if want := int64(entryOverhead + len("a") + 10); s.EstimatedBytes != want {
+		t.Fatalf("expected %d estimated bytes, got %d", want, s.EstimatedBytes)
+	}
+	if s.Uptime <= 0 {
+		t.Fatal("expected positive uptime")
+	}
+}
+
+func TestResetStats(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	cache.Get("b")
+
+	prev := cache.ResetStats()
+	if prev.Hits != 1 || prev.Misses != 1 || prev.Sets != 1 {
+		t.Fatalf("expected pre-reset snapshot, got %+v", prev)
+	}
+
+	cache.Get("a")
+
+	s := cache.Stats()
+	if s.Hits != 1 || s.Misses != 0 || s.Sets != 0 {
+		t.Fatalf("expected counters to restart from zero, got %+v", s)
+	}
+	if s.Entries != 1 {
+		t.Fatalf("expected gauges to be unaffected, got %+v", s)
+	}
+}
+
+func TestResetStatsConcurrent(t *testing.T) {
+	cache := New()
+	cache.Set("a", 1, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				cache.Get("a")
+			}
+		}()
+	}
+
+	// Resets racing with lookups must neither lose nor double-count hits.
+	var total uint64
+	for i := 0; i < 50; i++ {
+		total += cache.ResetStats().Hits
+	}
+	wg.Wait()
+	total += cache.ResetStats().Hits
+
+	if total != 4000 {
+		t.Fatalf("expected 4000 hits across all snapshots, got %d", total)
+	}
+}
+
+func TestRemovalBreakdown(t *testing.T) {
+	cache := New(WithMaxEntries(3))
+
+	cache.Set("lazy", 1, time.Millisecond)
+	cache.Set("janitor", 2, time.Millisecond)
+	cache.Set("deleted", 3, 0)
+	time.Sleep(2 * time.Millisecond)
+
+	cache.Get("lazy")
+	cache.deleteExpired()
+	cache.Delete("deleted")
+
+	for i := 0; i < 4; i++ {
+		cache.Set(string(rune('a'+i)), i, 0)
+	}
+
+	s := cache.Stats()
+	if s.ExpiredOnAccess != 1 || s.ExpiredByJanitor != 1 || s.Expirations != 2 {
+		t.Fatalf("unexpected expiration breakdown: %+v", s)
+	}
+	if s.Deletes != 1 || s.Evictions != 1 {
+		t.Fatalf("unexpected delete/eviction counts: %+v", s)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Set("stale", 3, time.Millisecond)
+	cache.Set("c", 4, 0)
+	time.Sleep(2 * time.Millisecond)
+	cache.Get("a")
+
+	keys := cache.Keys()
+	want := []string{"a", "c", "b"}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %v, got %v", want, keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, keys)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := New(WithMaxEntries(5))
+	defer cache.Stop()
+
+	events, cancel := cache.Subscribe()
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+	cache.Get("0")
+
+	if n := cache.Resize(3); n != 2 {
+		t.Fatalf("expected 2 evictions, got %d", n)
+	}
+	if cache.Len() != 3 || cache.Capacity() != 3 {
+		t.Fatalf("expected 3 entries and capacity 3, got %d and %d", cache.Len(), cache.Capacity())
+	}
+	for _, key := range []string{"1", "2"} {
+		if _, ok := cache.Get(key); ok {
+			t.Fatalf("expected least recently used %s to be evicted", key)
+		}
+	}
+	if s := cache.Stats(); s.Evictions != 2 {
+		t.Fatalf("expected 2 evictions in stats, got %d", s.Evictions)
+	}
+
+	evictedEvents := 0
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventEvicted {
+			evictedEvents++
+		}
+	}
+	if evictedEvents != 2 {
+		t.Fatalf("expected 2 evicted events, got %d", evictedEvents)
+	}
+
+	// The new limit applies to later writes.
+	cache.Set("5", 5, 0)
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 entries after Set, got %d", cache.Len())
+	}
+
+	if n := cache.Resize(0); n != 0 {
+		t.Fatalf("expected no evictions when unbounding, got %d", n)
+	}
+	for i := 10; i < 20; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+	if cache.Len() != 13 {
+		t.Fatalf("expected unbounded cache to hold 13 entries, got %d", cache.Len())
+	}
+}
+
+func TestGetE(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock))
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, time.Second)
+	clock.advance(2 * time.Second)
+
+	if v, err := cache.GetE("a"); err != nil || v != 1 {
+		t.Fatalf("expected a hit, got %v %v", v, err)
+	}
+	if _, err := cache.GetE("b"); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+	// The expired entry was removed, so it is now simply missing.
+	if _, err := cache.GetE("b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if s := cache.Stats(); s.Hits != 1 || s.Misses != 2 || s.Expirations != 1 {
+		t.Fatalf("expected GetE to count like Get, got %+v", s)
+	}
+
+	cache.Stop()
+	if _, err := cache.GetE("a"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestGetEStore(t *testing.T) {
+	errDown := errors.New("database down")
+	cache := New(WithStore(funcStore(func(_ context.Context, key string) (interface{}, time.Duration, error) {
+		switch key {
+		case "missing":
+			return nil, 0, ErrNotFound
+		case "broken":
+			return nil, 0, errDown
+		}
+		return "loaded:" + key, time.Minute, nil
+	})))
+	defer cache.Stop()
+
+	if v, err := cache.GetE("x"); err != nil || v != "loaded:x" {
+		t.Fatalf("expected a load, got %v %v", v, err)
+	}
+	if _, err := cache.GetE("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := cache.GetE("broken"); !errors.Is(err, errDown) {
+		t.Fatalf("expected the store error, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package tempuscache
+
+import "time"
+
+/*
+clock.go abstracts the passage of time.
+
+================================================================================
+WHY?
+================================================================================
+
+Expiration, the janitor, snapshots, and write-behind all depend on
+time. Testing them against the wall clock means real sleeps: slow
+suites that still flake on a loaded machine. With WithClock, a test
+(or a simulation) supplies a Clock it controls instead, and moves
+time forward explicitly.
+
+================================================================================
+SCOPE
+================================================================================
+
+Every deadline and timestamp the cache keeps goes through its Clock:
+TTLs and expiration, stale windows, refresh-ahead, access times,
+event times, hit-ratio windows, uptime, and the tickers and retry
+delays of the background workers.
+
+Durations that measure work rather than schedule it (janitor sweep
+and snapshot timings in logs, Store load costs used by early
+expiration) are taken from the wall clock.
+*/
+
+/*
+Clock is the source of time used by a Cache.
+
+Now returns the current time. NewTicker and After behave like their
+counterparts in package time, measured on this Clock.
+*/
+
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+/*
+Ticker is a ticker created by a Clock: C delivers ticks, Stop turns
+it off, as with time.Ticker.
+*/
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the wall clock, the default Clock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+// now returns the cache's current time in UnixNano.
+func (c *Cache) now() int64 {
+	return c.clock.Now().UnixNano()
+}
+
+// expired reports whether item has expired on the cache's clock.
+func (c *Cache) expired(item *Item) bool {
+	return item.expiredAt(c.now())
+}
@@ -0,0 +1,85 @@
+package tempuscache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a Clock that only moves when told to. Its tickers
+// fire when tick is called.
+type manualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []chan time.Time
+}
+
+func (m *manualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+func (m *manualClock) NewTicker(time.Duration) Ticker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan time.Time)
+	m.tickers = append(m.tickers, ch)
+	return manualTicker(ch)
+}
+
+func (m *manualClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- m.Now()
+	return ch
+}
+
+func (m *manualClock) advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// tick fires every ticker once, waiting for its worker to receive it.
+func (m *manualClock) tick() {
+	m.mu.Lock()
+	tickers := m.tickers
+	m.mu.Unlock()
+	for _, ch := range tickers {
+		ch <- m.Now()
+	}
+}
+
+type manualTicker chan time.Time
+
+func (t manualTicker) C() <-chan time.Time { return t }
+func (t manualTicker) Stop()               {}
+
+func TestWithClock(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock), WithCleanupInterval(time.Minute))
+	defer cache.Stop()
+
+	cache.Set("a", 1, time.Hour)
+	cache.Set("b", 2, 2*time.Hour)
+
+	clock.advance(30 * time.Minute)
+	if ttl, _ := cache.TTL("a"); ttl != 30*time.Minute {
+		t.Fatalf("expected 30m left on the fake clock, got %v", ttl)
+	}
+	if up := cache.Stats().Uptime; up != 30*time.Minute {
+		t.Fatalf("expected uptime on the fake clock, got %v", up)
+	}
+
+	clock.advance(31 * time.Minute)
+	if _, found := cache.Get("a"); found {
+		t.Fatal("expected 'a' to expire once the fake clock passes its TTL")
+	}
+
+	clock.advance(time.Hour)
+	clock.tick()
+	clock.tick() // the second tick is received after the first sweep completed
+	if n := cache.Len(); n != 0 {
+		t.Fatalf("expected the janitor to remove 'b' on a fake tick, got %d entries", n)
+	}
+}
@@ -0,0 +1,166 @@
+package tempuscache
+
+import "reflect"
+
+/*
+clone.go implements copy-on-read.
+
+================================================================================
+WHY?
+================================================================================
+
+Get returns the stored value itself. For maps, slices, and pointers
+that is shared mutable state: a caller that modifies what it read
+races with every other reader of the same entry, and silently
+changes the cached value for all of them.
+
+With WithCopyOnRead, every read hands out a deep copy instead.
+
+================================================================================
+HOW VALUES ARE COPIED
+================================================================================
+
+In order of precedence:
+
+1. Values implementing Cloner are copied with their Clone method
+   (per-type control, e.g. for types with unexported state).
+2. Otherwise the function given to WithCopyOnRead is used, or
+   DeepCopy if it was nil.
+
+Values stored in encoded form (WithSerializer, WithCompression) are
+already decoded into a fresh copy on every read and are not copied
+again.
+
+Copy-on-read protects the cache from its readers only. A caller that
+keeps modifying a value after Set still changes the cached copy;
+WithSerializer protects against both.
+*/
+
+/*
+Cloner is implemented by values that know how to copy themselves.
+Clone must return a deep copy of the same dynamic type.
+*/
+
+type Cloner interface {
+	Clone() interface{}
+}
+
+/*
+read returns a stored value in the form handed to callers: decoded
+(see packed.go) and, with WithCopyOnRead, copied.
+*/
+
+func (c *Cache) read(value interface{}) interface{} {
+	if c.clone == nil || value == nil {
+		return c.unpack(value)
+	}
+	if _, ok := value.(*packed); ok {
+		return c.unpack(value)
+	}
+	if v, ok := value.(Cloner); ok {
+		return v.Clone()
+	}
+	return c.clone(value)
+}
+
+/*
+DeepCopy returns a deep copy of v using reflection.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Maps, slices, arrays, pointers, and interfaces are copied
+  recursively.
+- Structs are copied by value, and their exported fields
+  recursively. Unexported fields are copied shallowly, so reference
+  types hidden in them stay shared; implement Cloner for such types.
+- Nested values implementing Cloner are copied with Clone.
+- Channels, functions, and other kinds are returned as-is.
+
+v must not contain reference cycles.
+*/
+
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Interface && v.CanInterface() && !isNilRef(v) {
+		if cl, ok := v.Interface().(Cloner); ok {
+			if r := reflect.ValueOf(cl.Clone()); r.IsValid() && r.Type().AssignableTo(v.Type()) {
+				return r
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return m
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return s
+
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return a
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(deepCopy(v.Elem()))
+		return p
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(deepCopy(v.Elem()))
+		return i
+
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := s.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+		return s
+	}
+	return v
+}
+
+// isNilRef reports whether v is a nil pointer, map, or slice, on
+// which a Clone method must not be called.
+func isNilRef(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package tempuscache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cloneCounter struct {
+	n      *int
+	clones *int
+}
+
+func (c cloneCounter) Clone() interface{} {
+	*c.clones++
+	n := *c.n
+	return cloneCounter{n: &n, clones: c.clones}
+}
+
+func TestCopyOnRead(t *testing.T) {
+	cache := New(WithCopyOnRead(nil))
+	defer cache.Stop()
+
+	cache.Set("m", map[string][]int{"a": {1, 2}}, 0)
+
+	v, _ := cache.Get("m")
+	v.(map[string][]int)["a"][0] = 99
+	v.(map[string][]int)["b"] = nil
+
+	v, _ = cache.Get("m")
+	m := v.(map[string][]int)
+	if len(m) != 1 || m["a"][0] != 1 {
+		t.Fatalf("expected the cached map to be untouched, got %v", m)
+	}
+
+	// Values read through Update are copies as well.
+	stored := reflect.ValueOf(cache.data["m"].Value.(*Item).value).Pointer()
+	cache.Update("m", func(old interface{}, exists bool) (interface{}, time.Duration) {
+		if reflect.ValueOf(old).Pointer() == stored {
+			t.Error("expected Update to receive a copy")
+		}
+		return old, 0
+	})
+}
+
+func TestCopyOnReadUsesCloner(t *testing.T) {
+	clones := 0
+	n := 1
+	cache := New(WithCopyOnRead(func(v interface{}) interface{} {
+		t.Fatal("expected Clone to take precedence over the cache-wide function")
+		return v
+	}))
+	defer cache.Stop()
+
+	cache.Set("c", cloneCounter{n: &n, clones: &clones}, 0)
+	v, _ := cache.Get("c")
+	*v.(cloneCounter).n = 5
+
+	if clones != 1 || n != 1 {
+		t.Fatalf("expected one Clone and an untouched original, got %d clones, n=%d", clones, n)
+	}
+}
+
+func TestCopyOnReadForLoads(t *testing.T) {
+	shared := []int{1, 2, 3}
+	cache := New(WithCopyOnRead(nil), WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		return shared, 0, nil
+	})))
+	defer cache.Stop()
+
+	v, _, err := cache.GetContext(t.Context(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.([]int)[0] = 99
+	if v, _ := cache.Get("k"); v.([]int)[0] != 1 {
+		t.Fatal("expected the loading caller to receive its own copy")
+	}
+}
+
+type deepCopyInner struct {
+	Vals   []string
+	hidden map[string]int
+}
+
+type deepCopyOuter struct {
+	Name  string
+	Inner *deepCopyInner
+	Any   interface{}
+	Arr   [2][]int
+}
+
+func TestDeepCopy(t *testing.T) {
+	orig := deepCopyOuter{
+		Name:  "x",
+		Inner: &deepCopyInner{Vals: []string{"a"}, hidden: map[string]int{"h": 1}},
+		Any:   map[string]int{"k": 1},
+		Arr:   [2][]int{{1}, {2}},
+	}
+
+	cp := DeepCopy(orig).(deepCopyOuter)
+	cp.Inner.Vals[0] = "changed"
+	cp.Any.(map[string]int)["k"] = 2
+	cp.Arr[0][0] = 9
+
+	if orig.Inner.Vals[0] != "a" || orig.Any.(map[string]int)["k"] != 1 || orig.Arr[0][0] != 1 {
+		t.Fatalf("expected exported state to be copied deeply, original now %+v", orig)
+	}
+	if cp.Inner == orig.Inner {
+		t.Fatal("expected pointers to be copied")
+	}
+	if cp.Inner.hidden["h"] != 1 {
+		t.Fatal("expected unexported fields to be copied shallowly")
+	}
+
+	if DeepCopy(nil) != nil {
+		t.Fatal("expected nil to stay nil")
+	}
+	var nilMap map[string]int
+	if DeepCopy(nilMap).(map[string]int) != nil {
+		t.Fatal("expected a nil map to stay nil")
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Config is the tempusd configuration file format.
+
+================================================================================
+EXAMPLE
+================================================================================
+
+	max_entries: 100000
+	cleanup_interval: 1m
+	top_k: 20
+
+	persistence:
+	  snapshot_path: /var/lib/tempusd/cache.snap
+	  snapshot_interval: 5m
+	  aof_path: /var/lib/tempusd/cache.aof
+	  aof_rewrite_size: 67108864
+
+	listen:
+	  resp: 127.0.0.1:6380
+	  memcached: 127.0.0.1:11211
+	  rest: 127.0.0.1:7070
+	  admin: 127.0.0.1:9090
+
+	admin:
+	  token: change-me
+
+	metrics:
+	  prometheus: true
+	  namespace: tempusd
+	  expvar: true
+
+	log:
+	  level: info
+	  format: text
+
+Durations use Go syntax ("30s", "5m"). Empty listen addresses
+disable the corresponding frontend. Unknown keys are rejected so
+typos fail loudly instead of being silently ignored.
+
+max_entries and cleanup_interval are re-read whenever the file
+changes; every other setting requires a restart.
+*/
+
+type Config struct {
+	MaxEntries      int           `yaml:"max_entries"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	TopK            int           `yaml:"top_k"`
+
+	Persistence PersistenceConfig `yaml:"persistence"`
+	Listen      ListenConfig      `yaml:"listen"`
+	Admin       AdminConfig       `yaml:"admin"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Log         LogConfig         `yaml:"log"`
+
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+/*
+PersistenceConfig configures snapshots and the append-only log.
+
+SnapshotPath alone (without SnapshotInterval) still loads the
+snapshot at startup and writes it at shutdown.
+*/
+
+type PersistenceConfig struct {
+	SnapshotPath     string        `yaml:"snapshot_path"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+	AOFPath          string        `yaml:"aof_path"`
+	AOFRewriteSize   int64         `yaml:"aof_rewrite_size"`
+}
+
+// ListenConfig holds the listen address of every network frontend.
+type ListenConfig struct {
+	RESP      string `yaml:"resp"`
+	Memcached string `yaml:"memcached"`
+	REST      string `yaml:"rest"`
+	Admin     string `yaml:"admin"`
+}
+
+// AdminConfig configures the admin endpoint (see package tempusadmin).
+type AdminConfig struct {
+	Token string `yaml:"token"`
+}
+
+/*
+MetricsConfig selects the metrics exposed on the admin listener:
+Prometheus at /metrics and expvar at /debug/vars.
+*/
+
+type MetricsConfig struct {
+	Prometheus bool   `yaml:"prometheus"`
+	Namespace  string `yaml:"namespace"`
+	Expvar     bool   `yaml:"expvar"`
+}
+
+// LogConfig configures the process logger.
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// defaultConfig is used for keys missing from the file.
+func defaultConfig() Config {
+	return Config{
+		CleanupInterval: time.Minute,
+		Listen:          ListenConfig{RESP: "127.0.0.1:6380"},
+		Log:             LogConfig{Level: "info", Format: "text"},
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+/*
+loadConfig reads and validates the configuration file at path.
+An empty path yields the defaults.
+*/
+
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, cfg.validate()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, cfg.validate()
+}
+
+// validate rejects configurations that cannot work.
+func (cfg Config) validate() error {
+	if cfg.MaxEntries < 0 {
+		return errors.New("max_entries must not be negative")
+	}
+	if cfg.Persistence.SnapshotInterval > 0 && cfg.Persistence.SnapshotPath == "" {
+		return errors.New("persistence.snapshot_interval requires persistence.snapshot_path")
+	}
+	if cfg.Persistence.AOFRewriteSize > 0 && cfg.Persistence.AOFPath == "" {
+		return errors.New("persistence.aof_rewrite_size requires persistence.aof_path")
+	}
+	if cfg.Listen == (ListenConfig{}) {
+		return errors.New("at least one listen address must be set")
+	}
+	if (cfg.Metrics.Prometheus || cfg.Metrics.Expvar) && cfg.Listen.Admin == "" {
+		return errors.New("metrics require listen.admin")
+	}
+	if _, err := cfg.Log.level(); err != nil {
+		return err
+	}
+	switch cfg.Log.Format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log.format must be text or json, got %q", cfg.Log.Format)
+	}
+	return nil
+}
+
+func (l LogConfig) level() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return level, fmt.Errorf("log.level: %w", err)
+	}
+	return level, nil
+}
+
+// logger builds the process logger described by l.
+func (l LogConfig) logger() *slog.Logger {
+	level, _ := l.level()
+	opts := &slog.HandlerOptions{Level: level}
+	if l.Format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tempusd.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `
+max_entries: 500
+cleanup_interval: 30s
+persistence:
+  snapshot_path: /tmp/cache.snap
+  snapshot_interval: 5m
+listen:
+  memcached: 127.0.0.1:11211
+  admin: 127.0.0.1:9090
+metrics:
+  prometheus: true
+log:
+  level: debug
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxEntries != 500 || cfg.CleanupInterval != 30*time.Second {
+		t.Fatalf("unexpected cache settings: %+v", cfg)
+	}
+	if cfg.Persistence.SnapshotInterval != 5*time.Minute {
+		t.Fatalf("unexpected persistence settings: %+v", cfg.Persistence)
+	}
+	// Nested defaults are kept for keys the file does not mention.
+	if cfg.Listen.RESP != "127.0.0.1:6380" || cfg.Log.Format != "text" || cfg.ShutdownTimeout != 10*time.Second {
+		t.Fatalf("expected defaults to be kept: %+v", cfg)
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	for _, path := range []string{"", writeConfig(t, "")} {
+		if _, err := loadConfig(path); err != nil {
+			t.Fatalf("expected defaults to be valid for %q, got %v", path, err)
+		}
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	cases := map[string]string{
+		"max_entrys: 5":                            "field max_entrys not found",
+		"max_entries: -1":                          "max_entries",
+		"persistence: {snapshot_interval: 1m}":     "snapshot_path",
+		"listen: {resp: ''}":                       "listen address",
+		"metrics: {prometheus: true}":              "listen.admin",
+		"log: {level: loud}":                       "log.level",
+		"cleanup_interval: often":                  "time.Duration",
+		"persistence: {aof_rewrite_size: 1048576}": "aof_path",
+	}
+	for content, want := range cases {
+		_, err := loadConfig(writeConfig(t, content))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error containing %q, got %v", content, want, err)
+		}
+	}
+}
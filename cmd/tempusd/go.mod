@@ -0,0 +1,29 @@
+module github.com/Krishna8167/tempuscache/v2/cmd/tempusd
+
+go 1.24.3
+
+require (
+	github.com/Krishna8167/tempuscache/v2 v2.0.0
+	github.com/Krishna8167/tempuscache/v2/tempusprom v0.0.0
+	github.com/Krishna8167/tempuscache/v2/tempusreload v0.0.0
+	github.com/prometheus/client_golang v1.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+replace (
+	github.com/Krishna8167/tempuscache/v2 => ../../
+	github.com/Krishna8167/tempuscache/v2/tempusprom => ../../tempusprom
+	github.com/Krishna8167/tempuscache/v2/tempusreload => ../../tempusreload
+)
@@ -0,0 +1,221 @@
+/*
+Command tempusd runs TempusCache as a standalone daemon.
+
+================================================================================
+USAGE
+================================================================================
+
+	tempusd -config /etc/tempusd.yaml
+
+Without -config, tempusd serves an unbounded cache over RESP on
+127.0.0.1:6380. See Config for the file format.
+
+================================================================================
+FRONTENDS
+================================================================================
+
+	listen.resp       Redis protocol          (package tempusresp)
+	listen.memcached  memcached ASCII protocol (package tempusmc)
+	listen.rest       HTTP/JSON API           (package tempusrest)
+	listen.admin      Admin endpoint at /, Prometheus at /metrics,
+	                  expvar at /debug/vars   (package tempusadmin)
+
+All frontends share one cache.
+
+================================================================================
+LIFECYCLE
+================================================================================
+
+At startup, the snapshot (if configured and present) is loaded and
+then the append-only log (if configured) is replayed on top of it.
+
+While running, the configuration file is watched: changes to
+max_entries and cleanup_interval are applied to the live cache
+(package tempusreload). Other settings take effect on restart.
+
+On SIGINT or SIGTERM, tempusd stops accepting connections, waits for
+in-flight requests, writes a final snapshot, and closes the log,
+all within shutdown_timeout.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/Krishna8167/tempuscache/v2/tempusadmin"
+	"github.com/Krishna8167/tempuscache/v2/tempusmc"
+	"github.com/Krishna8167/tempuscache/v2/tempusprom"
+	"github.com/Krishna8167/tempuscache/v2/tempusreload"
+	"github.com/Krishna8167/tempuscache/v2/tempusresp"
+	"github.com/Krishna8167/tempuscache/v2/tempusrest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML configuration file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tempusd:", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, *configPath); err != nil {
+		fmt.Fprintln(os.Stderr, "tempusd:", err)
+		os.Exit(1)
+	}
+}
+
+/*
+server is the lifecycle shared by every network frontend.
+*/
+
+type server interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+}
+
+/*
+run starts the cache and all configured frontends, and blocks until
+ctx is cancelled or a frontend fails. configPath, if set, is watched
+for reloadable settings.
+*/
+
+func run(ctx context.Context, cfg Config, configPath string) error {
+	log := cfg.Log.logger()
+	cache := newCache(cfg, log)
+
+	if configPath != "" {
+		w, err := tempusreload.Watch(cache, configPath, tempusreload.WithLogger(log))
+		if err != nil {
+			cache.Stop()
+			return err
+		}
+		defer w.Stop()
+	}
+
+	servers := map[string]server{}
+	if cfg.Listen.RESP != "" {
+		servers["resp"] = tempusresp.New(cache, tempusresp.WithAddr(cfg.Listen.RESP))
+	}
+	if cfg.Listen.Memcached != "" {
+		servers["memcached"] = tempusmc.New(cache, tempusmc.WithAddr(cfg.Listen.Memcached))
+	}
+	if cfg.Listen.REST != "" {
+		servers["rest"] = tempusrest.New(cache, tempusrest.WithAddr(cfg.Listen.REST))
+	}
+	if cfg.Listen.Admin != "" {
+		srv, err := adminServer(cfg, cache)
+		if err != nil {
+			cache.Stop()
+			return err
+		}
+		servers["admin"] = srv
+	}
+
+	failed := make(chan error, len(servers))
+	var wg sync.WaitGroup
+	for name, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Info("tempusd: listening", "frontend", name)
+			err := srv.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Info("tempusd: shutting down")
+	case runErr = <-failed:
+		log.Error("tempusd: frontend failed", "err", runErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	for name, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Warn("tempusd: frontend shutdown incomplete", "frontend", name, "err", err)
+		}
+	}
+	wg.Wait()
+
+	if err := cache.Close(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("final snapshot: %w", err))
+	}
+	return runErr
+}
+
+// newCache builds the cache described by cfg and restores its
+// persisted state.
+func newCache(cfg Config, log *slog.Logger) *tempuscache.Cache {
+	opts := []tempuscache.Option{
+		tempuscache.WithMaxEntries(cfg.MaxEntries),
+		tempuscache.WithCleanupInterval(cfg.CleanupInterval),
+		tempuscache.WithLogger(log),
+	}
+	if cfg.TopK > 0 {
+		opts = append(opts, tempuscache.WithTopK(cfg.TopK))
+	}
+	if cfg.Metrics.Expvar {
+		opts = append(opts, tempuscache.WithExpvar("tempusd"))
+	}
+
+	// WithSnapshotPath loads the snapshot in New, before WithAOF
+	// replays the log on top of it.
+	p := cfg.Persistence
+	if p.SnapshotPath != "" {
+		opts = append(opts,
+			tempuscache.WithAutoSnapshot(p.SnapshotPath, p.SnapshotInterval),
+			tempuscache.WithSnapshotPath(p.SnapshotPath),
+		)
+	}
+	if p.AOFPath != "" {
+		opts = append(opts, tempuscache.WithAOF(p.AOFPath), tempuscache.WithAOFRewriteSize(p.AOFRewriteSize))
+	}
+	return tempuscache.New(opts...)
+}
+
+// adminServer builds the admin listener: admin API plus metrics.
+func adminServer(cfg Config, cache *tempuscache.Cache) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/", tempusadmin.NewHandler(cache, tempusadmin.Opts{Token: cfg.Admin.Token}))
+
+	if cfg.Metrics.Prometheus {
+		reg := prometheus.NewRegistry()
+		err := reg.Register(tempusprom.NewCollector(cache, tempusprom.Opts{
+			Namespace: cfg.Metrics.Namespace,
+			CacheName: "tempusd",
+		}))
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	}
+	if cfg.Metrics.Expvar {
+		mux.Handle("GET /debug/vars", expvar.Handler())
+	}
+
+	return &http.Server{Addr: cfg.Listen.Admin, Handler: mux}, nil
+}
@@ -0,0 +1,89 @@
+package tempuscache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+)
+
+/*
+Codec defines how snapshots are serialized.
+
+================================================================================
+DESIGN
+================================================================================
+
+A Codec creates stream encoders and decoders. Save() and Load()
+push a small header followed by one *SnapshotEntry per entry
+through them:
+
+    enc := codec.NewEncoder(w)
+    enc.Encode(&header)
+    enc.Encode(&entry) ...
+
+Working on streams rather than single values lets stateful formats
+such as gob send type information once per snapshot instead of once
+per entry.
+
+================================================================================
+WHY PLUGGABLE?
+================================================================================
+
+- Values that gob cannot handle can be serialized by the caller.
+- Alternative formats (JSON, msgpack, protobuf wrappers) can be
+  used for interoperability with other tooling.
+
+Compression and destinations (files, S3, pipes) are orthogonal
+and are handled by the io.Writer / io.Reader given to Save/Load.
+*/
+
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder writes values to an underlying stream.
+// *gob.Encoder and *json.Encoder satisfy it.
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Decoder reads values from an underlying stream.
+// *gob.Decoder and *json.Decoder satisfy it.
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+/*
+GobCodec is the default Codec, based on encoding/gob.
+
+Concrete value types stored behind interface{} must be registered
+with gob.Register before saving or loading.
+*/
+
+type GobCodec struct{}
+
+func (GobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (GobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+/*
+JSONCodec serializes snapshots as a stream of JSON documents.
+
+Values are decoded into their generic JSON representation:
+numbers become float64, objects become map[string]interface{}.
+It suits caches holding JSON-friendly data and snapshots that
+must be readable by other tools.
+*/
+
+type JSONCodec struct{}
+
+func (JSONCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (JSONCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// codecOrDefault returns the configured Codec, falling back to GobCodec.
+func (c *Cache) codecOrDefault() Codec {
+	if c.codec == nil {
+		return GobCodec{}
+	}
+	return c.codec
+}
@@ -0,0 +1,123 @@
+package tempuscache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+/*
+compression.go implements transparent value compression.
+
+================================================================================
+WHY?
+================================================================================
+
+Text payloads such as JSON often compress several times over. When
+memory rather than CPU bounds how much a cache can hold, storing
+them compressed multiplies the effective capacity.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+With WithCompression, every write of a []byte or string value of at
+least compressMinSize bytes is compressed before it is stored, and
+every read returns the decompressed value, of the original type:
+
+- Get and every other read return the original bytes or string.
+  A []byte is freshly decompressed on each read, so callers may
+  modify it freely.
+- Values of other types, short values, and values the Compressor
+  cannot shrink are stored as-is.
+- Snapshots and the append-only log store decompressed values, so
+  files stay readable by caches configured without compression.
+- EstimatedBytes, and therefore WithMaxEntries-style capacity
+  planning, count the compressed size.
+
+Compression runs under the cache lock on the write path, and
+decompression on every read, so hits on compressed values allocate.
+With WithSerializer, the encoded form of every value is compressed
+instead (see packed.go).
+
+================================================================================
+STATISTICS
+================================================================================
+
+Stats reports two gauges over the entries currently stored
+compressed:
+
+- CompressedBytes   → Bytes they occupy
+- UncompressedBytes → Bytes they would occupy uncompressed
+
+UncompressedBytes / CompressedBytes is the achieved ratio.
+*/
+
+// compressMinSize is the smallest value worth compressing; below it,
+// compression headers tend to outweigh the savings.
+const compressMinSize = 128
+
+/*
+Compressor compresses and decompresses values for WithCompression.
+
+Implementations must be safe for concurrent use. GzipCompressor is
+provided; snappy, zstd, or any other algorithm can be plugged in
+with a small adapter.
+*/
+
+type Compressor interface {
+	Compress(src []byte) ([]byte, error)
+	Decompress(src []byte) ([]byte, error)
+}
+
+/*
+GzipCompressor returns a Compressor using compress/gzip at the given
+level (gzip.DefaultCompression, gzip.BestSpeed, ...).
+*/
+
+func GzipCompressor(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+func (g gzipCompressor) Compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, g.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g gzipCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+/*
+trackCompressed adds (sign = 1) or removes (sign = -1) a stored
+value from the compression gauges.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) trackCompressed(value interface{}, sign int64) {
+	if v, ok := value.(*packed); ok && v.compressed {
+		c.compressedBytes += sign * int64(len(v.data))
+		c.uncompressedBytes += sign * int64(v.raw)
+	}
+}
@@ -0,0 +1,108 @@
+package tempuscache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	cache := New(WithCompression(GzipCompressor(gzip.BestSpeed)))
+	defer cache.Stop()
+
+	payload := []byte(strings.Repeat(`{"name":"tempus","tags":["a","b"]},`, 100))
+	text := strings.Repeat("hello ", 100)
+	cache.Set("bytes", payload, 0)
+	cache.Set("string", text, 0)
+	cache.Set("short", "tiny", 0)
+	cache.Set("int", 42, 0)
+
+	v, _ := cache.Get("bytes")
+	if got, ok := v.([]byte); !ok || !bytes.Equal(got, payload) {
+		t.Fatal("expected the []byte value to round-trip")
+	}
+	v.([]byte)[0] = 'X'
+	if v, _ := cache.Get("bytes"); v.([]byte)[0] != payload[0] {
+		t.Fatal("expected each read to return a fresh copy")
+	}
+	if v, _ := cache.Get("string"); v != text {
+		t.Fatal("expected the string value to round-trip as a string")
+	}
+	if v, _ := cache.Get("short"); v != "tiny" {
+		t.Fatal("expected short values to be stored as-is")
+	}
+	if v, _ := cache.Get("int"); v != 42 {
+		t.Fatal("expected non-text values to be stored as-is")
+	}
+
+	s := cache.Stats()
+	raw := int64(len(payload) + len(text))
+	if s.UncompressedBytes != raw || s.CompressedBytes <= 0 || s.CompressedBytes*4 > raw {
+		t.Fatalf("expected compressed gauges for both text values, got %d of %d", s.CompressedBytes, s.UncompressedBytes)
+	}
+	if s.EstimatedBytes >= raw {
+		t.Fatalf("expected EstimatedBytes to count the compressed size, got %d", s.EstimatedBytes)
+	}
+
+	cache.Set("string", "replaced", 0)
+	cache.Delete("bytes")
+	if s := cache.Stats(); s.CompressedBytes != 0 || s.UncompressedBytes != 0 {
+		t.Fatalf("expected the gauges to drop with the values, got %+v", s)
+	}
+}
+
+func TestCompressionOnEveryReadPath(t *testing.T) {
+	cache := New(WithCompression(GzipCompressor(gzip.DefaultCompression)))
+	defer cache.Stop()
+
+	text := strings.Repeat("abc", 100)
+	cache.Set("k", text, 0)
+
+	if v, _, _ := cache.GetContext(t.Context(), "k"); v != text {
+		t.Fatal("GetContext returned the stored form")
+	}
+	if !cache.CompareAndSwap("k", text, text+"!", 0) {
+		t.Fatal("expected CompareAndSwap to compare against the original value")
+	}
+	cache.Update("k", func(old interface{}, exists bool) (interface{}, time.Duration) {
+		if old != text+"!" {
+			t.Errorf("Update saw %T", old)
+		}
+		return old, 0
+	})
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	plain := New()
+	defer plain.Stop()
+	if err := plain.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := plain.Get("k"); v != text+"!" {
+		t.Fatal("expected snapshots to hold uncompressed values")
+	}
+}
+
+type failingCompressor struct{}
+
+func (failingCompressor) Compress([]byte) ([]byte, error)   { return nil, errors.New("boom") }
+func (failingCompressor) Decompress([]byte) ([]byte, error) { return nil, errors.New("boom") }
+
+func TestCompressionFailureStoresRaw(t *testing.T) {
+	cache := New(WithCompression(failingCompressor{}))
+	defer cache.Stop()
+
+	text := strings.Repeat("x", 500)
+	cache.Set("k", text, 0)
+	if v, _ := cache.Get("k"); v != text {
+		t.Fatal("expected the value to be stored uncompressed")
+	}
+	if s := cache.Stats(); s.CompressedBytes != 0 {
+		t.Fatalf("expected nothing compressed, got %d bytes", s.CompressedBytes)
+	}
+}
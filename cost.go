@@ -0,0 +1,112 @@
+package tempuscache
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+cost.go implements weighted capacity (WithMaxCost, SetWithCost).
+
+================================================================================
+WHY?
+================================================================================
+
+WithMaxEntries counts a 50-byte flag and a 5 MB blob alike, so the
+limit either wastes memory on small values or overcommits on large
+ones. EstimatedBytes cannot fix this: for values other than strings,
+[]byte, and Sizer implementations it does not know their size. The
+caller usually does.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Every entry has a cost: the one given to SetWithCost, or 1 for
+entries written any other way. Costs below 1 count as 1. Overwriting
+an entry with Set keeps its cost, as it keeps its pin and priority;
+call SetWithCost again to change it.
+
+With WithMaxCost(n), writes that bring the total cost above n evict
+entries exactly as WithMaxEntries does (least recently used first,
+honoring pins and priorities) until the total fits. Both limits may
+be set; each is enforced independently.
+
+- An entry costing more than n on its own is refused with
+  ErrTooLarge and counted in Stats().Rejected.
+- If only pinned entries and the entry just written are left, the
+  total may stay above n, as with WithMaxEntries (see pin.go).
+- The entry just written is itself evicted if everything else has a
+  higher priority or is pinned (see priority.go).
+
+Stats().Cost reports the total cost, Inspect the cost of one entry.
+Costs, like pins, are not recorded by snapshots or the append-only
+log: restored entries cost 1.
+*/
+
+/*
+SetWithCost stores value under key like Set and charges cost against
+WithMaxCost (see cost.go). Like SetMany, it does not write through
+to the Store.
+*/
+
+func (c *Cache) SetWithCost(key string, value interface{}, ttl time.Duration, cost int64) error {
+	cost = max(cost, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxCost > 0 && cost > c.maxCost {
+		c.counters.rejected.Add(1)
+		return fmt.Errorf("%w: cost is %d, limit is %d", ErrTooLarge, cost, c.maxCost)
+	}
+	if err := c.set(key, value, ttl); err != nil {
+		return err
+	}
+	c.charge(key, cost)
+	return nil
+}
+
+/*
+charge sets the cost of key and evicts entries until the total fits
+again.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) charge(key string, cost int64) {
+	elem, found := c.data[key]
+	if !found {
+		return
+	}
+	item := elem.Value.(*Item)
+	c.cost += cost - item.cost
+	item.cost = cost
+	c.shed()
+}
+
+/*
+shed evicts least recently used entries while the total cost exceeds
+WithMaxCost.
+
+RETURNS:
+The number of entries evicted.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) shed() int {
+	evicted := 0
+	for c.maxCost > 0 && c.cost > c.maxCost {
+		elem := c.evictOldest()
+		if elem == nil {
+			break
+		}
+		c.lru.Remove(elem)
+		recycle(elem)
+		evicted++
+	}
+	return evicted
+}
@@ -0,0 +1,65 @@
+package tempuscache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaxCost(t *testing.T) {
+	cache := New(WithMaxCost(100))
+	defer cache.Stop()
+
+	cache.SetWithCost("blob", "big", 0, 60)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, key, 0)
+	}
+	if s := cache.Stats(); s.Cost != 63 || s.Entries != 4 {
+		t.Fatalf("expected a total cost of 63 in 4 entries, got %+v", s)
+	}
+
+	// The next big write evicts least recently used entries until it fits.
+	cache.SetWithCost("blob2", "big", 0, 50)
+	if _, ok := cache.Get("blob"); ok {
+		t.Fatal("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "b", "c", "blob2"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+	if s := cache.Stats(); s.Cost != 53 || s.Evictions != 1 {
+		t.Fatalf("expected a total cost of 53 after 1 eviction, got %+v", s)
+	}
+
+	// Overwrites keep the cost; SetWithCost changes it.
+	cache.Set("blob2", "small", 0)
+	if info, _ := cache.Inspect("blob2"); info.Cost != 50 {
+		t.Fatalf("expected the overwrite to keep its cost, got %d", info.Cost)
+	}
+	cache.SetWithCost("blob2", "small", 0, 0)
+	if s := cache.Stats(); s.Cost != 4 {
+		t.Fatalf("expected costs below 1 to count as 1, got a total of %d", s.Cost)
+	}
+}
+
+func TestMaxCostRejectsTooLarge(t *testing.T) {
+	cache := New(WithMaxCost(10))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	err := cache.SetWithCost("huge", 2, 0, 11)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected the refused write to evict nothing")
+	}
+	if s := cache.Stats(); s.Rejected != 1 || s.Cost != 1 {
+		t.Fatalf("expected 1 rejection and a cost of 1, got %+v", s)
+	}
+
+	cache.Flush()
+	if s := cache.Stats(); s.Cost != 0 {
+		t.Fatalf("expected Flush to reset the cost, got %d", s.Cost)
+	}
+}
@@ -0,0 +1,117 @@
+package tempuscache
+
+import "time"
+
+/*
+doorkeeper.go implements the doorkeeper, an admission filter enabled
+by WithDoorkeeper.
+
+================================================================================
+WHY?
+================================================================================
+
+Under LRU every new key is admitted, and every admission into a
+full cache evicts an entry. A scan touching many keys once each
+("one-hit wonders") therefore cycles the whole cache, evicting the
+working set for entries that are never read again.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+While the cache is full (at WithMaxEntries, or at or above
+WithMaxCost), a write of a new key is cached only if the same key
+was written before within the current window. The first write is
+remembered by the doorkeeper and dropped without an error, so reads
+keep falling through to the Store (if any), and counted in
+Stats().NotAdmitted. The second write is admitted as usual.
+
+- Overwrites of cached keys, and writes while the cache has room,
+  are never filtered.
+- Every write path is filtered, including Store loads and restores.
+- The doorkeeper forgets every key when the window ends, or once it
+  has remembered expected keys, whichever comes first, so its false
+  positive rate stays bounded.
+
+================================================================================
+DATA STRUCTURE
+================================================================================
+
+A bloom filter of 10 bits per expected key and doorkeeperHashes
+hash functions, derived from one key hash (see hash.go) by double
+hashing as in sketch.go. A false positive admits a key on its first
+write: the filter errs towards plain LRU, never against a key
+written twice.
+
+With 10 bits per key the false positive rate stays near 1% until
+the filter is reset.
+*/
+
+// doorkeeperHashes is the number of bits set per key.
+const doorkeeperHashes = 4
+
+type doorkeeper struct {
+	bits     []uint64
+	nbits    uint64
+	expected int
+	added    int
+	window   time.Duration
+	reset    int64
+}
+
+func newDoorkeeper(expected int, window time.Duration) *doorkeeper {
+	expected = max(expected, 64)
+	nbits := uint64(expected) * 10
+	return &doorkeeper{
+		bits:     make([]uint64, (nbits+63)/64),
+		nbits:    nbits,
+		expected: expected,
+		window:   window,
+	}
+}
+
+/*
+allow records hash h and reports whether it had been recorded before
+in the current window. now is in UnixNano.
+*/
+
+func (d *doorkeeper) allow(h uint64, now int64) bool {
+	if d.added >= d.expected || d.window > 0 && now-d.reset >= int64(d.window) {
+		clear(d.bits)
+		d.added = 0
+		d.reset = now
+	}
+
+	seen := true
+	h1, h2 := h, (h>>32)|1
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		idx := (h1 + i*h2) % d.nbits
+		word, mask := idx/64, uint64(1)<<(idx%64)
+		if d.bits[word]&mask == 0 {
+			seen = false
+			d.bits[word] |= mask
+		}
+	}
+	if !seen {
+		d.added++
+	}
+	return seen
+}
+
+/*
+admitNew reports whether a write of key, which is not cached, may
+insert it (see doorkeeper.go).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) admitNew(key string) bool {
+	full := c.maxEntries > 0 && c.lru.Len() >= c.maxEntries ||
+		c.maxCost > 0 && c.cost >= c.maxCost
+	if !full || c.doorkeeper.allow(c.hash(key), c.now()) {
+		return true
+	}
+	c.counters.notAdmitted.Add(1)
+	return false
+}
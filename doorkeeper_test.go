@@ -0,0 +1,55 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDoorkeeperFiltersOneHitWonders(t *testing.T) {
+	cache := New(WithMaxEntries(3), WithDoorkeeper(1000, 0))
+	defer cache.Stop()
+
+	// Writes while the cache has room are admitted directly.
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, key, 0)
+	}
+
+	// A scan of keys written once leaves the working set alone.
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprint("scan", i), i, 0)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to survive the scan", key)
+		}
+	}
+	if s := cache.Stats(); s.NotAdmitted < 95 || s.Evictions > 5 {
+		t.Fatalf("expected the scan to be filtered, got %+v", s)
+	}
+
+	// A key written twice is admitted.
+	cache.Set("d", 1, 0)
+	cache.Set("d", 2, 0)
+	if v, ok := cache.Get("d"); !ok || v != 2 {
+		t.Fatalf("expected the second write to be admitted, got %v", v)
+	}
+}
+
+func TestDoorkeeperWindow(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock), WithMaxEntries(1), WithDoorkeeper(1000, time.Minute))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 1, 0)
+	clock.advance(2 * time.Minute)
+	cache.Set("b", 2, 0)
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected the doorkeeper to forget keys when the window ends")
+	}
+	cache.Set("b", 3, 0)
+	if _, ok := cache.Get("b"); !ok {
+		t.Fatal("expected the second write within the window to be admitted")
+	}
+}
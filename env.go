@@ -0,0 +1,164 @@
+package tempuscache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+/*
+env.go implements configuration from environment variables, for
+twelve-factor deployments that tune the cache without code changes.
+
+================================================================================
+VARIABLES
+================================================================================
+
+With prefix "TEMPUS":
+
+	TEMPUS_MAX_ENTRIES             WithMaxEntries              (integer)
+	TEMPUS_CLEANUP_INTERVAL        WithCleanupInterval         (duration)
+	TEMPUS_DEFAULT_TTL             WithDefaultTTL              (duration)
+	TEMPUS_TOP_K                   WithTopK                    (integer)
+	TEMPUS_SNAPSHOT_PATH           WithAutoSnapshot, path      (string)
+	TEMPUS_SNAPSHOT_INTERVAL       WithAutoSnapshot, interval  (duration)
+	TEMPUS_AOF_PATH                WithAOF                     (string)
+	TEMPUS_AOF_REWRITE_SIZE        WithAOFRewriteSize          (bytes)
+	TEMPUS_EXPVAR                  WithExpvar                  (string)
+	TEMPUS_STALE_WHILE_REVALIDATE  WithStaleWhileRevalidate    (duration)
+	TEMPUS_REFRESH_AHEAD           WithRefreshAhead            (float)
+	TEMPUS_EARLY_EXPIRATION        WithEarlyExpiration         (float)
+
+Durations use Go syntax ("30s", "5m"). Unset and empty variables
+are ignored, so the option keeps its default or the value set in
+code.
+*/
+
+/*
+OptionsFromEnv returns the options described by the environment
+variables named prefix + "_" + VARIABLE (see env.go); with an empty
+prefix, the bare variable names are used.
+
+ERRORS:
+Every malformed variable is reported, by name, in one joined error;
+the options parsed from the others are still returned.
+*/
+
+func OptionsFromEnv(prefix string) ([]Option, error) {
+	e := envReader{prefix: prefix}
+	var opts []Option
+
+	if n, ok := e.int("MAX_ENTRIES"); ok {
+		opts = append(opts, WithMaxEntries(n))
+	}
+	if d, ok := e.duration("CLEANUP_INTERVAL"); ok {
+		opts = append(opts, WithCleanupInterval(d))
+	}
+	if d, ok := e.duration("DEFAULT_TTL"); ok {
+		opts = append(opts, WithDefaultTTL(d))
+	}
+	if n, ok := e.int("TOP_K"); ok {
+		opts = append(opts, WithTopK(n))
+	}
+
+	path, pathSet := e.string("SNAPSHOT_PATH")
+	interval, intervalSet := e.duration("SNAPSHOT_INTERVAL")
+	if pathSet || intervalSet {
+		opts = append(opts, WithAutoSnapshot(path, interval))
+	}
+
+	if path, ok := e.string("AOF_PATH"); ok {
+		opts = append(opts, WithAOF(path))
+	}
+	if n, ok := e.int64("AOF_REWRITE_SIZE"); ok {
+		opts = append(opts, WithAOFRewriteSize(n))
+	}
+	if name, ok := e.string("EXPVAR"); ok {
+		opts = append(opts, WithExpvar(name))
+	}
+	if d, ok := e.duration("STALE_WHILE_REVALIDATE"); ok {
+		opts = append(opts, WithStaleWhileRevalidate(d))
+	}
+	if f, ok := e.float("REFRESH_AHEAD"); ok {
+		opts = append(opts, WithRefreshAhead(f))
+	}
+	if f, ok := e.float("EARLY_EXPIRATION"); ok {
+		opts = append(opts, WithEarlyExpiration(f))
+	}
+
+	return opts, e.err
+}
+
+/*
+NewFromEnv creates a cache configured by opts and then by the
+environment (see OptionsFromEnv): a variable that is set overrides
+the corresponding option given in code.
+
+    cache, err := tempuscache.NewFromEnv("TEMPUS",
+        tempuscache.WithMaxEntries(10000), // default, TEMPUS_MAX_ENTRIES wins
+        tempuscache.WithStore(userStore),
+    )
+
+No cache is created if a variable is malformed.
+*/
+
+func NewFromEnv(prefix string, opts ...Option) (*Cache, error) {
+	envOpts, err := OptionsFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return New(append(opts, envOpts...)...), nil
+}
+
+/*
+envReader looks up prefixed variables and collects parse errors.
+*/
+
+type envReader struct {
+	prefix string
+	err    error
+}
+
+func (e *envReader) string(name string) (string, bool) {
+	if e.prefix != "" {
+		name = e.prefix + "_" + name
+	}
+	v := os.Getenv(name)
+	return v, v != ""
+}
+
+// parse looks up name and converts it, recording a failure.
+func envParse[T any](e *envReader, name string, conv func(string) (T, error)) (T, bool) {
+	var zero T
+	s, ok := e.string(name)
+	if !ok {
+		return zero, false
+	}
+	v, err := conv(s)
+	if err != nil {
+		if e.prefix != "" {
+			name = e.prefix + "_" + name
+		}
+		e.err = errors.Join(e.err, fmt.Errorf("tempuscache: %s: invalid value %q", name, s))
+		return zero, false
+	}
+	return v, true
+}
+
+func (e *envReader) int(name string) (int, bool) {
+	return envParse(e, name, strconv.Atoi)
+}
+
+func (e *envReader) int64(name string) (int64, bool) {
+	return envParse(e, name, func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+}
+
+func (e *envReader) float(name string) (float64, bool) {
+	return envParse(e, name, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+}
+
+func (e *envReader) duration(name string) (time.Duration, bool) {
+	return envParse(e, name, time.ParseDuration)
+}
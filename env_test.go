@@ -0,0 +1,58 @@
+package tempuscache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("APP_CACHE_MAX_ENTRIES", "50")
+	t.Setenv("APP_CACHE_CLEANUP_INTERVAL", "2m")
+	t.Setenv("APP_CACHE_DEFAULT_TTL", "10m")
+	t.Setenv("APP_CACHE_TOP_K", "")
+	t.Setenv("APP_CACHE_REFRESH_AHEAD", "0.25")
+
+	cache, err := NewFromEnv("APP_CACHE", WithMaxEntries(10), WithTopK(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Stop()
+
+	// Set variables override code; empty ones are ignored.
+	if cache.Capacity() != 50 || cache.CleanupInterval() != 2*time.Minute {
+		t.Fatalf("expected capacity 50 and interval 2m, got %d and %v", cache.Capacity(), cache.CleanupInterval())
+	}
+	if cache.DefaultTTL() != 10*time.Minute {
+		t.Fatalf("expected default TTL 10m, got %v", cache.DefaultTTL())
+	}
+	if cache.topK == nil {
+		t.Fatal("expected WithTopK from code to be kept")
+	}
+	if cache.refreshAhead != 0.25 {
+		t.Fatalf("expected refresh-ahead 0.25, got %v", cache.refreshAhead)
+	}
+}
+
+func TestOptionsFromEnvErrors(t *testing.T) {
+	t.Setenv("MAX_ENTRIES", "many")
+	t.Setenv("CLEANUP_INTERVAL", "5")
+	t.Setenv("AOF_REWRITE_SIZE", "1048576")
+
+	opts, err := OptionsFromEnv("")
+	if err == nil {
+		t.Fatal("expected malformed variables to be reported")
+	}
+	for _, name := range []string{"MAX_ENTRIES", "CLEANUP_INTERVAL"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected %s in error, got %v", name, err)
+		}
+	}
+	if len(opts) != 1 {
+		t.Fatalf("expected the valid variable to still yield an option, got %d", len(opts))
+	}
+
+	if _, err := NewFromEnv(""); err == nil {
+		t.Fatal("expected NewFromEnv to fail")
+	}
+}
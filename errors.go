@@ -0,0 +1,46 @@
+package tempuscache
+
+import "errors"
+
+/*
+errors.go defines the sentinel errors shared across the cache and
+its companion packages.
+
+================================================================================
+WHY?
+================================================================================
+
+Every error the cache returns for a condition a caller may want to
+handle is, or wraps, one of the exported Err values, so it can be
+matched with errors.Is instead of by message:
+
+	ErrNotFound      Key (or Store entry) does not exist    (store.go)
+	ErrExpired       Key exists but its TTL has elapsed
+	ErrClosed        Cache has been stopped
+	ErrFrozen        Write to a frozen cache                (freeze.go)
+	ErrTooLarge      Key or value exceeds a configured limit
+	ErrInvalidKey    Key refused by WithKeyValidator        (keys.go)
+	ErrNotNumeric    Increment of a non-numeric value       (atomic_ops.go)
+	ErrAOFDisabled   Log operation without WithAOF          (aof.go)
+	ErrBadTrace      Unreadable trace file                  (trace.go)
+	ErrInvalidConfig Rejected by NewWithError               (logging.go)
+	ErrExpvarExists  expvar name already published          (expvar.go)
+
+Errors carrying details wrap the sentinel, e.g.
+fmt.Errorf("%w: ...", ErrTooLarge), so errors.Is still matches.
+*/
+
+// ErrExpired reports a key that is stored but whose TTL has elapsed.
+var ErrExpired = errors.New("tempuscache: expired")
+
+// ErrClosed is returned by operations that need a running cache
+// after Stop or Close.
+var ErrClosed = errors.New("tempuscache: cache closed")
+
+// ErrFrozen is returned by writes to a cache frozen with
+// RejectWrites (see Freeze).
+var ErrFrozen = errors.New("tempuscache: cache frozen")
+
+// ErrTooLarge is returned when a key or value exceeds a configured
+// size limit.
+var ErrTooLarge = errors.New("tempuscache: too large")
@@ -0,0 +1,172 @@
+package tempuscache
+
+import "time"
+
+/*
+events.go implements a lightweight event bus for cache lifecycle events.
+
+================================================================================
+DELIVERY SEMANTICS
+================================================================================
+
+- Events are emitted while the cache lock is held, so every
+  subscriber observes them in the exact order they happened.
+- Delivery is NON-BLOCKING: each subscriber has a bounded buffer,
+  and an event that does not fit is dropped for that subscriber
+  rather than stalling the cache. Drops are counted in
+  Stats().DroppedEvents.
+- With no subscribers, emitting an event costs a single length check.
+- Every event is also recorded in the operation trace, if one is
+  configured (see trace.go).
+
+Slow consumers therefore lose events but can never slow down
+callers of Get/Set, which is the right trade-off for debugging UIs
+and audit trails.
+*/
+
+// EventType identifies what happened to a key.
+type EventType uint8
+
+const (
+	EventSet     EventType = iota + 1 // Entry created or overwritten
+	EventHit                          // Lookup found a live entry
+	EventMiss                         // Lookup found nothing (or an expired entry)
+	EventDeleted                      // Live entry removed explicitly
+	EventEvicted                      // Entry removed by LRU capacity eviction
+	EventExpired                      // Entry removed because its TTL elapsed
+	EventFlushed                      // All entries removed by Flush (Key is empty)
+)
+
+// String returns the lower-case name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventDeleted:
+		return "deleted"
+	case EventEvicted:
+		return "evicted"
+	case EventExpired:
+		return "expired"
+	case EventFlushed:
+		return "flushed"
+	}
+	return "unknown"
+}
+
+/*
+Event describes a single cache lifecycle event.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Type -> What happened
+Key  -> Affected key (empty for EventFlushed)
+Time -> When it happened
+*/
+
+type Event struct {
+	Type EventType
+	Key  string
+	Time time.Time
+}
+
+// eventBuffer is the per-subscriber channel capacity.
+const eventBuffer = 1024
+
+/*
+Subscribe registers a new event subscriber.
+
+================================================================================
+RETURNS
+================================================================================
+
+- A receive-only channel delivering events in order.
+- A cancel function that unregisters the subscriber and closes the
+  channel. Calling cancel more than once is safe.
+
+Stop() closes all remaining subscriber channels, so a consumer
+ranging over the channel terminates when the cache shuts down.
+
+================================================================================
+EXAMPLE
+================================================================================
+
+    events, cancel := cache.Subscribe()
+    defer cancel()
+
+    for ev := range events {
+        log.Printf("%s %s", ev.Type, ev.Key)
+    }
+*/
+
+func (c *Cache) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+
+	c.mu.Lock()
+	if c.stopped {
+		close(ch)
+	} else {
+		c.subs = append(c.subs, ch)
+		c.subscribersChanged()
+	}
+	c.mu.Unlock()
+
+	cancel := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, sub := range c.subs {
+			if sub == ch {
+				c.subs = append(c.subs[:i], c.subs[i+1:]...)
+				c.subscribersChanged()
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, cancel
+}
+
+/*
+emit delivers an event to every subscriber without blocking.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) emit(typ EventType, key string) {
+	c.trace(typ, key)
+	if len(c.subs) == 0 {
+		return
+	}
+
+	ev := Event{Type: typ, Key: key, Time: c.clock.Now()}
+	for _, ch := range c.subs {
+		select {
+		case ch <- ev:
+		default:
+			c.counters.droppedEvents.Add(1)
+		}
+	}
+}
+
+/*
+closeSubscribers closes and unregisters every subscriber channel.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) closeSubscribers() {
+	for _, ch := range c.subs {
+		close(ch)
+	}
+	c.subs = nil
+	c.subscribersChanged()
+	c.stopped = true
+}
@@ -0,0 +1,127 @@
+package tempuscache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func collect(events <-chan Event, n int) []Event {
+	out := make([]Event, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-events:
+			out = append(out, ev)
+		case <-time.After(time.Second):
+			return out
+		}
+	}
+	return out
+}
+
+func TestSubscribe(t *testing.T) {
+	cache := New(WithMaxEntries(1))
+	defer cache.Stop()
+
+	events, cancel := cache.Subscribe()
+	defer cancel()
+
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.Set("b", 2, 0) // evicts "a"
+	cache.Delete("b")
+	cache.Set("c", 3, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	cache.Get("c")
+	cache.Flush()
+
+	want := []struct {
+		typ EventType
+		key string
+	}{
+		{EventSet, "a"},
+		{EventHit, "a"},
+		{EventMiss, "missing"},
+		{EventEvicted, "a"},
+		{EventSet, "b"},
+		{EventDeleted, "b"},
+		{EventSet, "c"},
+		{EventExpired, "c"},
+		{EventMiss, "c"},
+		{EventFlushed, ""},
+	}
+
+	got := collect(events, len(want))
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Key != w.key {
+			t.Fatalf("event %d: expected %s %q, got %s %q", i, w.typ, w.key, got[i].Type, got[i].Key)
+		}
+	}
+}
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	_, cancel := cache.Subscribe()
+	defer cancel()
+
+	for i := 0; i < eventBuffer+10; i++ {
+		cache.Set("k", i, 0)
+	}
+
+	if dropped := cache.Stats().DroppedEvents; dropped != 10 {
+		t.Fatalf("expected 10 dropped events, got %d", dropped)
+	}
+}
+
+func TestSubscribeCancelAndStop(t *testing.T) {
+	cache := New()
+
+	events, cancel := cache.Subscribe()
+	cancel()
+	cancel() // must be safe to call twice
+
+	if _, ok := <-events; ok {
+		t.Fatal("expected cancelled channel to be closed")
+	}
+
+	events, _ = cache.Subscribe()
+	cache.Stop()
+	if _, ok := <-events; ok {
+		t.Fatal("expected Stop to close subscriber channels")
+	}
+
+	events, _ = cache.Subscribe()
+	if _, ok := <-events; ok {
+		t.Fatal("expected subscription after Stop to be closed")
+	}
+}
+
+func TestFlushAOF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	cache := New(WithAOF(path))
+	cache.Set("a", 1, 0)
+	cache.Flush()
+	cache.Set("b", 2, 0)
+	cache.Stop()
+
+	if cache.Len() != 1 || cache.Stats().EstimatedBytes != estimateSize("b", 2) {
+		t.Fatalf("unexpected state after Flush: len=%d", cache.Len())
+	}
+
+	restored := New(WithAOF(path))
+	defer restored.Stop()
+
+	if _, found := restored.Get("a"); found {
+		t.Fatal("expected flushed key not to be replayed")
+	}
+	if _, found := restored.Get("b"); !found {
+		t.Fatal("expected key written after Flush to be replayed")
+	}
+}
@@ -1,6 +1,8 @@
 package tempuscache
 
-import "container/list"
+import (
+	"container/list"
+)
 
 /*
 evictOldest removes the least recently used (LRU) entry
@@ -23,25 +25,61 @@ eviction behavior.
 ALGORITHM
 ================================================================================
 
-1. Retrieve the last element from the LRU list.
+1. Retrieve the last element from the LRU list holding an entry of
+   the lowest priority present, moving pinned and higher-priority
+   elements found before it to the front (see pin.go, priority.go).
 2. If it exists:
-   - Remove it from both:
-       a) The linked list
-       b) The hash map
+   - Remove its key from the hash map.
    - Increment eviction statistics counter.
+   - Return the element, still linked, for reuse.
+
+The caller MUST reuse the returned element for the entry it is
+inserting (see pool.go): it stays in the linked list, so the insert
+needs neither a new Item nor a new list node.
+
+With WithSampledEviction, step 1 instead picks the least recently
+used of a sample of entries (see sampled.go).
+
+Returns nil if the cache is empty, every entry is pinned, or the
+cache is frozen.
 
 TIME COMPLEXITY:
-O(1)
+O(1) plus the pinned and higher-priority entries skipped (see
+priority.go).
 
 The use of a doubly linked list ensures constant-time removal.
 */
 
-func (c *Cache) evictOldest() {
-	elem := c.lru.Back()
-	if elem != nil {
-		c.removeElement(elem)
-		c.stats.Evictions++
+func (c *Cache) evictOldest() *list.Element {
+	c.applyPromotions()
+	if c.pinned >= c.lru.Len() || c.Frozen() {
+		return nil
+	}
+
+	var elem *list.Element
+	if c.evictionSamples > 0 {
+		elem = c.sampleVictim()
+	} else {
+		lowest := c.lowestPriority()
+		elem = c.lru.Back()
+		for item := elem.Value.(*Item); item.pinned || item.priority != lowest; item = elem.Value.(*Item) {
+			c.lru.MoveToFront(elem)
+			elem = c.lru.Back()
+		}
+	}
+
+	item := elem.Value.(*Item)
+	c.unindex(item)
+	if c.onEvict != nil {
+		c.onEvict(item.key, c.unpack(item.value), EventEvicted)
 	}
+	if c.ghosts != nil {
+		c.ghosts.add(c.hash(item.key))
+	}
+	c.counters.evictions.Add(1)
+	c.storm.record(c)
+	c.emit(EventEvicted, item.key)
+	return elem
 }
 
 /*
@@ -71,6 +109,9 @@ To maintain structural integrity:
 This ensures there are no dangling references between
 the list and the hash map.
 
+The element's Item is then recycled (see pool.go): callers must not
+use it after removeElement returns.
+
 TIME COMPLEXITY:
 O(1)
 
@@ -82,6 +123,118 @@ It does NOT perform its own synchronization.
 
 func (c *Cache) removeElement(e *list.Element) {
 	c.lru.Remove(e)
-	item := e.Value.(*Item)
+	c.unindex(e.Value.(*Item))
+	recycle(e)
+}
+
+/*
+unindex drops item from the primary map and the lock-free index,
+and releases its size from the byte total.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) unindex(item *Item) {
 	delete(c.data, item.key)
+	c.index.Delete(item.key)
+	if c.expiry != nil {
+		c.expiry.remove(item)
+	}
+	c.bytes -= item.size
+	c.cost -= item.cost
+	c.trackCompressed(item.value, -1)
+	if item.pinned {
+		c.pinned--
+	} else {
+		c.priorities[item.priority.slot()]--
+	}
+}
+
+/*
+expireElement removes an element whose TTL has elapsed and
+records the removal in the Expirations counter.
+
+The janitor flag attributes the removal to its cause:
+
+- true  → ExpiredByJanitor (active expiration)
+- false → ExpiredOnAccess (lazy expiration, discovered by a read
+          or write touching the key)
+
+While the cache is frozen, the element is left in place.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) expireElement(e *list.Element, janitor bool) {
+	if c.Frozen() {
+		return
+	}
+	item := e.Value.(*Item)
+	key := item.key
+	if c.onEvict != nil {
+		c.onEvict(key, c.unpack(item.value), EventExpired)
+	}
+	c.removeElement(e)
+	c.counters.expirations.Add(1)
+	if janitor {
+		c.counters.expiredByJanitor.Add(1)
+	} else {
+		c.counters.expiredOnAccess.Add(1)
+	}
+	c.emit(EventExpired, key)
+}
+
+/*
+SetOnEvict replaces the eviction callback of a running cache (see
+WithOnEvict); nil removes it. Removals already in progress finish
+with the previous callback, since both run under the same lock.
+*/
+
+func (c *Cache) SetOnEvict(fn func(key string, value interface{}, reason EventType)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvict = fn
+}
+
+/*
+evictionStorm detects eviction storms: more evictions within one
+second than the cache's entire capacity. This means the working set
+is being cycled through the cache faster than it can be reused, and
+usually indicates an undersized cache or a scan-heavy workload.
+
+At most one warning is logged per second.
+*/
+
+type evictionStorm struct {
+	second int64
+	count  int
+	warned bool
+}
+
+/*
+record counts one eviction and logs a warning when the storm
+threshold is crossed.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (s *evictionStorm) record(c *Cache) {
+	if c.log == nil {
+		return
+	}
+
+	now := c.clock.Now().Unix()
+	if now != s.second {
+		s.second, s.count, s.warned = now, 0, false
+	}
+	s.count++
+
+	if !s.warned && s.count >= c.maxEntries {
+		s.warned = true
+		c.log.Warn("tempuscache: eviction storm",
+			"evictions_per_second", s.count, "max_entries", c.maxEntries)
+	}
 }
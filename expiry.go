@@ -0,0 +1,168 @@
+package tempuscache
+
+import "container/heap"
+
+/*
+expiry.go implements the bucketed expiration index enabled by
+WithTTLBuckets.
+
+================================================================================
+WHY?
+================================================================================
+
+The janitor's default sweep walks every entry to find the expired
+ones (see deleteExpired), so its cost grows with the cache, not with
+the work done. In caches where most entries use one of a few TTLs,
+deadlines cluster, and entries expiring together can be found
+together.
+
+================================================================================
+STRUCTURE
+================================================================================
+
+Deadlines are rounded up to a multiple of the bucket width; entries
+with the same rounded deadline share a bucket. A bucket is due once
+its end has passed: every entry in it has expired then.
+
+STRUCTURE FIELDS:
+
+width   -> Bucket width in nanoseconds
+buckets -> Entries by bucket end (UnixNano)
+ends    -> Min-heap of bucket ends, so the next due bucket is found in
+           O(1); an end may remain after its bucket empties, and is
+           skipped when popped
+
+Every entry with a deadline is in exactly one bucket (Item.bucket
+records which), and entries without one are in none. Writes, Expire,
+and removals keep the buckets current in O(1), plus O(log b) to
+open one of b buckets.
+
+================================================================================
+JANITOR
+================================================================================
+
+A sweep pops due buckets and removes their entries, without looking
+at any entry that has not expired: O(expired + due buckets) instead
+of O(n). Entries are removed up to one bucket width after they
+expire; lookups still treat them as expired on time. With
+WithStaleWhileRevalidate, a bucket is due once the stale window has
+passed its end as well.
+*/
+
+type expiryBuckets struct {
+	width   int64
+	buckets map[int64]map[*Item]struct{}
+	ends    endHeap
+}
+
+func newExpiryBuckets(width int64) *expiryBuckets {
+	return &expiryBuckets{width: width, buckets: make(map[int64]map[*Item]struct{})}
+}
+
+/*
+add files item under its deadline, which must be set.
+*/
+
+func (b *expiryBuckets) add(item *Item) {
+	end := (item.expiration/b.width + 1) * b.width
+	bucket, found := b.buckets[end]
+	if !found {
+		bucket = make(map[*Item]struct{})
+		b.buckets[end] = bucket
+		heap.Push(&b.ends, end)
+	}
+	bucket[item] = struct{}{}
+	item.bucket = end
+}
+
+// remove drops item from its bucket, if it is in one.
+func (b *expiryBuckets) remove(item *Item) {
+	if item.bucket == 0 {
+		return
+	}
+	if bucket := b.buckets[item.bucket]; len(bucket) <= 1 {
+		delete(b.buckets, item.bucket)
+	} else {
+		delete(bucket, item)
+	}
+	item.bucket = 0
+}
+
+/*
+pop removes and returns the entries of the earliest bucket ending at
+or before end, or nil if there is none.
+*/
+
+func (b *expiryBuckets) pop(end int64) map[*Item]struct{} {
+	for len(b.ends) > 0 && b.ends[0] <= end {
+		next := heap.Pop(&b.ends).(int64)
+		if bucket, found := b.buckets[next]; found {
+			delete(b.buckets, next)
+			return bucket
+		}
+	}
+	return nil
+}
+
+// reset forgets every bucket, as flush does every entry.
+func (b *expiryBuckets) reset() {
+	b.buckets = make(map[int64]map[*Item]struct{})
+	b.ends = nil
+}
+
+/*
+schedule refiles item after its deadline was set or changed.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) schedule(item *Item) {
+	if c.expiry == nil {
+		return
+	}
+	c.expiry.remove(item)
+	if item.expiration != 0 {
+		c.expiry.add(item)
+	}
+}
+
+/*
+expireBuckets is the janitor sweep over the expiration index: it
+removes the entries of every due bucket and returns their number.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) expireBuckets() int {
+	end := c.now()
+	if c.staleWindow > 0 && c.store != nil {
+		end -= int64(c.staleWindow)
+	}
+
+	removed := 0
+	for bucket := c.expiry.pop(end); bucket != nil; bucket = c.expiry.pop(end) {
+		for item := range bucket {
+			item.bucket = 0
+			c.expireElement(c.data[item.key], true)
+			removed++
+		}
+	}
+	return removed
+}
+
+// endHeap is a min-heap of bucket ends (see container/heap).
+type endHeap []int64
+
+func (h endHeap) Len() int           { return len(h) }
+func (h endHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h endHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *endHeap) Push(x any)        { *h = append(*h, x.(int64)) }
+
+func (h *endHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,59 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLBucketsExpireWholeBuckets(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	c := New(WithClock(clock), WithTTLBuckets(time.Second))
+	defer c.Stop()
+
+	for _, k := range []string{"a", "b", "c"} {
+		c.Set(k, 1, time.Minute)
+	}
+	c.Set("hour", 1, time.Hour)
+	c.Set("forever", 1, 0)
+	c.Set("extended", 1, time.Minute)
+	c.Expire("extended", 2*time.Hour)
+	c.Set("overwritten", 1, time.Minute)
+	c.Set("overwritten", 1, 2*time.Hour)
+	c.Set("deleted", 1, time.Minute)
+	c.Delete("deleted")
+
+	clock.advance(59 * time.Second)
+	if n := c.DeleteExpired(); n != 0 {
+		t.Fatalf("expected no removals before the deadline, got %d", n)
+	}
+
+	clock.advance(2 * time.Second)
+	if n := c.DeleteExpired(); n != 3 {
+		t.Fatalf("expected the minute bucket to be removed, got %d", n)
+	}
+	if got := c.Stats(); got.Entries != 4 || got.ExpiredByJanitor != 3 {
+		t.Fatalf("unexpected stats after sweep: %+v", got)
+	}
+
+	clock.advance(time.Hour)
+	if n := c.DeleteExpired(); n != 1 {
+		t.Fatalf("expected the hour bucket to be removed, got %d", n)
+	}
+	if c.Len() != 3 {
+		t.Fatalf("expected extended, forever and overwritten to remain, got %d", c.Len())
+	}
+}
+
+func TestTTLBucketsFlush(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	c := New(WithClock(clock), WithTTLBuckets(time.Second))
+	defer c.Stop()
+
+	c.Set("a", 1, time.Second)
+	c.Flush()
+	c.Set("a", 2, time.Second)
+	clock.advance(3 * time.Second)
+	if n := c.DeleteExpired(); n != 1 {
+		t.Fatalf("expected only the new entry to be removed, got %d", n)
+	}
+}
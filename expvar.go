@@ -0,0 +1,72 @@
+package tempuscache
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// ErrExpvarExists is returned by PublishExpvar for a name that is already published.
+var ErrExpvarExists = errors.New("tempuscache: expvar name already published")
+
+// expvarMu makes the check and publication of PublishExpvar atomic.
+var expvarMu sync.Mutex
+
+/*
+expvarStats renders the cache's live statistics for expvar.
+
+The function is evaluated on every /debug/vars request, so the
+published values are always current.
+*/
+
+func (c *Cache) expvarStats() interface{} {
+	s := c.Stats()
+	return map[string]interface{}{
+		"hits":           s.Hits,
+		"misses":         s.Misses,
+		"sets":           s.Sets,
+		"deletes":        s.Deletes,
+		"evictions":      s.Evictions,
+		"expirations":    s.Expirations,
+		"entries":        s.Entries,
+		"bytes":          s.EstimatedBytes,
+		"uptime_seconds": s.Uptime.Seconds(),
+		"capacity":       c.Capacity(),
+	}
+}
+
+/*
+PublishExpvar registers the cache's live statistics under name
+in the standard expvar registry.
+
+================================================================================
+RESULT
+================================================================================
+
+Importing net/http/expvar-enabled servers (or net/http/pprof style
+debug muxes) then shows, under /debug/vars:
+
+    "sessions": {"capacity": 10000, "entries": 812, "evictions": 3,
+                 "expirations": 40, "hits": 9120, "misses": 301}
+
+================================================================================
+USAGE CONTRACT
+================================================================================
+
+expvar offers no way to unregister, so each name can be used once
+per process: unlike expvar.Publish, PublishExpvar returns an error
+wrapping ErrExpvarExists for a name already registered, instead of
+panicking.
+*/
+
+func (c *Cache) PublishExpvar(name string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("%w: %q", ErrExpvarExists, name)
+	}
+	expvar.Publish(name, expvar.Func(c.expvarStats))
+	return nil
+}
@@ -0,0 +1,53 @@
+package tempuscache
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// expvarName returns a name unused in this process, as expvar names cannot be unregistered.
+func expvarName(t *testing.T) string {
+	return t.Name() + "_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+func TestWithExpvar(t *testing.T) {
+	name := expvarName(t)
+	cache := New(WithExpvar(name), WithMaxEntries(5))
+	defer cache.Stop()
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("expected stats to be published")
+	}
+
+	var got map[string]float64
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["hits"] != 1 || got["entries"] != 1 || got["capacity"] != 5 {
+		t.Fatalf("unexpected published stats: %v", got)
+	}
+}
+
+func TestExpvarDuplicateName(t *testing.T) {
+	name := expvarName(t)
+	cache := New(WithExpvar(name))
+	defer cache.Stop()
+
+	if err := cache.PublishExpvar(name); !errors.Is(err, ErrExpvarExists) {
+		t.Fatalf("expected ErrExpvarExists, got %v", err)
+	}
+	if _, err := NewWithError(WithExpvar(name)); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	// New logs the conflict instead of panicking.
+	other := New(WithExpvar(name))
+	other.Stop()
+}
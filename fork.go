@@ -0,0 +1,92 @@
+package tempuscache
+
+/*
+fork.go implements Clone, which forks a cache into an independent
+copy.
+
+================================================================================
+WHY?
+================================================================================
+
+Speculative or request-scoped work often starts from a shared,
+warm baseline and must not leak its writes back into it. Rebuilding
+the baseline entry by entry through Set is slow, resets TTLs unless
+each deadline is recomputed, and loses pins, priorities, and costs.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Clone returns a new, running cache with:
+
+- The same entries in the same LRU order, including their absolute
+  deadlines, pins, priorities, costs, and per-entry metadata
+  (creation time, hits, last access).
+- The same configuration: the options New was given, with the
+  current capacity (Resize), cleanup interval (SetCleanupInterval),
+  default TTL (SetDefaultTTL), and eviction callback (SetOnEvict).
+
+It does not inherit:
+
+- Options binding an external resource that two caches cannot
+  share: WithAOF, WithAutoSnapshot, WithSnapshotPath, WithExpvar,
+  WithInvalidation, and WithTrace.
+- Statistics, event subscribers, and the frozen state: the clone
+  starts with zero counters and is writable.
+
+Entries are independent from then on: writes, deletions, and
+evictions in one cache do not affect the other. Values themselves
+are shared, exactly as between a Set and later Gets; values kept in
+encoded form (WithSerializer, WithCompression) are immutable, and
+WithCopyOnRead keeps readers of either cache from modifying them.
+
+================================================================================
+COST
+================================================================================
+
+O(n) under the source's exclusive lock: one Item and list node per
+entry, without re-encoding values or running any write path (key
+validation, size limits, admission, events, persistence).
+*/
+
+/*
+Clone returns an independent copy of the cache (see fork.go).
+*/
+
+func (c *Cache) Clone() *Cache {
+	d := configure(c.opts)
+	d.aofPath, d.aofRewriteSize = "", 0
+	d.snapshotPath, d.snapshotInterval, d.snapshotLoad = "", 0, false
+	d.expvarName = ""
+	d.inval = nil
+	d.tracer = nil
+	d.initReadPath()
+
+	c.mu.Lock()
+	c.applyPromotions()
+	d.maxEntries = c.maxEntries
+	d.interval = c.interval
+	d.defaultTTL, d.onEvict = c.defaultTTL, c.onEvict
+
+	for elem := c.lru.Back(); elem != nil; elem = elem.Prev() {
+		src := elem.Value.(*Item)
+		item := newItem(src.key, src.value, src.expiration, src.size, src.created)
+		item.written, item.delta = src.written, src.delta
+		item.pinned, item.priority, item.cost = src.pinned, src.priority, src.cost
+		item.accessed.Store(src.accessed.Load())
+		item.hits.Store(src.hits.Load())
+
+		d.publish(item)
+		d.schedule(item)
+		e := d.lru.PushFront(item)
+		d.data[item.key] = e
+		d.index.Store(item.key, e)
+	}
+	d.bytes, d.cost = c.bytes, c.cost
+	d.pinned, d.priorities = c.pinned, c.priorities
+	d.compressedBytes, d.uncompressedBytes = c.compressedBytes, c.uncompressedBytes
+	c.mu.Unlock()
+
+	d.start()
+	return d
+}
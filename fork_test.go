@@ -0,0 +1,62 @@
+package tempuscache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	snap := filepath.Join(t.TempDir(), "base.snap")
+	base := New(WithClock(clock), WithMaxEntries(10), WithAutoSnapshot(snap, 0))
+	defer base.Stop()
+
+	base.Set("a", 1, time.Minute)
+	base.SetPinned("b", 2, 0)
+	base.SetWithPriority("c", 3, 0, PriorityHigh)
+	base.Get("a")
+	base.Resize(3)
+
+	fork := base.Clone()
+	defer fork.Stop()
+
+	if !slices.Equal(fork.Keys(), base.Keys()) {
+		t.Fatalf("expected the same LRU order, got %v and %v", fork.Keys(), base.Keys())
+	}
+	if ttl, _ := fork.TTL("a"); ttl != time.Minute {
+		t.Fatalf("expected the same deadline, got %v", ttl)
+	}
+	if info, _ := fork.Inspect("b"); !info.Pinned {
+		t.Fatal("expected pins to be copied")
+	}
+	if info, _ := fork.Inspect("c"); info.Priority != PriorityHigh {
+		t.Fatal("expected priorities to be copied")
+	}
+	if fork.Capacity() != 3 {
+		t.Fatalf("expected the resized capacity, got %d", fork.Capacity())
+	}
+	if s := fork.Stats(); s.Hits != 0 || s.Entries != 3 || s.EstimatedBytes != base.Stats().EstimatedBytes {
+		t.Fatalf("expected fresh counters and the same gauges, got %+v", s)
+	}
+
+	// The copies are independent.
+	fork.Set("d", 4, 0)
+	fork.Delete("b")
+	if _, ok := base.Get("d"); ok {
+		t.Fatal("expected writes to the clone not to reach the source")
+	}
+	if _, ok := base.Get("b"); !ok {
+		t.Fatal("expected deletes in the clone not to reach the source")
+	}
+
+	// Resources bound to the source are not inherited.
+	if err := fork.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(snap); !os.IsNotExist(err) {
+		t.Fatalf("expected the clone not to write the source's snapshot, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package tempuscache
+
+/*
+freeze.go implements read-only mode (Freeze, Unfreeze).
+
+================================================================================
+WHY?
+================================================================================
+
+During a blue/green cutover, the outgoing cache is a warm copy worth
+serving from while the incoming one fills, but nothing should change
+it any more: writes would be lost with it, and evictions or
+expirations would only make it colder.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+While frozen, the cache serves reads but does not change:
+
+- Writes of any kind (Set, SetContext, increments, CompareAndSwap,
+  Store loads, Warm, restores, ...) store nothing; see FreezePolicy
+  for what they return.
+- Delete, DeleteMany, Flush, and Expire change nothing and report
+  that no key was affected.
+- Hits do not reorder the LRU list.
+- Expired entries are reported as misses but not removed, and the
+  janitor does not run; Resize only changes the limit, evicting
+  nothing until the cache is unfrozen.
+
+Statistics, events, pins, and priorities keep working. Unfreeze
+resumes normal operation; expired entries are then removed as
+usual.
+*/
+
+// FreezePolicy selects what writes to a frozen cache return.
+type FreezePolicy int32
+
+const (
+	// RejectWrites makes writes fail with ErrFrozen. Methods without
+	// an error result drop the write silently.
+	RejectWrites FreezePolicy = iota + 1
+
+	// IgnoreWrites makes every write succeed without effect.
+	IgnoreWrites
+)
+
+/*
+Freeze makes the cache read-only (see freeze.go); policy selects
+whether writes fail or are ignored. Freezing a frozen cache changes
+its policy.
+*/
+
+func (c *Cache) Freeze(policy FreezePolicy) {
+	if policy != IgnoreWrites {
+		policy = RejectWrites
+	}
+	c.mu.Lock()
+	c.frozen.Store(int32(policy))
+	c.mu.Unlock()
+}
+
+// Unfreeze makes a frozen cache writable again.
+func (c *Cache) Unfreeze() {
+	c.mu.Lock()
+	c.frozen.Store(0)
+	c.mu.Unlock()
+}
+
+// Frozen reports whether the cache is read-only.
+func (c *Cache) Frozen() bool {
+	return c.frozen.Load() != 0
+}
+
+/*
+frozenWrite returns the result of a write to a frozen cache.
+*/
+
+func (c *Cache) frozenWrite() error {
+	if FreezePolicy(c.frozen.Load()) == IgnoreWrites {
+		return nil
+	}
+	return ErrFrozen
+}
@@ -0,0 +1,70 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFreezeRejectsWrites(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock), WithMaxEntries(2))
+	defer cache.Stop()
+
+	cache.Set("a", int64(1), 0)
+	cache.Set("b", 2, time.Second)
+	cache.Freeze(RejectWrites)
+	if !cache.Frozen() {
+		t.Fatal("expected the cache to be frozen")
+	}
+
+	if err := cache.SetContext(context.Background(), "c", 3, 0); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen, got %v", err)
+	}
+	if _, err := cache.IncrementBy("a", 1, 0); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected increments to be rejected, got %v", err)
+	}
+	cache.Set("a", 10, 0)
+	cache.Delete("a")
+	cache.Flush()
+	if cache.CompareAndSwap("a", int64(1), int64(2), 0) || cache.Expire("a", time.Minute) {
+		t.Fatal("expected CompareAndSwap and Expire to report no change")
+	}
+	if v, ok := cache.Get("a"); !ok || v != int64(1) {
+		t.Fatalf("expected reads to be served unchanged, got %v", v)
+	}
+
+	// Hits do not reorder; expired entries miss but stay.
+	cache.Get("b")
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Fatalf("expected hits not to reorder the list, got %v", keys)
+	}
+	clock.advance(2 * time.Second)
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected the expired entry to miss")
+	}
+	if cache.DeleteExpired() != 0 || cache.Len() != 2 {
+		t.Fatalf("expected the expired entry to stay, got %d entries", cache.Len())
+	}
+
+	cache.Unfreeze()
+	cache.Set("c", 3, 0)
+	if cache.Len() != 2 {
+		t.Fatalf("expected writes and expiration to resume, got %v", cache.Keys())
+	}
+}
+
+func TestFreezeIgnoresWrites(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	cache.Freeze(IgnoreWrites)
+	if err := cache.SetContext(context.Background(), "a", 1, 0); err != nil {
+		t.Fatalf("expected writes to be ignored silently, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("expected nothing to be stored")
+	}
+}
@@ -0,0 +1,99 @@
+package tempuscache
+
+/*
+frequency.go implements access frequency tracking, enabled by
+WithFrequencySketch.
+
+================================================================================
+WHY?
+================================================================================
+
+Recency says when a key was last used; frequency says how often.
+Frequency-based policies (LFU, TinyLFU admission) need a per-key
+count that covers keys not currently cached, in bounded memory, and
+that forgets the distant past so yesterday's hot keys do not stay
+hot forever.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Every lookup (hit or miss) is counted in a count-min sketch over key
+hashes (see sketch.go). After every 10 * width lookups, all counts
+are halved, so a count approximates recent lookups with older ones
+weighing exponentially less (aging, as in TinyLFU).
+
+Frequency(key) returns the estimate for any key, cached or not. It
+may over-count under hash collisions, and aging keeps it below about
+10 * width.
+
+Like WithTopK, tracking counts lookups in order under the lock, so
+it disables the lock-free hit path (see readpath.go).
+
+================================================================================
+MEMORY
+================================================================================
+
+16 * width bytes, independent of the number of distinct keys.
+*/
+
+type frequencySketch struct {
+	sketch *countMinSketch
+	added  int
+	period int
+}
+
+func newFrequencySketch(width int) *frequencySketch {
+	sketch := newCountMinSketch(width)
+	return &frequencySketch{sketch: sketch, period: 10 * int(sketch.width)}
+}
+
+/*
+observe counts one lookup of hash h, halving all counts at the end
+of every period.
+*/
+
+func (f *frequencySketch) observe(h uint64) {
+	f.sketch.add(h)
+	if f.added++; f.added >= f.period {
+		f.sketch.halve()
+		f.added /= 2
+	}
+}
+
+/*
+Frequency returns the estimated number of recent lookups of key (see
+frequency.go), or 0 unless WithFrequencySketch is used.
+*/
+
+func (c *Cache) Frequency(key string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.frequency == nil {
+		return 0
+	}
+	return c.frequency.sketch.estimate(c.hash(key))
+}
+
+/*
+observe counts one lookup of key in the hot-key tracker and the
+frequency sketch, whichever are enabled.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) observe(key string) {
+	if c.topK == nil && c.frequency == nil {
+		return
+	}
+
+	h := c.hash(key)
+	if c.topK != nil {
+		c.topK.observe(key, h)
+	}
+	if c.frequency != nil {
+		c.frequency.observe(h)
+	}
+}
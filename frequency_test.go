@@ -0,0 +1,46 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFrequencySketch(t *testing.T) {
+	cache := New(WithFrequencySketch(64))
+	defer cache.Stop()
+
+	cache.Set("hot", 1, 0)
+	for i := 0; i < 50; i++ {
+		cache.Get("hot")
+	}
+	cache.Get("missing")
+	cache.Get("missing")
+
+	if n := cache.Frequency("hot"); n < 50 {
+		t.Fatalf("expected at least 50 lookups of hot, got %d", n)
+	}
+	if n := cache.Frequency("missing"); n < 2 || n > 10 {
+		t.Fatalf("expected misses to be counted, got %d", n)
+	}
+	if n := New().Frequency("hot"); n != 0 {
+		t.Fatalf("expected 0 without a sketch, got %d", n)
+	}
+}
+
+func TestFrequencySketchAging(t *testing.T) {
+	cache := New(WithFrequencySketch(64))
+	defer cache.Stop()
+
+	for i := 0; i < 300; i++ {
+		cache.Get("old")
+	}
+	before := cache.Frequency("old")
+
+	// Enough other lookups to halve the counts several times.
+	for i := 0; i < 10*64*4; i++ {
+		cache.Get(fmt.Sprint(i % 8))
+	}
+	if after := cache.Frequency("old"); after >= before/4 {
+		t.Fatalf("expected old lookups to fade, got %d after %d", after, before)
+	}
+}
@@ -0,0 +1,100 @@
+package tempuscache
+
+/*
+ghost.go implements the ghost history, enabled by WithGhostHistory:
+a bounded record of recently evicted keys.
+
+================================================================================
+WHY?
+================================================================================
+
+Evictions alone do not say whether capacity is too small: evicting
+cold entries is the cache doing its job. Evicting entries that are
+requested again right afterwards is not. Telling the two apart
+requires remembering what was evicted after the entries are gone.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Every eviction records the hash of the evicted key; the n most
+recent are kept. A lookup that misses on a recorded key is counted
+in Stats().PrematureEvictions, and the key is forgotten, so each
+eviction is counted at most once.
+
+PrematureEvictions / Evictions approximates the share of evictions
+the cache would have avoided with about n more entries. A high
+share means capacity is too small for the working set.
+
+Ghosts are hashes (see hash.go), not keys: a collision can count a
+miss of a never-evicted key, with probability about n / 2^64.
+
+Membership (contains) is also what adaptive policies such as ARC
+and 2Q use to size their recency and frequency segments.
+
+================================================================================
+MEMORY
+================================================================================
+
+About 40 bytes per remembered eviction: a ring slot and a map entry.
+*/
+
+type ghostList struct {
+	ring  []uint64
+	next  int
+	slots map[uint64]int
+}
+
+func newGhostList(n int) *ghostList {
+	return &ghostList{ring: make([]uint64, 0, n), slots: make(map[uint64]int, n)}
+}
+
+/*
+add records hash h, forgetting the oldest recorded hash when full.
+*/
+
+func (g *ghostList) add(h uint64) {
+	if len(g.ring) < cap(g.ring) {
+		g.ring = append(g.ring, h)
+	} else {
+		// The oldest slot is only forgotten if h was not recorded again.
+		if old := g.ring[g.next]; g.slots[old] == g.next {
+			delete(g.slots, old)
+		}
+		g.ring[g.next] = h
+	}
+	g.slots[h] = g.next
+	g.next = (g.next + 1) % cap(g.ring)
+}
+
+// contains reports whether hash h was evicted recently.
+func (g *ghostList) contains(h uint64) bool {
+	_, ok := g.slots[h]
+	return ok
+}
+
+/*
+forget removes hash h and reports whether it was recorded.
+*/
+
+func (g *ghostList) forget(h uint64) bool {
+	if !g.contains(h) {
+		return false
+	}
+	delete(g.slots, h)
+	return true
+}
+
+/*
+missedGhost counts a miss of key in PrematureEvictions if key was
+evicted recently.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) missedGhost(key string) {
+	if c.ghosts != nil && c.ghosts.forget(c.hash(key)) {
+		c.counters.prematureEvictions.Add(1)
+	}
+}
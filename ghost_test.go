@@ -0,0 +1,42 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGhostHistoryCountsPrematureEvictions(t *testing.T) {
+	cache := New(WithMaxEntries(2), WithGhostHistory(2))
+	defer cache.Stop()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, key, 0)
+	}
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("never")
+	if s := cache.Stats(); s.PrematureEvictions != 1 || s.Evictions != 2 {
+		t.Fatalf("expected 1 premature eviction out of 2, got %+v", s)
+	}
+
+	// Only the last n evictions are remembered.
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+	cache.Get("b")
+	cache.Get("7")
+	if s := cache.Stats(); s.PrematureEvictions != 2 {
+		t.Fatalf("expected only the recent eviction to count, got %d", s.PrematureEvictions)
+	}
+}
+
+func TestGhostListReAdd(t *testing.T) {
+	g := newGhostList(2)
+	g.add(1)
+	g.add(2)
+	g.add(1) // overwrites the slot of 1, which is recorded again
+	g.add(3) // overwrites the slot of 2
+	if !g.contains(1) || g.contains(2) || !g.contains(3) {
+		t.Fatalf("unexpected ghosts: %v", g.slots)
+	}
+}
@@ -0,0 +1,60 @@
+package tempuscache
+
+import "hash/maphash"
+
+/*
+hash.go implements the key hash used by the cache's internal indexes
+(WithHasher).
+
+================================================================================
+WHERE KEYS ARE HASHED
+================================================================================
+
+The primary map hashes keys with the Go runtime's own hash. The
+cache hashes them itself for its probabilistic structures and lock
+stripes:
+
+- hot-key and frequency sketches (WithTopK, WithFrequencySketch)
+- the doorkeeper's bloom filter (WithDoorkeeper)
+- the ghost history (WithGhostHistory)
+- write-through lock stripes (WithWriteThrough)
+
+All of them use c.hash: the function given to WithHasher, or hashKey
+(64-bit FNV-1a) by default. FNV-1a mixes in every byte, so long
+common prefixes do not collide by themselves, but it is slow for
+long keys and its low bits mix poorly for some key schemes.
+MapHash is a faster, seeded alternative.
+
+Operation traces (WithTrace) always use hashKey: trace files are
+read by other processes and must hash keys the same way everywhere.
+
+================================================================================
+REQUIREMENTS
+================================================================================
+
+A hasher must be deterministic for the life of the cache and safe
+for concurrent use. It is called with and without the cache lock
+held, on every lookup when the sketches are enabled, so it should
+not allocate.
+*/
+
+/*
+MapHash returns a hasher for WithHasher based on hash/maphash, with
+a random seed chosen once per call. It is faster than the default on
+long keys, but hashes differ between processes.
+*/
+
+func MapHash() func(key string) uint64 {
+	seed := maphash.MakeSeed()
+	return func(key string) uint64 {
+		return maphash.String(seed, key)
+	}
+}
+
+// hash returns the hash of key used by the internal indexes.
+func (c *Cache) hash(key string) uint64 {
+	if c.hasher != nil {
+		return c.hasher(key)
+	}
+	return hashKey(key)
+}
@@ -0,0 +1,36 @@
+package tempuscache
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithHasher(t *testing.T) {
+	var calls atomic.Int64
+	constant := func(string) uint64 {
+		calls.Add(1)
+		return 42
+	}
+	cache := New(WithFrequencySketch(64), WithHasher(constant))
+	defer cache.Stop()
+
+	cache.Get("a")
+	cache.Get("b")
+	if calls.Load() == 0 {
+		t.Fatal("expected the hasher to be used")
+	}
+	// With every key hashing alike, the sketch cannot tell them apart.
+	if n := cache.Frequency("c"); n != 2 {
+		t.Fatalf("expected lookups of a and b to count for c, got %d", n)
+	}
+}
+
+func TestMapHash(t *testing.T) {
+	h := MapHash()
+	if h("key") != h("key") {
+		t.Fatal("expected MapHash to be deterministic")
+	}
+	if h("key:1") == h("key:2") {
+		t.Fatal("expected distinct keys to hash apart")
+	}
+}
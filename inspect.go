@@ -0,0 +1,102 @@
+package tempuscache
+
+import "time"
+
+/*
+EntryInfo describes a single cache entry for debugging purposes.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Key        -> The inspected key
+Created    -> When the key was inserted (overwrites keep the original time)
+LastAccess -> Last successful read (zero if never read)
+Expiration -> Absolute deadline (zero if the entry never expires)
+TTL        -> Remaining lifetime (0 if expired or never expires)
+Expired    -> Whether the deadline has passed but the entry has not
+              yet been removed by lazy expiration or the janitor
+Hits       -> Number of successful reads of this entry
+Size       -> Estimated memory footprint in bytes
+Position   -> LRU position: 0 is the most recently used entry,
+              Len()-1 the next eviction candidate (pinned and
+              higher-priority entries are skipped by eviction)
+Pinned     -> Whether the entry is exempt from eviction (see Pin)
+Priority   -> Eviction priority (see SetPriority)
+Cost       -> Share of WithMaxCost held (see SetWithCost)
+*/
+
+type EntryInfo struct {
+	Key        string
+	Created    time.Time
+	LastAccess time.Time
+	Expiration time.Time
+	TTL        time.Duration
+	Expired    bool
+	Hits       uint64
+	Size       int64
+	Position   int
+	Pinned     bool
+	Priority   Priority
+	Cost       int64
+}
+
+/*
+Inspect returns metadata about key without affecting cache state.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Does NOT move the entry in the LRU list.
+- Does NOT count as a hit or miss.
+- Does NOT remove the entry if it has expired; it is reported with
+  Expired set instead, which helps answer "is this stale?".
+
+Returns false if the key is not stored at all.
+
+================================================================================
+COST
+================================================================================
+
+Computing Position walks the LRU list from the front, so Inspect
+is O(n). It is intended for debugging and admin tooling, not for
+hot paths.
+*/
+
+func (c *Cache) Inspect(key string) (EntryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.applyPromotions()
+	elem, found := c.data[key]
+	if !found {
+		return EntryInfo{}, false
+	}
+
+	item := elem.Value.(*Item)
+	info := EntryInfo{
+		Key:      key,
+		Created:  time.Unix(0, item.created),
+		Expired:  c.expired(item),
+		Hits:     item.hits.Load(),
+		Size:     item.size,
+		Pinned:   item.pinned,
+		Priority: item.priority,
+		Cost:     item.cost,
+	}
+	if accessed := item.accessed.Load(); accessed != 0 {
+		info.LastAccess = time.Unix(0, accessed)
+	}
+	if item.expiration != 0 {
+		info.Expiration = time.Unix(0, item.expiration)
+		if !info.Expired {
+			info.TTL = info.Expiration.Sub(c.clock.Now())
+		}
+	}
+
+	for e := c.lru.Front(); e != elem; e = e.Next() {
+		info.Position++
+	}
+	return info, true
+}
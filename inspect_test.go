@@ -0,0 +1,47 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", "value", time.Hour)
+	cache.Set("b", 2, 0)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Set("b", 3, 0)
+
+	info, found := cache.Inspect("a")
+	if !found {
+		t.Fatal("expected 'a' to be inspectable")
+	}
+	if info.Hits != 2 || info.Position != 1 || info.LastAccess.IsZero() {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.TTL <= 0 || info.TTL > time.Hour {
+		t.Fatalf("unexpected remaining TTL: %v", info.TTL)
+	}
+
+	// Inspect must not promote the entry or touch stats.
+	before := cache.Stats()
+	cache.Inspect("a")
+	if after := cache.Stats(); after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Fatal("expected Inspect not to affect stats")
+	}
+	if info, _ := cache.Inspect("a"); info.Position != 1 {
+		t.Fatal("expected Inspect not to affect LRU order")
+	}
+
+	cache.Set("stale", 1, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if info, found := cache.Inspect("stale"); !found || !info.Expired {
+		t.Fatalf("expected stale entry to be reported as expired, got %+v", info)
+	}
+
+	if _, found := cache.Inspect("missing"); found {
+		t.Fatal("expected missing key not to be found")
+	}
+}
@@ -0,0 +1,140 @@
+package tempuscache
+
+import "time"
+
+/*
+interceptor.go implements interceptors: middleware wrapping Get, Set,
+and Delete, configured with WithInterceptor.
+
+================================================================================
+WHY?
+================================================================================
+
+Cross-cutting concerns (logging, tracing, allow/deny lists,
+encrypting the values of some keys) otherwise need a wrapper type
+around the cache, which every call site then has to use instead of
+*Cache, or a fork of the core.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+An Interceptor receives each operation as an Op and the next step of
+the chain. It may inspect or rewrite the Op before passing it on,
+rewrite the result on the way back, or answer without calling next
+at all:
+
+    deny := func(op tempuscache.Op, next tempuscache.Invoker) (interface{}, bool) {
+        if strings.HasPrefix(op.Key, "internal:") {
+            return nil, false
+        }
+        return next(op)
+    }
+
+Interceptors run in the order given to WithInterceptor, the first
+outermost; the last one calls the cache itself. Results are:
+
+- OpGet    → the value and whether it was found
+- OpSet    → (nil, true)
+- OpDelete → (nil, whether a live entry was removed)
+
+Interceptors wrap Get, Set, and Delete, and so GetBytes, SetBytes,
+and DeleteContext, which call them. Every other method (GetContext,
+SetContext, batch operations, increments, Range, snapshots, ...)
+reads and writes entries directly. An interceptor that transforms
+values, e.g. to encrypt them, must therefore be the only way those
+entries are accessed.
+
+================================================================================
+COST
+================================================================================
+
+Without interceptors, Get, Set, and Delete check one nil field. With
+them, each call adds the interceptors' own cost and one Op passed by
+value; hits still take the lock-free path (see readpath.go).
+*/
+
+// OpKind identifies the operation an interceptor is called for.
+type OpKind int
+
+const (
+	OpGet OpKind = iota + 1
+	OpSet
+	OpDelete
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpGet:
+		return "get"
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+/*
+Op describes one intercepted operation.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Kind  -> Get, Set, or Delete
+Key   -> Key the operation applies to
+Value -> Value to store (OpSet only)
+TTL   -> Lifetime of the stored value (OpSet only)
+*/
+
+type Op struct {
+	Kind  OpKind
+	Key   string
+	Value interface{}
+	TTL   time.Duration
+}
+
+// Invoker runs an Op: the rest of an interceptor chain, or the cache itself.
+type Invoker func(op Op) (interface{}, bool)
+
+// Interceptor wraps an Op with behavior of its own; see interceptor.go.
+type Interceptor func(op Op, next Invoker) (interface{}, bool)
+
+/*
+buildChain composes the configured interceptors around invoke, the
+first outermost. It runs once, when the cache is configured.
+*/
+
+func (c *Cache) buildChain() {
+	if len(c.interceptors) == 0 {
+		c.chain = nil
+		return
+	}
+
+	chain := Invoker(c.invoke)
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], chain
+		chain = func(op Op) (interface{}, bool) {
+			return interceptor(op, next)
+		}
+	}
+	c.chain = chain
+}
+
+/*
+invoke runs op on the cache, at the end of the interceptor chain.
+*/
+
+func (c *Cache) invoke(op Op) (interface{}, bool) {
+	switch op.Kind {
+	case OpGet:
+		return c.getValue(op.Key)
+	case OpSet:
+		c.setValue(op.Key, op.Value, op.TTL)
+		return nil, true
+	case OpDelete:
+		return nil, c.deleteKey(op.Key)
+	}
+	return nil, false
+}
@@ -0,0 +1,82 @@
+package tempuscache
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(op Op, next Invoker) (interface{}, bool) {
+			calls = append(calls, name+">"+op.Kind.String())
+			v, ok := next(op)
+			calls = append(calls, name+"<"+op.Kind.String())
+			return v, ok
+		}
+	}
+
+	c := New(WithInterceptor(record("outer")), WithInterceptor(record("inner")))
+	defer c.Stop()
+
+	c.Set("k", 1, time.Minute)
+	c.Get("k")
+	c.Delete("k")
+
+	want := []string{
+		"outer>set", "inner>set", "inner<set", "outer<set",
+		"outer>get", "inner>get", "inner<get", "outer<get",
+		"outer>delete", "inner>delete", "inner<delete", "outer<delete",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("unexpected call order:\n got %v\nwant %v", calls, want)
+	}
+}
+
+func TestInterceptorRewrites(t *testing.T) {
+	deny := func(op Op, next Invoker) (interface{}, bool) {
+		if strings.HasPrefix(op.Key, "internal:") {
+			return nil, false
+		}
+		return next(op)
+	}
+	reverse := func(s string) string {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r)
+	}
+	encode := func(op Op, next Invoker) (interface{}, bool) {
+		if op.Kind == OpSet {
+			op.Value = reverse(op.Value.(string))
+		}
+		v, ok := next(op)
+		if op.Kind == OpGet && ok {
+			v = reverse(v.(string))
+		}
+		return v, ok
+	}
+
+	c := New(WithInterceptor(deny, encode))
+	defer c.Stop()
+
+	c.Set("internal:secret", "x", 0)
+	if c.Len() != 0 {
+		t.Fatal("expected the denied write to be dropped")
+	}
+
+	c.Set("greeting", "hello", 0)
+	if v, _, _ := c.GetContext(context.Background(), "greeting"); v != "olleh" {
+		t.Fatalf("expected the stored value to be encoded, got %v", v)
+	}
+	if v, ok := c.Get("greeting"); !ok || v != "hello" {
+		t.Fatalf("expected the read to be decoded, got %v", v)
+	}
+	if v, ok := c.GetBytes([]byte("greeting")); !ok || v != "hello" {
+		t.Fatalf("expected GetBytes to be intercepted, got %v", v)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Package resp implements the client side of the Redis serialization
+protocol (RESP2) shared by tempusclient and tempusredis: encoding
+commands and decoding replies. The server side lives in tempusresp.
+*/
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+Error is an error reply sent by the server. Unlike I/O errors, it
+leaves the connection usable.
+*/
+
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// WriteCommand writes args as an array of bulk strings; the caller flushes w.
+func WriteCommand(w *bufio.Writer, args []string) {
+	w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		w.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+	}
+}
+
+/*
+ReadReply decodes one reply: string (simple or bulk), int64, nil
+(null bulk or array), or []interface{}. Error replies are returned
+as Error.
+*/
+
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errors.New("empty RESP reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = ReadReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid RESP reply %q", line)
+}
@@ -0,0 +1,44 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteCommand(t *testing.T) {
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	WriteCommand(w, []string{"SET", "k", ""})
+	w.Flush()
+
+	if got, want := b.String(), "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$0\r\n\r\n"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader(
+		"+OK\r\n:42\r\n$5\r\nhello\r\n$-1\r\n*2\r\n$1\r\na\r\n:1\r\n-ERR nope\r\n?\r\n"))
+
+	for _, want := range []interface{}{"OK", int64(42), "hello", nil, []interface{}{"a", int64(1)}} {
+		got, err := ReadReply(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %#v, got %#v", want, got)
+		}
+	}
+
+	var rerr Error
+	if _, err := ReadReply(r); !errors.As(err, &rerr) || rerr != "ERR nope" {
+		t.Fatalf("expected an error reply, got %v", err)
+	}
+	if _, err := ReadReply(r); err == nil {
+		t.Fatal("expected an invalid reply to fail")
+	}
+}
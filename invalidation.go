@@ -0,0 +1,320 @@
+package tempuscache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+/*
+invalidation.go implements cross-node invalidation.
+
+================================================================================
+WHY?
+================================================================================
+
+Several replicas of a service each hold their own cache. After one
+replica writes a key, the others keep serving their stale copy until
+it expires. With invalidation enabled, every local write publishes
+the affected keys, and every replica drops its local copy when it
+receives them, so the next read goes to the source of truth.
+
+================================================================================
+FLOW
+================================================================================
+
+Local write (Set, Delete, Flush, and every other write path):
+    → Key is queued.
+    → A background worker publishes queued keys in batches through
+      the Broadcaster, never blocking the writer.
+
+Remote message (via the Subscriber):
+    → Messages from this cache itself are ignored.
+    → Listed keys (or everything, for All) are removed locally.
+    → Removals are NOT re-published, so messages never ping-pong.
+
+Entries loaded from a snapshot or replayed from the append-only log
+are not published: they restore this node's state, they are not new
+writes.
+
+================================================================================
+BOUNDED QUEUE
+================================================================================
+
+If more than maxPendingInvalidations keys are queued before the
+worker catches up, the batch is collapsed into a single All message.
+Peers then drop more than necessary, but never keep a stale entry.
+*/
+
+// maxPendingInvalidations bounds the keys queued for publishing.
+const maxPendingInvalidations = 4096
+
+// broadcastTimeout bounds a single Broadcast call.
+const broadcastTimeout = 5 * time.Second
+
+/*
+Invalidation is the message exchanged between caches.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Origin -> Identifier of the publishing cache (see NodeID)
+Keys   -> Keys to drop
+All    -> Drop every entry (Keys is then ignored)
+*/
+
+type Invalidation struct {
+	Origin string
+	Keys   []string
+	All    bool
+}
+
+/*
+Broadcaster publishes invalidations to other caches.
+
+Broadcast is called from a background worker, one call at a time.
+*/
+
+type Broadcaster interface {
+	Broadcast(ctx context.Context, msg Invalidation) error
+}
+
+/*
+Subscriber delivers invalidations published by other caches.
+
+Subscribe registers handle and returns a function that unregisters
+it. handle is safe to call from any goroutine, concurrently.
+*/
+
+type Subscriber interface {
+	Subscribe(handle func(Invalidation)) (unsubscribe func(), err error)
+}
+
+/*
+invalidator holds the state of cross-node invalidation.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+broadcaster -> Outgoing transport (nil = receive only)
+subscriber  -> Incoming transport (nil = publish only)
+pending     -> Keys queued for publishing
+all         -> A Flush (or queue overflow) is queued
+wake        -> Signals the publishing worker
+*/
+
+type invalidator struct {
+	broadcaster Broadcaster
+	subscriber  Subscriber
+	pending     []string
+	all         bool
+	wake        chan struct{}
+}
+
+func newNodeID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+/*
+NodeID returns the identifier this cache uses as the Origin of the
+invalidations it publishes. It is random per Cache instance.
+*/
+
+func (c *Cache) NodeID() string {
+	return c.nodeID
+}
+
+/*
+invalidate queues key for publishing (all = drop everything).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) invalidate(key string, all bool) {
+	inv := c.inval
+	if inv == nil || inv.broadcaster == nil || c.restoring {
+		return
+	}
+
+	if all || len(inv.pending) >= maxPendingInvalidations {
+		inv.all, inv.pending = true, nil
+	} else if !inv.all {
+		inv.pending = append(inv.pending, key)
+	}
+
+	select {
+	case inv.wake <- struct{}{}:
+	default:
+	}
+}
+
+/*
+applyInvalidation removes the entries named by a remote message.
+
+Removals are logged in the append-only log but are neither counted
+as Deletes nor published again.
+*/
+
+func (c *Cache) applyInvalidation(msg Invalidation) {
+	if msg.Origin == c.nodeID {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.All {
+		c.flush()
+		c.counters.invalidations.Add(1)
+		c.aofAppend(aofOpFlush, "", nil, 0)
+		c.emit(EventFlushed, "")
+		return
+	}
+
+	for _, key := range msg.Keys {
+		c.supersede(key)
+		elem, found := c.data[key]
+		if !found {
+			continue
+		}
+		c.removeElement(elem)
+		c.counters.invalidations.Add(1)
+		c.aofAppend(aofOpDelete, key, nil, 0)
+		c.emit(EventDeleted, key)
+	}
+}
+
+/*
+startInvalidation subscribes to remote invalidations and launches
+the publishing worker configured by WithInvalidation().
+
+The worker shares stopChan with the janitor; on Stop() it publishes
+whatever is still queued before returning.
+*/
+
+func (c *Cache) startInvalidation() {
+	inv := c.inval
+	if inv == nil {
+		return
+	}
+
+	var unsubscribe func()
+	if inv.subscriber != nil {
+		var err error
+		unsubscribe, err = inv.subscriber.Subscribe(c.applyInvalidation)
+		if err != nil {
+			c.logger().Error("tempuscache: invalidation subscribe failed", "err", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := func() {
+		if unsubscribe != nil {
+			unsubscribe()
+		}
+		cancel()
+	}
+
+	if inv.broadcaster == nil {
+		c.workers.Add(1)
+		go func() {
+			defer c.workers.Done()
+			<-c.stopChan
+			stop()
+		}()
+		return
+	}
+
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		defer stop()
+		for {
+			select {
+			case <-inv.wake:
+				c.publishInvalidations(ctx)
+			case <-c.stopChan:
+				c.publishInvalidations(ctx)
+				return
+			}
+		}
+	}()
+}
+
+// publishInvalidations sends one batch of queued invalidations.
+func (c *Cache) publishInvalidations(ctx context.Context) {
+	inv := c.inval
+
+	c.mu.Lock()
+	msg := Invalidation{Origin: c.nodeID, Keys: inv.pending, All: inv.all}
+	inv.pending, inv.all = nil, false
+	c.mu.Unlock()
+
+	if len(msg.Keys) == 0 && !msg.All {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, broadcastTimeout)
+	defer cancel()
+	if err := inv.broadcaster.Broadcast(ctx, msg); err != nil {
+		c.logger().Warn("tempuscache: invalidation broadcast failed",
+			"keys", len(msg.Keys), "all", msg.All, "err", err)
+	}
+}
+
+/*
+MemoryBus is an in-process Broadcaster and Subscriber.
+
+It connects caches living in the same process, and serves as a
+reference implementation and test double for network transports.
+Delivery is synchronous.
+*/
+
+type MemoryBus struct {
+	mu       sync.Mutex
+	handlers map[int]func(Invalidation)
+	next     int
+}
+
+// NewMemoryBus creates an empty MemoryBus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{handlers: make(map[int]func(Invalidation))}
+}
+
+// Broadcast delivers msg to every subscriber, including the sender.
+func (b *MemoryBus) Broadcast(_ context.Context, msg Invalidation) error {
+	b.mu.Lock()
+	handlers := make([]func(Invalidation), 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+// Subscribe registers handle until the returned function is called.
+func (b *MemoryBus) Subscribe(handle func(Invalidation)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.handlers[id] = handle
+
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}, nil
+}
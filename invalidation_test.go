@@ -0,0 +1,90 @@
+package tempuscache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	bus := NewMemoryBus()
+	a := New(WithInvalidation(bus, bus))
+	b := New(WithInvalidation(nil, bus)) // receive-only
+	defer a.Stop()
+	defer b.Stop()
+
+	b.Set("k", "stale", 0)
+	b.Set("other", 1, 0)
+
+	a.Set("k", "fresh", 0)
+	waitFor(t, func() bool { _, found := b.Get("k"); return !found })
+
+	if v, found := a.Get("k"); !found || v != "fresh" {
+		t.Fatal("expected the writer to keep its own value")
+	}
+	if _, found := b.Get("other"); !found {
+		t.Fatal("expected unrelated keys to survive")
+	}
+	if n := b.Stats().Invalidations; n != 1 {
+		t.Fatalf("expected 1 invalidation, got %d", n)
+	}
+
+	a.Flush()
+	waitFor(t, func() bool { return b.Len() == 0 })
+}
+
+func TestInvalidationNotRepublished(t *testing.T) {
+	bus := NewMemoryBus()
+
+	var received []Invalidation
+	unsubscribe, _ := bus.Subscribe(func(msg Invalidation) { received = append(received, msg) })
+	defer unsubscribe()
+
+	src := New()
+	src.Set("restored", 1, 0)
+	var snap bytes.Buffer
+	src.Save(&snap)
+
+	cache := New(WithInvalidation(bus, bus))
+	cache.Load(&snap)
+	bus.Broadcast(context.Background(), Invalidation{Origin: "peer", Keys: []string{"restored"}})
+	cache.Stop()
+
+	// Only the peer's own message: neither the snapshot load nor the
+	// remotely triggered removal is published.
+	if len(received) != 1 || received[0].Origin != "peer" {
+		t.Fatalf("unexpected messages: %+v", received)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("expected the remote invalidation to be applied")
+	}
+}
+
+func TestInvalidationOverflow(t *testing.T) {
+	cache := New(WithInvalidation(NewMemoryBus(), nil))
+	defer cache.Stop()
+
+	// Hold the lock so the worker cannot drain the queue.
+	cache.mu.Lock()
+	for i := 0; i <= maxPendingInvalidations; i++ {
+		cache.set("k", i, 0)
+	}
+	all, pending := cache.inval.all, len(cache.inval.pending)
+	cache.mu.Unlock()
+
+	if !all || pending != 0 {
+		t.Fatalf("expected overflow to collapse into All, got all=%v pending=%d", all, pending)
+	}
+}
@@ -1,6 +1,7 @@
 package tempuscache
 
 import (
+	"sync/atomic"
 	"time"
 )
 
@@ -29,6 +30,20 @@ STRUCTURE FIELDS
 key        -> Stored key reference (used during eviction removal)
 value      -> Actual user data (generic via interface{})
 expiration -> Expiration timestamp in Unix nanoseconds (int64)
+size       -> Estimated memory footprint in bytes (see estimateSize)
+created    -> Insertion time in UnixNano
+written    -> Start of the current lifetime (last write or Expire) in UnixNano
+accessed   -> Time of the last successful read in UnixNano (0 = never read)
+view       -> Value and expiration published to the lock-free hit path
+              (see readpath.go)
+delta      -> Duration of the Store.Load that produced the value in
+              nanoseconds (0 = never loaded; see xfetch.go)
+hits       -> Number of successful reads
+pinned     -> Exempt from eviction (see pin.go)
+priority   -> Eviction rank among unpinned entries (see priority.go)
+cost       -> Share of WithMaxCost held by the entry (see cost.go)
+bucket     -> End of the expiration bucket holding the entry
+              (0 = none; see expiry.go)
 
 ================================================================================
 EXPIRATION MODEL
@@ -41,7 +56,7 @@ Expiration is represented as a UnixNano timestamp.
 
 - expiration > 0
     → The item is considered expired when:
-        time.Now().UnixNano() > expiration
+        now > expiration   (now read from the cache's Clock)
 
 ================================================================================
 WHY int64 (UnixNano) INSTEAD OF time.Time?
@@ -75,6 +90,17 @@ type Item struct {
 	key        string
 	value      interface{} //Atomic unit of storage in cache.
 	expiration int64       //stored UnixNano Meaning: Number of nanoseconds since January 1, 1970 UTC (Unix epoch).
+	size       int64
+	created    int64
+	written    int64
+	accessed   atomic.Int64
+	delta      int64
+	hits       atomic.Uint64
+	view       atomic.Pointer[itemView]
+	pinned     bool
+	priority   Priority
+	cost       int64
+	bucket     int64
 }
 
 /*
@@ -139,8 +165,17 @@ within LRU-linked structures.
 */
 
 func (i *Item) Expired() bool {
+	return i.expiredAt(time.Now().UnixNano())
+}
+
+/*
+expiredAt reports whether the item has expired at now (UnixNano).
+The cache checks expiration on its own Clock (see clock.go).
+*/
+
+func (i *Item) expiredAt(now int64) bool {
 	if i.expiration == 0 {
 		return false
 	}
-	return time.Now().UnixNano() > i.expiration
+	return now > i.expiration
 }
@@ -48,6 +48,9 @@ CONCURRENCY & SAFETY
 - stopChan is used as a lifecycle control signal
   for graceful shutdown.
 
+- janitorStop stops only this janitor, when
+  SetCleanupInterval replaces it.
+
 - The ticker is explicitly stopped before exit
   to prevent resource leakage.
 
@@ -75,17 +78,28 @@ favoring clarity and correctness over premature optimization.
 */
 
 func (c *Cache) startJanitor() {
+	c.janitorStop = nil
 	if c.interval <= 0 {
 		return
 	}
 
-	ticker := time.NewTicker(c.interval)
+	ticker := c.clock.NewTicker(c.interval)
+	stop := make(chan struct{})
+	c.janitorStop = stop
 
+	c.workers.Add(1)
 	go func() {
+		defer c.workers.Done()
 		for {
 			select {
-			case <-ticker.C:
-				c.deleteExpired()
+			case <-stop:
+				ticker.Stop()
+				return
+			case <-ticker.C():
+				start := time.Now()
+				removed := c.deleteExpired()
+				c.logger().Debug("tempuscache: janitor run",
+					"removed", removed, "duration", time.Since(start))
 			case <-c.stopChan:
 				ticker.Stop() //You stop the ticker before returning , because ticker leaks resources if not stopped.
 				return
@@ -105,6 +119,12 @@ SHUTDOWN MECHANISM
 - The goroutine responds by:
     1. Stopping the ticker.
     2. Returning cleanly.
+- Stop blocks until every background worker (janitor,
+  auto-snapshot) has returned, so no cleanup or snapshot
+  is still in flight once Stop returns.
+
+If append-only persistence is enabled, the log is also
+fsynced and closed. Event subscriber channels are closed.
 
 This prevents:
 
@@ -118,9 +138,9 @@ USAGE CONTRACT
 
 Stop should be called once per Cache lifecycle.
 
-IMPORTANT:
-Calling Stop multiple times will cause a panic,
-since closing an already closed channel is illegal in Go.
+Only the first call (to Stop or Close) has an effect;
+later calls return immediately instead of panicking on
+an already closed channel.
 
 ================================================================================
 WHY THIS MATTERS
@@ -135,5 +155,75 @@ production-grade systems.
 */
 
 func (c *Cache) Stop() {
-	close(c.stopChan)
+	c.stopOnce.Do(func() {
+		// Closed under the lock so that SetCleanupInterval never
+		// starts a janitor that Stop would not wait for.
+		c.mu.Lock()
+		close(c.stopChan)
+		c.mu.Unlock()
+		c.workers.Wait()
+
+		c.mu.Lock()
+		c.closeAOFLocked()
+		c.closeSubscribers()
+		c.flushTrace()
+		c.mu.Unlock()
+
+		// Closing the log prevents new rewrites; wait for any
+		// in-flight one to notice and discard its temp file.
+		c.aofWorkers.Wait()
+	})
+}
+
+/*
+DeleteExpired runs one janitor sweep immediately and returns the
+number of entries it removed.
+
+It lets tests driving a fake Clock (see the tempustest package)
+trigger cleanup deterministically, and callers that schedule their
+own maintenance do without WithCleanupInterval.
+*/
+
+func (c *Cache) DeleteExpired() int {
+	return c.deleteExpired()
+}
+
+/*
+SetCleanupInterval changes the janitor interval of a running cache.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- The running janitor, if any, is stopped and a new one started
+  with interval d; its first sweep is one full interval away.
+- d <= 0 disables active expiration, as with WithCleanupInterval.
+- After Stop, only the recorded interval changes.
+
+It is safe to call concurrently with every other method.
+*/
+
+func (c *Cache) SetCleanupInterval(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.janitorStop != nil {
+		close(c.janitorStop)
+	}
+	c.interval = d
+
+	select {
+	case <-c.stopChan:
+		c.janitorStop = nil
+	default:
+		c.startJanitor()
+	}
+}
+
+// CleanupInterval returns the current janitor interval; <= 0 means
+// active expiration is disabled.
+func (c *Cache) CleanupInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval
 }
@@ -0,0 +1,102 @@
+package tempuscache
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+keyed.go implements Keyed, a typed view of a Cache for keys that are
+not strings.
+
+================================================================================
+WHY?
+================================================================================
+
+Cache keys are strings throughout: in snapshots and the append-only
+log, in events, invalidation messages, Store calls, and every
+protocol front-end. Callers with integer or struct keys end up
+formatting them at every call site, often with fmt.Sprintf, which
+boxes its arguments and allocates several times per operation.
+
+Keyed takes the encoding off the call sites: it is created once
+with an encoder for its key type and converts keys on every call.
+
+================================================================================
+ENCODERS
+================================================================================
+
+An encoder must be deterministic and injective (distinct keys give
+distinct strings), and should not allocate beyond the returned
+string:
+
+- IntKey for integer types: decimal, the same string fmt.Sprint
+  gives, so existing entries, snapshots, and Stores stay valid.
+- For fixed-size arrays such as UUIDs, string(k[:]) is compact and
+  allocates only the string.
+- For small structs, append the fields with strconv.Append* into
+  one buffer, separated so that field boundaries are unambiguous.
+
+Every operation still allocates the key string once: the cache's
+indexes are keyed by string, and keys are retained by them and by
+events and loads.
+*/
+
+/*
+Keyed is a view of a Cache with keys of type K, encoded to strings by
+a fixed function. It holds no state of its own: entries written
+through it are ordinary entries of the Cache.
+*/
+
+type Keyed[K comparable] struct {
+	cache  *Cache
+	encode func(K) string
+}
+
+// NewKeyed returns a view of c whose keys are converted by encode.
+func NewKeyed[K comparable](c *Cache, encode func(K) string) *Keyed[K] {
+	return &Keyed[K]{cache: c, encode: encode}
+}
+
+// Get returns the value stored for key (see Cache.Get).
+func (k *Keyed[K]) Get(key K) (interface{}, bool) {
+	return k.cache.Get(k.encode(key))
+}
+
+// Set stores value under key (see Cache.Set).
+func (k *Keyed[K]) Set(key K, value interface{}, ttl time.Duration) {
+	k.cache.Set(k.encode(key), value, ttl)
+}
+
+// Delete removes key (see Cache.Delete).
+func (k *Keyed[K]) Delete(key K) {
+	k.cache.Delete(k.encode(key))
+}
+
+// Key returns the string under which key is stored in the Cache.
+func (k *Keyed[K]) Key(key K) string {
+	return k.encode(key)
+}
+
+// Cache returns the underlying Cache.
+func (k *Keyed[K]) Cache() *Cache {
+	return k.cache
+}
+
+// Integer is the set of types IntKey encodes.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+/*
+IntKey encodes an integer key in decimal, as fmt.Sprint would, with
+a single allocation (none for 0 to 99).
+*/
+
+func IntKey[K Integer](key K) string {
+	if key < 0 {
+		return strconv.FormatInt(int64(key), 10)
+	}
+	return strconv.FormatUint(uint64(key), 10)
+}
@@ -0,0 +1,46 @@
+package tempuscache
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestKeyed(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	ids := NewKeyed(cache, IntKey[int64])
+	ids.Set(42, "answer", 0)
+	if v, ok := ids.Get(42); !ok || v != "answer" {
+		t.Fatalf("expected the typed key to be found, got %v", v)
+	}
+	if v, ok := cache.Get("42"); !ok || v != "answer" {
+		t.Fatalf("expected the entry to be stored under its decimal string, got %v", v)
+	}
+	ids.Delete(42)
+	if cache.Len() != 0 {
+		t.Fatal("expected Delete to remove the entry")
+	}
+
+	type uuid [16]byte
+	uuids := NewKeyed(cache, func(k uuid) string { return string(k[:]) })
+	uuids.Set(uuid{1, 2, 3}, 1, 0)
+	if _, ok := uuids.Get(uuid{1, 2, 3}); !ok {
+		t.Fatal("expected array keys to work")
+	}
+}
+
+func TestIntKeyMatchesSprint(t *testing.T) {
+	for _, n := range []int64{0, 7, 99, 100, -1, math.MinInt64, math.MaxInt64} {
+		if got, want := IntKey(n), fmt.Sprint(n); got != want {
+			t.Fatalf("IntKey(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if got := IntKey(uint64(math.MaxUint64)); got != fmt.Sprint(uint64(math.MaxUint64)) {
+		t.Fatalf("unexpected encoding of the largest uint64: %q", got)
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = IntKey(12345) }); n > 1 {
+		t.Fatalf("expected at most 1 allocation, got %v", n)
+	}
+}
@@ -0,0 +1,199 @@
+package tempuscache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+keylock.go provides per-key mutual exclusion for filling misses.
+
+================================================================================
+WHY?
+================================================================================
+
+WithStore deduplicates concurrent loads, but only for values that a
+Store can produce. Callers that compute values themselves (a render,
+a remote call with request-specific credentials, ...) still run into
+the thundering herd: every goroutine that misses a hot key computes
+it.
+
+================================================================================
+USAGE
+================================================================================
+
+LockedGet hands exactly one of the goroutines missing a key a Fill
+token; the others wait for it and then see its value:
+
+    v, found, fill := cache.LockedGet("page:/home")
+    if !found {
+        v = render()
+        fill.Set(v, time.Minute)
+    }
+
+The holder must call Set or Release, even on error paths (defer
+fill.Release() is safe after Set). If it releases without a value,
+one of the waiters gets the Fill instead.
+
+KeyLock is the underlying primitive, usable on its own for any
+per-key critical section.
+*/
+
+/*
+KeyLock is a set of mutexes addressed by key. Locking one key never
+blocks another. The zero value is ready to use.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+mu    -> Protects locks
+locks -> Mutex of every key that is locked or waited on
+*/
+
+type KeyLock struct {
+	mu    sync.Mutex
+	locks map[string]*keyMutex
+}
+
+// keyMutex is one key's mutex; refs counts holders and waiters.
+type keyMutex struct {
+	ch   chan struct{}
+	refs int
+}
+
+// Lock locks key, blocking while another goroutine holds it.
+func (l *KeyLock) Lock(key string) {
+	l.LockContext(context.Background(), key)
+}
+
+/*
+LockContext is Lock that gives up when ctx ends, returning
+ctx.Err(). On error the key is not locked.
+*/
+
+func (l *KeyLock) LockContext(ctx context.Context, key string) error {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyMutex)
+	}
+	m, ok := l.locks[key]
+	if !ok {
+		m = &keyMutex{ch: make(chan struct{}, 1)}
+		l.locks[key] = m
+	}
+	m.refs++
+	l.mu.Unlock()
+
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		l.release(key, m)
+		return ctx.Err()
+	}
+}
+
+// Unlock unlocks key. It panics if key is not locked.
+func (l *KeyLock) Unlock(key string) {
+	l.mu.Lock()
+	m, ok := l.locks[key]
+	l.mu.Unlock()
+	if !ok {
+		panic("tempuscache: unlock of unlocked key " + key)
+	}
+
+	select {
+	case <-m.ch:
+	default:
+		panic("tempuscache: unlock of unlocked key " + key)
+	}
+	l.release(key, m)
+}
+
+// release drops one reference to m, forgetting it when unused.
+func (l *KeyLock) release(key string, m *keyMutex) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m.refs--
+	if m.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+/*
+Fill is the exclusive right to compute a missing key, handed out by
+LockedGet. Exactly one Fill exists per key at a time.
+*/
+
+type Fill struct {
+	c    *Cache
+	key  string
+	once sync.Once
+}
+
+// Set stores value under the key like Cache.Set and releases the Fill.
+func (f *Fill) Set(value interface{}, ttl time.Duration) {
+	f.once.Do(func() {
+		f.c.Set(f.key, value, ttl)
+		f.c.fills.Unlock(f.key)
+	})
+}
+
+/*
+Release gives the Fill up without storing a value, letting a waiting
+goroutine fill the key. It is a no-op after Set or a prior Release.
+*/
+
+func (f *Fill) Release() {
+	f.once.Do(func() {
+		f.c.fills.Unlock(f.key)
+	})
+}
+
+/*
+LockedGet is Get for callers that compute missing values themselves.
+
+RETURNS:
+- (value, true, nil)  -> The key is cached
+- (nil, false, fill)  -> The key is missing; the caller must compute
+                         it and call fill.Set, or fill.Release
+
+While a Fill for key is held, other LockedGet calls for key wait,
+then return the filled value (or receive the Fill if it was
+released).
+*/
+
+func (c *Cache) LockedGet(key string) (interface{}, bool, *Fill) {
+	value, found, fill, _ := c.LockedGetContext(context.Background(), key)
+	return value, found, fill
+}
+
+/*
+LockedGetContext is LockedGet that stops waiting for another
+goroutine's Fill when ctx ends, returning ctx.Err().
+*/
+
+func (c *Cache) LockedGetContext(ctx context.Context, key string) (interface{}, bool, *Fill, error) {
+	if value, found := c.Get(key); found {
+		return value, true, nil, nil
+	}
+
+	if err := c.fills.LockContext(ctx, key); err != nil {
+		return nil, false, nil, err
+	}
+	// The previous holder may have filled the key while we waited.
+	// The miss was counted already, so only a hit is recorded.
+	c.mu.Lock()
+	if elem, found := c.data[key]; found && !c.expired(elem.Value.(*Item)) {
+		c.hit(elem)
+		value := c.read(elem.Value.(*Item).value)
+		c.mu.Unlock()
+		c.fills.Unlock(key)
+		return value, true, nil, nil
+	}
+	c.mu.Unlock()
+	return nil, false, &Fill{c: c, key: key}, nil
+}
@@ -0,0 +1,94 @@
+package tempuscache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockedGetFillsOnce(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	var computed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, found, fill := cache.LockedGet("k")
+			if !found {
+				time.Sleep(10 * time.Millisecond)
+				v = computed.Add(1)
+				fill.Set(v, time.Minute)
+			}
+			if v != int32(1) {
+				t.Errorf("expected the single computed value, got %v", v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := computed.Load(); n != 1 {
+		t.Fatalf("expected one computation, got %d", n)
+	}
+}
+
+func TestLockedGetReleaseHandsOver(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	_, _, first := cache.LockedGet("k")
+	got := make(chan *Fill)
+	go func() {
+		_, _, fill := cache.LockedGet("k")
+		got <- fill
+	}()
+
+	first.Release()
+	second := <-got
+	if second == nil {
+		t.Fatal("expected the Fill to pass to the waiter after Release")
+	}
+	second.Set("v", 0)
+	second.Release()
+
+	if v, found, fill := cache.LockedGet("k"); !found || v != "v" || fill != nil {
+		t.Fatalf("expected the filled value, got %v, %v, %v", v, found, fill)
+	}
+}
+
+func TestLockedGetContext(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	_, _, fill := cache.LockedGet("k")
+	defer fill.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, _, err := cache.LockedGetContext(ctx, "k"); err != context.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestKeyLockIndependentKeys(t *testing.T) {
+	var l KeyLock
+	l.Lock("a")
+	l.Lock("b")
+	l.Unlock("a")
+	l.Unlock("b")
+
+	if len(l.locks) != 0 {
+		t.Fatalf("expected unused locks to be forgotten, got %d", len(l.locks))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Unlock of an unlocked key to panic")
+		}
+	}()
+	l.Unlock("a")
+}
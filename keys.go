@@ -0,0 +1,87 @@
+package tempuscache
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+keys.go implements key validation.
+
+================================================================================
+WHY?
+================================================================================
+
+The cache accepts any string as a key. A caller that builds keys
+from unbounded input (a full request body, a URL with its query
+string) silently fills the cache with huge, never-reused keys, and
+one that builds them from a missing field collides everything on "".
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+With WithKeyValidator, every key is checked in put(), the single
+write point, so no write path (Set, SetMany, Update, increments,
+read-through loads, ...) can bypass it. A refused write:
+
+- Changes nothing: an existing entry for the key keeps its value.
+- Is counted in Stats().Rejected and logged at debug level.
+- Returns an error wrapping ErrInvalidKey and the validator's error
+  from the methods that return errors (SetContext, IncrementBy, ...);
+  the others, such as Set, drop it silently.
+
+Reads are not validated: an invalid key simply misses.
+*/
+
+// ErrInvalidKey is wrapped by the errors of writes refused by a key
+// validator (see WithKeyValidator).
+var ErrInvalidKey = errors.New("tempuscache: invalid key")
+
+// errEmptyKey is returned by NonEmptyKey.
+var errEmptyKey = errors.New("key is empty")
+
+// keyQuoteMax bounds how much of a refused key errors and logs show.
+const keyQuoteMax = 64
+
+// NonEmptyKey is a key validator rejecting the empty key.
+func NonEmptyKey(key string) error {
+	if key == "" {
+		return errEmptyKey
+	}
+	return nil
+}
+
+// MaxKeyLength returns a key validator rejecting keys longer than n
+// bytes with an error wrapping ErrTooLarge.
+func MaxKeyLength(n int) func(key string) error {
+	return func(key string) error {
+		if len(key) > n {
+			return fmt.Errorf("%w: key is %d bytes, limit is %d", ErrTooLarge, len(key), n)
+		}
+		return nil
+	}
+}
+
+/*
+admit runs every key validator on key, counting and logging a
+refusal.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) admit(key string) error {
+	for _, validate := range c.keyValidators {
+		if err := validate(key); err != nil {
+			c.counters.rejected.Add(1)
+			quoted := key
+			if len(quoted) > keyQuoteMax {
+				quoted = quoted[:keyQuoteMax] + "..."
+			}
+			c.logger().Debug("tempuscache: write rejected", "key", quoted, "err", err)
+			return fmt.Errorf("%w %q: %w", ErrInvalidKey, quoted, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeyValidator(t *testing.T) {
+	cache := New(WithKeyValidator(NonEmptyKey), WithKeyValidator(MaxKeyLength(8)))
+	defer cache.Stop()
+
+	cache.Set("ok", 1, 0)
+	cache.Set("", 2, 0)
+	cache.Set(strings.Repeat("k", 2048), 3, 0)
+	if cache.Len() != 1 {
+		t.Fatalf("expected only the valid key to be stored, got %v", cache.Keys())
+	}
+
+	err := cache.SetContext(context.Background(), strings.Repeat("k", 2048), 3, 0)
+	if !errors.Is(err, ErrInvalidKey) || !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrInvalidKey wrapping ErrTooLarge, got %v", err)
+	}
+	if len(err.Error()) > 200 {
+		t.Fatalf("expected the key to be truncated in the error, got %d bytes", len(err.Error()))
+	}
+
+	if _, err := cache.IncrementBy("", 1, 0); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected increments to be validated, got %v", err)
+	}
+	cache.SetMany(map[string]Entry{"": {Value: 1}, "fine": {Value: 2}})
+
+	if s := cache.Stats(); s.Rejected != 5 || s.Entries != 2 {
+		t.Fatalf("expected 5 rejections and 2 entries, got %+v", s)
+	}
+}
@@ -0,0 +1,144 @@
+package tempuscache
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+)
+
+/*
+Close shuts the cache down and persists it to its configured
+snapshot path.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+1. Background workers are stopped exactly as Stop() does.
+2. If a snapshot path is configured (see WithSnapshotPath and
+   WithAutoSnapshot), a final snapshot is written via SaveFile().
+
+With WithSnapshotPath, New loads that snapshot again, so a cache
+persists across restarts without further code:
+
+    cache := New(WithSnapshotPath("/var/lib/app/cache.snap"))
+    defer cache.Close(ctx)
+
+================================================================================
+CONTEXT
+================================================================================
+
+ctx bounds how long Close may take. If ctx is done before the final
+snapshot completes, Close returns ctx.Err(). The write continues in
+the background and, thanks to atomic renames, either completes or
+leaves the previous snapshot untouched.
+
+================================================================================
+USE CASE
+================================================================================
+
+Rolling restarts: the outgoing process persists a warm cache that
+the incoming process loads at startup.
+*/
+
+func (c *Cache) Close(ctx context.Context) error {
+	c.Stop()
+
+	if c.snapshotPath == "" {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.SaveFile(c.snapshotPath)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+CloseOnSignal arranges for Close() to run when the process receives
+one of the given signals.
+
+================================================================================
+PARAMETERS
+================================================================================
+
+timeout (time.Duration):
+    Upper bound for the final snapshot (0 = no limit).
+
+sigs (...os.Signal):
+    Signals to wait for. Defaults to os.Interrupt when empty.
+
+================================================================================
+RETURN VALUE
+================================================================================
+
+A channel that receives Close()'s result once a signal has been
+handled. The caller typically waits on it and then exits:
+
+    done := cache.CloseOnSignal(5*time.Second, syscall.SIGTERM)
+    ...
+    if err := <-done; err != nil {
+        log.Print(err)
+    }
+    os.Exit(0)
+
+Signal delivery is stopped once the first signal is received, so a
+second signal falls back to the default behavior (usually exiting).
+*/
+
+func (c *Cache) CloseOnSignal(timeout time.Duration, sigs ...os.Signal) <-chan error {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	result := make(chan error, 1)
+	go func() {
+		<-ch
+		signal.Stop(ch)
+
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		result <- c.Close(ctx)
+	}()
+	return result
+}
+
+/*
+watchContext closes the cache once the context configured with
+WithContext ends. Close errors are logged, as there is no caller to
+return them to.
+
+The watcher is not one of the workers Stop() waits for: it calls
+Stop() itself, and exits as soon as the cache is stopped otherwise.
+*/
+
+func (c *Cache) watchContext() {
+	if c.ctx == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			if err := c.Close(context.Background()); err != nil {
+				c.logger().Error("tempuscache: close on context end failed", "err", err)
+			}
+		case <-c.stopChan:
+		}
+	}()
+}
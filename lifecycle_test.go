@@ -0,0 +1,175 @@
+package tempuscache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClosePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "close.snap")
+
+	cache := New(WithAutoSnapshot(path, 0))
+	cache.Set("a", 1, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := cache.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop after Close must be a no-op.
+	cache.Stop()
+
+	restored := New()
+	if err := restored.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if val, found := restored.Get("a"); !found || val != 1 {
+		t.Fatalf("expected 'a' to survive Close, got %v", val)
+	}
+}
+
+func TestSnapshotPathRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	// The first run starts empty: a missing snapshot is not an error.
+	c, err := NewWithError(WithSnapshotPath(path))
+	if err != nil {
+		t.Fatalf("expected a missing snapshot to be ignored, got %v", err)
+	}
+	c.Set("a", "1", 0)
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c = New(WithSnapshotPath(path))
+	defer c.Stop()
+	if v, ok := c.Get("a"); !ok || v != "1" {
+		t.Fatalf("expected New to load the snapshot, got %v, %v", v, ok)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWithError(WithSnapshotPath(path)); err == nil {
+		t.Fatal("expected NewWithError to report an unreadable snapshot")
+	}
+}
+
+func TestWithContextCloses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctx.snap")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cache := New(WithContext(ctx), WithCleanupInterval(time.Millisecond), WithAutoSnapshot(path, 0))
+	cache.Set("a", 1, 0)
+	cancel()
+
+	select {
+	case <-cache.stopChan:
+	case <-time.After(time.Second):
+		t.Fatal("expected the cache to stop when its context ends")
+	}
+	// The final snapshot is written right after the workers stop.
+	waitFor(t, func() bool {
+		restored := New()
+		defer restored.Stop()
+		if restored.LoadFile(path) != nil {
+			return false
+		}
+		val, found := restored.Get("a")
+		return found && val == 1
+	})
+}
+
+func TestSetCleanupInterval(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	cache.Set("a", 1, time.Millisecond)
+	cache.SetCleanupInterval(time.Millisecond)
+	if d := cache.CleanupInterval(); d != time.Millisecond {
+		t.Fatalf("expected interval 1ms, got %v", d)
+	}
+	waitFor(t, func() bool { return cache.Len() == 0 })
+
+	// Replacing the janitor leaves exactly one running.
+	cache.SetCleanupInterval(2 * time.Millisecond)
+	cache.Set("b", 1, time.Millisecond)
+	waitFor(t, func() bool { return cache.Len() == 0 })
+
+	cache.SetCleanupInterval(0)
+	cache.Set("c", 1, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if cache.Len() != 1 {
+		t.Fatal("expected a disabled janitor to leave the expired entry")
+	}
+
+	// After Stop, no janitor is started and Stop does not wait on one.
+	cache.Stop()
+	cache.SetCleanupInterval(time.Millisecond)
+	if cache.janitorStop != nil {
+		t.Fatal("expected no janitor after Stop")
+	}
+}
+
+func TestSetDefaultTTL(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock), WithDefaultTTL(time.Minute))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	if ttl, _ := cache.TTL("a"); ttl != time.Minute {
+		t.Fatalf("expected the default TTL, got %v", ttl)
+	}
+	cache.Set("b", 1, time.Hour)
+	if ttl, _ := cache.TTL("b"); ttl != time.Hour {
+		t.Fatalf("expected an explicit TTL to win, got %v", ttl)
+	}
+
+	cache.SetDefaultTTL(0)
+	if d := cache.DefaultTTL(); d != 0 {
+		t.Fatalf("expected the default to be off, got %v", d)
+	}
+	cache.Set("a", 2, 0)
+	cache.Set("c", 1, 0)
+	if ttl, _ := cache.TTL("a"); ttl != time.Minute {
+		t.Fatalf("expected an existing entry to keep its TTL, got %v", ttl)
+	}
+	if ttl, found := cache.TTL("c"); !found || ttl != 0 {
+		t.Fatalf("expected no expiration without a default, got %v", ttl)
+	}
+}
+
+func TestSetOnEvict(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock), WithMaxEntries(2))
+	defer cache.Stop()
+
+	var removed []string
+	cache.SetOnEvict(func(key string, value interface{}, reason EventType) {
+		removed = append(removed, fmt.Sprintf("%s=%v %s", key, value, reason))
+	})
+	cache.Set("a", 1, time.Second)
+	cache.Set("b", 2, 0)
+	clock.advance(2 * time.Second)
+	cache.Get("a")
+	cache.Set("c", 3, 0)
+	cache.Set("d", 4, 0)
+	cache.Delete("c")
+
+	want := []string{"a=1 expired", "b=2 evicted"}
+	if fmt.Sprint(removed) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, removed)
+	}
+
+	cache.SetOnEvict(nil)
+	cache.Set("e", 5, 0)
+	cache.Set("f", 6, 0)
+	if len(removed) != 2 || cache.Stats().Evictions != 2 {
+		t.Fatal("expected no callback once removed")
+	}
+}
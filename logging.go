@@ -0,0 +1,175 @@
+package tempuscache
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+/*
+logging.go wires TempusCache into structured logging.
+
+================================================================================
+WHY?
+================================================================================
+
+Much of the cache's work happens in the background: janitor runs,
+auto-snapshots, append-only log writes and rewrites. Without a
+logger, failures there are completely silent.
+
+================================================================================
+EVENTS
+================================================================================
+
+Debug:
+    - Janitor run (entries removed, duration)
+    - Snapshot saved / loaded
+
+Warn:
+    - Snapshot save or load failed
+    - Auto-snapshot failed
+    - Append-only log write or rewrite failed
+    - Eviction storm (more evictions per second than capacity)
+    - Suspicious configuration detected in New() (NewWithError
+      rejects it instead)
+
+Error:
+    - Append-only log could not be opened in New()
+
+All messages are prefixed with "tempuscache:" and carry structured
+attributes (path, err, counts, durations).
+*/
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+/*
+logger returns the configured logger, or a logger that discards
+everything when none was configured.
+*/
+
+func (c *Cache) logger() *slog.Logger {
+	if c.log == nil {
+		return discardLogger
+	}
+	return c.log
+}
+
+/*
+ErrInvalidConfig is returned by NewWithError for a configuration
+that New would accept but that is almost certainly unintended.
+*/
+
+var ErrInvalidConfig = errors.New("tempuscache: invalid configuration")
+
+/*
+configIssue is a configuration value that New accepts with a warning
+and NewWithError rejects.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+problem -> What is wrong
+effect  -> What New does about it ("" if nothing)
+attrs   -> Offending values, as slog attributes
+*/
+
+type configIssue struct {
+	problem string
+	effect  string
+	attrs   []any
+}
+
+/*
+configIssues lists configuration values that are accepted but
+almost certainly unintended.
+*/
+
+func (c *Cache) configIssues() []configIssue {
+	var issues []configIssue
+	add := func(problem, effect string, attrs ...any) {
+		issues = append(issues, configIssue{problem, effect, attrs})
+	}
+
+	if c.maxEntries < 0 {
+		add("negative max entries", "cache is unbounded", "max_entries", c.maxEntries)
+	}
+	if c.maxCost < 0 {
+		add("negative max cost", "cost is unbounded", "max_cost", c.maxCost)
+	}
+	if c.doorkeeper != nil && c.maxEntries <= 0 && c.maxCost <= 0 {
+		add("doorkeeper set on an unbounded cache", "option has no effect")
+	}
+	if c.interval < 0 {
+		add("negative cleanup interval", "janitor disabled", "interval", c.interval)
+	}
+	if c.snapshotInterval > 0 && c.snapshotPath == "" {
+		add("auto-snapshot interval set without a path", "auto-snapshot disabled")
+	}
+	if c.aofRewriteSize > 0 && c.aofPath == "" {
+		add("AOF rewrite size set without WithAOF", "option has no effect")
+	}
+	if _, ok := c.store.(WritableStore); c.writeThrough != nil && !ok {
+		add("write-through requires a WritableStore", "option has no effect")
+	}
+	if _, ok := c.store.(WritableStore); c.writeBehind != nil && !ok {
+		add("write-behind requires a WritableStore", "option has no effect")
+	}
+	if c.staleWindow > 0 && c.store == nil {
+		add("stale window set without WithStore", "option has no effect")
+	}
+	if c.refreshAhead > 0 && c.store == nil {
+		add("refresh-ahead set without WithStore", "option has no effect")
+	}
+	if c.refreshAhead < 0 || c.refreshAhead > 1 {
+		add("refresh-ahead threshold outside [0, 1]", "", "threshold", c.refreshAhead)
+	}
+	if c.earlyBeta > 0 && c.store == nil {
+		add("early expiration set without WithStore", "option has no effect")
+	}
+	if c.writeThrough != nil && c.writeBehind != nil {
+		add("both write-through and write-behind set", "write-behind disabled")
+	}
+	if c.expvarName != "" && expvar.Get(c.expvarName) != nil {
+		add("expvar name already published", "statistics not published", "name", c.expvarName)
+	}
+	return issues
+}
+
+/*
+validateConfig logs every configuration issue (see configIssues).
+*/
+
+func (c *Cache) validateConfig() {
+	log := c.logger()
+	for _, issue := range c.configIssues() {
+		msg := "tempuscache: " + issue.problem
+		if issue.effect != "" {
+			msg += ", " + issue.effect
+		}
+		log.Warn(msg, issue.attrs...)
+	}
+}
+
+/*
+checkConfig returns every configuration issue as one error wrapping
+ErrInvalidConfig, or nil.
+*/
+
+func (c *Cache) checkConfig() error {
+	issues := c.configIssues()
+	if len(issues) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(issues))
+	for i, issue := range issues {
+		problems[i] = issue.problem
+		for j := 0; j+1 < len(issue.attrs); j += 2 {
+			problems[i] += fmt.Sprintf(" (%v=%v)", issue.attrs[j], issue.attrs[j+1])
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+}
@@ -0,0 +1,72 @@
+package tempuscache
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	missing := filepath.Join(t.TempDir(), "no-such-dir", "cache.aof")
+	cache := New(WithLogger(logger), WithMaxEntries(2), WithAOF(missing))
+
+	for i := 0; i < 5; i++ {
+		cache.Set(string(rune('a'+i)), i, 0)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"append-only log disabled", "eviction storm"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected log to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	opts := []Option{WithLogger(logger), WithMaxEntries(-1), WithStaleWhileRevalidate(time.Second)}
+
+	// New accepts the configuration with a warning per problem...
+	New(opts...).Stop()
+	for _, want := range []string{"negative max entries, cache is unbounded", "stale window set without WithStore"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected log to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	// ...NewWithError rejects it, listing every problem.
+	cache, err := NewWithError(opts...)
+	if !errors.Is(err, ErrInvalidConfig) || cache != nil {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	for _, want := range []string{"negative max entries (max_entries=-1)", "stale window set without WithStore"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to contain %q, got %v", want, err)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "no-such-dir", "cache.aof")
+	if _, err := NewWithError(WithAOF(missing)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the log open error, got %v", err)
+	}
+
+	cache, err = NewWithError(WithMaxEntries(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Stop()
+	cache.Set("a", 1, 0)
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Fatal("expected a working cache")
+	}
+}
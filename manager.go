@@ -0,0 +1,232 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+/*
+manager.go implements Manager, a registry of named caches.
+
+================================================================================
+WHY?
+================================================================================
+
+Services with many caches (sessions, users, feature flags, ...)
+repeat the same options at every New call, export each cache's
+metrics separately, and need to remember to Close every one of them
+at shutdown. Manager keeps them in one place.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Open creates a cache under a name, with the manager's default
+  options followed by its own, so per-cache options override the
+  defaults. Opening an existing name returns the cache already
+  registered and ignores the options.
+- Stats sums the statistics of every cache; CacheStats returns them
+  per name.
+- Remove closes one cache and forgets it; CloseAll does so for all
+  of them.
+
+Options that bind an external resource by name (WithSnapshotPath,
+WithAutoSnapshot, WithAOF, WithExpvar) belong to a single cache:
+pass them to Open, not as defaults.
+
+================================================================================
+CONCURRENCY MODEL
+================================================================================
+
+All methods are safe for concurrent use. Caches are created and
+closed outside the registry lock, so a slow snapshot load or save
+does not block lookups of other caches.
+*/
+
+type Manager struct {
+	mu       sync.RWMutex
+	defaults []Option
+	caches   map[string]*Cache
+}
+
+// NewManager returns an empty Manager; defaults apply to every cache it opens.
+func NewManager(defaults ...Option) *Manager {
+	return &Manager{
+		defaults: defaults,
+		caches:   make(map[string]*Cache),
+	}
+}
+
+/*
+Open returns the cache registered under name, creating it with the
+manager's defaults and opts if there is none.
+
+Configuration errors are returned as NewWithError returns them,
+prefixed with the name of the cache; nothing is registered then.
+*/
+
+func (m *Manager) Open(name string, opts ...Option) (*Cache, error) {
+	if c := m.Cache(name); c != nil {
+		return c, nil
+	}
+
+	all := make([]Option, 0, len(m.defaults)+len(opts))
+	all = append(append(all, m.defaults...), opts...)
+	c, err := NewWithError(all...)
+	if err != nil {
+		return nil, fmt.Errorf("cache %q: %w", name, err)
+	}
+
+	m.mu.Lock()
+	if existing, found := m.caches[name]; found {
+		m.mu.Unlock()
+		c.Stop()
+		return existing, nil
+	}
+	m.caches[name] = c
+	m.mu.Unlock()
+	return c, nil
+}
+
+// Cache returns the cache registered under name, or nil.
+func (m *Manager) Cache(name string) *Cache {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.caches[name]
+}
+
+// Names returns the names of the registered caches, sorted.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.caches))
+	for name := range m.caches {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+/*
+Stats returns the statistics of all caches combined: counters and
+gauges are summed, and Uptime is the longest. HotKeys is left empty,
+as the same key may name different entries in different caches; see
+CacheStats.
+*/
+
+func (m *Manager) Stats() Stats {
+	var total Stats
+	for _, s := range m.CacheStats() {
+		total.add(s)
+	}
+	return total
+}
+
+// CacheStats returns the statistics of each cache, by name.
+func (m *Manager) CacheStats() map[string]Stats {
+	m.mu.RLock()
+	caches := make(map[string]*Cache, len(m.caches))
+	for name, c := range m.caches {
+		caches[name] = c
+	}
+	m.mu.RUnlock()
+
+	stats := make(map[string]Stats, len(caches))
+	for name, c := range caches {
+		stats[name] = c.Stats()
+	}
+	return stats
+}
+
+/*
+Remove unregisters the cache under name and closes it (see
+Cache.Close). It returns nil if there is no such cache.
+*/
+
+func (m *Manager) Remove(ctx context.Context, name string) error {
+	m.mu.Lock()
+	c, found := m.caches[name]
+	delete(m.caches, name)
+	m.mu.Unlock()
+
+	if !found {
+		return nil
+	}
+	if err := c.Close(ctx); err != nil {
+		return fmt.Errorf("cache %q: %w", name, err)
+	}
+	return nil
+}
+
+/*
+CloseAll unregisters every cache and closes them concurrently, all
+bounded by ctx. The errors of the caches that failed to close are
+joined, each prefixed with its cache's name.
+
+The manager stays usable: Open creates new caches afterwards.
+*/
+
+func (m *Manager) CloseAll(ctx context.Context) error {
+	m.mu.Lock()
+	caches := m.caches
+	m.caches = make(map[string]*Cache)
+	m.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for name, c := range caches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Close(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("cache %q: %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+/*
+add accumulates o into s, for combined views of several caches.
+*/
+
+func (s *Stats) add(o Stats) {
+	s.Hits += o.Hits
+	s.StaleHits += o.StaleHits
+	s.Misses += o.Misses
+	s.Sets += o.Sets
+	s.Deletes += o.Deletes
+	s.Evictions += o.Evictions
+	s.Expirations += o.Expirations
+	s.EarlyExpirations += o.EarlyExpirations
+	s.ExpiredByJanitor += o.ExpiredByJanitor
+	s.ExpiredOnAccess += o.ExpiredOnAccess
+	s.DroppedEvents += o.DroppedEvents
+	s.Invalidations += o.Invalidations
+	s.Loads += o.Loads
+	s.LoadErrors += o.LoadErrors
+	s.WriteBehindDropped += o.WriteBehindDropped
+	s.Busy += o.Busy
+	s.Rejected += o.Rejected
+	s.NotAdmitted += o.NotAdmitted
+	s.PrematureEvictions += o.PrematureEvictions
+
+	s.Entries += o.Entries
+	s.Pinned += o.Pinned
+	s.Cost += o.Cost
+	s.EstimatedBytes += o.EstimatedBytes
+	s.WriteBehindPending += o.WriteBehindPending
+	s.CompressedBytes += o.CompressedBytes
+	s.UncompressedBytes += o.UncompressedBytes
+	s.Uptime = max(s.Uptime, o.Uptime)
+}
@@ -0,0 +1,77 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManagerOpen(t *testing.T) {
+	m := NewManager(WithMaxEntries(2))
+	defer m.CloseAll(context.Background())
+
+	users, err := m.Open("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.Open("users", WithMaxEntries(100)); again != users {
+		t.Fatal("expected Open to return the registered cache")
+	}
+	sessions, _ := m.Open("sessions", WithMaxEntries(3))
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		users.Set(k, 1, 0)
+		sessions.Set(k, 1, 0)
+	}
+	if n := users.Stats().Entries; n != 2 {
+		t.Errorf("expected the default capacity of 2, got %d entries", n)
+	}
+	if n := sessions.Stats().Entries; n != 3 {
+		t.Errorf("expected the per-cache capacity of 3, got %d entries", n)
+	}
+
+	if got := m.Names(); !reflect.DeepEqual(got, []string{"sessions", "users"}) {
+		t.Errorf("unexpected names %v", got)
+	}
+	if s := m.Stats(); s.Sets != 8 || s.Entries != 5 || s.Evictions != 3 {
+		t.Errorf("expected combined stats, got %+v", s)
+	}
+	if m.CacheStats()["users"].Entries != 2 {
+		t.Error("expected per-cache stats by name")
+	}
+}
+
+func TestManagerOpenInvalidConfig(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Open("bad", WithMaxEntries(-1)); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if m.Cache("bad") != nil {
+		t.Fatal("expected a failed Open to register nothing")
+	}
+}
+
+func TestManagerCloseAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.snap")
+	m := NewManager()
+	flags, _ := m.Open("flags", WithSnapshotPath(path))
+	flags.Set("dark-mode", true, 0)
+	m.Open("other")
+
+	if err := m.CloseAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Names()) != 0 {
+		t.Fatal("expected CloseAll to unregister every cache")
+	}
+
+	reopened, _ := m.Open("flags", WithSnapshotPath(path))
+	if v, ok := reopened.Get("dark-mode"); !ok || v != true {
+		t.Fatal("expected CloseAll to save the snapshot")
+	}
+	if err := m.Remove(context.Background(), "flags"); err != nil || m.Cache("flags") != nil {
+		t.Fatalf("expected Remove to close and unregister, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package tempuscache
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+memoize.go implements Memoize, which wraps a function with a cache.
+
+================================================================================
+WHY?
+================================================================================
+
+Most callers want a cache in front of one expensive function: a
+query, a remote call, a render. Written by hand, that is a key
+format, a miss check, a type assertion, a Set, and, to avoid a
+thundering herd on hot keys, LockedGet with its Fill protocol.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+The memoized function looks up name and its arguments in the cache,
+and calls fn only on a miss, caching its result for ttl:
+
+    getUser := tempuscache.Memoize(cache, "user", db.GetUser, 5*time.Minute)
+    u, err := getUser(42)
+
+- Concurrent calls missing the same arguments call fn once; the
+  others wait and share its result (see LockedGet).
+- Errors are returned and not cached; the next caller, or the next
+  waiting one, calls fn again.
+- Arguments are formatted with %#v into the key, so distinct
+  values give distinct keys. Pass several arguments as a struct, or
+  use Cache.Memoize.
+- Pass values, not pointers. When Memoize's argument is itself a
+  pointer to a struct, array, slice, or map, %#v prints its contents
+  at call time (&pkg.T{...}): distinct pointers to equal contents
+  share a result, and mutating the pointee afterwards does not
+  invalidate it. Any other pointer, including those nested in a
+  struct and every argument of Cache.Memoize, is printed by address,
+  so equal contents never share a result.
+
+name separates memoized functions sharing a cache: keys are
+name + ":" + formatted arguments, and must not be written otherwise.
+Entries are ordinary entries, so Delete invalidates one result and
+capacity, TTLs, and statistics apply as usual.
+*/
+
+/*
+Memoize returns fn cached in c under name, with typed arguments and
+results (see memoize.go).
+*/
+
+func Memoize[A comparable, R any](c *Cache, name string, fn func(A) (R, error), ttl time.Duration) func(A) (R, error) {
+	return func(arg A) (R, error) {
+		v, err := c.memoized(memoKey(name, arg), ttl, func() (interface{}, error) {
+			return fn(arg)
+		})
+		r, _ := v.(R)
+		return r, err
+	}
+}
+
+/*
+Memoize returns fn cached under name, for functions of any number of
+arguments (see memoize.go).
+*/
+
+func (c *Cache) Memoize(name string, fn func(args ...interface{}) (interface{}, error), ttl time.Duration) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		return c.memoized(memoKey(name, args), ttl, func() (interface{}, error) {
+			return fn(args...)
+		})
+	}
+}
+
+// memoKey returns the key of a memoized call.
+func memoKey(name string, args interface{}) string {
+	return name + ":" + fmt.Sprintf("%#v", args)
+}
+
+/*
+memoized returns the value cached under key, or calls compute while
+holding the key's Fill and caches its result for ttl unless it fails.
+*/
+
+func (c *Cache) memoized(key string, ttl time.Duration, compute func() (interface{}, error)) (interface{}, error) {
+	v, found, fill := c.LockedGet(key)
+	if found {
+		return v, nil
+	}
+	defer fill.Release()
+
+	v, err := compute()
+	if err != nil {
+		return v, err
+	}
+	fill.Set(v, ttl)
+	return v, nil
+}
@@ -0,0 +1,88 @@
+package tempuscache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	c := New()
+	defer c.Stop()
+
+	var calls atomic.Int32
+	square := Memoize(c, "square", func(n int) (int, error) {
+		calls.Add(1)
+		return n * n, nil
+	}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if v, err := square(4); err != nil || v != 16 {
+			t.Fatalf("expected 16, got %v, %v", v, err)
+		}
+	}
+	square(5)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected one call per distinct argument, got %d", n)
+	}
+	if _, ok := c.Get(`square:4`); !ok {
+		t.Fatal("expected the result under name:argument")
+	}
+}
+
+func TestMemoizeErrorsNotCached(t *testing.T) {
+	c := New()
+	defer c.Stop()
+
+	fail := true
+	load := Memoize(c, "load", func(key string) (string, error) {
+		if fail {
+			return "", errors.New("unavailable")
+		}
+		return "value of " + key, nil
+	}, time.Minute)
+
+	if _, err := load("a"); err == nil {
+		t.Fatal("expected the error to be returned")
+	}
+	fail = false
+	if v, err := load("a"); err != nil || v != "value of a" {
+		t.Fatalf("expected a retry after the error, got %q, %v", v, err)
+	}
+}
+
+func TestMemoizeDeduplicates(t *testing.T) {
+	c := New()
+	defer c.Stop()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	slow := c.Memoize("slow", func(args ...interface{}) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return len(args), nil
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, _ := slow("x", 1); v != 2 {
+				t.Errorf("expected 2, got %v", v)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected concurrent misses to share one call, got %d", n)
+	}
+	if _, err := slow("x", 2); err != nil || calls.Load() != 2 {
+		t.Fatal("expected distinct arguments to be computed separately")
+	}
+}
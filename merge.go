@@ -0,0 +1,99 @@
+package tempuscache
+
+/*
+merge.go implements Merge, which imports the entries of one cache
+into another.
+
+================================================================================
+WHY?
+================================================================================
+
+Batch jobs often give each worker a private cache and collapse them
+into a shared one at the end. Doing that with Range and Set loses
+deadlines (Set takes a TTL, not a deadline) and needs a conflict
+rule at every call site.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Merge takes a snapshot of other (see SnapshotView), then writes its
+live entries into c, least recently used first, so the most recently
+used ones in other end up most recently used in c. Each entry keeps
+its absolute deadline.
+
+A key live in both caches is a conflict, resolved by MergePolicy.
+Entries only in other are always imported.
+
+Entries are imported through c's normal write path, exactly like
+Set: they count in Sets, are subject to key validation, size limits,
+admission, and capacity (importing into a full cache evicts), and
+are recorded by the append-only log. Pins, priorities, and costs
+are not carried over.
+
+The two caches are never locked together, so concurrent merges in
+opposite directions cannot deadlock; writes to other after the
+snapshot are not merged.
+*/
+
+// MergePolicy resolves keys present in both caches during Merge.
+type MergePolicy int
+
+const (
+	// KeepNewerExpiration keeps the entry that expires last; entries
+	// without expiration count as newest. Ties keep the destination.
+	KeepNewerExpiration MergePolicy = iota
+
+	// PreferDestination keeps the destination's entry.
+	PreferDestination
+
+	// PreferSource replaces the destination's entry with the source's.
+	PreferSource
+)
+
+/*
+Merge imports the live entries of other into c, resolving conflicts
+with policy (see merge.go).
+
+RETURNS:
+The number of entries written to c.
+*/
+
+func (c *Cache) Merge(other *Cache, policy MergePolicy) int {
+	view := other.SnapshotView()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := 0
+	for _, e := range view.entries {
+		if elem, found := c.data[e.key]; found {
+			item := elem.Value.(*Item)
+			if !c.expired(item) && !policy.replaces(item.expiration, e.expiration) {
+				continue
+			}
+		}
+		if err := c.put(e.key, other.unpack(e.value), e.expiration); err == nil {
+			merged++
+		}
+	}
+	return merged
+}
+
+/*
+replaces reports whether a source entry expiring at src replaces a
+live destination entry expiring at dst (UnixNano, 0 = never).
+*/
+
+func (p MergePolicy) replaces(dst, src int64) bool {
+	switch p {
+	case PreferSource:
+		return true
+	case PreferDestination:
+		return false
+	}
+	if dst == 0 {
+		return false
+	}
+	return src == 0 || src > dst
+}
@@ -0,0 +1,58 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergePolicies(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	newPair := func() (*Cache, *Cache) {
+		dst, src := New(WithClock(clock)), New(WithClock(clock))
+		dst.Set("both", "dst", time.Minute)
+		dst.Set("dst-only", 1, 0)
+		src.Set("both", "src", time.Hour)
+		src.Set("src-only", 2, 30*time.Second)
+		return dst, src
+	}
+
+	cases := []struct {
+		policy MergePolicy
+		want   string
+		merged int
+	}{
+		{KeepNewerExpiration, "src", 2},
+		{PreferDestination, "dst", 1},
+		{PreferSource, "src", 2},
+	}
+	for _, tc := range cases {
+		dst, src := newPair()
+		if n := dst.Merge(src, tc.policy); n != tc.merged {
+			t.Errorf("policy %d: expected %d merged, got %d", tc.policy, tc.merged, n)
+		}
+		if v, _ := dst.Get("both"); v != tc.want {
+			t.Errorf("policy %d: expected %q to win, got %v", tc.policy, tc.want, v)
+		}
+		if ttl, ok := dst.TTL("src-only"); !ok || ttl != 30*time.Second {
+			t.Errorf("policy %d: expected the source deadline to be kept, got %v", tc.policy, ttl)
+		}
+		if _, ok := dst.Get("dst-only"); !ok {
+			t.Errorf("policy %d: expected destination-only entries to stay", tc.policy)
+		}
+		dst.Stop()
+		src.Stop()
+	}
+}
+
+func TestMergeNeverExpiringWins(t *testing.T) {
+	dst, src := New(), New()
+	defer dst.Stop()
+	defer src.Stop()
+
+	dst.Set("k", "dst", 0)
+	src.Set("k", "src", time.Hour)
+	dst.Merge(src, KeepNewerExpiration)
+	if v, _ := dst.Get("k"); v != "dst" {
+		t.Fatalf("expected the entry without expiration to win, got %v", v)
+	}
+}
@@ -1,6 +1,9 @@
 package tempuscache
 
 import (
+	"context"
+	"io"
+	"log/slog"
 	"time"
 )
 
@@ -105,6 +108,9 @@ Choosing an appropriate cleanup interval depends on:
 
 This option provides operational control over
 the balance between performance and memory efficiency.
+
+The interval can be changed on a running cache with
+SetCleanupInterval.
 */
 
 func WithCleanupInterval(d time.Duration) Option {
@@ -167,6 +173,8 @@ Capacity tuning should consider:
 - Expected workload characteristics
 - TTL distribution
 
+The limit can be changed on a running cache with Resize.
+
 This option enables bounded, production-ready cache behavior.
 */
 
@@ -175,3 +183,688 @@ func WithMaxEntries(n int) Option {
 		c.maxEntries = n
 	}
 }
+
+/*
+WithAutoSnapshot makes the cache persist itself in the background.
+
+================================================================================
+PARAMETERS
+================================================================================
+
+path (string):
+    Snapshot file written via SaveFile().
+
+interval (time.Duration):
+    Time between consecutive snapshots.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+If path != "" and interval > 0:
+    - A background goroutine snapshots the cache every interval.
+    - Each write is atomic (temp file + rename), so a crash
+      mid-snapshot never corrupts the previous good file.
+    - Stop() terminates the worker.
+
+Otherwise:
+    - Auto-snapshot is disabled.
+
+Snapshot errors in the background are only logged (see
+WithLogger); call SaveFile() directly when the result matters.
+*/
+
+func WithAutoSnapshot(path string, interval time.Duration) Option {
+	return func(c *Cache) {
+		c.snapshotPath = path
+		c.snapshotInterval = interval
+	}
+}
+
+/*
+WithSnapshotPath ties a snapshot file to the cache's lifetime:
+
+- New loads path if it exists (a missing file is not an error:
+  the first run starts empty).
+- Close writes a final snapshot to path.
+
+Combine it with WithAutoSnapshot(path, interval) to also save
+periodically. With WithAOF, the log is replayed on top of the loaded
+snapshot.
+
+    cache := tempuscache.New(tempuscache.WithSnapshotPath("/var/lib/app/cache.snap"))
+    defer cache.Close(context.Background())
+
+Load errors are logged by New and returned by NewWithError.
+*/
+
+func WithSnapshotPath(path string) Option {
+	return func(c *Cache) {
+		c.snapshotPath = path
+		c.snapshotLoad = path != ""
+	}
+}
+
+/*
+WithAOF enables append-only file persistence at path.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+During New():
+    - An existing log at path is replayed into the cache.
+    - The log is compacted and reopened for appending.
+    - Every subsequent mutation is recorded until Stop().
+
+If the log cannot be opened, the cache starts without AOF
+persistence. Call OpenAOF() directly when the error matters.
+
+================================================================================
+SNAPSHOT VS AOF
+================================================================================
+
+Snapshots are compact but lose everything written since the
+last save. The AOF loses at most the record being written at
+the moment of a crash, at the cost of one write per mutation.
+*/
+
+func WithAOF(path string) Option {
+	return func(c *Cache) {
+		c.aofPath = path
+	}
+}
+
+/*
+WithAOFRewriteSize enables automatic compaction of the append-only log.
+
+================================================================================
+PARAMETER
+================================================================================
+
+n (int64):
+    Log size in bytes at which a background rewrite is considered.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+If n > 0:
+    - After each append, a background rewrite (see CompactNow())
+      starts when the log is at least n bytes AND at least twice
+      its size right after the previous rewrite.
+    - The doubling rule prevents back-to-back rewrites when the
+      live state alone already exceeds n.
+
+If n <= 0:
+    - The log is only compacted at open and by explicit CompactNow().
+
+Has no effect unless AOF persistence is enabled.
+*/
+
+func WithAOFRewriteSize(n int64) Option {
+	return func(c *Cache) {
+		c.aofRewriteSize = n
+	}
+}
+
+/*
+WithCodec selects the serialization format used by Save(), Load(),
+SaveFile(), LoadFile(), and automatic snapshots.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- The default is GobCodec.
+- JSONCodec is provided for human-readable snapshots.
+- Any type implementing Codec may be supplied.
+
+A snapshot must be loaded with the same Codec it was saved with.
+*/
+
+func WithCodec(codec Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+/*
+WithExpvar publishes the cache's live statistics under name in the
+standard expvar registry during New(). See PublishExpvar().
+
+A name that is already registered is logged by New() and rejected
+by NewWithError(); the statistics are then not published.
+*/
+
+func WithExpvar(name string) Option {
+	return func(c *Cache) {
+		c.expvarName = name
+	}
+}
+
+/*
+WithTopK enables tracking of the k most frequently looked-up keys.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+If k > 0:
+    - Every lookup (hit or miss) is counted in a count-min sketch.
+    - The k keys with the highest estimated counts are reported by
+      HotKeys() and Stats().HotKeys.
+
+If k <= 0:
+    - Tracking is disabled (the default) and adds no overhead.
+
+================================================================================
+WHY THIS MATTERS
+================================================================================
+
+A single key absorbing a large share of traffic is a common cause
+of lock contention and backend overload, and it is invisible in
+aggregate hit/miss counters.
+*/
+
+func WithTopK(k int) Option {
+	return func(c *Cache) {
+		if k > 0 {
+			c.topK = newTopKTracker(k)
+		} else {
+			c.topK = nil
+		}
+	}
+}
+
+/*
+WithLogger routes the cache's operational events to logger.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Background failures (auto-snapshot, append-only log) are logged
+  at Warn/Error level instead of being silently dropped.
+- Janitor runs and snapshot results are logged at Debug level.
+- Eviction storms and suspicious configuration produce warnings.
+
+A nil logger (the default) disables logging entirely.
+
+See logging.go for the full list of events.
+*/
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cache) {
+		c.log = logger
+	}
+}
+
+/*
+WithInvalidation enables cross-node invalidation.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Every local write publishes the affected key through b, in
+  batches, from a background worker.
+- Invalidations received through s remove the named keys locally.
+- Either side may be nil for publish-only or receive-only nodes.
+
+A single transport usually implements both interfaces:
+
+    bus := tempusredis.New("redis:6379")
+    cache := tempuscache.New(tempuscache.WithInvalidation(bus, bus))
+
+See invalidation.go for delivery details.
+
+================================================================================
+CONSISTENCY
+================================================================================
+
+Invalidation is asynchronous and best-effort: a peer may serve its
+stale copy for the short time a message is in flight, and messages
+lost by the transport are not retried. Keep TTLs as the backstop.
+*/
+
+func WithInvalidation(b Broadcaster, s Subscriber) Option {
+	return func(c *Cache) {
+		if b == nil && s == nil {
+			c.inval = nil
+			return
+		}
+		c.inval = &invalidator{
+			broadcaster: b,
+			subscriber:  s,
+			wake:        make(chan struct{}, 1),
+		}
+	}
+}
+
+/*
+WithStore makes the cache read-through: a miss in Get or GetContext
+loads the value from s, caches it, and returns it.
+
+================================================================================
+USAGE
+================================================================================
+
+    cache := tempuscache.New(tempuscache.WithStore(userStore))
+
+    user, found := cache.Get("user:42") // loaded from userStore on a miss
+
+Concurrent misses for the same key share a single Load call. See
+store.go for the details.
+
+Batch reads (GetMany) and the atomic operations do not load.
+*/
+
+func WithStore(s Store) Option {
+	return func(c *Cache) {
+		c.store = s
+	}
+}
+
+/*
+WithWriteThrough makes Set persist every value to the Store
+configured with WithStore, which must implement WritableStore.
+
+    cache := tempuscache.New(
+        tempuscache.WithStore(configStore),
+        tempuscache.WithWriteThrough(tempuscache.SaveBeforeCache),
+    )
+
+    err := cache.SetContext(ctx, "feature-flags", flags, 0)
+
+See writethrough.go for the ordering guarantees. Without a
+WritableStore the option is ignored and a warning is logged.
+*/
+
+func WithWriteThrough(order WriteOrder) Option {
+	return func(c *Cache) {
+		c.writeThrough = &writeThrough{order: order}
+	}
+}
+
+/*
+WithWriteBehind makes Set persist values to the Store configured
+with WithStore asynchronously, in batches. The Store must implement
+WritableStore (and may implement BatchStore).
+
+    cache := tempuscache.New(
+        tempuscache.WithStore(counterStore),
+        tempuscache.WithWriteBehind(tempuscache.WriteBehindConfig{
+            BatchSize: 500,
+            Interval:  2 * time.Second,
+        }),
+    )
+    defer cache.Close(ctx) // saves whatever is still queued
+
+Zero fields of cfg take their defaults. See writebehind.go for
+batching, backpressure, and retries. Ignored when combined with
+WithWriteThrough.
+*/
+
+func WithWriteBehind(cfg WriteBehindConfig) Option {
+	return func(c *Cache) {
+		c.writeBehind = newWriteBehind(cfg)
+	}
+}
+
+/*
+WithStaleWhileRevalidate lets Get serve an entry for up to window
+after it expires, while a background Store.Load replaces it.
+
+    cache := tempuscache.New(
+        tempuscache.WithStore(priceStore),
+        tempuscache.WithStaleWhileRevalidate(30*time.Second),
+    )
+
+Callers then see the Store's latency only for missing keys, never
+for keys that just expired. Requires WithStore. See stale.go.
+*/
+
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(c *Cache) {
+		c.staleWindow = window
+	}
+}
+
+/*
+WithRefreshAhead makes Get reload an entry in the background when
+less than threshold (a fraction between 0 and 1) of its TTL remains.
+
+    cache := tempuscache.New(
+        tempuscache.WithStore(sessionStore),
+        tempuscache.WithRefreshAhead(0.2), // last 20% of the TTL
+    )
+
+Hot keys are then replaced before they expire and never miss.
+Requires WithStore. See refresh.go.
+*/
+
+func WithRefreshAhead(threshold float64) Option {
+	return func(c *Cache) {
+		c.refreshAhead = threshold
+	}
+}
+
+/*
+WithEarlyExpiration enables probabilistic early expiration
+("X-Fetch"): Get occasionally treats a live entry as a miss and
+reloads it, with a probability that grows as its expiry approaches.
+
+beta scales how early reloads start; 1 is the usual choice, larger
+values reload earlier. Requires WithStore. See xfetch.go.
+*/
+
+func WithEarlyExpiration(beta float64) Option {
+	return func(c *Cache) {
+		c.earlyBeta = beta
+	}
+}
+
+/*
+WithContext ties the cache's lifetime to ctx: once ctx ends, the
+cache is closed as if by Close(), stopping the janitor and every
+other background worker (snapshots, invalidation, write-behind) and
+writing the final snapshot, if configured.
+
+It fits services whose goroutines are managed by an errgroup:
+
+    g, ctx := errgroup.WithContext(ctx)
+    cache := tempuscache.New(tempuscache.WithContext(ctx))
+
+Calling Stop or Close directly remains possible.
+*/
+
+func WithContext(ctx context.Context) Option {
+	return func(c *Cache) {
+		c.ctx = ctx
+	}
+}
+
+/*
+WithClock makes the cache read time from clock instead of the wall
+clock: TTLs, timestamps, and the tickers of background workers all
+follow it (see clock.go). Intended for tests and simulations.
+*/
+
+func WithClock(clock Clock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+/*
+WithTrace records every cache operation (set, hit, miss, removals)
+to w in a compact binary format, for offline analysis of access
+patterns. Read it back with NewTraceReader. See trace.go.
+
+    f, _ := os.Create("/var/tmp/cache.trace")
+    cache := tempuscache.New(tempuscache.WithTrace(f))
+    defer f.Close()
+    defer cache.Stop() // flushes the trace
+*/
+
+func WithTrace(w io.Writer) Option {
+	return func(c *Cache) {
+		c.tracer = newTracer(w)
+	}
+}
+
+/*
+WithCompression stores []byte and string values compressed with
+comp and decompresses them on read, trading CPU for memory. See
+compression.go.
+
+    cache := tempuscache.New(tempuscache.WithCompression(
+        tempuscache.GzipCompressor(gzip.BestSpeed)))
+*/
+
+func WithCompression(comp Compressor) Option {
+	return func(c *Cache) {
+		c.compressor = comp
+	}
+}
+
+/*
+WithSerializer stores every value encoded with s and decodes it on
+every read, so callers never share a cached object. Combined with
+WithCompression, the encoded bytes are compressed. See serializer.go.
+
+    cache := tempuscache.New(tempuscache.WithSerializer(tempuscache.GobSerializer()))
+*/
+
+func WithSerializer(s Serializer) Option {
+	return func(c *Cache) {
+		c.serializer = s
+	}
+}
+
+/*
+WithKeyValidator rejects writes of keys for which validate returns
+an error. It may be given several times; every validator must
+accept a key. NonEmptyKey and MaxKeyLength are provided:
+
+    cache := tempuscache.New(
+        tempuscache.WithKeyValidator(tempuscache.NonEmptyKey),
+        tempuscache.WithKeyValidator(tempuscache.MaxKeyLength(256)),
+    )
+
+See keys.go.
+*/
+
+func WithKeyValidator(validate func(key string) error) Option {
+	return func(c *Cache) {
+		c.keyValidators = append(c.keyValidators, validate)
+	}
+}
+
+/*
+WithMaxValueBytes stops values larger than n bytes, in their stored
+form, from being cached; policy selects whether such writes fail
+with ErrTooLarge (RejectOversize) or just leave the key uncached
+(SkipOversize). n <= 0 means no limit. See oversize.go.
+
+    cache := tempuscache.New(
+        tempuscache.WithMaxValueBytes(1<<20, tempuscache.RejectOversize),
+    )
+*/
+
+func WithMaxValueBytes(n int64, policy OversizePolicy) Option {
+	return func(c *Cache) {
+		c.maxValueBytes = n
+		c.oversizePolicy = policy
+	}
+}
+
+/*
+WithMaxCost bounds the total cost of the entries to n, evicting least
+recently used entries when a write exceeds it. Entries cost what is
+passed to SetWithCost, or 1. n <= 0 means no limit. See cost.go.
+
+    cache := tempuscache.New(tempuscache.WithMaxCost(64 << 20))
+    cache.SetWithCost("report", blob, time.Hour, int64(len(blob)))
+*/
+
+func WithMaxCost(n int64) Option {
+	return func(c *Cache) {
+		c.maxCost = n
+	}
+}
+
+/*
+WithSampledEviction replaces strict LRU with approximate LRU: hits no
+longer reorder the LRU list, and eviction removes the least recently
+used of k sampled entries. k <= 0 keeps strict LRU. See sampled.go.
+
+    cache := tempuscache.New(
+        tempuscache.WithMaxEntries(100_000),
+        tempuscache.WithSampledEviction(5),
+    )
+*/
+
+func WithSampledEviction(k int) Option {
+	return func(c *Cache) {
+		c.evictionSamples = max(k, 0)
+	}
+}
+
+/*
+WithDoorkeeper makes a full cache admit a new key only on its second
+write within window, so keys written once during a scan do not evict
+the working set. expected is the number of distinct keys the filter
+remembers before it resets; window <= 0 resets on that alone. See
+doorkeeper.go.
+
+    cache := tempuscache.New(
+        tempuscache.WithMaxEntries(10_000),
+        tempuscache.WithDoorkeeper(100_000, time.Minute),
+    )
+*/
+
+func WithDoorkeeper(expected int, window time.Duration) Option {
+	return func(c *Cache) {
+		c.doorkeeper = newDoorkeeper(expected, window)
+	}
+}
+
+/*
+WithFrequencySketch counts every lookup in a count-min sketch of
+width counters per row, with periodic aging, so Frequency can
+estimate how often any key was looked up recently. width <= 0
+disables it. Like WithTopK, it disables the lock-free hit path. See
+frequency.go.
+*/
+
+func WithFrequencySketch(width int) Option {
+	return func(c *Cache) {
+		if width > 0 {
+			c.frequency = newFrequencySketch(width)
+		} else {
+			c.frequency = nil
+		}
+	}
+}
+
+/*
+WithGhostHistory remembers the hashes of the last n evicted keys, so
+that misses on them are counted in Stats().PrematureEvictions: a
+direct measure of capacity being too small. n <= 0 disables it. See
+ghost.go.
+*/
+
+func WithGhostHistory(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.ghosts = newGhostList(n)
+		} else {
+			c.ghosts = nil
+		}
+	}
+}
+
+/*
+WithTTLBuckets indexes entries by deadline, rounded up to a multiple
+of width, so the janitor removes whole buckets of expired entries
+instead of scanning the cache. Expired entries may then linger up to
+width before removal. width <= 0 keeps the full scan. See expiry.go.
+
+    cache := tempuscache.New(
+        tempuscache.WithCleanupInterval(time.Second),
+        tempuscache.WithTTLBuckets(time.Second),
+    )
+*/
+
+func WithTTLBuckets(width time.Duration) Option {
+	return func(c *Cache) {
+		if width > 0 {
+			c.expiry = newExpiryBuckets(int64(width))
+		} else {
+			c.expiry = nil
+		}
+	}
+}
+
+/*
+WithHasher replaces the key hash of the cache's sketches, filters,
+and lock stripes (default: FNV-1a). h must be deterministic and safe
+for concurrent use. See hash.go.
+
+    cache := tempuscache.New(
+        tempuscache.WithTopK(10),
+        tempuscache.WithHasher(tempuscache.MapHash()),
+    )
+*/
+
+func WithHasher(h func(key string) uint64) Option {
+	return func(c *Cache) {
+		c.hasher = h
+	}
+}
+
+/*
+WithCopyOnRead makes every read return a deep copy of the stored
+value, so callers cannot modify the cached copy. Values implementing
+Cloner are copied with Clone; all others with clone, or DeepCopy if
+clone is nil. See clone.go.
+*/
+
+func WithCopyOnRead(clone func(v interface{}) interface{}) Option {
+	return func(c *Cache) {
+		if clone == nil {
+			clone = DeepCopy
+		}
+		c.clone = clone
+	}
+}
+
+/*
+WithInterceptor wraps Get, Set, and Delete with the given
+interceptors, the first outermost. Repeated uses append to the
+chain. See interceptor.go.
+
+    cache := tempuscache.New(tempuscache.WithInterceptor(logCalls, denyInternal))
+*/
+
+func WithInterceptor(interceptors ...Interceptor) Option {
+	return func(c *Cache) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+/*
+WithDefaultTTL sets the TTL of new entries written without one
+(ttl <= 0), so that nothing is cached forever by accident. Entries
+that already exist keep their expiration, as with Set, and Expire
+with ttl <= 0 still makes an entry permanent. d <= 0, the default,
+means entries without a TTL never expire.
+
+It can be changed on a running cache with SetDefaultTTL.
+*/
+
+func WithDefaultTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.defaultTTL = d
+	}
+}
+
+/*
+WithOnEvict calls fn with the key and value of every entry removed
+by capacity eviction (reason EventEvicted) or because its TTL
+elapsed (reason EventExpired). Deletes and flushes are not reported.
+
+fn runs while the cache holds its exclusive lock, in the order the
+removals happen: it must be fast and must NOT call back into the
+cache. Use Subscribe for asynchronous notifications instead.
+
+It can be replaced on a running cache with SetOnEvict.
+*/
+
+func WithOnEvict(fn func(key string, value interface{}, reason EventType)) Option {
+	return func(c *Cache) {
+		c.onEvict = fn
+	}
+}
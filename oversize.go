@@ -0,0 +1,73 @@
+package tempuscache
+
+import "fmt"
+
+/*
+oversize.go implements the maximum value size (WithMaxValueBytes).
+
+================================================================================
+WHY?
+================================================================================
+
+In a cache bounded by entries, one huge value costs as much memory
+as thousands of ordinary ones; in one bounded by memory, storing it
+evicts them. Values that are rarely worth caching at that size are
+better refused at the door.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Values are measured in put(), the single write point, as
+EstimatedBytes counts them: in their stored form, i.e. after
+WithSerializer and WithCompression, excluding the key and the fixed
+per-entry overhead. Every write of a larger value is counted in
+Stats().Rejected and handled according to the OversizePolicy:
+
+RejectOversize:
+    → The write is refused, and an existing entry for the key keeps
+      its old value.
+    → Methods returning errors (SetContext, IncrementBy, ...) return
+      an error wrapping ErrTooLarge; the others drop it silently.
+
+SkipOversize:
+    → The write succeeds without caching the value: an existing entry
+      for the key is deleted, so reads fall through to the Store (if
+      any) instead of serving the old value.
+    → No error is returned.
+
+With WithWriteThrough and SaveBeforeCache, the value is measured
+after the Store has saved it, so the Store keeps it even when it is
+rejected; with SaveAfterCache, a rejected value is not saved.
+*/
+
+// OversizePolicy selects what WithMaxValueBytes does with a value
+// over the limit.
+type OversizePolicy int
+
+const (
+	// RejectOversize refuses the write with ErrTooLarge.
+	RejectOversize OversizePolicy = iota + 1
+
+	// SkipOversize drops the key from the cache without an error.
+	SkipOversize
+)
+
+/*
+oversize handles a write of a value of n stored bytes over the
+limit (see OversizePolicy).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) oversize(key string, n int64) error {
+	c.counters.rejected.Add(1)
+	c.logger().Debug("tempuscache: oversized value not cached", "key", key, "bytes", n, "limit", c.maxValueBytes)
+
+	if c.oversizePolicy == SkipOversize {
+		c.delete(key)
+		return nil
+	}
+	return fmt.Errorf("%w: value is %d bytes, limit is %d", ErrTooLarge, n, c.maxValueBytes)
+}
@@ -0,0 +1,59 @@
+package tempuscache
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMaxValueBytesReject(t *testing.T) {
+	cache := New(WithMaxValueBytes(16, RejectOversize))
+	defer cache.Stop()
+
+	cache.Set("a", "small", 0)
+	cache.Set("a", strings.Repeat("x", 17), 0)
+	if v, _ := cache.Get("a"); v != "small" {
+		t.Fatalf("expected the old value to be kept, got %v", v)
+	}
+
+	err := cache.SetContext(context.Background(), "b", make([]byte, 100), 0)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected the oversized value not to be cached")
+	}
+	if s := cache.Stats(); s.Rejected != 2 || s.Sets != 1 {
+		t.Fatalf("expected 2 rejections and 1 set, got %+v", s)
+	}
+}
+
+func TestMaxValueBytesSkip(t *testing.T) {
+	cache := New(WithMaxValueBytes(16, SkipOversize))
+	defer cache.Stop()
+
+	cache.Set("a", "small", 0)
+	if err := cache.SetContext(context.Background(), "a", strings.Repeat("x", 17), 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the old value to be dropped")
+	}
+	if s := cache.Stats(); s.Rejected != 1 {
+		t.Fatalf("expected 1 rejection, got %d", s.Rejected)
+	}
+}
+
+func TestMaxValueBytesStoredSize(t *testing.T) {
+	cache := New(WithMaxValueBytes(200, RejectOversize), WithCompression(GzipCompressor(gzip.BestSpeed)))
+	defer cache.Stop()
+
+	// 4 KiB of repetitive text compresses well under the limit.
+	big := strings.Repeat("a", 4096)
+	cache.Set("a", big, 0)
+	if v, ok := cache.Get("a"); !ok || v != big {
+		t.Fatal("expected the compressed value to fit")
+	}
+}
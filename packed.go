@@ -0,0 +1,139 @@
+package tempuscache
+
+/*
+packed.go implements the stored form of values under WithSerializer
+and WithCompression.
+
+================================================================================
+PIPELINE
+================================================================================
+
+On every write, put() passes the value through pack():
+
+    value ──Serializer.Marshal──▶ bytes ──Compressor.Compress──▶ data
+
+Each stage is optional: without a Serializer only []byte and string
+values enter the pipeline, and compression is skipped for short
+values or when it does not shrink them. A value that went through
+neither stage is stored as-is.
+
+On every read, unpack() reverses the stages, so callers always see
+a value of the type they stored (up to what the Serializer can
+reproduce).
+*/
+
+// packed value kinds: how unpack rebuilds the original value.
+const (
+	packedBytes uint8 = iota
+	packedString
+	packedSerialized
+)
+
+/*
+packed is a value stored in encoded form.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+data       -> Stored bytes
+raw        -> Length of the bytes before compression
+kind       -> packedBytes, packedString, or packedSerialized
+compressed -> Whether data is compressed
+*/
+
+type packed struct {
+	data       []byte
+	raw        int
+	kind       uint8
+	compressed bool
+}
+
+// Size reports the stored size to estimateSize.
+func (v *packed) Size() int {
+	return len(v.data)
+}
+
+/*
+pack returns the form in which value is stored.
+
+Serialization and compression errors are logged and the value is
+stored as-is, since Set cannot report them.
+*/
+
+func (c *Cache) pack(value interface{}) interface{} {
+	if value == nil || c.serializer == nil && c.compressor == nil {
+		return value
+	}
+
+	var raw []byte
+	var kind uint8
+	switch v := value.(type) {
+	case []byte:
+		raw, kind = v, packedBytes
+	case string:
+		raw, kind = []byte(v), packedString
+	}
+
+	if c.serializer != nil {
+		data, err := c.serializer.Marshal(value)
+		if err != nil {
+			c.logger().Warn("tempuscache: serialization failed, storing value as-is", "err", err)
+			return value
+		}
+		raw, kind = data, packedSerialized
+	} else if raw == nil {
+		return value
+	}
+
+	p := &packed{data: raw, raw: len(raw), kind: kind}
+	if c.compressor != nil && len(raw) >= compressMinSize {
+		data, err := c.compressor.Compress(raw)
+		if err != nil {
+			c.logger().Warn("tempuscache: compression failed, storing value uncompressed", "err", err)
+		} else if len(data) < len(raw) {
+			p.data, p.compressed = data, true
+		}
+	}
+	if !p.compressed && kind != packedSerialized {
+		return value
+	}
+	return p
+}
+
+/*
+unpack returns the original form of a stored value.
+
+A value that fails to decode is logged and read as nil; this only
+happens if the Serializer or Compressor cannot round-trip its own
+output.
+*/
+
+func (c *Cache) unpack(value interface{}) interface{} {
+	p, ok := value.(*packed)
+	if !ok {
+		return value
+	}
+
+	raw := p.data
+	if p.compressed {
+		var err error
+		if raw, err = c.compressor.Decompress(p.data); err != nil {
+			c.logger().Error("tempuscache: decompression failed", "err", err)
+			return nil
+		}
+	}
+
+	switch p.kind {
+	case packedString:
+		return string(raw)
+	case packedSerialized:
+		v, err := c.serializer.Unmarshal(raw)
+		if err != nil {
+			c.logger().Error("tempuscache: deserialization failed", "err", err)
+			return nil
+		}
+		return v
+	}
+	return raw
+}
@@ -0,0 +1,425 @@
+package tempuscache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*
+persistence.go implements snapshot persistence for TempusCache.
+
+================================================================================
+SNAPSHOT FORMAT
+================================================================================
+
+A snapshot is a stream produced by the configured Codec
+(gob by default, see WithCodec) consisting of:
+
+1. A snapshotHeader (format version + entry count).
+2. One SnapshotEntry per live entry, ordered from the LEAST
+   recently used to the MOST recently used.
+
+Writing oldest-first means that replaying the entries in order
+naturally rebuilds the original LRU ordering.
+
+================================================================================
+EXPIRATION
+================================================================================
+
+Each entry stores its absolute expiration deadline (UnixNano), not
+a relative TTL. A restored entry therefore expires at exactly the
+same wall-clock instant it would have expired had the process
+never restarted.
+
+================================================================================
+VALUE TYPES
+================================================================================
+
+Values are stored as interface{}. With the default gob codec,
+concrete types must be registered:
+
+    gob.Register(MyStruct{})
+
+Built-in types (string, int, []byte, ...) work without registration.
+Values gob cannot handle need a custom Codec.
+*/
+
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+	Count   int
+}
+
+/*
+SnapshotEntry is the unit a Codec encodes and decodes.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Key        -> Cache key
+Value      -> Stored value
+Expiration -> Absolute deadline in UnixNano (0 = never expires)
+*/
+
+type SnapshotEntry struct {
+	Key        string
+	Value      interface{}
+	Expiration int64
+}
+
+/*
+snapshot captures all live entries in LRU order (oldest first).
+
+The lock is only held to take a View (see view.go); values are
+decoded and encoded afterwards, so slow I/O never blocks writers.
+*/
+
+func (c *Cache) snapshot() []SnapshotEntry {
+	v := c.SnapshotView()
+	entries := make([]SnapshotEntry, len(v.entries))
+	for i, e := range v.entries {
+		entries[i] = SnapshotEntry{
+			Key:        e.key,
+			Value:      c.unpack(e.value),
+			Expiration: e.expiration,
+		}
+	}
+	return entries
+}
+
+/*
+writeSnapshot encodes the given entries to w using the snapshot format.
+*/
+
+func writeSnapshot(w io.Writer, codec Codec, entries []SnapshotEntry) error {
+	enc := codec.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, Count: len(entries)}); err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Save writes a snapshot of all live entries to w.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Expired entries are skipped.
+- Values, absolute expiration deadlines, and LRU order are preserved.
+- Entries are encoded with the cache's Codec (gob unless WithCodec
+  was used).
+
+================================================================================
+WHY io.Writer?
+================================================================================
+
+Accepting any io.Writer lets callers persist anywhere and compose
+freely, for example:
+
+    zw := gzip.NewWriter(objectStoreUpload)
+    cache.Save(zw)
+    zw.Close()
+
+Save does not close w.
+*/
+
+func (c *Cache) Save(w io.Writer) error {
+	return writeSnapshot(w, c.codecOrDefault(), c.snapshot())
+}
+
+/*
+SaveFile serializes all live entries to the file at path.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Expired entries are skipped.
+- Values, absolute expiration deadlines, and LRU order are preserved.
+
+================================================================================
+CRASH SAFETY
+================================================================================
+
+The snapshot is written to a temporary file in the same directory,
+flushed to stable storage, and then atomically renamed over path.
+A crash mid-write leaves the previous good snapshot intact.
+
+================================================================================
+ERRORS
+================================================================================
+
+Returns any error from file creation, gob encoding (e.g. an
+unregistered value type), syncing, or renaming.
+
+================================================================================
+USE CASE
+================================================================================
+
+Services that restart frequently during deploys lose their entire
+cache every time, causing a cold-start thundering herd against
+the backing store. A snapshot taken at shutdown avoids this.
+*/
+
+func (c *Cache) SaveFile(path string) error {
+	start := time.Now()
+	// Failures are returned, not logged: the caller decides whether
+	// they are worth a warning (the auto-snapshot worker logs them).
+	if err := writeFileAtomic(path, c.Save); err != nil {
+		return err
+	}
+	c.logger().Debug("tempuscache: snapshot saved", "path", path, "duration", time.Since(start))
+	return nil
+}
+
+/*
+writeFileAtomic writes a file via temp-file + fsync + rename,
+so readers only ever observe either the old or the new content.
+*/
+
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+/*
+readSnapshot decodes a snapshot stream produced by writeSnapshot.
+*/
+
+func readSnapshot(r io.Reader, codec Codec) ([]SnapshotEntry, error) {
+	dec := codec.NewDecoder(r)
+
+	var hdr snapshotHeader
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, err
+	}
+	if hdr.Version != snapshotVersion {
+		return nil, fmt.Errorf("tempuscache: unsupported snapshot version %d", hdr.Version)
+	}
+
+	if hdr.Count < 0 {
+		return nil, fmt.Errorf("tempuscache: invalid snapshot entry count %d", hdr.Count)
+	}
+
+	// The header is untrusted input: grow the slice as entries are
+	// actually decoded instead of preallocating hdr.Count of them.
+	var entries []SnapshotEntry
+	for len(entries) < hdr.Count {
+		var e SnapshotEntry
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+/*
+restore applies decoded snapshot entries to the cache.
+
+================================================================================
+ALGORITHM
+================================================================================
+
+1. Acquire the exclusive lock once.
+2. Walk entries oldest-first:
+   - Skip entries whose deadline has already passed.
+   - Insert the rest with their ORIGINAL absolute deadline.
+3. Because each insert goes to the LRU front, the last entry
+   written (the most recently used one) ends up at the front.
+
+Entries are merged into any existing content; capacity limits
+apply as usual, so an oversized snapshot keeps its most recently
+used entries.
+
+RETURNS:
+The number of entries restored.
+*/
+
+func (c *Cache) restore(entries []SnapshotEntry) int {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.restoring = true
+	defer func() { c.restoring = false }()
+
+	n := 0
+	for _, e := range entries {
+		if e.Expiration != 0 && now > e.Expiration {
+			continue
+		}
+		c.put(e.Key, e.Value, e.Expiration)
+		n++
+	}
+	return n
+}
+
+/*
+Load repopulates the cache from a snapshot read from r.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- The snapshot is decoded with the cache's Codec, which must match
+  the one used by Save.
+- Entries that have already expired are skipped.
+- Remaining entries keep their original absolute deadline.
+- LRU order from the snapshot is preserved.
+- Existing keys in the cache are overwritten by snapshot values.
+
+The whole snapshot is decoded before the cache is touched, so the
+cache is left unmodified on decode errors.
+
+Load does not close r.
+*/
+
+func (c *Cache) Load(r io.Reader) error {
+	entries, err := readSnapshot(r, c.codecOrDefault())
+	if err != nil {
+		return err
+	}
+
+	c.restore(entries)
+	return nil
+}
+
+/*
+LoadFile repopulates the cache from a snapshot written by SaveFile.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Entries that expired while the snapshot sat on disk are skipped.
+- Remaining entries keep their original absolute deadline, so the
+  remaining TTL is reconstructed relative to load time:
+
+      remaining = deadline - now
+
+- LRU order from the snapshot is preserved.
+- Existing keys in the cache are overwritten by snapshot values.
+
+================================================================================
+ERRORS
+================================================================================
+
+Returns an error if the file cannot be opened, is not a valid
+snapshot, or was written by an unsupported format version.
+The cache is left unmodified on decode errors.
+*/
+
+func (c *Cache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		c.logger().Warn("tempuscache: snapshot load failed", "path", path, "err", err)
+		return err
+	}
+	defer f.Close()
+
+	if err := c.Load(f); err != nil {
+		c.logger().Warn("tempuscache: snapshot load failed", "path", path, "err", err)
+		return err
+	}
+	c.logger().Debug("tempuscache: snapshot loaded", "path", path, "entries", c.Len())
+	return nil
+}
+
+/*
+loadSnapshot loads the snapshot configured by WithSnapshotPath, if
+it exists.
+*/
+
+func (c *Cache) loadSnapshot() error {
+	if !c.snapshotLoad {
+		return nil
+	}
+	if _, err := os.Stat(c.snapshotPath); errors.Is(err, fs.ErrNotExist) {
+		c.logger().Debug("tempuscache: no snapshot to load", "path", c.snapshotPath)
+		return nil
+	}
+	return c.LoadFile(c.snapshotPath)
+}
+
+/*
+startAutoSnapshot launches the periodic snapshot worker configured
+by WithAutoSnapshot().
+
+================================================================================
+EXECUTION MODEL
+================================================================================
+
+- If no path or a non-positive interval is configured:
+    → Auto-snapshot is disabled.
+
+- Otherwise:
+    → A dedicated goroutine calls SaveFile() on every tick.
+    → It shares stopChan with the janitor, so Stop() terminates both.
+
+Every write goes through SaveFile()'s atomic rename, so a crash
+during a background snapshot never corrupts the last good file.
+*/
+
+func (c *Cache) startAutoSnapshot() {
+	if c.snapshotPath == "" || c.snapshotInterval <= 0 {
+		return
+	}
+
+	ticker := c.clock.NewTicker(c.snapshotInterval)
+
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		for {
+			select {
+			case <-ticker.C():
+				if err := c.SaveFile(c.snapshotPath); err != nil {
+					c.logger().Warn("tempuscache: auto-snapshot failed", "path", c.snapshotPath, "err", err)
+				}
+			case <-c.stopChan:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
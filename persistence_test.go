@@ -0,0 +1,159 @@
+package tempuscache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveFile(t *testing.T) {
+	cache := New()
+
+	cache.Set("old", 1, 0)
+	cache.Set("new", 2, time.Hour)
+	cache.Set("gone", 3, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	if err := cache.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	var hdr snapshotHeader
+	if err := dec.Decode(&hdr); err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Count != 2 {
+		t.Fatalf("expected 2 live entries, got %d", hdr.Count)
+	}
+
+	var first SnapshotEntry
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Key != "old" {
+		t.Fatalf("expected oldest entry first, got %q", first.Key)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	src := New()
+
+	src.Set("a", "x", 0)
+	src.Set("b", "y", 50*time.Millisecond)
+	src.Set("c", "z", 0)
+
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	if err := src.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := New(WithMaxEntries(2))
+	if err := dst.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Capacity 2 keeps the two most recently used entries.
+	if _, found := dst.Get("a"); found {
+		t.Fatal("expected least recently used entry to be evicted on load")
+	}
+	if val, found := dst.Get("c"); !found || val != "z" {
+		t.Fatalf("expected 'z', got %v", val)
+	}
+
+	// The original deadline is preserved, not reset at load time.
+	time.Sleep(60 * time.Millisecond)
+	if _, found := dst.Get("b"); found {
+		t.Fatal("expected restored entry to expire at its original deadline")
+	}
+}
+
+func TestAutoSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auto.snap")
+
+	cache := New(WithAutoSnapshot(path, 5*time.Millisecond))
+
+	cache.Set("a", 1, 0)
+	time.Sleep(30 * time.Millisecond)
+	cache.Stop()
+
+	restored := New()
+	if err := restored.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if val, found := restored.Get("a"); !found || val != 1 {
+		t.Fatalf("expected snapshot to contain 'a', got %v", val)
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp-*")
+	if len(matches) != 0 {
+		t.Fatalf("expected no leftover temp files, got %v", matches)
+	}
+}
+
+func TestSaveLoadWithCodec(t *testing.T) {
+	src := New(WithCodec(JSONCodec{}))
+	src.Set("a", "x", time.Hour)
+	src.Set("b", "y", 0)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := src.Save(zw); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := New(WithCodec(JSONCodec{}))
+	if err := dst.Load(zr); err != nil {
+		t.Fatal(err)
+	}
+
+	got := dst.GetMany([]string{"a", "b"})
+	if got["a"] != "x" || got["b"] != "y" {
+		t.Fatalf("unexpected restored state: %v", got)
+	}
+}
+
+func TestLoadTruncatedSnapshot(t *testing.T) {
+	// The header promises two entries but the stream ends after one.
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	enc.Encode(snapshotHeader{Version: snapshotVersion, Count: 2})
+	enc.Encode(SnapshotEntry{Key: "a", Value: "x"})
+
+	dst := New()
+	err := dst.Load(&buf)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if dst.Len() != 0 {
+		t.Fatalf("expected cache untouched, got %d entries", dst.Len())
+	}
+}
+
+func TestLoadNegativeSnapshotCount(t *testing.T) {
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(snapshotHeader{Version: snapshotVersion, Count: -1})
+
+	if err := New().Load(&buf); err == nil {
+		t.Fatal("expected error for negative entry count")
+	}
+}
@@ -0,0 +1,124 @@
+package tempuscache
+
+import "time"
+
+/*
+pin.go implements pinned entries, which LRU eviction never removes.
+
+================================================================================
+WHY?
+================================================================================
+
+Some entries must stay cached however cold they are: feature flags,
+schema metadata, and other small values whose absence costs far
+more than their space. Under recency alone, a burst of other keys
+evicts them.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+A pinned entry:
+
+- Is never chosen by eviction (Set at capacity, Resize).
+- Still counts towards WithMaxEntries, so the capacity left for
+  other entries shrinks accordingly.
+- Still expires on its TTL, and is still removed by Delete, Flush,
+  and cross-node invalidation.
+- Stays pinned when overwritten.
+
+Pins are cache-local state: snapshots and the append-only log do not
+record them, so they must be re-applied after a restore.
+
+If every entry is pinned, inserting a new key grows the cache past
+its capacity instead of failing. Later inserts evict one entry each,
+so the excess remains until entries are deleted or expire, or Resize
+evicts as many unpinned entries as needed.
+
+================================================================================
+EVICTION COST
+================================================================================
+
+evictOldest moves pinned entries it finds at the back of the LRU
+list to the front, so each is skipped once rather than on every
+eviction. Eviction stays amortised O(1); in exchange, the LRU
+position of a pinned entry (Keys, Inspect, snapshots) does not
+reflect how recently it was used.
+*/
+
+/*
+Pin exempts key from eviction (see pin.go).
+
+RETURNS:
+true if key holds a live entry, which is now pinned.
+*/
+
+func (c *Cache) Pin(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pin(key, true)
+}
+
+/*
+Unpin makes key evictable again. It becomes the most recently used
+entry.
+
+RETURNS:
+true if key holds a live entry, which is now unpinned.
+*/
+
+func (c *Cache) Unpin(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pin(key, false)
+}
+
+/*
+SetPinned stores value under key like Set and pins it in the same
+step, so it cannot be evicted in between. Like SetMany, it does not
+write through to the Store.
+*/
+
+func (c *Cache) SetPinned(key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.set(key, value, ttl); err != nil {
+		return err
+	}
+	c.pin(key, true)
+	return nil
+}
+
+/*
+pin sets the pinned state of key and keeps the pinned count.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) pin(key string, pinned bool) bool {
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+	item := elem.Value.(*Item)
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		return false
+	}
+
+	if item.pinned != pinned {
+		item.pinned = pinned
+		if pinned {
+			c.pinned++
+			c.priorities[item.priority.slot()]--
+		} else {
+			c.pinned--
+			c.priorities[item.priority.slot()]++
+			c.applyPromotions()
+			c.lru.MoveToFront(elem)
+		}
+	}
+	return true
+}
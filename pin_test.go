@@ -0,0 +1,85 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPinnedEntriesSurviveEviction(t *testing.T) {
+	cache := New(WithMaxEntries(3))
+	defer cache.Stop()
+
+	cache.SetPinned("flags", "on", 0)
+	cache.Set("a", 1, 0)
+	if !cache.Pin("a") || cache.Pin("missing") {
+		t.Fatal("expected Pin to report whether the key exists")
+	}
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+	for _, key := range []string{"flags", "a", "9"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+	if s := cache.Stats(); s.Entries != 3 || s.Pinned != 2 || s.Evictions != 9 {
+		t.Fatalf("expected 3 entries, 2 pinned, 9 evictions, got %+v", s)
+	}
+
+	// Overwrites keep the pin; Delete removes pinned entries.
+	cache.Set("a", 2, 0)
+	if info, _ := cache.Inspect("a"); !info.Pinned {
+		t.Fatal("expected the overwrite to stay pinned")
+	}
+	cache.Delete("flags")
+	if s := cache.Stats(); s.Pinned != 1 {
+		t.Fatalf("expected 1 pinned entry after Delete, got %d", s.Pinned)
+	}
+
+	// Unpinned entries are evictable again.
+	cache.Unpin("a")
+	cache.Set("x", 0, 0)
+	cache.Set("y", 0, 0)
+	cache.Set("z", 0, 0)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the unpinned entry to be evicted")
+	}
+}
+
+func TestAllPinnedGrowsPastCapacity(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+	defer cache.Stop()
+
+	cache.SetPinned("a", 1, 0)
+	cache.SetPinned("b", 2, 0)
+	cache.Set("c", 3, 0)
+	if cache.Len() != 3 {
+		t.Fatalf("expected the cache to grow past capacity, got %d entries", cache.Len())
+	}
+
+	// Later inserts evict one entry each, keeping the excess.
+	cache.Set("d", 4, 0)
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d", cache.Len())
+	}
+	if n := cache.Resize(1); n != 1 || cache.Len() != 2 {
+		t.Fatalf("expected Resize to evict only the unpinned entry, got %d evicted and %d left", n, cache.Len())
+	}
+}
+
+func TestPinnedEntriesExpire(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock))
+	defer cache.Stop()
+
+	cache.SetPinned("a", 1, time.Second)
+	clock.advance(2 * time.Second)
+	if cache.DeleteExpired() != 1 {
+		t.Fatal("expected the pinned entry to expire")
+	}
+	if s := cache.Stats(); s.Pinned != 0 {
+		t.Fatalf("expected no pinned entries, got %d", s.Pinned)
+	}
+}
@@ -0,0 +1,112 @@
+package tempuscache
+
+import (
+	"container/list"
+	"sync"
+)
+
+/*
+pool.go recycles entries so that steady-state writes stop allocating.
+
+================================================================================
+WHY?
+================================================================================
+
+Every unique insert used to allocate a fresh Item and a fresh list
+node, and every eviction, delete, or expiration dropped one of each
+on the floor for the garbage collector. On a full cache under churn
+that is pure GC pressure: the entry count never changes.
+
+================================================================================
+DESIGN
+================================================================================
+
+Two mechanisms, one per path:
+
+- Eviction: when an insert finds the cache full, the evicted entry
+  is not unlinked at all. Its Item and list node are reset in place
+  for the new key and moved to the front, so an insert into a full
+  cache allocates neither.
+
+- Delete / expiration / invalidation: the list node cannot be reused
+  (container/list only inserts nodes it allocates itself), but the
+  Item is returned to a sync.Pool and handed to the next insert.
+
+Flush() drops the whole map and list at once and does not recycle.
+
+================================================================================
+SAFETY
+================================================================================
+
+The lock-free hit path (see readpath.go) may still hold a pointer to
+an entry that is being recycled. Two rules keep it correct:
+
+- A retired Item publishes retiredView, which is always expired, so
+  a stale reader falls back to the locked path.
+- Every view carries its key, and fastGet rejects views for another
+  key, so a reader never returns the value of the entry's new key.
+
+A stale reader may still count one hit against the reused entry's
+metadata or promote it once; both only affect approximate recency.
+*/
+
+// itemPool holds retired Items for reuse by later inserts.
+var itemPool = sync.Pool{
+	New: func() any { return new(Item) },
+}
+
+// retiredView is published by recycled Items; its deadline has long passed.
+var retiredView = &itemView{expiration: 1}
+
+/*
+reset reinitialises item for a new lifetime under key.
+
+Atomic fields are stored rather than overwritten, since the lock-free
+hit path may still read them.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (item *Item) reset(key string, value interface{}, exp, size, now int64) {
+	item.key = key
+	item.value = value
+	item.expiration = exp
+	item.size = size
+	item.created = now
+	item.written = now
+	item.delta = 0
+	item.pinned = false
+	item.priority = PriorityNormal
+	item.cost = 1
+	item.bucket = 0
+	item.accessed.Store(0)
+	item.hits.Store(0)
+}
+
+/*
+newItem returns an Item for a fresh insert, reusing a retired one
+when available.
+*/
+
+func newItem(key string, value interface{}, exp, size, now int64) *Item {
+	item := itemPool.Get().(*Item)
+	item.reset(key, value, exp, size, now)
+	return item
+}
+
+/*
+recycle retires the Item of an element that has been removed from
+the cache and returns it to itemPool.
+
+NOTE:
+The caller must hold the exclusive lock and must not use the
+element's Item afterwards.
+*/
+
+func recycle(e *list.Element) {
+	item := e.Value.(*Item)
+	item.view.Store(retiredView)
+	item.value = nil
+	itemPool.Put(item)
+}
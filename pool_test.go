@@ -0,0 +1,81 @@
+package tempuscache
+
+import "testing"
+
+func TestEvictionReusesEntry(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Get("a")
+	cache.Get("a")
+	victim := cache.data["b"]
+
+	cache.Set("c", 3, 0)
+
+	if cache.data["c"] != victim {
+		t.Fatal("expected the insert to reuse the evicted entry")
+	}
+	if _, found := cache.Get("b"); found {
+		t.Fatal("expected 'b' to be evicted")
+	}
+	if v, _ := cache.Get("c"); v != 3 {
+		t.Fatalf("expected the new value, got %v", v)
+	}
+	if got := cache.Keys(); len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Fatalf("expected MRU order [c a], got %v", got)
+	}
+	if info, _ := cache.Inspect("c"); info.Hits != 1 {
+		t.Fatalf("expected fresh metadata on the reused entry, got %+v", info)
+	}
+	if s := cache.Stats(); s.Evictions != 1 || s.Entries != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestRecycledEntryNotServed(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	stale := cache.data["a"]
+	cache.Delete("a")
+
+	// A lock-free reader that looked "a" up before the delete.
+	cache.index.Store("a", stale)
+	if _, found := cache.fastGet("a"); found {
+		t.Fatal("expected a retired entry to be rejected")
+	}
+
+	// The same Item, reused for another key.
+	item := stale.Value.(*Item)
+	item.reset("b", 2, 0, 0, cache.now())
+	cache.publish(item)
+	if _, found := cache.fastGet("a"); found {
+		t.Fatal("expected an entry recycled for another key to be rejected")
+	}
+}
+
+/*
+BenchmarkEvictionAllocs measures allocations of inserts into a full
+cache, with keys built up front and a nil value so that only the
+cache's own allocations are counted.
+
+The evicted entry is reused in place, so what remains is the
+published view and the lock-free index insert.
+*/
+
+func BenchmarkEvictionAllocs(b *testing.B) {
+	cache := New(WithMaxEntries(100))
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = string(rune('a'+i%26)) + string(rune('A'+i/26))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(keys[i%len(keys)], nil, 0)
+	}
+}
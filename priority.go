@@ -0,0 +1,151 @@
+package tempuscache
+
+import "time"
+
+/*
+priority.go implements per-entry eviction priorities.
+
+================================================================================
+WHY?
+================================================================================
+
+When data of mixed criticality shares one cache, recency alone
+decides what survives: a burst of cheap, easily recomputed entries
+evicts expensive ones just because they are newer.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Every entry has a Priority, PriorityNormal unless set otherwise with
+SetWithPriority or SetPriority. Eviction always removes an entry of
+the lowest priority present, and the least recently used one among
+those:
+
+- PriorityLow entries go first.
+- PriorityNormal entries are evicted only once no Low entry is left.
+- PriorityHigh entries are evicted only once only High (and pinned)
+  entries are left.
+
+Pinned entries (see pin.go) are above every priority: they are never
+evicted at all. Priorities only affect eviction; expiration, Delete,
+and capacity accounting treat all entries alike. Overwriting an
+entry keeps its priority.
+
+Like pins, priorities are not recorded by snapshots or the
+append-only log.
+
+================================================================================
+EVICTION COST
+================================================================================
+
+evictOldest walks from the back of the LRU list, moving each entry
+of a higher priority than the victim's to the front. Moving them
+preserves the relative order of the entries within each priority,
+which is all that eviction uses; the LRU position reported by Keys
+and Inspect then only reflects recency within a priority.
+
+An eviction costs O(1) plus the number of higher-priority entries
+it moves. That is small when lower-priority entries make up a fair
+share of the cache, but approaches O(n) when a few Low entries churn
+behind many High ones.
+*/
+
+// Priority ranks entries for eviction (see priority.go).
+type Priority int8
+
+const (
+	// PriorityLow entries are evicted before any other.
+	PriorityLow Priority = iota - 1
+
+	// PriorityNormal is the priority of entries written without one.
+	PriorityNormal
+
+	// PriorityHigh entries are evicted only when nothing else is left.
+	PriorityHigh
+)
+
+// numPriorities is the number of Priority levels.
+const numPriorities = int(PriorityHigh-PriorityLow) + 1
+
+// slot returns the index of p in Cache.priorities, clamping values
+// outside the defined levels.
+func (p Priority) slot() int {
+	return int(min(max(p, PriorityLow), PriorityHigh) - PriorityLow)
+}
+
+/*
+SetWithPriority stores value under key like Set and gives it
+priority p in the same step. Like SetMany, it does not write through
+to the Store.
+*/
+
+func (c *Cache) SetWithPriority(key string, value interface{}, ttl time.Duration, p Priority) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.set(key, value, ttl); err != nil {
+		return err
+	}
+	c.prioritize(key, p)
+	return nil
+}
+
+/*
+SetPriority changes the priority of key. Values outside
+PriorityLow..PriorityHigh are clamped.
+
+RETURNS:
+true if key holds a live entry.
+*/
+
+func (c *Cache) SetPriority(key string, p Priority) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.prioritize(key, p)
+}
+
+/*
+prioritize sets the priority of key and keeps the per-priority
+counts.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) prioritize(key string, p Priority) bool {
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+	item := elem.Value.(*Item)
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		return false
+	}
+
+	p = PriorityLow + Priority(p.slot())
+	if !item.pinned {
+		c.priorities[item.priority.slot()]--
+		c.priorities[p.slot()]++
+	}
+	item.priority = p
+	return true
+}
+
+/*
+lowestPriority returns the lowest priority held by an unpinned
+entry, or PriorityHigh if there is none.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) lowestPriority() Priority {
+	for i, n := range c.priorities {
+		if n > 0 {
+			return PriorityLow + Priority(i)
+		}
+	}
+	return PriorityHigh
+}
@@ -0,0 +1,84 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEvictionByPriority(t *testing.T) {
+	cache := New(WithMaxEntries(4))
+	defer cache.Stop()
+
+	cache.SetWithPriority("high", 1, 0, PriorityHigh)
+	cache.SetWithPriority("low1", 2, 0, PriorityLow)
+	cache.Set("normal", 3, 0)
+	cache.SetWithPriority("low2", 4, 0, PriorityLow)
+
+	// Low entries go first, least recently used first, even when
+	// older entries of other priorities sit behind them.
+	cache.Get("low1")
+	cache.Set("x", 5, 0)
+	if _, ok := cache.Get("low2"); ok {
+		t.Fatal("expected the least recently used low-priority entry to be evicted first")
+	}
+	cache.Set("y", 6, 0)
+	if _, ok := cache.Get("low1"); ok {
+		t.Fatal("expected the remaining low-priority entry to be evicted next")
+	}
+
+	// Then normal entries in LRU order; high survives.
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+	if _, ok := cache.Get("high"); !ok {
+		t.Fatal("expected the high-priority entry to survive")
+	}
+	for _, key := range []string{"7", "8", "9"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+
+	// Only high-priority entries left: evicted in LRU order.
+	for _, key := range []string{"7", "8", "9"} {
+		cache.SetPriority(key, PriorityHigh)
+	}
+	cache.Get("7")
+	cache.SetWithPriority("z", 0, 0, PriorityHigh)
+	if _, ok := cache.Get("high"); ok {
+		t.Fatal("expected the least recently used high-priority entry to be evicted")
+	}
+}
+
+func TestPriorityWithPins(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+	defer cache.Stop()
+
+	cache.SetWithPriority("a", 1, 0, PriorityLow)
+	cache.Pin("a")
+	cache.SetWithPriority("b", 2, 0, PriorityHigh)
+	cache.Set("c", 3, 0)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected the pinned low-priority entry to survive")
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected the only unpinned entry to be evicted")
+	}
+
+	// Unpinning restores the priority; overwrites keep it.
+	cache.Unpin("a")
+	cache.Set("a", 4, 0)
+	if info, _ := cache.Inspect("a"); info.Priority != PriorityLow {
+		t.Fatalf("expected the priority to be kept, got %d", info.Priority)
+	}
+	cache.Set("d", 5, 0)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the low-priority entry to be evicted once unpinned")
+	}
+	if !cache.SetPriority("d", 100) {
+		t.Fatal("expected SetPriority to find the key")
+	}
+	if info, _ := cache.Inspect("d"); info.Priority != PriorityHigh {
+		t.Fatalf("expected out-of-range priorities to be clamped, got %d", info.Priority)
+	}
+}
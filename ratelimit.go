@@ -0,0 +1,107 @@
+package tempuscache
+
+import "time"
+
+/*
+ratelimit.go implements RateLimiter, a per-key rate limiter stored in
+a Cache.
+
+================================================================================
+WHY?
+================================================================================
+
+API throttling on top of a cache is usually written as Get, check,
+Set: two concurrent requests both read the same count and both get
+through. IncrementBy closes that race for fixed windows, but fixed
+windows let twice the limit through around each window boundary.
+
+================================================================================
+ALGORITHM
+================================================================================
+
+RateLimiter is a token bucket of limit tokens refilled at limit per
+period, implemented as GCRA (generic cell rate algorithm): each key
+stores a single int64, the time at which its bucket will be full
+again (its theoretical arrival time, TAT).
+
+A request for n tokens at now:
+
+1. tat = max(stored TAT, now)
+2. next = tat + n × period/limit
+3. Allowed if next - period <= now; the key then stores next.
+
+Denied requests change nothing, so a client retrying in a loop does
+not push its own limit further away.
+
+Every check is one Compute, so it is atomic under the cache lock. A
+key's TTL is the time until its bucket is full again: after that its
+state is the same as a new key's, and expiration (lazy or by the
+janitor) reclaims it. Idle clients cost nothing.
+
+================================================================================
+STORAGE
+================================================================================
+
+Entries are ordinary int64 entries keyed by the limited keys, and
+allowed requests count as Sets. Use a cache dedicated to limiting, or prefix keys so
+they cannot collide with other entries; a key holding a value that
+is not an int64 is treated as a new key and overwritten.
+*/
+
+type RateLimiter struct {
+	cache    *Cache
+	period   int64
+	interval int64
+}
+
+/*
+NewRateLimiter returns a limiter allowing each key limit requests
+per period, with bursts of up to limit. limit must be positive.
+*/
+
+func NewRateLimiter(c *Cache, limit int, period time.Duration) *RateLimiter {
+	if limit <= 0 {
+		panic("tempuscache: NewRateLimiter with non-positive limit")
+	}
+	return &RateLimiter{
+		cache:    c,
+		period:   int64(period),
+		interval: max(int64(period)/int64(limit), 1),
+	}
+}
+
+// Allow reports whether one request for key is allowed, and counts it if so.
+func (l *RateLimiter) Allow(key string) bool {
+	return l.AllowN(key, 1)
+}
+
+/*
+AllowN reports whether n requests for key are allowed at once, and
+counts them if so. Requests for more than the burst are never
+allowed.
+*/
+
+func (l *RateLimiter) AllowN(key string, n int) bool {
+	cost := int64(n) * l.interval
+	if cost > l.period {
+		return false
+	}
+
+	_, allowed := l.cache.Compute(key, func(old interface{}, exists bool) (interface{}, time.Duration, bool) {
+		now := l.cache.now()
+		tat, _ := old.(int64)
+		tat = max(tat, now)
+
+		next := tat + cost
+		if next-l.period > now {
+			return nil, 0, false
+		}
+		return next, time.Duration(next - now), true
+	})
+	return allowed
+}
+
+// Reset forgets the requests counted for key.
+func (l *RateLimiter) Reset(key string) {
+	l.cache.Delete(key)
+}
@@ -0,0 +1,83 @@
+package tempuscache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	c := New(WithClock(clock))
+	defer c.Stop()
+	limiter := NewRateLimiter(c, 3, 3*time.Second)
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("client") {
+			t.Fatalf("expected request %d of the burst to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("client") {
+		t.Fatal("expected the request beyond the burst to be denied")
+	}
+	if n := c.Stats().Sets; n != 3 {
+		t.Fatalf("expected denied requests not to write, got %d Sets", n)
+	}
+	if !limiter.Allow("other") {
+		t.Fatal("expected keys to be limited independently")
+	}
+
+	clock.advance(time.Second)
+	if !limiter.Allow("client") || limiter.Allow("client") {
+		t.Fatal("expected one token to be refilled after one interval")
+	}
+
+	if limiter.AllowN("fresh", 4) {
+		t.Fatal("expected requests larger than the burst to be denied")
+	}
+	limiter.Reset("client")
+	if !limiter.AllowN("client", 3) {
+		t.Fatal("expected Reset to restore the full burst")
+	}
+}
+
+func TestRateLimiterExpiresIdleKeys(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	c := New(WithClock(clock))
+	defer c.Stop()
+	limiter := NewRateLimiter(c, 10, time.Second)
+
+	limiter.AllowN("client", 5)
+	if ttl, ok := c.TTL("client"); !ok || ttl != 500*time.Millisecond {
+		t.Fatalf("expected the key to live until its bucket refills, got %v", ttl)
+	}
+	clock.advance(time.Second)
+	if n := c.DeleteExpired(); n != 1 {
+		t.Fatalf("expected the idle key to expire, got %d removals", n)
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	c := New()
+	defer c.Stop()
+	limiter := NewRateLimiter(c, 100, time.Hour)
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if limiter.Allow("shared") {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := allowed.Load(); n != 100 {
+		t.Fatalf("expected exactly the burst of 100 to be allowed, got %d", n)
+	}
+}
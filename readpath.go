@@ -0,0 +1,149 @@
+package tempuscache
+
+import "container/list"
+
+/*
+readpath.go implements the lock-free hit path of Get.
+
+================================================================================
+WHY?
+================================================================================
+
+Get needs the exclusive lock: a hit moves the entry to the front of
+the LRU list. On read-mostly workloads every core then queues on the
+same mutex, and throughput stops growing with the number of cores.
+
+================================================================================
+DESIGN
+================================================================================
+
+Every entry publishes an immutable view (value, expiration) through
+an atomic pointer, and a concurrent index (sync.Map) mirrors c.data,
+mapping keys to list elements. Writers, which hold the lock, replace the view or the index
+entry; a hit only performs atomic loads:
+
+    index.Load(key) → item.view.Load() → not expired → hit
+
+LRU promotion is recorded out-of-band: the hit sends the entry to a
+bounded promotion buffer, without blocking. The buffer is applied
+under the lock before the LRU list is read or changed (writes,
+locked hits, eviction, Keys, Inspect, snapshots, log rewrites), so
+promotions keep their order relative to other operations. When the buffer is full,
+further promotions are dropped: recency becomes approximate under
+extreme read pressure, as in other high-throughput caches. With
+WithSampledEviction, hits are not promoted at all (see sampled.go).
+
+Statistics, per-entry access metadata, and hit-ratio windows are
+all updated atomically (see stats.go and window.go).
+
+================================================================================
+WHEN THE LOCK IS STILL TAKEN
+================================================================================
+
+Misses and expired entries always take the locked path, which
+removes, counts, and loads them as before. The fast path is also
+bypassed entirely while a feature needs to observe hits in order
+under the lock: event subscribers, operation traces, top-K and
+frequency tracking, and the read-time policies of stale-while-revalidate, refresh-ahead,
+and early expiration.
+*/
+
+// promotionBuffer is the number of pending LRU promotions.
+const promotionBuffer = 4096
+
+/*
+itemView is the published, immutable state of an entry read by the
+lock-free hit path.
+*/
+
+type itemView struct {
+	key        string
+	value      interface{}
+	expiration int64
+}
+
+/*
+publish makes the current value and expiration of item visible to
+the lock-free hit path.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) publish(item *Item) {
+	item.view.Store(&itemView{key: item.key, value: item.value, expiration: item.expiration})
+}
+
+/*
+initReadPath decides whether the configured features allow the
+lock-free hit path.
+*/
+
+func (c *Cache) initReadPath() {
+	c.fastReads = c.tracer == nil && c.topK == nil && c.frequency == nil &&
+		c.staleWindow <= 0 && c.refreshAhead <= 0 && c.earlyBeta <= 0
+	c.promotions = make(chan *list.Element, promotionBuffer)
+}
+
+/*
+fastGet returns key if it is a live entry and can be served without
+the lock. found = false means the caller must take the locked path,
+whether key is missing or not.
+*/
+
+func (c *Cache) fastGet(key string) (value interface{}, found bool) {
+	if !c.fastReads || c.subscribers.Load() > 0 {
+		return nil, false
+	}
+
+	v, ok := c.index.Load(key)
+	if !ok {
+		return nil, false
+	}
+	elem := v.(*list.Element)
+	item := elem.Value.(*Item)
+	view := item.view.Load()
+	now := c.now()
+	if view.key != key || view.expiration != 0 && now > view.expiration {
+		return nil, false // expired, or recycled for another key
+	}
+
+	item.hits.Add(1)
+	item.accessed.Store(now)
+	c.counters.hits.Add(1)
+	c.window.record(now/1e9, true)
+
+	if c.evictionSamples == 0 && !c.Frozen() {
+		select {
+		case c.promotions <- elem:
+		default:
+		}
+	}
+	return c.read(view.value), true
+}
+
+/*
+applyPromotions moves the entries hit on the lock-free path to the
+front of the LRU list, in hit order. Entries removed in the meantime
+are skipped: container/list ignores elements no longer in the list.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) applyPromotions() {
+	for {
+		select {
+		case elem := <-c.promotions:
+			c.lru.MoveToFront(elem)
+		default:
+			return
+		}
+	}
+}
+
+// subscribersChanged keeps the lock-free subscriber count in sync
+// with c.subs. The caller must hold the exclusive lock.
+func (c *Cache) subscribersChanged() {
+	c.subscribers.Store(int32(len(c.subs)))
+}
@@ -0,0 +1,148 @@
+package tempuscache
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFreeHitsPromote(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+	defer cache.Stop()
+	if !cache.fastReads {
+		t.Fatal("expected a default cache to use lock-free hits")
+	}
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Get("a") // lock-free: promotion is buffered
+	cache.Set("c", 3, 0)
+
+	if _, found := cache.Get("b"); found {
+		t.Fatal("expected 'b' to be evicted: the hit on 'a' must count for LRU")
+	}
+	if _, found := cache.Get("a"); !found {
+		t.Fatal("expected 'a' to survive eviction")
+	}
+	if got := cache.Keys(); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("expected MRU order [a c], got %v", got)
+	}
+	if info, _ := cache.Inspect("a"); info.Hits != 2 || info.LastAccess.IsZero() {
+		t.Fatalf("expected lock-free hits in the entry metadata, got %+v", info)
+	}
+}
+
+func TestLockFreeHitsSeeWrites(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	cache.Set("k", 1, 0)
+	cache.Get("k")
+	cache.Set("k", 2, 0)
+	if v, _ := cache.Get("k"); v != 2 {
+		t.Fatalf("expected the overwritten value, got %v", v)
+	}
+
+	cache.Expire("k", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, found := cache.Get("k"); found {
+		t.Fatal("expected an expired entry to miss")
+	}
+	if s := cache.Stats(); s.Hits != 2 || s.Misses != 1 || s.ExpiredOnAccess != 1 {
+		t.Fatalf("expected the expired read to take the locked path, got %+v", s)
+	}
+
+	cache.Set("k", 3, 0)
+	cache.Flush()
+	if _, found := cache.Get("k"); found {
+		t.Fatal("expected Flush to clear the lock-free index")
+	}
+}
+
+func TestLockFreeHitsBypassedForSubscribers(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+	cache.Set("k", 1, 0)
+
+	events, cancel := cache.Subscribe()
+	defer cancel()
+
+	cache.Get("k")
+	select {
+	case ev := <-events:
+		if ev.Type != EventHit {
+			t.Fatalf("expected a hit event, got %v", ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected hits to be reported to subscribers")
+	}
+}
+
+func TestLockFreeHitsConcurrent(t *testing.T) {
+	cache := New(WithMaxEntries(50))
+	defer cache.Stop()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := strconv.Itoa((g*7 + i) % 100)
+				switch i % 4 {
+				case 0:
+					cache.Set(key, i, 0)
+				case 1:
+					cache.Delete(key)
+				default:
+					if v, found := cache.Get(key); found {
+						if _, ok := v.(int); !ok {
+							t.Errorf("unexpected value %v", v)
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := cache.Len(); n > 50 {
+		t.Fatalf("expected capacity to hold, got %d entries", n)
+	}
+	n := 0
+	cache.index.Range(func(any, any) bool { n++; return true })
+	if n != cache.Len() {
+		t.Fatalf("expected the index to mirror the cache, got %d vs %d", n, cache.Len())
+	}
+}
+
+func TestGetHitAllocs(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"lock-free", nil},
+		{"top-k", []Option{WithTopK(10)}},
+		{"trace", []Option{WithTrace(io.Discard)}},
+		{"store", []Option{WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			return "value", time.Hour, nil
+		})), WithStaleWhileRevalidate(time.Minute), WithRefreshAhead(0.1), WithEarlyExpiration(1)}},
+	}
+
+	for _, tc := range cases {
+		cache := New(tc.opts...)
+		cache.Set("key", "value", time.Hour)
+
+		if n := testing.AllocsPerRun(100, func() { cache.Get("key") }); n != 0 {
+			t.Errorf("%s: Get hit: %v allocs, want 0", tc.name, n)
+		}
+		if n := testing.AllocsPerRun(100, func() { cache.GetContext(ctx, "key") }); n != 0 {
+			t.Errorf("%s: GetContext hit: %v allocs, want 0", tc.name, n)
+		}
+		cache.Stop()
+	}
+}
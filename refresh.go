@@ -0,0 +1,42 @@
+package tempuscache
+
+/*
+refresh.go implements refresh-ahead.
+
+================================================================================
+WHY?
+================================================================================
+
+Read-through (and even stale-while-revalidate) only reloads a key
+once it has expired. For hot keys, refresh-ahead reloads them just
+before: a Get that finds an entry in the last part of its lifetime
+returns it as usual and starts a background Store.Load. The entry is
+replaced before it expires, so a key that keeps being read never
+misses.
+
+================================================================================
+THRESHOLD
+================================================================================
+
+With WithRefreshAhead(0.2), an entry written with a 10 minute TTL is
+refreshed by the first Get in its last 2 minutes. Keys that are not
+read in that period simply expire, so cold keys cost nothing.
+
+Refreshes share the deduplicated loads of store.go: at most one is
+in flight per key, however many Gets trigger it. Entries without a
+TTL are never refreshed.
+*/
+
+/*
+dueForRefresh reports whether less than the refresh-ahead fraction
+of item's lifetime remains.
+*/
+
+func (c *Cache) dueForRefresh(item *Item) bool {
+	if item.expiration == 0 {
+		return false
+	}
+	lifetime := item.expiration - item.written
+	remaining := item.expiration - c.now()
+	return float64(remaining) < c.refreshAhead*float64(lifetime)
+}
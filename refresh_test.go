@@ -0,0 +1,87 @@
+package tempuscache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			return loads.Add(1), 100 * time.Millisecond, nil
+		})),
+		WithRefreshAhead(0.5),
+	)
+	defer cache.Stop()
+
+	if v, _ := cache.Get("k"); v != int32(1) {
+		t.Fatalf("expected the initial load, got %v", v)
+	}
+	cache.Get("k")
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected no refresh early in the lifetime, got %d loads", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if v, _ := cache.Get("k"); v != int32(1) {
+		t.Fatalf("expected the current value while refreshing, got %v", v)
+	}
+	waitFor(t, func() bool { v, _ := cache.Get("k"); return v == int32(2) })
+	if n := loads.Load(); n != 2 {
+		t.Fatalf("expected exactly one refresh, got %d loads", n)
+	}
+	if ttl, _ := cache.TTL("k"); ttl <= 50*time.Millisecond {
+		t.Fatalf("expected the refresh to reset the TTL, got %v", ttl)
+	}
+}
+
+func TestRefreshAheadSkipsPermanentEntries(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			loads.Add(1)
+			return "v", 0, nil
+		})),
+		WithRefreshAhead(1),
+	)
+	defer cache.Stop()
+
+	cache.Set("k", "v", 0)
+	cache.Get("k")
+	time.Sleep(10 * time.Millisecond)
+	if n := loads.Load(); n != 0 {
+		t.Fatalf("expected entries without a TTL never to be refreshed, got %d loads", n)
+	}
+}
+
+func TestRefreshAheadWithoutTTL(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			loads.Add(1)
+			return "new", 0, nil
+		})),
+		WithRefreshAhead(0.5),
+	)
+	defer cache.Stop()
+
+	cache.Set("k", "old", 40*time.Millisecond)
+	time.Sleep(25 * time.Millisecond)
+	cache.Get("k")
+	waitFor(t, func() bool { v, _ := cache.Get("k"); return v == "new" })
+
+	// A refresh with TTL 0 makes the entry permanent: no deadline, no more refreshes.
+	if ttl, found := cache.TTL("k"); !found || ttl != 0 {
+		t.Fatalf("expected no expiration after the refresh, got %v (%v)", ttl, found)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, found := cache.Get("k"); !found || v != "new" {
+		t.Fatalf("expected the refreshed value past the old deadline, got %v (%v)", v, found)
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected a single refresh, got %d loads", n)
+	}
+}
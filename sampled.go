@@ -0,0 +1,75 @@
+package tempuscache
+
+import "container/list"
+
+/*
+sampled.go implements sampled (approximate LRU) eviction, enabled by
+WithSampledEviction.
+
+================================================================================
+WHY?
+================================================================================
+
+Strict LRU moves an entry to the front of the list on every hit.
+The lock-free read path defers those moves (see readpath.go), but
+they still have to be applied under the exclusive lock, which caps
+throughput on read-heavy workloads.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+With WithSampledEviction(k), hits no longer touch the LRU list: they
+only record the entry's access time, as they always have. When an
+entry must be evicted, k entries are sampled and the least recently
+used of them (last read or written) is evicted, as Redis does.
+
+- Larger k approximates strict LRU more closely, at O(k) per
+  eviction; 5 to 10 is usually enough.
+- Pinned entries are never sampled as victims. Priorities apply
+  within the sample: the victim is the least recently used entry
+  of the lowest priority sampled, so a lower-priority entry outside
+  the sample may outlive it (see priority.go).
+- The list now orders entries by write, not use: Keys, Inspect
+  positions, and snapshots report write order.
+
+================================================================================
+SAMPLING
+================================================================================
+
+Samples are the first k unpinned entries of an iteration over the
+primary map, which Go starts at a random position. Entries in the
+same bucket tend to be sampled together, so the sample is not
+uniform, but it is cheap and unbiased with respect to age.
+*/
+
+/*
+sampleVictim returns the element to evict under sampled eviction, or
+nil if every entry is pinned.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) sampleVictim() *list.Element {
+	var victim *list.Element
+	var oldest int64
+	var priority Priority
+
+	sampled := 0
+	for _, elem := range c.data {
+		item := elem.Value.(*Item)
+		if item.pinned {
+			continue
+		}
+
+		used := max(item.accessed.Load(), item.written)
+		if victim == nil || item.priority < priority || item.priority == priority && used < oldest {
+			victim, oldest, priority = elem, used, item.priority
+		}
+		if sampled++; sampled == c.evictionSamples {
+			break
+		}
+	}
+	return victim
+}
@@ -0,0 +1,52 @@
+package tempuscache
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSampledEviction(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	// Sampling every entry makes the victim deterministic.
+	cache := New(WithClock(clock), WithMaxEntries(3), WithSampledEviction(3))
+	defer cache.Stop()
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, key, 0)
+		clock.advance(time.Second)
+	}
+	cache.Get("a")
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"c", "b", "a"}) {
+		t.Fatalf("expected hits not to reorder the list, got %v", keys)
+	}
+
+	clock.advance(time.Second)
+	cache.Set("d", "d", 0)
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+}
+
+func TestSampledEvictionHonorsPins(t *testing.T) {
+	cache := New(WithMaxEntries(2), WithSampledEviction(1))
+	defer cache.Stop()
+
+	cache.SetPinned("a", 1, 0)
+	cache.Set("b", 2, 0)
+	for i := 0; i < 10; i++ {
+		cache.Set("c", i, 0)
+		cache.Set("b", i, 0)
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected the pinned entry never to be sampled as a victim")
+	}
+	if s := cache.Stats(); s.Entries != 2 {
+		t.Fatalf("expected 2 entries, got %d", s.Entries)
+	}
+}
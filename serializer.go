@@ -0,0 +1,83 @@
+package tempuscache
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+/*
+serializer.go implements storing values in encoded form.
+
+================================================================================
+WHY?
+================================================================================
+
+By default the cache stores the values it is given: Get returns the
+very map or struct pointer that was Set, and a caller that modifies
+it changes the cached copy under every other reader, without any
+lock. With WithSerializer, values are encoded on Set and decoded on
+every read, so each caller works on its own copy.
+
+An encoded form is also what compression works on (see compression.go)
+and what off-heap storage needs (see the tempusslab package).
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Every non-nil value is marshaled when written, by any write path,
+  and unmarshaled on every read.
+- A value that fails to marshal is logged and stored as-is.
+- Numeric values remain usable by IncrementBy and IncrementFloatBy
+  as long as the Serializer reproduces int64 and float64.
+- Snapshots and the append-only log store decoded values, encoded
+  with the snapshot Codec as usual.
+- EstimatedBytes counts the encoded size.
+
+Encoding costs CPU and an allocation on every read; it buys isolation
+and a compact, pointer-free representation.
+*/
+
+/*
+Serializer encodes values for WithSerializer.
+
+Unmarshal must return a value equivalent to the one given to
+Marshal, including its dynamic type, since Get returns it as-is.
+Implementations must be safe for concurrent use.
+*/
+
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+/*
+GobSerializer returns a Serializer based on encoding/gob.
+
+It preserves dynamic types, so concrete types stored in the cache
+must be registered with gob.Register, as for snapshots. Each value
+carries its own type description, which makes small values
+comparatively large.
+*/
+
+func GobSerializer() Serializer {
+	return gobSerializer{}
+}
+
+type gobSerializer struct{}
+
+func (gobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobSerializer) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
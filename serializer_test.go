@@ -0,0 +1,81 @@
+package tempuscache
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"strings"
+	"testing"
+)
+
+type serializedProfile struct {
+	Name string
+	Tags []string
+}
+
+func init() {
+	gob.Register(serializedProfile{})
+	gob.Register(map[string]int{})
+}
+
+func TestSerializerIsolatesCallers(t *testing.T) {
+	cache := New(WithSerializer(GobSerializer()))
+	defer cache.Stop()
+
+	m := map[string]int{"a": 1}
+	cache.Set("m", m, 0)
+	m["a"] = 2 // the caller keeps mutating its own map
+
+	v, _ := cache.Get("m")
+	got := v.(map[string]int)
+	if got["a"] != 1 {
+		t.Fatal("expected the cached copy to be unaffected by the caller")
+	}
+	got["a"] = 3
+	if v, _ := cache.Get("m"); v.(map[string]int)["a"] != 1 {
+		t.Fatal("expected each reader to get its own copy")
+	}
+
+	p := serializedProfile{Name: "tempus", Tags: []string{"x"}}
+	cache.Set("p", p, 0)
+	if v, _ := cache.Get("p"); v.(serializedProfile).Name != "tempus" {
+		t.Fatalf("expected the struct to round-trip, got %#v", v)
+	}
+}
+
+func TestSerializerKeepsNumericOps(t *testing.T) {
+	cache := New(WithSerializer(GobSerializer()))
+	defer cache.Stop()
+
+	cache.IncrementBy("n", 2, 0)
+	if n, err := cache.IncrementBy("n", 3, 0); err != nil || n != 5 {
+		t.Fatalf("expected 5, got %d, %v", n, err)
+	}
+	if v, _ := cache.Get("n"); v != int64(5) {
+		t.Fatalf("expected int64(5), got %#v", v)
+	}
+}
+
+func TestSerializerWithCompression(t *testing.T) {
+	cache := New(WithSerializer(GobSerializer()), WithCompression(GzipCompressor(gzip.BestSpeed)))
+	defer cache.Stop()
+
+	p := serializedProfile{Name: strings.Repeat("n", 1000)}
+	cache.Set("p", p, 0)
+	if v, _ := cache.Get("p"); v.(serializedProfile).Name != p.Name {
+		t.Fatal("expected the struct to round-trip through both stages")
+	}
+	if s := cache.Stats(); s.CompressedBytes == 0 || s.UncompressedBytes <= s.CompressedBytes {
+		t.Fatalf("expected the encoded struct to be compressed, got %+v", s)
+	}
+}
+
+func TestSerializerFailureStoresAsIs(t *testing.T) {
+	cache := New(WithSerializer(GobSerializer()))
+	defer cache.Stop()
+
+	fn := func() {}
+	cache.Set("fn", fn, 0) // gob cannot encode functions
+	if v, found := cache.Get("fn"); !found || v == nil {
+		t.Fatal("expected an unencodable value to be stored as-is")
+	}
+}
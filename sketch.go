@@ -0,0 +1,117 @@
+package tempuscache
+
+/*
+sketch.go implements a count-min sketch: a fixed-size, approximate
+frequency counter for an unbounded set of keys.
+
+================================================================================
+DATA STRUCTURE
+================================================================================
+
+depth rows of width counters each. A key is hashed once; depth
+indexes are derived from that hash (double hashing):
+
+    index_i = (h1 + i*h2) mod width
+
+add()      increments the key's counter in every row.
+estimate() returns the MINIMUM of the key's counters.
+
+Collisions can only inflate counters, never deflate them, so the
+estimate is an upper bound whose error shrinks as width grows.
+
+================================================================================
+MEMORY
+================================================================================
+
+depth * width * 4 bytes, independent of the number of distinct keys.
+
+================================================================================
+AGING
+================================================================================
+
+halve() divides every counter by two. Called periodically, it turns
+the lifetime counts into decaying ones that track recent frequency.
+*/
+
+const sketchDepth = 4
+
+type countMinSketch struct {
+	width  uint64
+	counts [sketchDepth][]uint32
+}
+
+func newCountMinSketch(width int) *countMinSketch {
+	if width < 64 {
+		width = 64
+	}
+	s := &countMinSketch{width: uint64(width)}
+	for i := range s.counts {
+		s.counts[i] = make([]uint32, width)
+	}
+	return s
+}
+
+/*
+add increments the counters for hash h and returns the new estimate.
+*/
+
+func (s *countMinSketch) add(h uint64) uint64 {
+	h1, h2 := h, (h>>32)|1
+	min := uint32(^uint32(0))
+	for i := range s.counts {
+		idx := (h1 + uint64(i)*h2) % s.width
+		if s.counts[i][idx] < ^uint32(0) {
+			s.counts[i][idx]++
+		}
+		if s.counts[i][idx] < min {
+			min = s.counts[i][idx]
+		}
+	}
+	return uint64(min)
+}
+
+/*
+estimate returns the approximate count for hash h.
+*/
+
+func (s *countMinSketch) estimate(h uint64) uint64 {
+	h1, h2 := h, (h>>32)|1
+	min := uint32(^uint32(0))
+	for i := range s.counts {
+		idx := (h1 + uint64(i)*h2) % s.width
+		if s.counts[i][idx] < min {
+			min = s.counts[i][idx]
+		}
+	}
+	return uint64(min)
+}
+
+/*
+halve divides every counter by two, so old counts fade in favour of
+recent ones (see frequency.go).
+*/
+
+func (s *countMinSketch) halve() {
+	for i := range s.counts {
+		for j := range s.counts[i] {
+			s.counts[i][j] >>= 1
+		}
+	}
+}
+
+/*
+hashKey computes a 64-bit FNV-1a hash of key without allocating.
+*/
+
+func hashKey(key string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	return h
+}
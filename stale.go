@@ -0,0 +1,121 @@
+package tempuscache
+
+import (
+	"context"
+)
+
+/*
+stale.go implements stale-while-revalidate serving.
+
+================================================================================
+WHY?
+================================================================================
+
+With plain read-through, the first Get after an entry expires waits
+for Store.Load. For a hot key that is a latency spike every TTL.
+With a stale window, that Get returns the expired value immediately
+and the reload happens in the background; callers only ever wait
+for keys that are missing or too stale.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+For an entry that expired less than the stale window ago:
+
+  - Get and GetContext return the expired value as a hit, and count
+    it in Stats.StaleHits.
+  - A background Store.Load replaces it. Concurrent stale reads of
+    the key share that load, and so do misses (see store.go).
+  - If the load fails, the stale value keeps being served (and
+    reloaded) until the window ends. If the Store reports
+    ErrNotFound, the entry is removed.
+
+The janitor keeps entries until their stale window ends. Every other
+read path (GetMany, TTL, the atomic operations, ...) treats them as
+expired, as usual.
+
+Stale serving needs a Store; without WithStore the window is
+ignored.
+*/
+
+/*
+lookup is get() for the read-through paths (Get, GetContext).
+
+On top of get(), it serves entries within the stale window, and
+reports whether a background refresh of key should be started
+(stale entries, and entries due for refresh-ahead; see refresh.go).
+Entries picked for early expiration are reported as misses, so the
+caller loads them (see xfetch.go).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) lookup(key string) (value interface{}, found, refresh bool) {
+	if c.staleWindow > 0 && c.store != nil {
+		if elem, ok := c.data[key]; ok {
+			item := elem.Value.(*Item)
+			if c.expired(item) && !c.pastStale(item) {
+				c.observe(key)
+				c.hit(elem)
+				c.counters.staleHits.Add(1)
+				return c.read(item.value), true, true
+			}
+		}
+	}
+
+	if c.earlyBeta > 0 && c.store != nil {
+		if elem, ok := c.data[key]; ok {
+			item := elem.Value.(*Item)
+			if !c.expired(item) && c.expiresEarly(item) {
+				c.observe(key)
+				c.counters.earlyExpirations.Add(1)
+				c.recordLookup(false)
+				c.emit(EventMiss, key)
+				return nil, false, false
+			}
+		}
+	}
+
+	value, found = c.get(key)
+	if found && c.refreshAhead > 0 && c.store != nil {
+		refresh = c.dueForRefresh(c.data[key].Value.(*Item))
+	}
+	return value, found, refresh
+}
+
+/*
+pastStale reports whether item is expired and, when stale serving
+is enabled, past its stale window too: such entries are removed by
+the janitor.
+*/
+
+func (c *Cache) pastStale(item *Item) bool {
+	if !c.expired(item) {
+		return false
+	}
+	if c.staleWindow <= 0 || c.store == nil {
+		return true
+	}
+	return c.now() > item.expiration+int64(c.staleWindow)
+}
+
+/*
+revalidate reloads key from the Store in the background. Refreshes
+share the deduplicated load of store.go, so at most one is in flight
+per key.
+*/
+
+func (c *Cache) revalidate(key string) {
+	call, leader := c.joinLoad(key)
+	if !leader {
+		return
+	}
+
+	go func() {
+		if c.runLoad(context.Background(), key, call); call.err != nil {
+			c.logger().Warn("tempuscache: background refresh failed", "key", key, "err", call.err)
+		}
+	}()
+}
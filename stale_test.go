@@ -0,0 +1,121 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int32
+	release := make(chan struct{}, 1)
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			<-release
+			return version.Add(1), 20 * time.Millisecond, nil
+		})),
+		WithStaleWhileRevalidate(time.Hour),
+	)
+	defer cache.Stop()
+
+	release <- struct{}{}
+	if v, _ := cache.Get("k"); v != int32(1) {
+		t.Fatalf("expected the initial load, got %v", v)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// Expired: served stale without waiting for the (blocked) load.
+	if v, found := cache.Get("k"); !found || v != int32(1) {
+		t.Fatalf("expected the stale value, got %v (%v)", v, found)
+	}
+	if v, _ := cache.Get("k"); v != int32(1) {
+		t.Fatalf("expected the stale value again, got %v", v)
+	}
+	if n := cache.Stats().StaleHits; n != 2 {
+		t.Fatalf("expected 2 stale hits, got %d", n)
+	}
+
+	release <- struct{}{}
+	waitFor(t, func() bool { v, _ := cache.Get("k"); return v == int32(2) })
+	if n := version.Load(); n != 2 {
+		t.Fatalf("expected one shared background refresh, got %d loads", n)
+	}
+
+	// The janitor keeps stale entries until the window ends.
+	time.Sleep(30 * time.Millisecond)
+	if n := cache.deleteExpired(); n != 0 {
+		t.Fatalf("expected the janitor to keep the stale entry, removed %d", n)
+	}
+}
+
+func TestStaleWindowEnds(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			loads.Add(1)
+			return nil, 0, errors.New("down")
+		})),
+		WithStaleWhileRevalidate(20*time.Millisecond),
+	)
+	defer cache.Stop()
+
+	cache.Set("k", "v", 10*time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	if v, _ := cache.Get("k"); v != "v" {
+		t.Fatalf("expected stale value while the store is down, got %v", v)
+	}
+	waitFor(t, func() bool { return loads.Load() == 1 })
+
+	time.Sleep(20 * time.Millisecond)
+	if _, found := cache.Get("k"); found {
+		t.Fatal("expected a miss once the stale window has ended")
+	}
+	if n := cache.deleteExpired(); n != 0 {
+		t.Fatalf("expected the entry to be gone already, janitor removed %d", n)
+	}
+}
+
+func TestStaleRemovedWhenNotFound(t *testing.T) {
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			return nil, 0, ErrNotFound
+		})),
+		WithStaleWhileRevalidate(time.Hour),
+	)
+	defer cache.Stop()
+
+	cache.Set("k", "v", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.Get("k")
+	waitFor(t, func() bool { return cache.Len() == 0 })
+}
+
+func TestStaleReloadWithoutTTL(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			loads.Add(1)
+			return "new", 0, nil
+		})),
+		WithStaleWhileRevalidate(time.Hour),
+	)
+	defer cache.Stop()
+
+	cache.Set("k", "old", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.Get("k")
+	waitFor(t, func() bool { v, _ := cache.Get("k"); return v == "new" })
+
+	// A reload with TTL 0 never expires, rather than keeping the stale deadline.
+	for i := 0; i < 5; i++ {
+		cache.Get("k")
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected a single reload, got %d", n)
+	}
+	if ttl, found := cache.TTL("k"); !found || ttl != 0 {
+		t.Fatalf("expected no expiration, got %v (%v)", ttl, found)
+	}
+}
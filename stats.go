@@ -1,5 +1,10 @@
 package tempuscache
 
+import (
+	"sync/atomic"
+	"time"
+)
+
 /*
 Stats represents runtime performance metrics of the cache.
 
@@ -9,9 +14,62 @@ PURPOSE
 
 This structure tracks key operational indicators:
 
-- Hits      → Successful retrievals (valid key found)
-- Misses    → Failed lookups (missing or expired key)
-- Evictions → Entries removed due to LRU capacity constraints
+Counters (cumulative since construction):
+
+- Hits        → Successful retrievals (valid key found)
+- StaleHits   → Hits served from an expired entry within its stale
+                window, also counted in Hits
+                (see WithStaleWhileRevalidate)
+- Misses      → Failed lookups (missing or expired key)
+- EarlyExpirations → Live entries treated as misses ahead of their
+                     expiry, also counted in Misses
+                     (see WithEarlyExpiration)
+- Sets        → Entries created or overwritten by any write path
+
+Removals, broken down by cause:
+
+- Deletes          → Live entries removed explicitly (Delete, DeleteMany)
+- Evictions        → Entries removed due to LRU capacity constraints
+- Expirations      → Entries removed because their TTL elapsed, i.e.
+                     ExpiredByJanitor + ExpiredOnAccess
+- ExpiredByJanitor → Expired entries removed by the background janitor
+- ExpiredOnAccess  → Expired entries removed lazily when touched by
+                     a read or write
+
+- DroppedEvents → Events discarded because a subscriber's buffer
+                  was full (see Subscribe)
+- Invalidations → Entries removed because another node invalidated
+                  them (see WithInvalidation)
+- Loads         → Store.Load calls made on misses (see WithStore);
+                  concurrent misses for one key count once
+- LoadErrors    → Loads that failed with an error other than
+                  ErrNotFound
+- WriteBehindDropped → Write-behind values dropped after their save
+                       failed every retry (see WithWriteBehind)
+- Busy               → TryGet and TrySet calls rejected because the
+                       cache was busy
+- Rejected           → Writes refused because of their key
+                       (see WithKeyValidator) or not cached because
+                       of their size (see WithMaxValueBytes)
+- NotAdmitted        → Writes of new keys not cached because the
+                       doorkeeper had not seen them before
+                       (see WithDoorkeeper)
+- PrematureEvictions → Misses of keys evicted shortly before
+                       (see WithGhostHistory)
+
+Gauges (computed when Stats() is called):
+
+- Entries        → Number of entries currently stored
+- Pinned         → Entries exempt from eviction, included in Entries
+                   (see Pin)
+- Cost           → Total cost of the entries (see SetWithCost)
+- EstimatedBytes → Approximate memory held by keys and values
+- Uptime         → Time since the cache was constructed
+- HotKeys        → Most frequently looked-up keys (only with WithTopK)
+- WriteBehindPending → Keys queued for saving (only with WithWriteBehind)
+- CompressedBytes    → Stored size of the values kept compressed
+                       (only with WithCompression)
+- UncompressedBytes  → Original size of those same values
 
 These metrics provide visibility into cache effectiveness
 and operational behavior.
@@ -32,30 +90,197 @@ For example:
 
     hit_ratio = Hits / (Hits + Misses)
 
+The removal breakdown turns capacity tuning into arithmetic:
+
+- High Evictions        → capacity is too small for the working set,
+                          if PrematureEvictions is a large share of
+                          them (see WithGhostHistory).
+- High ExpiredOnAccess  → TTLs are shorter than the re-read interval.
+- High ExpiredByJanitor → entries outlive their usefulness unread.
+
 ================================================================================
 CONCURRENCY MODEL
 ================================================================================
 
-Stats fields are modified under Cache-level locking.
-The Stats() method returns a snapshot under read lock,
-ensuring consistent reads without race conditions.
+Stats is a plain value: a snapshot returned by Stats() and
+ResetStats(). The live counters are kept in a counters struct of
+atomic integers (see below), so counting never needs the cache lock
+and Stats() only takes the read lock briefly for the gauges.
+*/
+
+type Stats struct {
+	Hits        uint64
+	StaleHits   uint64
+	Misses      uint64
+	Sets        uint64
+	Deletes     uint64
+	Evictions   uint64
+	Expirations uint64
+
+	EarlyExpirations uint64
+
+	ExpiredByJanitor uint64
+	ExpiredOnAccess  uint64
+
+	DroppedEvents uint64
+	Invalidations uint64
+	Loads         uint64
+	LoadErrors    uint64
+
+	WriteBehindDropped uint64
+	Busy               uint64
+	Rejected           uint64
+	NotAdmitted        uint64
+	PrematureEvictions uint64
+
+	Entries        int
+	Pinned         int
+	Cost           int64
+	EstimatedBytes int64
+	Uptime         time.Duration
+	HotKeys        []KeyCount
+
+	WriteBehindPending int
+
+	CompressedBytes   int64
+	UncompressedBytes int64
+}
+
+/*
+counters holds the live values of the Stats counters.
 
 ================================================================================
-DESIGN SIMPLICITY
+WHY ATOMIC?
 ================================================================================
 
-The struct is intentionally minimal:
+Counters used to be plain fields updated under the cache lock,
+which made every Stats() call contend with Get and Set for the
+mutex. As atomics they can be read, and updated, from any goroutine
+without it.
 
-- No internal locking
-- No atomic counters
-- Synchronization handled at Cache level
+Hits and misses are bumped by every lookup, from every core. They
+sit on cache lines of their own so that increments of one do not
+invalidate the other (false sharing).
+*/
+
+type counters struct {
+	hits   atomic.Uint64
+	_      [56]byte
+	misses atomic.Uint64
+	_      [56]byte
 
-This keeps the data structure lightweight
-and avoids unnecessary complexity.
+	staleHits          atomic.Uint64
+	sets               atomic.Uint64
+	deletes            atomic.Uint64
+	evictions          atomic.Uint64
+	expirations        atomic.Uint64
+	earlyExpirations   atomic.Uint64
+	expiredByJanitor   atomic.Uint64
+	expiredOnAccess    atomic.Uint64
+	droppedEvents      atomic.Uint64
+	invalidations      atomic.Uint64
+	loads              atomic.Uint64
+	loadErrors         atomic.Uint64
+	writeBehindDropped atomic.Uint64
+	busy               atomic.Uint64
+	rejected           atomic.Uint64
+	notAdmitted        atomic.Uint64
+	prematureEvictions atomic.Uint64
+}
+
+/*
+snapshot returns the current counter values. With reset set, every
+counter is zeroed as it is read, so each event is counted in exactly
+one snapshot. Counters are read one by one: a snapshot taken under
+load may include an event in one counter and not yet in a related
+one (e.g. an eviction but not the set that caused it).
 */
 
-type Stats struct {
-	Hits      uint64
-	Misses    uint64
-	Evictions uint64
+func (k *counters) snapshot(reset bool) Stats {
+	load := func(v *atomic.Uint64) uint64 {
+		if reset {
+			return v.Swap(0)
+		}
+		return v.Load()
+	}
+	return Stats{
+		Hits:               load(&k.hits),
+		StaleHits:          load(&k.staleHits),
+		Misses:             load(&k.misses),
+		Sets:               load(&k.sets),
+		Deletes:            load(&k.deletes),
+		Evictions:          load(&k.evictions),
+		Expirations:        load(&k.expirations),
+		EarlyExpirations:   load(&k.earlyExpirations),
+		ExpiredByJanitor:   load(&k.expiredByJanitor),
+		ExpiredOnAccess:    load(&k.expiredOnAccess),
+		DroppedEvents:      load(&k.droppedEvents),
+		Invalidations:      load(&k.invalidations),
+		Loads:              load(&k.loads),
+		LoadErrors:         load(&k.loadErrors),
+		WriteBehindDropped: load(&k.writeBehindDropped),
+		Busy:               load(&k.busy),
+		Rejected:           load(&k.rejected),
+		NotAdmitted:        load(&k.notAdmitted),
+		PrematureEvictions: load(&k.prematureEvictions),
+	}
+}
+
+/*
+Sizer can be implemented by cached values that know their own
+memory footprint. estimateSize uses it in preference to its
+built-in heuristics.
+*/
+
+type Sizer interface {
+	Size() int
+}
+
+/*
+entryOverhead approximates the fixed cost of one entry:
+the Item struct, its list.Element, and the map bucket slot.
+*/
+
+const entryOverhead = 112
+
+/*
+estimateSize approximates the memory held by one entry.
+
+================================================================================
+HEURISTICS
+================================================================================
+
+- Sizer values report their own size.
+- Strings and []byte count their length.
+- Fixed-size scalars count their width.
+- Any other value counts as one pointer-sized word; the memory
+  it references is not traversed.
+
+The result is an estimate for dashboards and capacity planning,
+not an exact accounting of heap usage.
+*/
+
+func estimateSize(key string, value interface{}) int64 {
+	return int64(entryOverhead+len(key)) + valueSize(value)
+}
+
+// valueSize is the share of estimateSize taken by value.
+func valueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case Sizer:
+		return int64(v.Size())
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	}
+	return 8
 }
@@ -0,0 +1,278 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+store.go implements read-through loading from a backing Store.
+
+================================================================================
+WHY?
+================================================================================
+
+Without it, every caller repeats the cache-aside dance:
+
+    v, ok := cache.Get(key)
+    if !ok {
+        v, err = db.Load(ctx, key)
+        ...
+        cache.Set(key, v, ttl)
+    }
+
+With WithStore, a miss fetches the value from the Store, caches it,
+and returns it, so callers only ever call Get (or GetContext).
+
+================================================================================
+DEDUPLICATION
+================================================================================
+
+Concurrent misses for the same key share a single Store.Load call:
+the first caller loads, the others wait for its result. A burst of
+requests for a cold hot key therefore costs the backing source one
+query, not one per request.
+
+The shared load runs with the first caller's context values but not
+its cancellation, since other callers depend on it. Any caller whose
+own context ends, the first one included, stops waiting and returns
+its context error; the load itself carries on for the others. A
+Store that panics fails the load with an error for every waiter.
+
+================================================================================
+CONSISTENCY
+================================================================================
+
+If the key is written while a load is in flight, the write wins: the
+loaded value is returned to the waiting callers but not cached over
+the newer entry.
+
+Loaded values are not published as invalidations (see
+WithInvalidation): they come from the source of truth, so peers
+holding the same key are not stale.
+*/
+
+// ErrNotFound is returned by a Store that has no value for a key.
+// The lookup is then reported as a miss rather than an error.
+var ErrNotFound = errors.New("tempuscache: not found")
+
+/*
+Store is the backing source of a read-through cache.
+
+Load returns the value of key and the TTL to cache it with (0 or
+less: never expires), ErrNotFound if the source has no such key, or
+any other error if the source could not be queried.
+*/
+
+type Store interface {
+	Load(ctx context.Context, key string) (value interface{}, ttl time.Duration, err error)
+}
+
+/*
+loadCall is an in-flight Store.Load shared by concurrent misses.
+superseded (guarded by the cache lock) is set when the key is
+deleted, invalidated, or flushed during the load: the loaded value
+may predate the removal, so it is returned but not cached.
+*/
+
+type loadCall struct {
+	done       chan struct{}
+	value      interface{}
+	found      bool
+	err        error
+	superseded bool
+}
+
+/*
+GetContext returns key, loading it from the Store configured with
+WithStore on a miss.
+
+RETURNS:
+- (value, true, nil)  -> Cache hit, or successfully loaded
+- (nil, false, nil)   -> Miss, and the Store reported ErrNotFound
+                         (or no Store is configured)
+- (nil, false, err)   -> The Store failed, or ctx ended while waiting
+
+Unlike Get, load failures are returned instead of logged.
+*/
+
+func (c *Cache) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	if value, found := c.fastGet(key); found {
+		return value, true, nil
+	}
+
+	c.mu.Lock()
+	value, found, refresh := c.lookup(key)
+	c.mu.Unlock()
+
+	if refresh {
+		c.revalidate(key)
+	}
+	if found || c.store == nil {
+		return value, found, nil
+	}
+	return c.load(ctx, key)
+}
+
+/*
+load fetches key from the Store, sharing the call with concurrent
+misses for the same key. With WithCopyOnRead, each caller receives
+its own copy of the shared result.
+
+The shared load runs on the leader's ctx without its cancellation,
+so a leader that gives up does not fail the other waiters; every
+caller, the leader included, stops waiting when its own ctx ends.
+*/
+
+func (c *Cache) load(ctx context.Context, key string) (interface{}, bool, error) {
+	call, leader := c.joinLoad(key)
+	if leader {
+		go c.runLoad(context.WithoutCancel(ctx), key, call)
+	}
+
+	select {
+	case <-call.done:
+		return c.read(call.value), call.found, call.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+/*
+joinLoad returns the in-flight load of key, or registers a new one.
+leader reports whether the caller registered it, and so must run it
+with runLoad.
+*/
+
+func (c *Cache) joinLoad(key string) (call *loadCall, leader bool) {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	if call, inflight := c.loads[key]; inflight {
+		return call, false
+	}
+	call = &loadCall{done: make(chan struct{})}
+	if c.loads == nil {
+		c.loads = make(map[string]*loadCall)
+	}
+	c.loads[key] = call
+	return call, true
+}
+
+/*
+runLoad performs a load registered by joinLoad and releases its
+waiters. A panicking Store is reported as the load's error rather
+than leaving the waiters blocked forever.
+*/
+
+func (c *Cache) runLoad(ctx context.Context, key string, call *loadCall) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.counters.loadErrors.Add(1)
+			call.value, call.found = nil, false
+			call.err = fmt.Errorf("tempuscache: store panicked loading %q: %v", key, r)
+		}
+
+		c.loadMu.Lock()
+		delete(c.loads, key)
+		c.loadMu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.found, call.err = c.fetch(ctx, key, call)
+}
+
+// fetch calls Store.Load for call and caches the result.
+func (c *Cache) fetch(ctx context.Context, key string, call *loadCall) (interface{}, bool, error) {
+	c.mu.RLock()
+	var before int64
+	if elem, found := c.data[key]; found {
+		before = elem.Value.(*Item).written
+	}
+	c.mu.RUnlock()
+
+	start := time.Now()
+	value, ttl, err := c.store.Load(ctx, key)
+	delta := time.Since(start)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters.loads.Add(1)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// The source dropped the key: stop serving a stale copy.
+		if elem, found := c.data[key]; found && c.expired(elem.Value.(*Item)) {
+			c.expireElement(elem, false)
+		}
+		return nil, false, nil
+	case err != nil:
+		c.counters.loadErrors.Add(1)
+		return nil, false, err
+	}
+
+	// A removal during the load must not be undone by its result.
+	if call.superseded {
+		return value, true, nil
+	}
+	// A write that raced with the load is newer than the loaded value.
+	if elem, found := c.data[key]; found {
+		item := elem.Value.(*Item)
+		if item.written != before && !c.expired(item) {
+			return c.read(item.value), true, nil
+		}
+	}
+
+	// Unlike Set, ttl <= 0 means no expiry even when replacing an
+	// expired copy: keeping its deadline would leave it expired.
+	var exp int64
+	if ttl > 0 {
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	}
+	c.write(key, value, exp, false)
+	if elem, found := c.data[key]; found {
+		elem.Value.(*Item).delta = int64(delta)
+	}
+	return value, true, nil
+}
+
+/*
+supersede marks the in-flight load and Tiered backfills of key, if
+any, as superseded (see loadCall and backfill).
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) supersede(key string) {
+	for _, b := range c.backfills[key] {
+		b.superseded = true
+	}
+	if c.store == nil {
+		return
+	}
+	c.loadMu.Lock()
+	if call, inflight := c.loads[key]; inflight {
+		call.superseded = true
+	}
+	c.loadMu.Unlock()
+}
+
+// supersedeAll is supersede for every in-flight load, on Flush.
+func (c *Cache) supersedeAll() {
+	for _, fills := range c.backfills {
+		for _, b := range fills {
+			b.superseded = true
+		}
+	}
+	if c.store == nil {
+		return
+	}
+	c.loadMu.Lock()
+	for _, call := range c.loads {
+		call.superseded = true
+	}
+	c.loadMu.Unlock()
+}
@@ -0,0 +1,201 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// funcStore adapts a function to the Store interface.
+type funcStore func(ctx context.Context, key string) (interface{}, time.Duration, error)
+
+func (f funcStore) Load(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	return f(ctx, key)
+}
+
+func TestReadThrough(t *testing.T) {
+	errDown := errors.New("database down")
+	cache := New(WithStore(funcStore(func(_ context.Context, key string) (interface{}, time.Duration, error) {
+		switch key {
+		case "missing":
+			return nil, 0, ErrNotFound
+		case "broken":
+			return nil, 0, errDown
+		}
+		return "loaded:" + key, time.Minute, nil
+	})))
+	defer cache.Stop()
+
+	if v, found := cache.Get("a"); !found || v != "loaded:a" {
+		t.Fatalf("expected loaded value, got %v (%v)", v, found)
+	}
+	if ttl, _ := cache.TTL("a"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected the store TTL, got %v", ttl)
+	}
+
+	if _, found, err := cache.GetContext(context.Background(), "missing"); found || err != nil {
+		t.Fatalf("expected plain miss, got (%v, %v)", found, err)
+	}
+	if _, found, err := cache.GetContext(context.Background(), "broken"); found || !errors.Is(err, errDown) {
+		t.Fatalf("expected store error, got (%v, %v)", found, err)
+	}
+	if _, found := cache.Get("broken"); found {
+		t.Fatal("expected Get to report a failed load as a miss")
+	}
+
+	s := cache.Stats()
+	if s.Loads != 4 || s.LoadErrors != 2 {
+		t.Fatalf("expected 4 loads and 2 errors, got %d and %d", s.Loads, s.LoadErrors)
+	}
+}
+
+func TestReadThroughDeduplicates(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := New(WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		return "v", 0, nil
+	})))
+	defer cache.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, found := cache.Get("k"); !found || v != "v" {
+				t.Errorf("expected v, got %v (%v)", v, found)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool { return calls.Load() == 1 })
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 load, got %d", n)
+	}
+}
+
+func TestReadThroughWaiterCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	cache := New(WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		<-release
+		return "v", 0, nil
+	})))
+	defer cache.Stop()
+
+	go cache.Get("k")
+	waitFor(t, func() bool { cache.loadMu.Lock(); defer cache.loadMu.Unlock(); return len(cache.loads) == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := cache.GetContext(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the waiter to give up, got %v", err)
+	}
+}
+
+func TestReadThroughLeaderCancel(t *testing.T) {
+	release := make(chan struct{})
+	cache := New(WithStore(funcStore(func(ctx context.Context, _ string) (interface{}, time.Duration, error) {
+		<-release
+		return "v", 0, ctx.Err()
+	})))
+	defer cache.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() { _, _, err := cache.GetContext(ctx, "k"); leader <- err }()
+	waitFor(t, func() bool { cache.loadMu.Lock(); defer cache.loadMu.Unlock(); return len(cache.loads) == 1 })
+
+	waiter := make(chan interface{})
+	go func() { v, _ := cache.Get("k"); waiter <- v }()
+
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the leader to give up, got %v", err)
+	}
+	close(release)
+	if v := <-waiter; v != "v" {
+		t.Fatalf("expected the waiter to get the loaded value, got %v", v)
+	}
+}
+
+func TestReadThroughStorePanic(t *testing.T) {
+	release := make(chan struct{})
+	cache := New(WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		<-release
+		panic("boom")
+	})))
+	defer cache.Stop()
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { _, _, err := cache.GetContext(context.Background(), "k"); errs <- err }()
+	}
+	waitFor(t, func() bool { cache.loadMu.Lock(); defer cache.loadMu.Unlock(); return len(cache.loads) == 1 })
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Fatalf("expected the panic as an error, got %v", err)
+		}
+	}
+	if _, _, err := cache.GetContext(context.Background(), "k"); err == nil {
+		t.Fatal("expected a new load after the failed one")
+	}
+}
+
+func TestReadThroughConcurrentWriteWins(t *testing.T) {
+	release := make(chan struct{})
+	cache := New(WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		<-release
+		return "stale", 0, nil
+	})))
+	defer cache.Stop()
+
+	done := make(chan interface{})
+	go func() { v, _ := cache.Get("k"); done <- v }()
+	waitFor(t, func() bool { cache.loadMu.Lock(); defer cache.loadMu.Unlock(); return len(cache.loads) == 1 })
+
+	cache.Set("k", "fresh", 0)
+	close(release)
+
+	if v := <-done; v != "fresh" {
+		t.Fatalf("expected the concurrent write to win, got %v", v)
+	}
+}
+
+func TestReadThroughConcurrentDeleteWins(t *testing.T) {
+	release := make(chan struct{})
+	cache := New(WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		<-release
+		return "stale", 0, nil
+	})))
+	defer cache.Stop()
+
+	done := make(chan interface{})
+	go func() { v, _ := cache.Get("k"); done <- v }()
+	waitFor(t, func() bool { cache.loadMu.Lock(); defer cache.loadMu.Unlock(); return len(cache.loads) == 1 })
+
+	cache.Delete("k")
+	close(release)
+
+	if v := <-done; v != "stale" {
+		t.Fatalf("expected the loaded value to be returned, got %v", v)
+	}
+	cache.mu.RLock()
+	_, cached := cache.data["k"]
+	cache.mu.RUnlock()
+	if cached {
+		t.Fatal("expected a load racing a Delete not to be cached")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Package tempusadmin provides an HTTP admin endpoint for TempusCache.
+
+================================================================================
+USAGE
+================================================================================
+
+	cache := tempuscache.New(tempuscache.WithMaxEntries(10000))
+
+	debugMux.Handle("/debug/cache/", http.StripPrefix("/debug/cache",
+	    tempusadmin.NewHandler(cache, tempusadmin.Opts{
+	        Token: os.Getenv("CACHE_ADMIN_TOKEN"),
+	    })))
+
+================================================================================
+ENDPOINTS
+================================================================================
+
+Read-only:
+
+	GET    /stats                    Stats() as JSON
+	GET    /keys?offset=0&limit=100  Live keys, most recently used first
+	GET    /entry?key=K              Inspect(K) as JSON (404 if absent)
+
+Mutating (require "Authorization: Bearer <Opts.Token>"):
+
+	DELETE /entry?key=K              Delete(K)
+	POST   /flush                    Flush()
+
+================================================================================
+SECURITY
+================================================================================
+
+Mutating endpoints are disabled (403) unless Opts.Token is set.
+Tokens are compared in constant time. Entry inspection reports
+metadata only; cached values are never exposed.
+
+Read-only endpoints are unauthenticated: mount the handler on a
+mux that is not reachable from untrusted networks.
+*/
+package tempusadmin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+/*
+Opts configures a Handler.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Token -> Bearer token required by mutating endpoints
+         (empty = mutating endpoints disabled)
+*/
+
+type Opts struct {
+	Token string
+}
+
+/*
+Handler serves the admin endpoints for a single Cache.
+*/
+
+type Handler struct {
+	cache *tempuscache.Cache
+	opts  Opts
+	mux   *http.ServeMux
+}
+
+/*
+KeysPage is the response body of GET /keys.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Keys       -> Keys on this page
+Total      -> Number of live keys at the time of the request
+NextOffset -> Offset of the next page (0 when this is the last page)
+*/
+
+type KeysPage struct {
+	Keys       []string `json:"keys"`
+	Total      int      `json:"total"`
+	NextOffset int      `json:"next_offset"`
+}
+
+// NewHandler creates a Handler serving cache.
+func NewHandler(cache *tempuscache.Cache, opts Opts) *Handler {
+	h := &Handler{cache: cache, opts: opts, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("GET /stats", h.stats)
+	h.mux.HandleFunc("GET /keys", h.keys)
+	h.mux.HandleFunc("GET /entry", h.entry)
+	h.mux.HandleFunc("DELETE /entry", h.authorized(h.deleteEntry))
+	h.mux.HandleFunc("POST /flush", h.authorized(h.flush))
+
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) stats(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.cache.Stats())
+}
+
+/*
+keys serves one page of Keys().
+
+Pages are computed from a fresh snapshot on every request, so keys
+touched between two requests can move across page boundaries.
+*/
+
+func (h *Handler) keys(w http.ResponseWriter, r *http.Request) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", defaultLimit)
+	if err != nil || limit <= 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, maxLimit)
+
+	all := h.cache.Keys()
+	page := KeysPage{Keys: []string{}, Total: len(all)}
+	if offset < len(all) {
+		end := min(offset+limit, len(all))
+		page.Keys = all[offset:end]
+		if end < len(all) {
+			page.NextOffset = end
+		}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) entry(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	info, found := h.cache.Inspect(key)
+	if !found {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *Handler) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	h.cache.Delete(r.URL.Query().Get("key"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) flush(w http.ResponseWriter, _ *http.Request) {
+	h.cache.Flush()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+authorized guards a mutating endpoint with the configured bearer token.
+*/
+
+func (h *Handler) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.opts.Token == "" {
+			http.Error(w, "mutating endpoints are disabled", http.StatusForbidden)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,95 @@
+package tempusadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func do(h http.Handler, method, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerReadOnly(t *testing.T) {
+	cache := tempuscache.New()
+	for _, k := range []string{"a", "b", "c"} {
+		cache.Set(k, 1, 0)
+	}
+	h := NewHandler(cache, Opts{})
+
+	rec := do(h, http.MethodGet, "/keys?limit=2", "")
+	var page KeysPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Keys) != 2 || page.Keys[0] != "c" || page.NextOffset != 2 {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	rec = do(h, http.MethodGet, "/keys?offset=2&limit=2", "")
+	page = KeysPage{}
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Keys) != 1 || page.Keys[0] != "a" || page.NextOffset != 0 {
+		t.Fatalf("unexpected last page: %+v", page)
+	}
+
+	if rec := do(h, http.MethodGet, "/keys?limit=x", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid limit, got %d", rec.Code)
+	}
+
+	rec = do(h, http.MethodGet, "/entry?key=a", "")
+	var info tempuscache.EntryInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil || info.Key != "a" {
+		t.Fatalf("unexpected entry response %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(h, http.MethodGet, "/entry?key=missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	rec = do(h, http.MethodGet, "/stats", "")
+	var stats tempuscache.Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || stats.Entries != 3 {
+		t.Fatalf("unexpected stats response %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandlerMutating(t *testing.T) {
+	cache := tempuscache.New()
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	if rec := do(NewHandler(cache, Opts{}), http.MethodPost, "/flush", "x"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without configured token, got %d", rec.Code)
+	}
+
+	h := NewHandler(cache, Opts{Token: "secret"})
+	if rec := do(h, http.MethodDelete, "/entry?key=a", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong token, got %d", rec.Code)
+	}
+	if cache.Len() != 2 {
+		t.Fatal("expected unauthorized delete to have no effect")
+	}
+
+	if rec := do(h, http.MethodDelete, "/entry?key=a", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if _, found := cache.Get("a"); found {
+		t.Fatal("expected 'a' to be deleted")
+	}
+
+	if rec := do(h, http.MethodPost, "/flush", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("expected cache to be flushed")
+	}
+}
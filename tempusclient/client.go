@@ -0,0 +1,429 @@
+/*
+Package tempusclient is a Go client for a TempusCache served over
+RESP (see package tempusresp), with the same method set as the
+embedded cache.
+
+================================================================================
+USAGE
+================================================================================
+
+	var cache tempusclient.Cache = tempuscache.New()
+
+	// ...becomes:
+
+	var cache tempusclient.Cache = tempusclient.New("cache-host:6380")
+
+Both *tempuscache.Cache and *tempusclient.Client implement Cache, so
+code written against the interface does not change. So does Ring,
+which shards keys across several servers with consistent hashing:
+
+	var cache tempusclient.Cache = tempusclient.NewRing(
+	    []string{"cache-1:6380", "cache-2:6380", "cache-3:6380"})
+
+================================================================================
+ERRORS
+================================================================================
+
+The embedded API has no error returns, so the Cache methods follow
+the usual cache-client convention instead: network failures are
+treated as misses (Get/TTL return false, Set/Delete are dropped)
+and reported to the handler set with WithErrorHandler.
+
+Callers that need errors use the *Context variants, which accept a
+context and return them explicitly.
+
+================================================================================
+VALUES
+================================================================================
+
+RESP values are byte strings. Set sends strings and []byte as-is and
+formats any other value with fmt; Get always returns a string.
+
+================================================================================
+CONNECTION POOLING
+================================================================================
+
+Connections are dialed on demand and returned to an idle pool after
+each command. At most WithPoolSize connections are kept idle; extra
+ones are closed. A connection that fails mid-command is discarded;
+one the server closed while idle is replaced transparently.
+*/
+package tempusclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Krishna8167/tempuscache/v2/internal/resp"
+)
+
+// ErrClosed is returned by commands issued after Close.
+var ErrClosed = errors.New("tempusclient: client closed")
+
+/*
+ServerError is an error reply sent by the server.
+*/
+
+type ServerError string
+
+func (e ServerError) Error() string { return "tempusclient: server error: " + string(e) }
+
+/*
+Cache is the method set shared by *tempuscache.Cache and *Client.
+*/
+
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+	TTL(key string) (time.Duration, bool)
+}
+
+/*
+Option configures a Client.
+*/
+
+type Option func(*Client)
+
+// WithPoolSize sets the maximum number of idle connections (default 8).
+func WithPoolSize(n int) Option {
+	return func(c *Client) {
+		c.poolSize = n
+	}
+}
+
+// WithDialTimeout bounds connection establishment (default 5s).
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.dialTimeout = d
+	}
+}
+
+// WithTimeout bounds each command of the Cache methods (default 1s).
+// The *Context variants use their context's deadline instead.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithErrorHandler receives errors swallowed by the Cache methods
+// (default: discarded).
+func WithErrorHandler(fn func(error)) Option {
+	return func(c *Client) {
+		c.onError = fn
+	}
+}
+
+/*
+Client talks to a tempusresp server.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+addr        -> Server address
+poolSize    -> Maximum number of idle connections
+dialTimeout -> Connection establishment bound
+timeout     -> Per-command bound for the Cache methods
+onError     -> Receives errors swallowed by the Cache methods
+mu          -> Protects idle and closed
+idle        -> Idle connections, most recently used last
+closed      -> Set by Close
+*/
+
+type Client struct {
+	addr        string
+	poolSize    int
+	dialTimeout time.Duration
+	timeout     time.Duration
+	onError     func(error)
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+type conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+}
+
+/*
+New creates a Client for the server at addr.
+
+No connection is made until the first command; use Ping to check
+reachability up front.
+*/
+
+func New(addr string, opts ...Option) *Client {
+	c := &Client{
+		addr:        addr,
+		poolSize:    8,
+		dialTimeout: 5 * time.Second,
+		timeout:     time.Second,
+		onError:     func(error) {},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+/*
+Close closes all idle connections. Commands in flight complete and
+their connections are closed when released.
+*/
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle, c.closed = nil, true
+	c.mu.Unlock()
+
+	for _, cn := range idle {
+		cn.nc.Close()
+	}
+	return nil
+}
+
+// Get fetches key; failures are reported as misses.
+func (c *Client) Get(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	val, found, err := c.GetContext(ctx, key)
+	if err != nil {
+		c.onError(err)
+		return nil, false
+	}
+	return val, found
+}
+
+// Set stores value under key; failures are reported to the error handler.
+func (c *Client) Set(key string, value interface{}, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.SetContext(ctx, key, value, ttl); err != nil {
+		c.onError(err)
+	}
+}
+
+// Delete removes key; failures are reported to the error handler.
+func (c *Client) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.DeleteContext(ctx, key); err != nil {
+		c.onError(err)
+	}
+}
+
+// TTL returns the remaining lifetime of key; failures are reported as misses.
+func (c *Client) TTL(key string) (time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	ttl, found, err := c.TTLContext(ctx, key)
+	if err != nil {
+		c.onError(err)
+		return 0, false
+	}
+	return ttl, found
+}
+
+// GetContext fetches key, returning any network or server error.
+func (c *Client) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	s, ok := reply.(string)
+	if !ok {
+		return nil, false, unexpected(reply)
+	}
+	return s, true, nil
+}
+
+// SetContext stores value under key (ttl <= 0 = never expires).
+func (c *Client) SetContext(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	args := []string{"SET", key, format(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+// DeleteContext removes key.
+func (c *Client) DeleteContext(ctx context.Context, key string) error {
+	_, err := c.do(ctx, "DEL", key)
+	return err
+}
+
+/*
+TTLContext returns the remaining lifetime of key, with the same
+conventions as the embedded cache: (0, true) for keys that never
+expire and (0, false) for missing keys.
+*/
+
+func (c *Client) TTLContext(ctx context.Context, key string) (time.Duration, bool, error) {
+	reply, err := c.do(ctx, "PTTL", key)
+	if err != nil {
+		return 0, false, err
+	}
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, false, unexpected(reply)
+	}
+	switch {
+	case ms == -2:
+		return 0, false, nil
+	case ms < 0:
+		return 0, true, nil
+	}
+	return time.Duration(ms) * time.Millisecond, true, nil
+}
+
+// Ping checks that the server is reachable and responding.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+/*
+do sends one command on a pooled connection and returns the decoded
+reply: string (simple or bulk), int64, nil (null bulk), or
+[]interface{}. Error replies are returned as ServerError.
+
+If ctx ends first, the round trip is interrupted, ctx.Err() is
+returned, and the connection is discarded.
+
+An idle connection may have been closed by the server (e.g. by its
+idle timeout) while it sat in the pool. If a reused connection fails
+before any reply byte arrives, the command is retried once on a
+freshly dialed one. Every command the client sends is safe to repeat.
+*/
+
+func (c *Client) do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, reused, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, replied, err := c.exchange(ctx, cn, args)
+	if err != nil && reused && !replied && ctx.Err() == nil {
+		if cn, err = c.dial(ctx); err != nil {
+			return nil, err
+		}
+		reply, _, err = c.exchange(ctx, cn, args)
+	}
+	return reply, err
+}
+
+/*
+exchange runs one round trip on cn, then releases or discards it.
+replied reports whether any of the reply was read.
+*/
+
+func (c *Client) exchange(ctx context.Context, cn *conn, args []string) (reply interface{}, replied bool, err error) {
+	deadline, _ := ctx.Deadline()
+	cn.nc.SetDeadline(deadline)
+	// The deadline alone misses cancellation: expire the connection
+	// as soon as ctx is done.
+	stop := context.AfterFunc(ctx, func() { cn.nc.SetDeadline(time.Now()) })
+
+	reply, replied, err = cn.roundTrip(args)
+	if !stop() {
+		// The connection may be mid-reply, or about to have its
+		// deadline cut short: it cannot be reused.
+		cn.nc.Close()
+		if err != nil {
+			return nil, replied, ctx.Err()
+		}
+		return reply, replied, nil
+	}
+	var serr ServerError
+	if err != nil && !errors.As(err, &serr) {
+		cn.nc.Close()
+		return nil, replied, err
+	}
+	c.release(cn)
+	return reply, replied, err
+}
+
+// acquire returns an idle connection (reused = true) or dials a new one.
+func (c *Client) acquire(ctx context.Context) (cn *conn, reused bool, err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, false, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, true, nil
+	}
+	c.mu.Unlock()
+
+	cn, err = c.dial(ctx)
+	return cn, false, err
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: c.dialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{nc: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}, nil
+}
+
+func (c *Client) release(cn *conn) {
+	c.mu.Lock()
+	if !c.closed && len(c.idle) < c.poolSize {
+		c.idle = append(c.idle, cn)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	cn.nc.Close()
+}
+
+func (cn *conn) roundTrip(args []string) (reply interface{}, replied bool, err error) {
+	resp.WriteCommand(cn.w, args)
+	if err := cn.w.Flush(); err != nil {
+		return nil, false, err
+	}
+	if _, err := cn.r.Peek(1); err != nil {
+		return nil, false, err
+	}
+	reply, err = resp.ReadReply(cn.r)
+	if rerr, ok := err.(resp.Error); ok {
+		return nil, true, ServerError(rerr)
+	}
+	return reply, true, err
+}
+
+func unexpected(reply interface{}) error {
+	return fmt.Errorf("tempusclient: unexpected reply %v", reply)
+}
+
+func format(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(val)
+}
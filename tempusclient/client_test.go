@@ -0,0 +1,140 @@
+package tempusclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/Krishna8167/tempuscache/v2/tempusresp"
+)
+
+var (
+	_ Cache = (*tempuscache.Cache)(nil)
+	_ Cache = (*Client)(nil)
+)
+
+func startServer(t *testing.T, opts ...tempusresp.Option) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := tempusresp.New(tempuscache.New(), opts...)
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	return ln.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	client := New(startServer(t), WithPoolSize(2))
+	defer client.Close()
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.Set("a", "hello", 0)
+	client.Set("b", 42, time.Hour)
+
+	if v, found := client.Get("a"); !found || v != "hello" {
+		t.Fatalf("expected hello, got %v (%v)", v, found)
+	}
+	if v, found := client.Get("b"); !found || v != "42" {
+		t.Fatalf("expected non-string value to round-trip as a string, got %v", v)
+	}
+	if _, found := client.Get("missing"); found {
+		t.Fatal("expected miss")
+	}
+
+	if ttl, found := client.TTL("a"); !found || ttl != 0 {
+		t.Fatalf("expected (0, true) for non-expiring key, got (%v, %v)", ttl, found)
+	}
+	if ttl, found := client.TTL("b"); !found || ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("unexpected TTL %v", ttl)
+	}
+
+	client.Delete("a")
+	if _, found := client.TTL("a"); found {
+		t.Fatal("expected deleted key to be gone")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var reported []error
+	client := New(addr, WithErrorHandler(func(err error) { reported = append(reported, err) }))
+
+	if _, found := client.Get("a"); found {
+		t.Fatal("expected unreachable server to be reported as a miss")
+	}
+	if _, _, err := client.GetContext(context.Background(), "a"); err == nil {
+		t.Fatal("expected GetContext to return the dial error")
+	}
+	if len(reported) != 1 {
+		t.Fatalf("expected 1 reported error, got %d", len(reported))
+	}
+
+	client.Close()
+	if err := client.Ping(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestClientStaleConnection(t *testing.T) {
+	client := New(startServer(t, tempusresp.WithIdleTimeout(20*time.Millisecond)))
+	defer client.Close()
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Let the server drop the pooled connection.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := client.SetContext(context.Background(), "a", "v", 0); err != nil {
+		t.Fatalf("expected a retry on a fresh connection, got %v", err)
+	}
+	if v, found := client.Get("a"); !found || v != "v" {
+		t.Fatalf("expected v, got %v (%v)", v, found)
+	}
+}
+
+func TestClientCancel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// A server that accepts commands and never replies.
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer nc.Close()
+		}
+	}()
+
+	client := New(ln.Addr().String())
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, _, err := client.GetContext(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,488 @@
+package tempusclient
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoNodes is returned when no healthy node can serve a key.
+var ErrNoNodes = errors.New("tempusclient: no healthy nodes")
+
+/*
+RingOption configures a Ring.
+*/
+
+type RingOption func(*Ring)
+
+// WithVirtualNodes sets the number of points each node owns on the
+// hash ring (default 160). More points spread keys more evenly.
+func WithVirtualNodes(n int) RingOption {
+	return func(r *Ring) {
+		r.vnodes = n
+	}
+}
+
+// WithClientOptions sets the options used to create each node's Client.
+func WithClientOptions(opts ...Option) RingOption {
+	return func(r *Ring) {
+		r.clientOpts = opts
+	}
+}
+
+// WithHealthCheck sets how often every node is pinged (default 1s,
+// 0 disables background checks).
+func WithHealthCheck(interval time.Duration) RingOption {
+	return func(r *Ring) {
+		r.healthInterval = interval
+	}
+}
+
+// WithRingTimeout bounds each command issued through the Cache
+// methods (default 1s).
+func WithRingTimeout(d time.Duration) RingOption {
+	return func(r *Ring) {
+		r.timeout = d
+	}
+}
+
+// WithRingErrorHandler receives errors swallowed by the Cache methods.
+func WithRingErrorHandler(fn func(error)) RingOption {
+	return func(r *Ring) {
+		r.onError = fn
+	}
+}
+
+/*
+NodeStatus reports the health of one node.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Addr      -> Node address
+Healthy   -> Whether keys are currently routed to it
+LastError -> Error that made it unhealthy (nil when healthy)
+LastCheck -> Time of the last health check or failed request
+*/
+
+type NodeStatus struct {
+	Addr      string
+	Healthy   bool
+	LastError error
+	LastCheck time.Time
+}
+
+/*
+Ring distributes keys across a fleet of cache servers with
+consistent hashing.
+
+================================================================================
+USAGE
+================================================================================
+
+	ring := tempusclient.NewRing([]string{"cache-1:6380", "cache-2:6380"})
+	defer ring.Close()
+
+	var cache tempusclient.Cache = ring
+
+================================================================================
+CONSISTENT HASHING
+================================================================================
+
+Each node owns WithVirtualNodes points on a 64-bit ring, placed by
+hashing "<addr>#<i>". A key belongs to the first point at or after
+its own hash. Adding or removing a node therefore only moves the
+keys of the ring segments it gains or loses (about 1/N of them);
+every other key stays where it is.
+
+================================================================================
+HEALTH
+================================================================================
+
+A node is marked unhealthy when a request to it fails with a network
+error, or when a background health check (PING) fails. Keys of an
+unhealthy node are served by the next healthy node on the ring; the
+ring itself is unchanged, so they move back as soon as the node
+passes a health check again. Server error replies do not affect
+health.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+vnodes         -> Points per node on the ring
+clientOpts     -> Options for each node's Client
+healthInterval -> Background health check period (0 = none)
+timeout        -> Per-command timeout of the Cache methods
+onError        -> Receives errors swallowed by the Cache methods
+mu             -> Protects nodes, points, and closed
+nodes          -> Nodes by address
+points         -> Ring points, sorted by hash
+closed         -> Set by Close
+stop           -> Closed by Close to stop the health checker
+wg             -> Tracks the health checker
+*/
+
+type Ring struct {
+	vnodes         int
+	clientOpts     []Option
+	healthInterval time.Duration
+	timeout        time.Duration
+	onError        func(error)
+
+	mu     sync.RWMutex
+	nodes  map[string]*ringNode
+	points []ringPoint
+	closed bool
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+type ringNode struct {
+	client    *Client
+	healthy   bool
+	lastErr   error
+	lastCheck time.Time
+}
+
+type ringPoint struct {
+	hash uint64
+	addr string
+}
+
+/*
+NewRing creates a Ring over the servers at addrs. Nodes start out
+healthy; no connection is made until the first command or health
+check.
+*/
+
+func NewRing(addrs []string, opts ...RingOption) *Ring {
+	r := &Ring{
+		vnodes:         160,
+		healthInterval: time.Second,
+		timeout:        time.Second,
+		onError:        func(error) {},
+		nodes:          make(map[string]*ringNode),
+		stop:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	for _, addr := range addrs {
+		r.Add(addr)
+	}
+
+	if r.healthInterval > 0 {
+		r.wg.Add(1)
+		go r.healthLoop()
+	}
+	return r
+}
+
+// Add puts the node at addr on the ring. Adding a known node is a no-op.
+func (r *Ring) Add(addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.nodes[addr]; known || r.closed {
+		return
+	}
+	r.nodes[addr] = &ringNode{client: New(addr, r.clientOpts...), healthy: true}
+	r.rebuildLocked()
+}
+
+// Remove takes the node at addr off the ring and closes its connections.
+func (r *Ring) Remove(addr string) {
+	r.mu.Lock()
+	n, known := r.nodes[addr]
+	if known {
+		delete(r.nodes, addr)
+		r.rebuildLocked()
+	}
+	r.mu.Unlock()
+
+	if known {
+		n.client.Close()
+	}
+}
+
+// Nodes reports the health of every node, sorted by address.
+func (r *Ring) Nodes() []NodeStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]NodeStatus, 0, len(r.nodes))
+	for addr, n := range r.nodes {
+		statuses = append(statuses, NodeStatus{Addr: addr, Healthy: n.healthy, LastError: n.lastErr, LastCheck: n.lastCheck})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Addr < statuses[j].Addr })
+	return statuses
+}
+
+// NodeFor returns the address of the node currently serving key.
+func (r *Ring) NodeFor(key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addr, _, err := r.lookupLocked(key)
+	return addr, err
+}
+
+/*
+Close stops the health checker and closes every node's connections.
+*/
+
+func (r *Ring) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.stop)
+	nodes := r.nodes
+	r.nodes, r.points = nil, nil
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	for _, n := range nodes {
+		n.client.Close()
+	}
+	return nil
+}
+
+// Get fetches key from its node; failures are reported as misses.
+func (r *Ring) Get(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	val, found, err := r.GetContext(ctx, key)
+	if err != nil {
+		r.onError(err)
+		return nil, false
+	}
+	return val, found
+}
+
+// Set stores value under key on its node; failures are reported to the error handler.
+func (r *Ring) Set(key string, value interface{}, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.SetContext(ctx, key, value, ttl); err != nil {
+		r.onError(err)
+	}
+}
+
+// Delete removes key from its node; failures are reported to the error handler.
+func (r *Ring) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.DeleteContext(ctx, key); err != nil {
+		r.onError(err)
+	}
+}
+
+// TTL returns the remaining lifetime of key; failures are reported as misses.
+func (r *Ring) TTL(key string) (time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	ttl, found, err := r.TTLContext(ctx, key)
+	if err != nil {
+		r.onError(err)
+		return 0, false
+	}
+	return ttl, found
+}
+
+// GetContext fetches key from its node, returning any error.
+func (r *Ring) GetContext(ctx context.Context, key string) (val interface{}, found bool, err error) {
+	err = r.route(key, func(c *Client) error {
+		val, found, err = c.GetContext(ctx, key)
+		return err
+	})
+	return val, found, err
+}
+
+// SetContext stores value under key on its node (ttl <= 0 = never expires).
+func (r *Ring) SetContext(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return r.route(key, func(c *Client) error {
+		return c.SetContext(ctx, key, value, ttl)
+	})
+}
+
+// DeleteContext removes key from its node.
+func (r *Ring) DeleteContext(ctx context.Context, key string) error {
+	return r.route(key, func(c *Client) error {
+		return c.DeleteContext(ctx, key)
+	})
+}
+
+// TTLContext returns the remaining lifetime of key (see Client.TTLContext).
+func (r *Ring) TTLContext(ctx context.Context, key string) (ttl time.Duration, found bool, err error) {
+	err = r.route(key, func(c *Client) error {
+		ttl, found, err = c.TTLContext(ctx, key)
+		return err
+	})
+	return ttl, found, err
+}
+
+/*
+route runs fn against the node serving key. A network error marks
+the node unhealthy, so the next request for its keys goes to the
+next node on the ring.
+*/
+
+func (r *Ring) route(key string, fn func(*Client) error) error {
+	r.mu.RLock()
+	addr, n, err := r.lookupLocked(key)
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	err = fn(n.client)
+	var serr ServerError
+	if err != nil && !errors.As(err, &serr) {
+		r.markUnhealthy(addr, n, err)
+	}
+	return err
+}
+
+/*
+lookupLocked walks the ring clockwise from the hash of key to the
+first healthy node.
+
+NOTE:
+The caller must hold r.mu (read or write).
+*/
+
+func (r *Ring) lookupLocked(key string) (string, *ringNode, error) {
+	if r.closed {
+		return "", nil, ErrClosed
+	}
+	if len(r.points) == 0 {
+		return "", nil, ErrNoNodes
+	}
+
+	h := ringHash(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	for i := range r.points {
+		p := r.points[(start+i)%len(r.points)]
+		if n := r.nodes[p.addr]; n.healthy {
+			return p.addr, n, nil
+		}
+	}
+	return "", nil, ErrNoNodes
+}
+
+/*
+rebuildLocked recomputes the ring points from the node set.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (r *Ring) rebuildLocked() {
+	points := make([]ringPoint, 0, len(r.nodes)*r.vnodes)
+	for addr := range r.nodes {
+		for i := 0; i < r.vnodes; i++ {
+			points = append(points, ringPoint{hash: ringHash(addr + "#" + strconv.Itoa(i)), addr: addr})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].addr < points[j].addr
+	})
+	r.points = points
+}
+
+func (r *Ring) markUnhealthy(addr string, n *ringNode, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The node may have been removed (or re-added) meanwhile.
+	if r.nodes[addr] != n {
+		return
+	}
+	n.healthy, n.lastErr, n.lastCheck = false, err, time.Now()
+}
+
+// healthLoop pings every node each interval until Close.
+func (r *Ring) healthLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.checkHealth()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// checkHealth pings every node concurrently and records the results.
+func (r *Ring) checkHealth() {
+	r.mu.RLock()
+	nodes := make(map[string]*ringNode, len(r.nodes))
+	for addr, n := range r.nodes {
+		nodes[addr] = n
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for addr, n := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), r.healthInterval)
+			err := n.client.Ping(ctx)
+			cancel()
+
+			r.mu.Lock()
+			if r.nodes[addr] == n {
+				n.healthy, n.lastErr, n.lastCheck = err == nil, err, time.Now()
+			}
+			r.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+/*
+ringHash places keys and virtual nodes on the ring: FNV-1a followed
+by a 64-bit finalizer, since FNV alone clusters similar strings such
+as "node#1" and "node#2".
+*/
+
+func ringHash(s string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,131 @@
+package tempusclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/Krishna8167/tempuscache/v2/tempusresp"
+)
+
+var (
+	_ Cache               = (*Ring)(nil)
+	_ tempuscache.TTLTier = (*Client)(nil)
+	_ tempuscache.TTLTier = (*Ring)(nil)
+
+	_ tempuscache.ContextTier = (*Client)(nil)
+	_ tempuscache.ContextTier = (*Ring)(nil)
+)
+
+func TestRingDistributesKeys(t *testing.T) {
+	addrs := []string{startServer(t), startServer(t), startServer(t)}
+	ring := NewRing(addrs, WithHealthCheck(0))
+	defer ring.Close()
+
+	owners := make(map[string]string)
+	perNode := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		key := "key-" + strconv.Itoa(i)
+		ring.Set(key, i, 0)
+
+		addr, err := ring.NodeFor(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		owners[key] = addr
+		perNode[addr]++
+	}
+	for _, addr := range addrs {
+		if perNode[addr] < 50 {
+			t.Fatalf("expected keys on every node, got %v", perNode)
+		}
+	}
+
+	// Each key is stored on exactly its owner.
+	for key, owner := range owners {
+		direct := New(owner)
+		_, found := direct.Get(key)
+		direct.Close()
+		if !found {
+			t.Fatalf("expected %q on %s", key, owner)
+		}
+	}
+
+	// Removing a node only moves that node's keys.
+	ring.Remove(addrs[2])
+	for key, owner := range owners {
+		addr, _ := ring.NodeFor(key)
+		if owner != addrs[2] && addr != owner {
+			t.Fatalf("key %q moved from %s to %s", key, owner, addr)
+		}
+		if addr == addrs[2] {
+			t.Fatalf("key %q still routed to a removed node", key)
+		}
+	}
+
+	// Adding it back restores the original placement.
+	ring.Add(addrs[2])
+	for key, owner := range owners {
+		if addr, _ := ring.NodeFor(key); addr != owner {
+			t.Fatalf("key %q placed on %s after re-adding, want %s", key, addr, owner)
+		}
+	}
+}
+
+func TestRingFailover(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flaky := tempusresp.New(tempuscache.New())
+	go flaky.Serve(ln)
+
+	healthy := startServer(t)
+	ring := NewRing([]string{ln.Addr().String(), healthy}, WithHealthCheck(10*time.Millisecond))
+	defer ring.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	flaky.Shutdown(ctx)
+
+	unhealthy := func() bool {
+		for _, n := range ring.Nodes() {
+			if n.Addr == ln.Addr().String() {
+				return !n.Healthy && n.LastError != nil
+			}
+		}
+		return false
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !unhealthy() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the stopped node to be reported unhealthy: %+v", ring.Nodes())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for i := 0; i < 20; i++ {
+		key := "key-" + strconv.Itoa(i)
+		if err := ring.SetContext(ctx, key, "v", 0); err != nil {
+			t.Fatal(err)
+		}
+		if addr, _ := ring.NodeFor(key); addr != healthy {
+			t.Fatalf("expected %q to fail over to %s, got %s", key, healthy, addr)
+		}
+	}
+}
+
+func TestRingNoNodes(t *testing.T) {
+	ring := NewRing(nil)
+	if _, _, err := ring.GetContext(context.Background(), "k"); !errors.Is(err, ErrNoNodes) {
+		t.Fatalf("expected ErrNoNodes, got %v", err)
+	}
+	ring.Close()
+	if _, _, err := ring.GetContext(context.Background(), "k"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
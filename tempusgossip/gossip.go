@@ -0,0 +1,704 @@
+/*
+Package tempusgossip replicates recent cache writes between peers
+with an epidemic (gossip) protocol, so every node converges to a
+similar hot set.
+
+================================================================================
+WHY?
+================================================================================
+
+With N replicas each running a read-through cache, a hot key is
+loaded from the backing store N times: once per replica. When the
+replica that loaded it gossips the value, the others receive it
+before their own first miss, and the backing store sees one load
+instead of N.
+
+================================================================================
+USAGE
+================================================================================
+
+	node, err := tempusgossip.New(cache,
+	    tempusgossip.WithBindAddr("0.0.0.0:7946"),
+	    tempusgossip.WithAdvertiseAddr("10.0.0.5:7946"))
+	if err != nil { ... }
+	defer node.Close()
+
+	node.Join("10.0.0.6:7946") // any live member is enough
+
+	node.Set("user:42", user, time.Minute) // stored and gossiped
+	v, ok := node.Get("user:42")
+
+Writes made through the Node are gossiped; writes made directly on
+the Cache stay local. Loaders should therefore store their results
+with Node.Set.
+
+================================================================================
+PROTOCOL
+================================================================================
+
+Every gossip interval, each node:
+
+	→ Increments its own heartbeat.
+	→ Picks up to fanout random live members.
+	→ Sends each of them its member list and its queued operations
+	  over a short-lived TCP connection.
+
+On receipt, a node:
+
+	→ Merges the member list (higher heartbeat wins).
+	→ Applies every operation it has not seen before, and queues it
+	  to be forwarded in turn.
+
+Each queued operation is sent in retransmit rounds and then
+dropped, so an operation reaches the whole cluster in O(log N)
+rounds without any node talking to every other node.
+
+================================================================================
+CONSISTENCY
+================================================================================
+
+Replication is best effort: it aims for a similar hot set, not for
+identical caches.
+
+  - Conflicting writes to the same key resolve last-writer-wins on
+    the writer's wall clock.
+  - Operations queued while no peer is known are kept (up to
+    maxQueue) and sent once a peer appears; beyond that the oldest
+    are dropped.
+  - TTLs are sent as remaining durations, so clock skew between
+    nodes does not shorten or extend them.
+
+================================================================================
+FAILURE DETECTION
+================================================================================
+
+A member whose heartbeat has not increased within the dead-after
+period (WithDeadAfter) is considered dead: it is no longer gossiped
+to and is reported with Alive false by Members. It comes back as
+soon as a newer heartbeat is heard.
+
+================================================================================
+ENCODING
+================================================================================
+
+Messages are encoded with a tempuscache.Codec (gob by default), so
+the same gob.Register calls needed for snapshots apply here.
+
+================================================================================
+SECURITY
+================================================================================
+
+Messages are neither authenticated nor encrypted. Bind to a private
+network interface.
+*/
+package tempusgossip
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultBindAddr is the listen address used when WithBindAddr is not given.
+const DefaultBindAddr = "127.0.0.1:7946"
+
+// ErrClosed is returned by Join after Close.
+var ErrClosed = errors.New("tempusgossip: node closed")
+
+const (
+	// maxQueue bounds the operations waiting to be gossiped.
+	maxQueue = 4096
+
+	// maxMessageSize bounds a single incoming message.
+	maxMessageSize = 64 << 20
+
+	// seenRetention is how long operation IDs and dead members are
+	// remembered, to recognize late duplicates.
+	seenRetention = time.Minute
+)
+
+/*
+Option configures a Node.
+*/
+
+type Option func(*Node)
+
+// WithBindAddr sets the TCP listen address (default DefaultBindAddr).
+func WithBindAddr(addr string) Option {
+	return func(n *Node) {
+		n.bindAddr = addr
+	}
+}
+
+// WithAdvertiseAddr sets the address peers use to reach this node
+// (default: the address actually bound).
+func WithAdvertiseAddr(addr string) Option {
+	return func(n *Node) {
+		n.advertiseAddr = addr
+	}
+}
+
+// WithInterval sets the gossip round period (default 200ms).
+func WithInterval(d time.Duration) Option {
+	return func(n *Node) {
+		n.interval = d
+	}
+}
+
+// WithFanout sets the number of peers contacted per round (default 3).
+func WithFanout(k int) Option {
+	return func(n *Node) {
+		n.fanout = k
+	}
+}
+
+// WithRetransmit sets the number of rounds each operation is
+// forwarded in (default 4).
+func WithRetransmit(rounds int) Option {
+	return func(n *Node) {
+		n.retransmit = rounds
+	}
+}
+
+// WithDeadAfter sets how long a member may go without a new
+// heartbeat before it is considered dead (default 5s).
+func WithDeadAfter(d time.Duration) Option {
+	return func(n *Node) {
+		n.deadAfter = d
+	}
+}
+
+// WithCodec sets the message encoding (default tempuscache.GobCodec).
+func WithCodec(codec tempuscache.Codec) Option {
+	return func(n *Node) {
+		n.codec = codec
+	}
+}
+
+// WithLogger logs failed exchanges with peers.
+func WithLogger(logger *slog.Logger) Option {
+	return func(n *Node) {
+		n.log = logger
+	}
+}
+
+/*
+Member describes a node of the cluster as seen locally.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+ID       -> Node identifier (the cache's NodeID)
+Addr     -> Gossip address
+LastSeen -> When its heartbeat last increased (zero for the local node)
+Alive    -> Whether it is still gossiped to
+*/
+
+type Member struct {
+	ID       string
+	Addr     string
+	LastSeen time.Time
+	Alive    bool
+}
+
+// wireMember is a member as exchanged on the wire.
+type wireMember struct {
+	ID        string
+	Addr      string
+	Heartbeat uint64
+}
+
+/*
+op is a replicated write.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Origin -> Node that made the write
+Seq    -> Per-origin sequence number; (Origin, Seq) identifies the op
+Key    -> Written key
+Value  -> New value (unused for deletes)
+TTL    -> Remaining lifetime when sent (0 = never expires)
+Delete -> The op removes Key
+Time   -> Writer's wall clock, for last-writer-wins (UnixNano)
+*/
+
+type op struct {
+	Origin string
+	Seq    uint64
+	Key    string
+	Value  interface{}
+	TTL    time.Duration
+	Delete bool
+	Time   int64
+}
+
+// message is the unit exchanged between peers.
+type message struct {
+	Members []wireMember
+	Ops     []op
+}
+
+type memberState struct {
+	addr      string
+	heartbeat uint64
+	lastSeen  time.Time
+	dead      bool
+}
+
+// queuedOp is an op waiting to be gossiped.
+type queuedOp struct {
+	op        op
+	expires   int64 // absolute deadline of the value, 0 = none
+	remaining int   // rounds left
+}
+
+/*
+Node replicates writes of one Cache to its gossip peers.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cache         -> Replicated cache
+id            -> This node's identifier (cache.NodeID())
+bindAddr      -> Listen address
+advertiseAddr -> Address sent to peers
+interval      -> Gossip round period
+fanout        -> Peers contacted per round
+retransmit    -> Rounds each op is forwarded in
+deadAfter     -> Heartbeat silence after which a member is dead
+codec         -> Message encoding
+log           -> Logger (never nil)
+ln            -> Gossip listener
+mu            -> Protects everything below
+heartbeat     -> This node's heartbeat
+seq           -> Last op sequence number issued
+members       -> Other known members by ID
+queue         -> Ops waiting to be gossiped, oldest first
+seen          -> Recently applied op IDs and when they were seen
+lastWrite     -> Recent write time per key, for last-writer-wins
+closed        -> Set by Close
+stop          -> Closed by Close to stop the workers
+wg            -> Tracks the workers and connection handlers
+*/
+
+type Node struct {
+	cache         *tempuscache.Cache
+	id            string
+	bindAddr      string
+	advertiseAddr string
+	interval      time.Duration
+	fanout        int
+	retransmit    int
+	deadAfter     time.Duration
+	codec         tempuscache.Codec
+	log           *slog.Logger
+	ln            net.Listener
+
+	mu        sync.Mutex
+	heartbeat uint64
+	seq       uint64
+	members   map[string]*memberState
+	queue     []*queuedOp
+	seen      map[string]time.Time
+	lastWrite map[string]time.Time
+	closed    bool
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+/*
+New starts gossiping writes of cache.
+
+It binds the listen address immediately and starts the gossip
+worker. The node is alone until Join is called (or a peer joins it).
+*/
+
+func New(cache *tempuscache.Cache, opts ...Option) (*Node, error) {
+	n := &Node{
+		cache:      cache,
+		id:         cache.NodeID(),
+		bindAddr:   DefaultBindAddr,
+		interval:   200 * time.Millisecond,
+		fanout:     3,
+		retransmit: 4,
+		deadAfter:  5 * time.Second,
+		codec:      tempuscache.GobCodec{},
+		log:        slog.New(slog.DiscardHandler),
+		members:    make(map[string]*memberState),
+		seen:       make(map[string]time.Time),
+		lastWrite:  make(map[string]time.Time),
+		stop:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	ln, err := net.Listen("tcp", n.bindAddr)
+	if err != nil {
+		return nil, err
+	}
+	n.ln = ln
+	if n.advertiseAddr == "" {
+		n.advertiseAddr = ln.Addr().String()
+	}
+
+	n.wg.Add(2)
+	go n.accept()
+	go n.run()
+	return n, nil
+}
+
+// Addr returns the address advertised to peers.
+func (n *Node) Addr() string {
+	return n.advertiseAddr
+}
+
+/*
+Join contacts the given members and announces this node to them.
+It returns how many of them were reached; an error is returned
+only if none was.
+*/
+
+func (n *Node) Join(addrs ...string) (int, error) {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return 0, ErrClosed
+	}
+	msg := n.messageLocked(nil)
+	n.mu.Unlock()
+
+	joined := 0
+	var lastErr error
+	for _, addr := range addrs {
+		if err := n.send(addr, msg); err != nil {
+			lastErr = err
+			continue
+		}
+		joined++
+	}
+	if joined == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return joined, nil
+}
+
+/*
+Members returns every known member, this node included, sorted by
+address.
+*/
+
+func (n *Node) Members() []Member {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	members := []Member{{ID: n.id, Addr: n.advertiseAddr, Alive: true}}
+	for id, m := range n.members {
+		members = append(members, Member{ID: id, Addr: m.addr, LastSeen: m.lastSeen, Alive: !m.dead})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Addr < members[j].Addr })
+	return members
+}
+
+// Get reads key from the local cache.
+func (n *Node) Get(key string) (interface{}, bool) {
+	return n.cache.Get(key)
+}
+
+// TTL returns the local remaining lifetime of key (see Cache.TTL).
+func (n *Node) TTL(key string) (time.Duration, bool) {
+	return n.cache.TTL(key)
+}
+
+// Set stores key locally and gossips the write to the cluster.
+func (n *Node) Set(key string, value interface{}, ttl time.Duration) {
+	n.cache.Set(key, value, ttl)
+	n.record(op{Key: key, Value: value, TTL: max(ttl, 0)})
+}
+
+// Delete removes key locally and gossips the removal to the cluster.
+func (n *Node) Delete(key string) {
+	n.cache.Delete(key)
+	n.record(op{Key: key, Delete: true})
+}
+
+/*
+Close stops gossiping and waits for in-flight exchanges. The cache
+itself is left running.
+*/
+
+func (n *Node) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	close(n.stop)
+	n.mu.Unlock()
+
+	err := n.ln.Close()
+	n.wg.Wait()
+	return err
+}
+
+// record stamps a local write and queues it for gossiping.
+func (n *Node) record(o op) {
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.seq++
+	o.Origin, o.Seq, o.Time = n.id, n.seq, now.UnixNano()
+	n.lastWrite[o.Key] = now
+	n.enqueueLocked(o, now)
+}
+
+/*
+enqueueLocked queues o for retransmit rounds.
+
+NOTE:
+The caller must hold n.mu.
+*/
+
+func (n *Node) enqueueLocked(o op, now time.Time) {
+	q := &queuedOp{op: o, remaining: n.retransmit}
+	if o.TTL > 0 {
+		q.expires = now.Add(o.TTL).UnixNano()
+	}
+	if len(n.queue) >= maxQueue {
+		n.queue = n.queue[1:]
+	}
+	n.queue = append(n.queue, q)
+}
+
+/*
+messageLocked builds the message for one round from the member
+list and the given queued ops. TTLs are converted back to remaining
+durations at send time.
+
+NOTE:
+The caller must hold n.mu.
+*/
+
+func (n *Node) messageLocked(queued []*queuedOp) message {
+	msg := message{Members: []wireMember{{ID: n.id, Addr: n.advertiseAddr, Heartbeat: n.heartbeat}}}
+	for id, m := range n.members {
+		if !m.dead {
+			msg.Members = append(msg.Members, wireMember{ID: id, Addr: m.addr, Heartbeat: m.heartbeat})
+		}
+	}
+
+	now := time.Now().UnixNano()
+	for _, q := range queued {
+		o := q.op
+		if q.expires != 0 {
+			if q.expires <= now {
+				continue
+			}
+			o.TTL = time.Duration(q.expires - now)
+		}
+		msg.Ops = append(msg.Ops, o)
+	}
+	return msg
+}
+
+// run executes one gossip round per interval until Close.
+func (n *Node) run() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.round()
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+/*
+round sends the member list and pending ops to up to fanout random
+live peers.
+*/
+
+func (n *Node) round() {
+	now := time.Now()
+
+	n.mu.Lock()
+	n.heartbeat++
+	n.reapLocked(now)
+
+	var peers []string
+	for _, m := range n.members {
+		if !m.dead {
+			peers = append(peers, m.addr)
+		}
+	}
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	if len(peers) > n.fanout {
+		peers = peers[:n.fanout]
+	}
+
+	// Ops are only consumed by rounds that have someone to send them to.
+	var queued []*queuedOp
+	if len(peers) > 0 {
+		queued = n.queue
+		kept := n.queue[:0:0]
+		for _, q := range n.queue {
+			if q.remaining--; q.remaining > 0 {
+				kept = append(kept, q)
+			}
+		}
+		n.queue = kept
+	}
+	msg := n.messageLocked(queued)
+	n.mu.Unlock()
+
+	for _, addr := range peers {
+		if err := n.send(addr, msg); err != nil {
+			n.log.Debug("tempusgossip: gossip failed", "peer", addr, "err", err)
+		}
+	}
+}
+
+/*
+reapLocked marks silent members dead and forgets old dead members,
+seen op IDs, and write times.
+
+NOTE:
+The caller must hold n.mu.
+*/
+
+func (n *Node) reapLocked(now time.Time) {
+	for id, m := range n.members {
+		switch {
+		case !m.dead && now.Sub(m.lastSeen) > n.deadAfter:
+			m.dead = true
+			n.log.Warn("tempusgossip: member considered dead", "id", id, "addr", m.addr)
+		case m.dead && now.Sub(m.lastSeen) > seenRetention:
+			delete(n.members, id)
+		}
+	}
+	for id, t := range n.seen {
+		if now.Sub(t) > seenRetention {
+			delete(n.seen, id)
+		}
+	}
+	for key, t := range n.lastWrite {
+		if now.Sub(t) > seenRetention {
+			delete(n.lastWrite, key)
+		}
+	}
+}
+
+// send delivers msg to the peer at addr over a new connection.
+func (n *Node) send(addr string, msg message) error {
+	conn, err := net.DialTimeout("tcp", addr, n.interval*5)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(n.interval * 5))
+	return n.codec.NewEncoder(conn).Encode(&msg)
+}
+
+// accept serves incoming gossip connections until Close.
+func (n *Node) accept() {
+	defer n.wg.Done()
+
+	for {
+		conn, err := n.ln.Accept()
+		if err != nil {
+			return
+		}
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			defer conn.Close()
+
+			conn.SetDeadline(time.Now().Add(n.interval * 5))
+			var msg message
+			r := io.LimitReader(conn, maxMessageSize)
+			if err := n.codec.NewDecoder(r).Decode(&msg); err != nil {
+				n.log.Debug("tempusgossip: invalid message", "peer", conn.RemoteAddr().String(), "err", err)
+				return
+			}
+			n.merge(msg)
+		}()
+	}
+}
+
+/*
+merge folds a received message into the local state: newer
+heartbeats refresh members, and unseen ops are applied and queued
+for forwarding.
+*/
+
+func (n *Node) merge(msg message) {
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	for _, wm := range msg.Members {
+		if wm.ID == n.id {
+			continue
+		}
+		m, known := n.members[wm.ID]
+		switch {
+		case !known:
+			n.members[wm.ID] = &memberState{addr: wm.Addr, heartbeat: wm.Heartbeat, lastSeen: now}
+		case wm.Heartbeat > m.heartbeat:
+			if m.dead {
+				n.log.Info("tempusgossip: member alive again", "id", wm.ID, "addr", wm.Addr)
+			}
+			m.addr, m.heartbeat, m.lastSeen, m.dead = wm.Addr, wm.Heartbeat, now, false
+		}
+	}
+
+	for _, o := range msg.Ops {
+		if o.Origin == n.id {
+			continue
+		}
+		id := o.Origin + "/" + strconv.FormatUint(o.Seq, 10)
+		if _, dup := n.seen[id]; dup {
+			continue
+		}
+		n.seen[id] = now
+		n.enqueueLocked(o, now)
+
+		written := time.Unix(0, o.Time)
+		if last, ok := n.lastWrite[o.Key]; ok && written.Before(last) {
+			continue
+		}
+		n.lastWrite[o.Key] = written
+
+		if o.Delete {
+			n.cache.Delete(o.Key)
+		} else {
+			n.cache.Set(o.Key, o.Value, o.TTL)
+		}
+	}
+}
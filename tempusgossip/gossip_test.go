@@ -0,0 +1,97 @@
+package tempusgossip
+
+import (
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 3s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func startCluster(t *testing.T, size int, opts ...Option) []*Node {
+	t.Helper()
+	opts = append([]Option{
+		WithBindAddr("127.0.0.1:0"),
+		WithInterval(10 * time.Millisecond),
+	}, opts...)
+
+	nodes := make([]*Node, size)
+	for i := range nodes {
+		cache := tempuscache.New()
+		t.Cleanup(cache.Stop)
+
+		node, err := New(cache, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { node.Close() })
+		nodes[i] = node
+
+		if i > 0 {
+			if _, err := node.Join(nodes[0].Addr()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		waitFor(t, func() bool { return len(node.Members()) == size })
+	}
+	return nodes
+}
+
+func TestGossipReplicatesWrites(t *testing.T) {
+	nodes := startCluster(t, 4)
+
+	nodes[1].Set("user:42", "alice", time.Minute)
+	for _, node := range nodes {
+		waitFor(t, func() bool { v, found := node.Get("user:42"); return found && v == "alice" })
+	}
+	if ttl, _ := nodes[3].TTL("user:42"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected the remaining TTL to be replicated, got %v", ttl)
+	}
+
+	nodes[2].Delete("user:42")
+	for _, node := range nodes {
+		waitFor(t, func() bool { _, found := node.Get("user:42"); return !found })
+	}
+}
+
+func TestGossipLastWriterWins(t *testing.T) {
+	nodes := startCluster(t, 2)
+
+	nodes[0].Set("k", "old", 0)
+	nodes[1].Set("k", "new", 0)
+
+	for _, node := range nodes {
+		waitFor(t, func() bool { v, _ := node.Get("k"); return v == "new" })
+	}
+}
+
+func TestGossipFailureDetection(t *testing.T) {
+	nodes := startCluster(t, 3, WithDeadAfter(100*time.Millisecond))
+
+	nodes[2].Close()
+	waitFor(t, func() bool {
+		for _, m := range nodes[0].Members() {
+			if m.ID == nodes[2].id {
+				return !m.Alive
+			}
+		}
+		return false
+	})
+
+	if _, err := nodes[2].Join(nodes[0].Addr()); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
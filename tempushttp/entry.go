@@ -0,0 +1,206 @@
+package tempushttp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+entry is a cached HTTP response.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Status -> Status code
+Header -> Response headers, as sent
+Body   -> Complete response body
+Stored -> When the response was cached, for the Age header
+Fresh  -> Until when it may be served without revalidation
+Vary   -> Request headers named by Vary, with the values they had:
+          only requests with the same values are served the entry
+*/
+
+type entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	Stored time.Time
+	Fresh  time.Time
+	Vary   map[string]string
+}
+
+/*
+newEntry returns the entry of a response to req, fresh for fresh
+from now.
+*/
+
+func newEntry(req *http.Request, status int, header http.Header, body []byte, fresh time.Duration) *entry {
+	now := time.Now()
+	e := &entry{Status: status, Header: header, Body: body, Stored: now, Fresh: now.Add(fresh)}
+	for _, name := range varyNames(header) {
+		if e.Vary == nil {
+			e.Vary = make(map[string]string)
+		}
+		e.Vary[name] = req.Header.Get(name)
+	}
+	return e
+}
+
+// matches reports whether e may answer req (see entry.Vary).
+func (e *entry) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// varyNames returns the header names listed by Vary, canonicalized.
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// age returns the Age header value of e at now, in whole seconds.
+func (e *entry) age(now time.Time) string {
+	return strconv.FormatInt(int64(now.Sub(e.Stored)/time.Second), 10)
+}
+
+// cacheableStatus lists the status codes cacheable by default (RFC 9110, section 15.1).
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+/*
+directives parses a Cache-Control header into its directives, names
+lowercased, values unquoted ("" for directives without one).
+*/
+
+func directives(h http.Header) map[string]string {
+	d := make(map[string]string)
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name != "" {
+				d[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return d
+}
+
+/*
+storable reports whether a response with status and header may be
+stored: its status is cacheable, it is not no-store, and it does not
+vary on everything ("Vary: *"). A shared cache (Middleware) also
+refuses private responses and those setting cookies; a private one
+(Transport) serves a single client and keeps them.
+*/
+
+func storable(status int, header http.Header, shared bool) bool {
+	if !cacheableStatus[status] {
+		return false
+	}
+	d := directives(header)
+	if _, ok := d["no-store"]; ok {
+		return false
+	}
+	if shared {
+		if _, ok := d["private"]; ok || header.Get("Set-Cookie") != "" {
+			return false
+		}
+	}
+	for _, name := range varyNames(header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+lifetime returns how long a stored response with header may be
+served without revalidation:
+
+- no-cache → 0: every use must be revalidated.
+- s-maxage (shared caches only), else max-age → its value.
+- Neither → fallback.
+*/
+
+func lifetime(header http.Header, fallback time.Duration, shared bool) time.Duration {
+	d := directives(header)
+	if _, ok := d["no-cache"]; ok {
+		return 0
+	}
+
+	names := []string{"max-age"}
+	if shared {
+		names = []string{"s-maxage", "max-age"}
+	}
+	for _, name := range names {
+		if v, ok := d[name]; ok {
+			seconds, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || seconds <= 0 {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fallback
+}
+
+/*
+bypass reports whether req must neither be served from nor stored in
+a shared cache: unsafe methods, credentials, and no-store requests.
+*/
+
+func bypass(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return true
+	}
+	if req.Header.Get("Authorization") != "" {
+		return true
+	}
+	_, noStore := directives(req.Header)["no-store"]
+	return noStore
+}
+
+// revalidate reports whether req asks for a fresh response (no-cache).
+func revalidate(req *http.Request) bool {
+	_, noCache := directives(req.Header)["no-cache"]
+	return noCache
+}
+
+/*
+DefaultKey keys a request by method, host, and request URI. The
+host is that of the URL for outgoing requests without Host set.
+*/
+
+func DefaultKey(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	return req.Method + " " + host + req.URL.RequestURI()
+}
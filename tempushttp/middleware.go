@@ -0,0 +1,223 @@
+/*
+Package tempushttp caches HTTP responses in a TempusCache: those a
+server sends, with Middleware, and those a client receives, with
+Transport (see transport.go).
+
+================================================================================
+USAGE
+================================================================================
+
+	cache := tempuscache.New(tempuscache.WithMaxCost(256 << 20))
+	http.ListenAndServe(":8080", tempushttp.Middleware(cache)(mux))
+
+================================================================================
+WHAT IS CACHED
+================================================================================
+
+The middleware behaves as a shared cache (RFC 9111), without
+revalidation:
+
+  - Only GET and HEAD requests without Authorization are served from
+    or stored in the cache; requests with "Cache-Control: no-store"
+    bypass it, and "no-cache" skips the lookup but stores the fresh
+    response.
+  - A response is stored for its s-maxage or max-age, or the
+    WithDefaultTTL fallback if it has neither (none by default).
+  - Responses with an uncacheable status, no-store, private, or
+    no-cache, Set-Cookie, or "Vary: *" are never stored, nor are
+    bodies larger than WithMaxBodyBytes.
+  - A response with Vary is only served to requests with the same
+    values of the headers it names; others replace it.
+
+Cached responses are replayed with their status, headers, and body,
+plus an Age header. Every response carries "X-Cache: HIT" or
+"X-Cache: MISS".
+
+================================================================================
+KEYS AND STORAGE
+================================================================================
+
+Responses are keyed by DefaultKey (method, host, and request URI)
+unless WithKey is given. Entries are ordinary cache entries costing
+their body size (see SetWithCost), so WithMaxCost bounds the memory
+held by bodies, and Delete of a key purges one response. Use a cache
+dedicated to responses, or a key function with a prefix.
+*/
+package tempushttp
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultMaxBodyBytes is the largest body cached when WithMaxBodyBytes is not given.
+const DefaultMaxBodyBytes = 1 << 20
+
+/*
+Option configures Middleware and Transport.
+*/
+
+type Option func(*config)
+
+type config struct {
+	key             func(*http.Request) string
+	defaultTTL      time.Duration
+	maxBodyBytes    int64
+	revalidationTTL time.Duration
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		key:             DefaultKey,
+		maxBodyBytes:    DefaultMaxBodyBytes,
+		revalidationTTL: DefaultRevalidationTTL,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithKey sets the function deriving cache keys from requests (default DefaultKey).
+func WithKey(key func(*http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.key = key
+	}
+}
+
+/*
+WithDefaultTTL caches responses without s-maxage or max-age for ttl.
+0, the default, leaves them uncached.
+*/
+
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(cfg *config) {
+		cfg.defaultTTL = ttl
+	}
+}
+
+// WithMaxBodyBytes sets the largest body cached (default DefaultMaxBodyBytes).
+func WithMaxBodyBytes(n int64) Option {
+	return func(cfg *config) {
+		cfg.maxBodyBytes = n
+	}
+}
+
+/*
+Middleware returns HTTP middleware serving cacheable responses from
+cache (see the package documentation).
+*/
+
+func Middleware(cache *tempuscache.Cache, opts ...Option) func(http.Handler) http.Handler {
+	cfg := newConfig(opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if bypass(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := cfg.key(r)
+			if !revalidate(r) {
+				if v, ok := cache.Get(key); ok {
+					if e, ok := v.(*entry); ok && e.matches(r) {
+						replay(w, r, e)
+						return
+					}
+				}
+			}
+
+			rec := &recorder{ResponseWriter: w, limit: cfg.maxBodyBytes}
+			w.Header().Set("X-Cache", "MISS")
+			next.ServeHTTP(rec, r)
+			cfg.store(cache, key, r, rec)
+		})
+	}
+}
+
+// replay writes the cached response e.
+func replay(w http.ResponseWriter, r *http.Request, e *entry) {
+	h := w.Header()
+	for name, values := range e.Header {
+		h[name] = append([]string(nil), values...)
+	}
+	h.Set("Age", e.age(time.Now()))
+	h.Set("X-Cache", "HIT")
+	w.WriteHeader(e.Status)
+	if r.Method != http.MethodHead {
+		w.Write(e.Body)
+	}
+}
+
+// store caches the response recorded by rec, if it is cacheable.
+func (cfg *config) store(cache *tempuscache.Cache, key string, r *http.Request, rec *recorder) {
+	if rec.status == 0 {
+		rec.capture(http.StatusOK)
+	}
+	if rec.overflow {
+		return
+	}
+	if !storable(rec.status, rec.header, true) {
+		return
+	}
+	ttl := lifetime(rec.header, cfg.defaultTTL, true)
+	if ttl <= 0 {
+		return
+	}
+
+	rec.header.Del("X-Cache")
+	e := newEntry(r, rec.status, rec.header, rec.body.Bytes(), ttl)
+	cache.SetWithCost(key, e, ttl, int64(len(e.Body)))
+}
+
+/*
+recorder passes a response through to the client while keeping a
+copy of it, up to limit body bytes.
+*/
+
+type recorder struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+// capture records the status and headers as they are sent.
+func (rec *recorder) capture(status int) {
+	rec.status = status
+	rec.header = rec.ResponseWriter.Header().Clone()
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	// Informational responses (1xx) precede the final one.
+	if rec.status == 0 && status >= 200 {
+		rec.capture(status)
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.capture(http.StatusOK)
+	}
+	if !rec.overflow {
+		if int64(rec.body.Len()+len(p)) > rec.limit {
+			rec.overflow = true
+			rec.body = bytes.Buffer{}
+		} else {
+			rec.body.Write(p)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,121 @@
+package tempushttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func serve(h http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func countingHandler(calls *atomic.Int32, cacheControl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, strings.Repeat("x", int(n)))
+	})
+}
+
+func TestMiddlewareCachesByMaxAge(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+
+	var calls atomic.Int32
+	h := Middleware(cache)(countingHandler(&calls, "public, max-age=60"))
+
+	first := serve(h, http.MethodGet, "/page?id=1", nil)
+	second := serve(h, http.MethodGet, "/page?id=1", nil)
+	if calls.Load() != 1 {
+		t.Fatalf("expected one handler call, got %d", calls.Load())
+	}
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("unexpected X-Cache headers %q, %q", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+	}
+	if second.Body.String() != "x" || second.Header().Get("Content-Type") != "text/plain" {
+		t.Fatalf("expected the cached response to be replayed, got %q", second.Body.String())
+	}
+	if second.Header().Get("Age") != "0" {
+		t.Fatalf("expected an Age header, got %q", second.Header().Get("Age"))
+	}
+	if ttl, ok := cache.TTL(DefaultKey(httptest.NewRequest(http.MethodGet, "/page?id=1", nil))); !ok || ttl > time.Minute {
+		t.Fatalf("expected the entry to live for max-age, got %v", ttl)
+	}
+
+	serve(h, http.MethodGet, "/page?id=2", nil)
+	if calls.Load() != 2 {
+		t.Fatal("expected distinct URLs to be cached separately")
+	}
+}
+
+func TestMiddlewareBypass(t *testing.T) {
+	cases := []struct {
+		name         string
+		cacheControl string
+		method       string
+		header       http.Header
+		opts         []Option
+	}{
+		{"no max-age", "", http.MethodGet, nil, nil},
+		{"private", "private, max-age=60", http.MethodGet, nil, nil},
+		{"no-store", "no-store", http.MethodGet, nil, nil},
+		{"post", "max-age=60", http.MethodPost, nil, nil},
+		{"authorization", "max-age=60", http.MethodGet, http.Header{"Authorization": {"Bearer t"}}, nil},
+		{"request no-store", "max-age=60", http.MethodGet, http.Header{"Cache-Control": {"no-store"}}, nil},
+		{"body too large", "max-age=60", http.MethodGet, nil, []Option{WithMaxBodyBytes(0)}},
+	}
+	for _, tc := range cases {
+		cache := tempuscache.New()
+		var calls atomic.Int32
+		h := Middleware(cache, tc.opts...)(countingHandler(&calls, tc.cacheControl))
+
+		serve(h, tc.method, "/", tc.header)
+		serve(h, tc.method, "/", tc.header)
+		if calls.Load() != 2 {
+			t.Errorf("%s: expected the response not to be cached", tc.name)
+		}
+		cache.Stop()
+	}
+}
+
+func TestMiddlewareOptions(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+
+	var calls atomic.Int32
+	byPath := func(r *http.Request) string { return "page:" + r.URL.Path }
+	h := Middleware(cache, WithDefaultTTL(time.Minute), WithKey(byPath))(countingHandler(&calls, ""))
+
+	serve(h, http.MethodGet, "/a?utm=1", nil)
+	serve(h, http.MethodGet, "/a?utm=2", nil)
+	if calls.Load() != 1 {
+		t.Fatalf("expected the default TTL and custom key to apply, got %d calls", calls.Load())
+	}
+	if _, ok := cache.Get("page:/a"); !ok {
+		t.Fatal("expected the response under the custom key")
+	}
+
+	serve(h, http.MethodGet, "/a", http.Header{"Cache-Control": {"no-cache"}})
+	if calls.Load() != 2 {
+		t.Fatal("expected a no-cache request to reach the handler")
+	}
+	if rr := serve(h, http.MethodGet, "/a", nil); rr.Body.String() != "xx" {
+		t.Fatalf("expected the no-cache response to be stored, got %q", rr.Body.String())
+	}
+}
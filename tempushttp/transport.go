@@ -0,0 +1,258 @@
+package tempushttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+/*
+transport.go implements Transport, an http.RoundTripper caching the
+responses of outgoing requests.
+
+================================================================================
+USAGE
+================================================================================
+
+	client := &http.Client{
+	    Transport: tempushttp.NewTransport(cache, http.DefaultTransport),
+	}
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+Transport is a private cache (RFC 9111): it serves one client, so
+unlike Middleware it stores private responses and those setting
+cookies, and ignores s-maxage.
+
+- GET and HEAD requests are cached; others, and requests with
+  "Cache-Control: no-store" or conditional headers of their own
+  (If-None-Match, If-Modified-Since), go straight to the base
+  transport.
+- Requests with Authorization are keyed by a digest of it as well,
+  so responses are never shared across credentials.
+- A fresh response (within max-age, or WithDefaultTTL) is served
+  without a request.
+- A stale response with an ETag or Last-Modified is revalidated with
+  If-None-Match or If-Modified-Since: a 304 reply refreshes and
+  serves it, any other reply replaces it. Such responses are kept
+  WithRevalidationTTL past their freshness, including no-cache
+  responses, which are revalidated on every use.
+- Vary, body size limits, and "X-Cache" (HIT, MISS, or
+  REVALIDATED) behave as for Middleware.
+
+Cacheable responses are read fully before RoundTrip returns, so that
+they can be stored; bodies larger than WithMaxBodyBytes are streamed
+through uncached. Transport errors are returned as is, never masked
+by a stale response.
+*/
+
+// DefaultRevalidationTTL is how long responses with validators are kept past freshness by default.
+const DefaultRevalidationTTL = time.Hour
+
+/*
+WithRevalidationTTL sets how long Transport keeps responses with an
+ETag or Last-Modified past their freshness, to revalidate them
+(default DefaultRevalidationTTL). 0 drops them once stale. Middleware
+does not revalidate, and ignores it.
+*/
+
+func WithRevalidationTTL(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.revalidationTTL = d
+	}
+}
+
+/*
+Transport is a caching http.RoundTripper (see transport.go).
+*/
+
+type Transport struct {
+	cache *tempuscache.Cache
+	base  http.RoundTripper
+	cfg   *config
+}
+
+// NewTransport returns a Transport caching in cache the responses of base (nil = http.DefaultTransport).
+func NewTransport(cache *tempuscache.Cache, base http.RoundTripper, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{cache: cache, base: base, cfg: newConfig(opts)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if bypassTransport(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	key := t.key(req)
+	var cached *entry
+	if v, ok := t.cache.Get(key); ok {
+		if e, ok := v.(*entry); ok && e.matches(req) {
+			cached = e
+		}
+	}
+	if cached != nil && !revalidate(req) && time.Now().Before(cached.Fresh) {
+		return cached.response(req, "HIT"), nil
+	}
+
+	out := req
+	if cached != nil {
+		out = conditional(req, cached)
+	}
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && out != req {
+		resp.Body.Close()
+		e := cached.refresh(req, resp.Header, t.cfg.defaultTTL)
+		t.store(key, e)
+		return e.response(req, "REVALIDATED"), nil
+	}
+	return t.record(req, key, resp)
+}
+
+/*
+key returns the cache key of req: the configured key, plus a digest
+of the Authorization header if there is one.
+*/
+
+func (t *Transport) key(req *http.Request) string {
+	key := t.cfg.key(req)
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		key += " auth:" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+/*
+record returns resp, caching it first if it is storable and its body
+is within the size limit.
+*/
+
+func (t *Transport) record(req *http.Request, key string, resp *http.Response) (*http.Response, error) {
+	if !storable(resp.StatusCode, resp.Header, false) {
+		return withCacheHeader(resp, "MISS"), nil
+	}
+	fresh := lifetime(resp.Header, t.cfg.defaultTTL, false)
+	if fresh <= 0 && !hasValidator(resp.Header) {
+		return withCacheHeader(resp, "MISS"), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.cfg.maxBodyBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.cfg.maxBodyBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return withCacheHeader(resp, "MISS"), nil
+	}
+	resp.Body.Close()
+
+	e := newEntry(req, resp.StatusCode, resp.Header.Clone(), body, fresh)
+	t.store(key, e)
+	return e.response(req, "MISS"), nil
+}
+
+// store caches e for its freshness, plus the revalidation period if it has a validator.
+func (t *Transport) store(key string, e *entry) {
+	ttl := time.Until(e.Fresh)
+	if hasValidator(e.Header) {
+		ttl = max(ttl, 0) + t.cfg.revalidationTTL
+	}
+	if ttl > 0 {
+		t.cache.SetWithCost(key, e, ttl, int64(len(e.Body)))
+	}
+}
+
+/*
+bypassTransport reports whether req is passed to the base transport
+uncached: unsafe methods, no-store requests, and requests already
+carrying validators of their own.
+*/
+
+func bypassTransport(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return true
+	}
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return true
+	}
+	_, noStore := directives(req.Header)["no-store"]
+	return noStore
+}
+
+// hasValidator reports whether a response with header can be revalidated.
+func hasValidator(header http.Header) bool {
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// conditional returns a copy of req revalidating e.
+func conditional(req *http.Request, e *entry) *http.Request {
+	if !hasValidator(e.Header) {
+		return req
+	}
+	out := req.Clone(req.Context())
+	if etag := e.Header.Get("ETag"); etag != "" {
+		out.Header.Set("If-None-Match", etag)
+	}
+	if modified := e.Header.Get("Last-Modified"); modified != "" {
+		out.Header.Set("If-Modified-Since", modified)
+	}
+	return out
+}
+
+/*
+refresh returns a copy of e revalidated by a 304 reply to req with
+header: the reply's headers replace the stored ones (RFC 9111,
+section 4.3.4), and freshness restarts, with fallback as the
+lifetime if they set none.
+*/
+
+func (e *entry) refresh(req *http.Request, header http.Header, fallback time.Duration) *entry {
+	merged := e.Header.Clone()
+	for name, values := range header {
+		merged[name] = values
+	}
+	return newEntry(req, e.Status, merged, e.Body, lifetime(merged, fallback, false))
+}
+
+// response returns e as the response to req, marked with cacheStatus.
+func (e *entry) response(req *http.Request, cacheStatus string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", e.age(time.Now()))
+	header.Set("X-Cache", cacheStatus)
+	return &http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// withCacheHeader marks an uncached resp with cacheStatus.
+func withCacheHeader(resp *http.Response, cacheStatus string) *http.Response {
+	resp.Header.Set("X-Cache", cacheStatus)
+	return resp
+}
@@ -0,0 +1,139 @@
+package tempushttp
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// origin is a RoundTripper answering with fixed headers, honoring If-None-Match.
+type origin struct {
+	header http.Header
+	body   string
+	calls  int
+	seen   []*http.Request
+}
+
+func (o *origin) RoundTrip(req *http.Request) (*http.Response, error) {
+	o.calls++
+	o.seen = append(o.seen, req)
+	status, body := http.StatusOK, o.body
+	if etag := o.header.Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == etag {
+		status, body = http.StatusNotModified, ""
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     o.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp, string(body)
+}
+
+func TestTransportServesFreshResponses(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+	o := &origin{header: http.Header{"Cache-Control": {"private, max-age=60"}}, body: "data"}
+	rt := NewTransport(cache, o)
+
+	first, _ := get(t, rt, "https://api.example.com/v1/items?page=1", nil)
+	second, body := get(t, rt, "https://api.example.com/v1/items?page=1", nil)
+	if o.calls != 1 {
+		t.Fatalf("expected one origin call, got %d", o.calls)
+	}
+	if first.Header.Get("X-Cache") != "MISS" || second.Header.Get("X-Cache") != "HIT" || body != "data" {
+		t.Fatalf("unexpected responses: %q, %q, %q", first.Header.Get("X-Cache"), second.Header.Get("X-Cache"), body)
+	}
+	if second.StatusCode != http.StatusOK || second.ContentLength != 4 {
+		t.Fatalf("unexpected cached response %+v", second)
+	}
+
+	get(t, rt, "https://api.example.com/v1/items?page=1", http.Header{"Authorization": {"Bearer other"}})
+	if o.calls != 2 {
+		t.Fatal("expected responses not to be shared across credentials")
+	}
+}
+
+func TestTransportRevalidatesWithETag(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+	o := &origin{header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}, body: "data"}
+	rt := NewTransport(cache, o)
+
+	get(t, rt, "https://api.example.com/config", nil)
+	resp, body := get(t, rt, "https://api.example.com/config", nil)
+	if o.calls != 2 {
+		t.Fatalf("expected no-cache responses to be revalidated, got %d calls", o.calls)
+	}
+	if got := o.seen[1].Header.Get("If-None-Match"); got != `"v1"` {
+		t.Fatalf("expected a conditional request, got If-None-Match %q", got)
+	}
+	if resp.Header.Get("X-Cache") != "REVALIDATED" || resp.StatusCode != http.StatusOK || body != "data" {
+		t.Fatalf("expected the cached body after a 304, got %d %q", resp.StatusCode, body)
+	}
+	if o.seen[0].Header.Get("If-None-Match") != "" {
+		t.Fatal("expected the caller's request not to be modified")
+	}
+
+	o.header.Set("Etag", `"v2"`)
+	o.body = "new data"
+	if _, body := get(t, rt, "https://api.example.com/config", nil); body != "new data" {
+		t.Fatalf("expected a changed resource to replace the entry, got %q", body)
+	}
+}
+
+func TestTransportBypass(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+	o := &origin{header: http.Header{"Cache-Control": {"max-age=60"}}, body: "data"}
+	rt := NewTransport(cache, o, WithMaxBodyBytes(2))
+
+	resp, body := get(t, rt, "https://api.example.com/big", nil)
+	get(t, rt, "https://api.example.com/big", nil)
+	if o.calls != 2 || body != "data" || resp.Header.Get("X-Cache") != "MISS" {
+		t.Fatalf("expected oversized bodies to stream through uncached, got %d calls, %q", o.calls, body)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/small", nil)
+	rt.RoundTrip(req)
+	rt.RoundTrip(req)
+	if o.calls != 4 {
+		t.Fatal("expected POST requests not to be cached")
+	}
+}
+
+func TestTransportDefaultTTL(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+	o := &origin{header: http.Header{"Vary": {"Accept"}}, body: "data"}
+	rt := NewTransport(cache, o, WithDefaultTTL(time.Minute))
+
+	json := http.Header{"Accept": {"application/json"}}
+	get(t, rt, "https://api.example.com/x", json)
+	get(t, rt, "https://api.example.com/x", json)
+	if o.calls != 1 {
+		t.Fatalf("expected the default TTL to apply, got %d calls", o.calls)
+	}
+	get(t, rt, "https://api.example.com/x", http.Header{"Accept": {"text/xml"}})
+	if o.calls != 2 {
+		t.Fatal("expected a different Vary header value to miss")
+	}
+}
@@ -0,0 +1,80 @@
+package tempusload
+
+import (
+	"math/bits"
+	"time"
+)
+
+/*
+histogram counts latencies in log-linear buckets: each power of two
+is split into 8 sub-buckets, so a reported percentile is within
+12.5% of the exact value. Recording is a few integer operations and
+never allocates.
+*/
+
+const subBuckets = 8
+
+type histogram struct {
+	counts [62 * subBuckets]uint64
+	total  uint64
+	max    time.Duration
+}
+
+// bucket returns the index of the bucket holding ns.
+func bucket(ns uint64) int {
+	if ns < subBuckets {
+		return int(ns)
+	}
+	exp := bits.Len64(ns) - 1 // ns is in [2^exp, 2^(exp+1)), exp >= 3
+	sub := (ns >> (exp - 3)) & (subBuckets - 1)
+	return (exp-2)*subBuckets + int(sub)
+}
+
+// upper returns the largest value that falls into bucket i.
+func upper(i int) time.Duration {
+	if i < subBuckets {
+		return time.Duration(i)
+	}
+	exp, sub := i/subBuckets+2, uint64(i%subBuckets)
+	low := uint64(1)<<exp | sub<<(exp-3)
+	return time.Duration(low + 1<<(exp-3) - 1)
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.counts[bucket(uint64(d))]++
+	h.total++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *histogram) merge(other *histogram) {
+	for i, n := range other.counts {
+		h.counts[i] += n
+	}
+	h.total += other.total
+	h.max = max(h.max, other.max)
+}
+
+// percentile returns the latency below which a fraction q of the
+// recorded values fall (0 for an empty histogram).
+func (h *histogram) percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.total))
+	if rank >= h.total {
+		rank = h.total - 1
+	}
+	var seen uint64
+	for i, n := range h.counts {
+		seen += n
+		if seen > rank {
+			return min(upper(i), h.max)
+		}
+	}
+	return h.max
+}
@@ -0,0 +1,46 @@
+package tempusload
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	for _, ns := range []uint64{0, 1, 7, 8, 9, 15, 16, 100, 1000, 123456789, 1 << 62} {
+		i := bucket(ns)
+		if up := upper(i); uint64(up) < ns {
+			t.Fatalf("bucket %d of %d has upper bound %d", i, ns, up)
+		}
+		if i > 0 && uint64(upper(i-1)) >= ns {
+			t.Fatalf("%d also fits the previous bucket %d", ns, i-1)
+		}
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	var h histogram
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, 500 * time.Microsecond},
+		{0.99, 990 * time.Microsecond},
+	} {
+		got := h.percentile(tc.q)
+		if got < tc.want || float64(got) > float64(tc.want)*1.125 {
+			t.Fatalf("p%v: expected %v within 12.5%%, got %v", tc.q*100, tc.want, got)
+		}
+	}
+	if got := h.percentile(1); got != time.Millisecond {
+		t.Fatalf("expected p100 to be the maximum, got %v", got)
+	}
+
+	var empty histogram
+	if got := empty.percentile(0.5); got != 0 {
+		t.Fatalf("expected 0 for an empty histogram, got %v", got)
+	}
+}
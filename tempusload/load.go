@@ -0,0 +1,359 @@
+/*
+Package tempusload drives a cache with a synthetic workload and
+reports throughput and latency percentiles.
+
+================================================================================
+WHY?
+================================================================================
+
+The package benchmarks cover a single hot key and strictly unique
+keys: useful for micro-optimizations, but far from real traffic.
+tempusload generates the access patterns caches are actually sized
+for (skewed popularity, uniform spread, sequential scans) at a
+chosen read/write mix and concurrency.
+
+================================================================================
+USAGE
+================================================================================
+
+	report, err := tempusload.Run(ctx, cache, tempusload.Config{
+	    Keys:         100000,
+	    Distribution: tempusload.Zipfian,
+	    ReadRatio:    0.95,
+	    Goroutines:   32,
+	    Duration:     10 * time.Second,
+	    Preload:      true,
+	})
+	fmt.Println(report)
+
+Any Target works: a *tempuscache.Cache, a Tiered cache, or a remote
+tempusclient.Client, which makes it a load generator for tempusd as
+well.
+
+================================================================================
+MEASUREMENT
+================================================================================
+
+Every operation is timed individually, which adds a few tens of
+nanoseconds per call; compare runs made with the same tool rather
+than with raw benchmark numbers. Latencies are bucketed with 12.5%
+precision.
+*/
+package tempusload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+Target is the cache under load. *tempuscache.Cache, *tempuscache.Tiered,
+and tempusclient.Client implement it.
+*/
+
+type Target interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+// Distribution selects which keys the workload accesses.
+type Distribution int
+
+const (
+	// Zipfian accesses a few keys very often and most rarely, like
+	// most real caches (the default).
+	Zipfian Distribution = iota
+
+	// Uniform accesses every key equally often.
+	Uniform
+
+	// Scan walks the key space sequentially, each goroutine from its
+	// own offset: the worst case for recency-based eviction.
+	Scan
+)
+
+// String returns the lower-case name of the distribution.
+func (d Distribution) String() string {
+	switch d {
+	case Zipfian:
+		return "zipfian"
+	case Uniform:
+		return "uniform"
+	case Scan:
+		return "scan"
+	}
+	return "unknown"
+}
+
+/*
+Config describes a workload. Zero fields take the defaults in
+parentheses.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Keys         -> Size of the key space (10000)
+Distribution -> Key access pattern (Zipfian)
+ZipfS        -> Skew of the Zipfian distribution, > 1 (1.1); higher
+                values concentrate accesses on fewer keys
+ReadRatio    -> Fraction of operations that are reads (0.9); negative
+                for a write-only workload
+Goroutines   -> Concurrent workers (GOMAXPROCS)
+Duration     -> How long to run (1s), unless Operations is set
+Operations   -> Total operations to run, split across workers
+TTL          -> TTL of written entries (0 = no expiration)
+ValueSize    -> Size of written values in bytes (64)
+Preload      -> Write every key once before measuring
+Seed         -> Random seed, for reproducible key sequences (0 = random)
+*/
+
+type Config struct {
+	Keys         int
+	Distribution Distribution
+	ZipfS        float64
+	ReadRatio    float64
+	Goroutines   int
+	Duration     time.Duration
+	Operations   int
+	TTL          time.Duration
+	ValueSize    int
+	Preload      bool
+	Seed         uint64
+}
+
+func (cfg *Config) applyDefaults() error {
+	if cfg.Keys <= 0 {
+		cfg.Keys = 10000
+	}
+	if cfg.ZipfS == 0 {
+		cfg.ZipfS = 1.1
+	}
+	if cfg.ZipfS <= 1 {
+		return errors.New("tempusload: ZipfS must be greater than 1")
+	}
+	if cfg.ReadRatio == 0 {
+		cfg.ReadRatio = 0.9
+	}
+	if cfg.ReadRatio > 1 {
+		return errors.New("tempusload: ReadRatio must be at most 1")
+	}
+	if cfg.Goroutines <= 0 {
+		cfg.Goroutines = runtime.GOMAXPROCS(0)
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = time.Second
+	}
+	if cfg.ValueSize <= 0 {
+		cfg.ValueSize = 64
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = rand.Uint64()
+	}
+	return nil
+}
+
+/*
+Latency summarizes the distribution of operation latencies.
+*/
+
+type Latency struct {
+	P50, P90, P99, P999, Max time.Duration
+}
+
+/*
+Report is the outcome of a Run.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Config     -> The workload, with defaults applied
+Elapsed    -> Measured wall-clock duration (excluding Preload)
+Operations -> Operations completed
+Reads      -> Get calls
+Writes     -> Set calls
+Hits       -> Get calls that found a value
+Throughput -> Operations per second
+Read       -> Latency of Get calls
+Write      -> Latency of Set calls
+*/
+
+type Report struct {
+	Config     Config
+	Elapsed    time.Duration
+	Operations uint64
+	Reads      uint64
+	Writes     uint64
+	Hits       uint64
+	Throughput float64
+	Read       Latency
+	Write      Latency
+}
+
+// HitRatio returns Hits / Reads, or 0 without reads.
+func (r Report) HitRatio() float64 {
+	if r.Reads == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Reads)
+}
+
+// String formats the report on a few human-readable lines.
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"%s keys=%d reads=%.0f%% goroutines=%d: %d ops in %v (%.0f ops/s), hit ratio %.3f\n"+
+			"  get p50=%v p90=%v p99=%v p99.9=%v max=%v\n"+
+			"  set p50=%v p90=%v p99=%v p99.9=%v max=%v",
+		r.Config.Distribution, r.Config.Keys, max(r.Config.ReadRatio, 0)*100, r.Config.Goroutines,
+		r.Operations, r.Elapsed.Round(time.Millisecond), r.Throughput, r.HitRatio(),
+		r.Read.P50, r.Read.P90, r.Read.P99, r.Read.P999, r.Read.Max,
+		r.Write.P50, r.Write.P90, r.Write.P99, r.Write.P999, r.Write.Max)
+}
+
+/*
+Run applies the workload described by cfg to target and reports the
+results. It stops early, with the results so far, when ctx ends.
+*/
+
+func Run(ctx context.Context, target Target, cfg Config) (Report, error) {
+	if err := cfg.applyDefaults(); err != nil {
+		return Report{}, err
+	}
+
+	keys := make([]string, cfg.Keys)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	value := make([]byte, cfg.ValueSize)
+	if cfg.Preload {
+		for _, key := range keys {
+			target.Set(key, value, cfg.TTL)
+		}
+	}
+
+	if cfg.Operations == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	workers := make([]*worker, cfg.Goroutines)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range workers {
+		w := newWorker(cfg, i, keys, value)
+		if cfg.Operations > 0 {
+			w.budget = cfg.Operations / cfg.Goroutines
+			if i < cfg.Operations%cfg.Goroutines {
+				w.budget++
+			}
+		}
+		workers[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx, target)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := Report{Config: cfg, Elapsed: elapsed}
+	var reads, writes histogram
+	for _, w := range workers {
+		report.Hits += w.hits
+		reads.merge(&w.reads)
+		writes.merge(&w.writes)
+	}
+	report.Reads, report.Writes = reads.total, writes.total
+	report.Operations = reads.total + writes.total
+	report.Throughput = float64(report.Operations) / elapsed.Seconds()
+	report.Read = latency(&reads)
+	report.Write = latency(&writes)
+	return report, nil
+}
+
+func latency(h *histogram) Latency {
+	return Latency{
+		P50:  h.percentile(0.50),
+		P90:  h.percentile(0.90),
+		P99:  h.percentile(0.99),
+		P999: h.percentile(0.999),
+		Max:  h.max,
+	}
+}
+
+/*
+worker is one load-generating goroutine. It owns its random source
+and histograms, so workers share nothing while running.
+*/
+
+type worker struct {
+	cfg    Config
+	rng    *rand.Rand
+	zipf   *rand.Zipf
+	next   int // next key index for Scan
+	keys   []string
+	value  []byte
+	budget int // operations left (-1 = until ctx ends)
+	hits   uint64
+	reads  histogram
+	writes histogram
+}
+
+func newWorker(cfg Config, id int, keys []string, value []byte) *worker {
+	rng := rand.New(rand.NewPCG(cfg.Seed, uint64(id)))
+	w := &worker{cfg: cfg, rng: rng, keys: keys, value: value, budget: -1}
+	w.next = id * len(keys) / cfg.Goroutines
+	if cfg.Distribution == Zipfian {
+		w.zipf = rand.NewZipf(rng, cfg.ZipfS, 1, uint64(len(keys)-1))
+	}
+	return w
+}
+
+func (w *worker) key() string {
+	switch w.cfg.Distribution {
+	case Uniform:
+		return w.keys[w.rng.IntN(len(w.keys))]
+	case Scan:
+		key := w.keys[w.next]
+		w.next = (w.next + 1) % len(w.keys)
+		return key
+	}
+	return w.keys[w.zipf.Uint64()]
+}
+
+// checkEvery is how many operations run between context checks.
+const checkEvery = 64
+
+func (w *worker) run(ctx context.Context, target Target) {
+	for i := 0; w.budget != 0; i++ {
+		if w.budget > 0 {
+			w.budget--
+		}
+		if i%checkEvery == 0 && ctx.Err() != nil {
+			return
+		}
+
+		key := w.key()
+		if w.rng.Float64() < w.cfg.ReadRatio {
+			start := time.Now()
+			_, found := target.Get(key)
+			w.reads.record(time.Since(start))
+			if found {
+				w.hits++
+			}
+		} else {
+			start := time.Now()
+			target.Set(key, w.value, w.cfg.TTL)
+			w.writes.record(time.Since(start))
+		}
+	}
+}
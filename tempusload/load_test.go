@@ -0,0 +1,67 @@
+package tempusload
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func TestRunOperations(t *testing.T) {
+	for _, dist := range []Distribution{Zipfian, Uniform, Scan} {
+		cache := tempuscache.New()
+		report, err := Run(context.Background(), cache, Config{
+			Keys:         1000,
+			Distribution: dist,
+			ReadRatio:    0.8,
+			Goroutines:   3,
+			Operations:   10000,
+			Preload:      true,
+			Seed:         1,
+		})
+		cache.Stop()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Operations != 10000 || report.Reads+report.Writes != 10000 {
+			t.Fatalf("%v: expected exactly 10000 operations, got %+v", dist, report)
+		}
+		if report.Reads < 7500 || report.Reads > 8500 {
+			t.Fatalf("%v: expected about 80%% reads, got %d", dist, report.Reads)
+		}
+		if report.HitRatio() != 1 {
+			t.Fatalf("%v: expected every read of a preloaded cache to hit, got %v", dist, report.HitRatio())
+		}
+		if r := report.Read; r.P50 > r.P99 || r.P99 > r.Max || r.Max == 0 {
+			t.Fatalf("%v: inconsistent read latencies %+v", dist, r)
+		}
+		if !strings.Contains(report.String(), dist.String()) {
+			t.Fatalf("expected the report to name the distribution, got %q", report)
+		}
+	}
+}
+
+func TestRunDuration(t *testing.T) {
+	cache := tempuscache.New(tempuscache.WithMaxEntries(100))
+	defer cache.Stop()
+
+	report, err := Run(context.Background(), cache, Config{Duration: 50 * time.Millisecond, Goroutines: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Operations == 0 || report.Elapsed < 50*time.Millisecond || report.Elapsed > time.Second {
+		t.Fatalf("expected a run of about 50ms, got %d ops in %v", report.Operations, report.Elapsed)
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+
+	if _, err := Run(context.Background(), cache, Config{ZipfS: 0.5}); err == nil {
+		t.Fatal("expected an error for ZipfS <= 1")
+	}
+}
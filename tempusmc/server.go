@@ -0,0 +1,677 @@
+/*
+Package tempusmc serves a TempusCache over the memcached ASCII
+protocol, so services using memcached clients can be pointed at it
+unchanged.
+
+================================================================================
+USAGE
+================================================================================
+
+	srv := tempusmc.New(cache, tempusmc.WithAddr("127.0.0.1:11211"))
+	go srv.ListenAndServe()
+	...
+	srv.Shutdown(ctx)
+
+================================================================================
+SUPPORTED COMMANDS
+================================================================================
+
+	get <key>*
+	gets <key>*
+	set|add|replace <key> <flags> <exptime> <bytes> [noreply]
+	cas <key> <flags> <exptime> <bytes> <cas unique> [noreply]
+	delete <key> [noreply]
+	touch <key> <exptime> [noreply]
+	flush_all [noreply]
+	stats
+	version
+	quit
+
+Unknown commands receive "ERROR"; malformed ones "CLIENT_ERROR".
+
+================================================================================
+SEMANTICS
+================================================================================
+
+  - Values written over the protocol are stored as *Item, which keeps
+    the client flags and a CAS unique alongside the data. Values
+    written by Go code in the same process are returned with flags 0
+    and CAS unique 0; strings and []byte are returned as-is, anything
+    else is formatted with fmt.
+  - exptime follows memcached: 0 never expires (clearing the
+    deadline of a replaced item), up to 30 days is relative seconds,
+    larger values are absolute Unix timestamps, and negative values
+    expire the item immediately.
+  - cas is atomic: the CAS unique is compared and the item swapped
+    in a single Cache.Compute. It compares the unique stored in the
+    item, so it also works when the cache serializes values.
+
+================================================================================
+PERSISTENCE
+================================================================================
+
+When the cache uses the default gob codec for snapshots or the
+append-only log, *Item values are registered by this package and
+round-trip automatically.
+
+================================================================================
+SECURITY
+================================================================================
+
+There is no authentication, and the server binds to 127.0.0.1:11211
+by default. Do not expose it to untrusted networks.
+*/
+package tempusmc
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultAddr is the listen address used when WithAddr is not given.
+const DefaultAddr = "127.0.0.1:11211"
+
+// Version is reported by the "version" command.
+const Version = "tempuscache-2"
+
+// ErrServerClosed is returned by Serve after Shutdown has been called.
+var ErrServerClosed = errors.New("tempusmc: server closed")
+
+var errLineTooLong = errors.New("tempusmc: line too long")
+
+const (
+	maxKeyLen   = 250
+	maxItemSize = 1 << 20
+	maxLineLen  = 2048
+
+	// relativeExpiryLimit is memcached's cutoff between relative
+	// seconds and absolute Unix timestamps.
+	relativeExpiryLimit = 60 * 60 * 24 * 30
+)
+
+func init() {
+	gob.Register(&Item{})
+}
+
+/*
+Item is the value stored for keys written over the memcached protocol.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Flags -> Opaque client flags, returned unchanged by get
+Data  -> Value bytes
+CAS   -> Unique version assigned on every store, used by gets/cas
+*/
+
+type Item struct {
+	Flags uint32
+	Data  []byte
+	CAS   uint64
+}
+
+/*
+Option configures a Server.
+*/
+
+type Option func(*Server)
+
+// WithAddr sets the TCP listen address (default DefaultAddr).
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithIdleTimeout closes connections that send no command for d
+// (default 0 = never).
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.idleTimeout = d
+	}
+}
+
+/*
+Server serves the memcached ASCII protocol for a single Cache.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cache       -> Cache being served
+addr        -> Listen address used by ListenAndServe
+idleTimeout -> Per-command read deadline (0 = none)
+cas         -> Source of CAS uniques
+mu          -> Protects listeners, conns, and closed
+listeners   -> Listeners being served
+conns       -> Open client connections
+closed      -> Set by Shutdown; rejects further Serve calls
+wg          -> Tracks connection handlers so Shutdown can wait for them
+*/
+
+type Server struct {
+	cache       *tempuscache.Cache
+	addr        string
+	idleTimeout time.Duration
+	cas         atomic.Uint64
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// New creates a Server for cache. It does not start listening.
+func New(cache *tempuscache.Cache, opts ...Option) *Server {
+	s := &Server{
+		cache:     cache,
+		addr:      DefaultAddr,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+/*
+ListenAndServe listens on the configured address and serves clients
+until Shutdown is called, in which case it returns nil.
+*/
+
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+/*
+Serve accepts connections on ln until Shutdown is called, in which
+case it returns nil. ln is closed on return.
+*/
+
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+/*
+Shutdown stops accepting connections, closes client connections,
+and waits for their handlers to return or for ctx to be done.
+
+A command already being executed completes before its connection
+closes. The cache itself is not stopped.
+*/
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	for conn := range s.conns {
+		// Unblock handlers waiting for the next command; a command
+		// that is mid-execution still gets to write its reply.
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+/*
+handle reads and executes commands from one client until it
+disconnects, sends quit, or the server shuts down.
+*/
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		if s.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+		// Checked after arming the deadline so it cannot overwrite
+		// the one set by a concurrent Shutdown.
+		if s.isClosed() {
+			return
+		}
+
+		line, err := readLine(r)
+		if err == errLineTooLong {
+			w.WriteString("CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			w.WriteString("ERROR\r\n")
+			w.Flush()
+			continue
+		}
+
+		quit, err := s.exec(r, w, fields)
+		if err != nil {
+			return
+		}
+
+		// Pipelined commands are answered with a single write.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+/*
+exec runs one command and writes its reply.
+
+RETURNS:
+- quit -> The client asked to close the connection
+- err  -> The connection is unusable (e.g. a truncated data block)
+*/
+
+func (s *Server) exec(r *bufio.Reader, w *bufio.Writer, fields []string) (quit bool, err error) {
+	cmd, args := fields[0], fields[1:]
+
+	switch cmd {
+	case "get", "gets":
+		if len(args) == 0 {
+			w.WriteString("ERROR\r\n")
+			break
+		}
+		for _, key := range args {
+			s.writeValue(w, key, cmd == "gets")
+		}
+		w.WriteString("END\r\n")
+
+	case "set", "add", "replace", "cas":
+		return false, s.store(r, w, cmd, args)
+
+	case "delete":
+		noreply := trimNoreply(&args)
+		if len(args) != 1 {
+			reply(w, noreply, "CLIENT_ERROR bad command line format")
+			break
+		}
+		if s.cache.DeleteMany(args[0]) == 1 {
+			reply(w, noreply, "DELETED")
+		} else {
+			reply(w, noreply, "NOT_FOUND")
+		}
+
+	case "touch":
+		noreply := trimNoreply(&args)
+		if len(args) != 2 {
+			reply(w, noreply, "CLIENT_ERROR bad command line format")
+			break
+		}
+		exptime, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			reply(w, noreply, "CLIENT_ERROR bad command line format")
+			break
+		}
+		ttl, expired := parseExptime(exptime)
+		var touched bool
+		if expired {
+			touched = s.cache.DeleteMany(args[0]) == 1
+		} else {
+			touched = s.cache.Expire(args[0], ttl)
+		}
+		if touched {
+			reply(w, noreply, "TOUCHED")
+		} else {
+			reply(w, noreply, "NOT_FOUND")
+		}
+
+	case "flush_all":
+		noreply := trimNoreply(&args)
+		if len(args) > 0 && args[0] != "0" {
+			reply(w, noreply, "CLIENT_ERROR delayed flush_all is not supported")
+			break
+		}
+		s.cache.Flush()
+		reply(w, noreply, "OK")
+
+	case "stats":
+		s.writeStats(w)
+
+	case "version":
+		w.WriteString("VERSION " + Version + "\r\n")
+
+	case "quit":
+		return true, nil
+
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+	return false, nil
+}
+
+/*
+store implements set, add, replace, and cas.
+
+Once the byte count has been parsed, the data block is consumed
+even if the rest of the command line is rejected, so the connection
+stays in sync with the client.
+*/
+
+func (s *Server) store(r *bufio.Reader, w *bufio.Writer, cmd string, args []string) error {
+	noreply := trimNoreply(&args)
+
+	want := 4
+	if cmd == "cas" {
+		want = 5
+	}
+	if len(args) != want {
+		reply(w, noreply, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+
+	size, err := strconv.Atoi(args[3])
+	if err != nil || size < 0 {
+		reply(w, noreply, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+	if size > maxItemSize {
+		// Too large to buffer; the stream cannot be resynchronized.
+		reply(w, false, "SERVER_ERROR object too large for cache")
+		w.Flush()
+		return errors.New("tempusmc: object too large")
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if data[size] != '\r' || data[size+1] != '\n' {
+		reply(w, noreply, "CLIENT_ERROR bad data chunk")
+		return nil
+	}
+
+	key := args[0]
+	flags, err1 := strconv.ParseUint(args[1], 10, 32)
+	exptime, err2 := strconv.ParseInt(args[2], 10, 64)
+	if err1 != nil || err2 != nil || !validKey(key) {
+		reply(w, noreply, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+
+	ttl, expired := parseExptime(exptime)
+	item := &Item{Flags: uint32(flags), Data: data[:size], CAS: s.cas.Add(1)}
+
+	var stored bool
+	switch cmd {
+	case "set":
+		if expired {
+			s.cache.Delete(key)
+		} else {
+			s.cache.Set(key, item, ttl)
+		}
+		stored = true
+
+	case "add":
+		_, loaded := s.cache.GetOrSet(key, item, ttl)
+		stored = !loaded
+		if stored && expired {
+			s.cache.Delete(key)
+		}
+
+	case "replace":
+		stored = s.cache.Replace(key, item, ttl)
+		if stored && expired {
+			s.cache.Delete(key)
+		}
+
+	case "cas":
+		unique, err := strconv.ParseUint(args[4], 10, 64)
+		if err != nil {
+			reply(w, noreply, "CLIENT_ERROR bad command line format")
+			return nil
+		}
+		found := false
+		_, swapped := s.cache.Compute(key, func(cur interface{}, exists bool) (interface{}, time.Duration, bool) {
+			found = exists
+			old, ok := cur.(*Item)
+			return item, ttl, ok && old.CAS == unique
+		})
+		switch {
+		case !found:
+			reply(w, noreply, "NOT_FOUND")
+			return nil
+		case !swapped:
+			reply(w, noreply, "EXISTS")
+			return nil
+		}
+		if expired {
+			s.cache.Delete(key)
+		}
+		stored = true
+	}
+
+	if stored {
+		reply(w, noreply, "STORED")
+	} else {
+		reply(w, noreply, "NOT_STORED")
+	}
+	return nil
+}
+
+// writeValue writes one VALUE block for key, if it is present.
+func (s *Server) writeValue(w *bufio.Writer, key string, withCAS bool) {
+	val, found := s.cache.Get(key)
+	if !found {
+		return
+	}
+
+	var (
+		flags uint32
+		cas   uint64
+		data  []byte
+	)
+	switch v := val.(type) {
+	case *Item:
+		flags, cas, data = v.Flags, v.CAS, v.Data
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		data = []byte(fmt.Sprint(v))
+	}
+
+	w.WriteString("VALUE " + key + " " + strconv.FormatUint(uint64(flags), 10) + " " + strconv.Itoa(len(data)))
+	if withCAS {
+		w.WriteString(" " + strconv.FormatUint(cas, 10))
+	}
+	w.WriteString("\r\n")
+	w.Write(data)
+	w.WriteString("\r\n")
+}
+
+// writeStats writes a memcached-style stats report from Stats().
+func (s *Server) writeStats(w *bufio.Writer) {
+	st := s.cache.Stats()
+
+	stat := func(name string, v interface{}) {
+		fmt.Fprintf(w, "STAT %s %v\r\n", name, v)
+	}
+	stat("uptime", int64(st.Uptime.Seconds()))
+	stat("time", time.Now().Unix())
+	stat("version", Version)
+	stat("curr_items", st.Entries)
+	stat("bytes", st.EstimatedBytes)
+	stat("limit_maxitems", s.cache.Capacity())
+	stat("get_hits", st.Hits)
+	stat("get_misses", st.Misses)
+	stat("cmd_set", st.Sets)
+	stat("evictions", st.Evictions)
+	stat("expired_unfetched", st.ExpiredByJanitor)
+	stat("reclaimed", st.Expirations)
+	w.WriteString("END\r\n")
+}
+
+/*
+parseExptime converts a memcached exptime into a TTL.
+
+RETURNS:
+- ttl     -> Relative TTL (tempuscache.NoExpiration for exptime 0,
+             so a store also clears the deadline of a replaced item)
+- expired -> The item should expire immediately
+*/
+
+func parseExptime(exptime int64) (ttl time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return tempuscache.NoExpiration, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= relativeExpiryLimit:
+		return time.Duration(exptime) * time.Second, false
+	}
+
+	ttl = time.Until(time.Unix(exptime, 0))
+	if ttl <= 0 {
+		return 0, true
+	}
+	return ttl, false
+}
+
+/*
+readLine reads one command line, including its "\n". Lines longer
+than maxLineLen (memcached's limit) return errLineTooLong, so a client
+cannot grow the buffer without bound.
+*/
+
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLineLen {
+			return "", errLineTooLong
+		}
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+// trimNoreply removes a trailing "noreply" argument and reports it.
+func trimNoreply(args *[]string) bool {
+	a := *args
+	if len(a) > 0 && a[len(a)-1] == "noreply" {
+		*args = a[:len(a)-1]
+		return true
+	}
+	return false
+}
+
+// validKey reports whether key satisfies memcached's key rules.
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func reply(w *bufio.Writer, noreply bool, msg string) {
+	if !noreply {
+		w.WriteString(msg + "\r\n")
+	}
+}
@@ -0,0 +1,183 @@
+package tempusmc
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func startServer(t *testing.T, cache *tempuscache.Cache) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(cache)
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, bufio.NewReader(conn)
+}
+
+func send(conn net.Conn, lines ...string) {
+	conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+}
+
+func expect(t *testing.T, r *bufio.Reader, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != w+"\r\n" {
+			t.Fatalf("expected %q, got %q", w, line)
+		}
+	}
+}
+
+func TestMemcachedStorage(t *testing.T) {
+	cache := tempuscache.New()
+	conn, r := startServer(t, cache)
+
+	send(conn, "set a 5 0 5", "hello")
+	expect(t, r, "STORED")
+	send(conn, "get a missing")
+	expect(t, r, "VALUE a 5 5", "hello", "END")
+
+	send(conn, "add a 0 0 1", "x")
+	expect(t, r, "NOT_STORED")
+	send(conn, "add b 0 0 1", "x")
+	expect(t, r, "STORED")
+	send(conn, "replace missing 0 0 1", "x")
+	expect(t, r, "NOT_STORED")
+	send(conn, "replace b 0 0 1", "y")
+	expect(t, r, "STORED")
+
+	send(conn, "delete b")
+	expect(t, r, "DELETED")
+	send(conn, "delete b")
+	expect(t, r, "NOT_FOUND")
+
+	send(conn, "set quiet 0 0 1 noreply", "q", "get quiet")
+	expect(t, r, "VALUE quiet 0 1", "q", "END")
+
+	send(conn, "set gone 0 -1 1", "g", "get gone")
+	expect(t, r, "STORED", "END")
+
+	// exptime 0 never expires, even over an item that did.
+	send(conn, "set t 0 100 1", "t", "set t 0 0 1", "u")
+	expect(t, r, "STORED", "STORED")
+	if ttl, found := cache.TTL("t"); !found || ttl != 0 {
+		t.Fatalf("expected exptime 0 to clear the TTL, got %v (%v)", ttl, found)
+	}
+
+	cache.Set("native", 42, 0)
+	send(conn, "get native")
+	expect(t, r, "VALUE native 0 2", "42", "END")
+}
+
+func TestMemcachedCAS(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) { testCAS(t, tempuscache.New()) })
+	t.Run("Serialized", func(t *testing.T) {
+		testCAS(t, tempuscache.New(tempuscache.WithSerializer(tempuscache.GobSerializer())))
+	})
+}
+
+func testCAS(t *testing.T, cache *tempuscache.Cache) {
+	conn, r := startServer(t, cache)
+
+	send(conn, "set k 0 0 1", "a")
+	expect(t, r, "STORED")
+
+	send(conn, "gets k")
+	header, _ := r.ReadString('\n')
+	expect(t, r, "a", "END")
+	fields := strings.Fields(header)
+	if len(fields) != 5 {
+		t.Fatalf("expected CAS unique in %q", header)
+	}
+	unique := fields[4]
+
+	send(conn, "cas k 0 0 1 "+unique, "b")
+	expect(t, r, "STORED")
+	send(conn, "cas k 0 0 1 "+unique, "c")
+	expect(t, r, "EXISTS")
+	send(conn, "cas missing 0 0 1 1", "c")
+	expect(t, r, "NOT_FOUND")
+	send(conn, "get k")
+	expect(t, r, "VALUE k 0 1", "b", "END")
+}
+
+func TestMemcachedTouchAndMisc(t *testing.T) {
+	cache := tempuscache.New()
+	conn, r := startServer(t, cache)
+
+	send(conn, "set k 0 0 1", "a")
+	expect(t, r, "STORED")
+	send(conn, "touch k 100")
+	expect(t, r, "TOUCHED")
+	if ttl, _ := cache.TTL("k"); ttl <= 0 || ttl > 100*time.Second {
+		t.Fatalf("unexpected TTL after touch: %v", ttl)
+	}
+	send(conn, "touch missing 100")
+	expect(t, r, "NOT_FOUND")
+
+	send(conn, "bogus")
+	expect(t, r, "ERROR")
+	send(conn, "set k x 0 1", "a")
+	expect(t, r, "CLIENT_ERROR bad command line format")
+
+	send(conn, "version")
+	expect(t, r, "VERSION "+Version)
+
+	send(conn, "flush_all")
+	expect(t, r, "OK")
+	if cache.Len() != 0 {
+		t.Fatal("expected flush_all to empty the cache")
+	}
+
+	send(conn, "stats")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "END\r\n" {
+			break
+		}
+		if !strings.HasPrefix(line, "STAT ") {
+			t.Fatalf("unexpected stats line %q", line)
+		}
+	}
+
+	send(conn, "quit")
+	if _, err := r.ReadByte(); err == nil {
+		t.Fatal("expected connection to be closed after quit")
+	}
+}
+
+func TestMemcachedLineTooLong(t *testing.T) {
+	conn, r := startServer(t, tempuscache.New())
+
+	send(conn, "get "+strings.Repeat("k", maxLineLen))
+	expect(t, r, "CLIENT_ERROR line too long")
+	if _, err := r.ReadByte(); err == nil {
+		t.Fatal("expected connection to be closed after an oversized line")
+	}
+}
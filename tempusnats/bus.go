@@ -0,0 +1,486 @@
+/*
+Package tempusnats carries TempusCache invalidations over NATS.
+
+================================================================================
+USAGE
+================================================================================
+
+	bus := tempusnats.New("nats:4222",
+	    tempusnats.WithNamespace("sessions"),
+	    tempusnats.WithLogger(logger))
+	defer bus.Close()
+
+	cache := tempuscache.New(tempuscache.WithInvalidation(bus, bus))
+
+================================================================================
+SUBJECTS
+================================================================================
+
+Each namespace gets its own subject:
+
+	tempuscache.<namespace>.invalidate
+
+so independent caches (e.g. "sessions" and "users") can share one
+NATS deployment without invalidating each other. The default
+namespace is "default".
+
+================================================================================
+WIRE FORMAT
+================================================================================
+
+Each invalidation is one PUB of a JSON document:
+
+	{"origin": "<node id>", "keys": ["a", "b"], "all": false}
+
+The package speaks the NATS client protocol directly and has no
+dependency on a NATS client library.
+
+================================================================================
+GRACEFUL DEGRADATION
+================================================================================
+
+The broker being down never affects local cache operations:
+
+  - Broadcast returns an error (which the cache logs) and redials on
+    the next call; the invalidation is dropped.
+  - The subscription is retried in the background with exponential
+    backoff, logging every failure, until Close or unsubscribe.
+  - WithFlushOnReconnect makes every resubscription deliver an All
+    invalidation, for deployments that prefer a cold cache to a
+    possibly stale one after an outage.
+*/
+package tempusnats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultNamespace is the namespace used when WithNamespace is not given.
+const DefaultNamespace = "default"
+
+// ErrClosed is returned by Broadcast and Subscribe after Close.
+var ErrClosed = errors.New("tempusnats: bus closed")
+
+// maxPayload bounds incoming message sizes.
+const maxPayload = 64 << 20
+
+/*
+Option configures a Bus.
+*/
+
+type Option func(*Bus)
+
+// WithNamespace selects the subject namespace (default DefaultNamespace).
+func WithNamespace(ns string) Option {
+	return func(b *Bus) {
+		b.subject = Subject(ns)
+	}
+}
+
+// WithToken authenticates with a NATS token.
+func WithToken(token string) Option {
+	return func(b *Bus) {
+		b.auth.Token = token
+	}
+}
+
+// WithUserInfo authenticates with a NATS user and password.
+func WithUserInfo(user, password string) Option {
+	return func(b *Bus) {
+		b.auth.User, b.auth.Pass = user, password
+	}
+}
+
+// WithDialTimeout bounds connection establishment (default 5s).
+func WithDialTimeout(d time.Duration) Option {
+	return func(b *Bus) {
+		b.dialTimeout = d
+	}
+}
+
+// WithBackoff sets the initial and maximum delay between subscription
+// reconnect attempts (default 100ms and 10s).
+func WithBackoff(initial, maximum time.Duration) Option {
+	return func(b *Bus) {
+		b.backoffMin, b.backoffMax = initial, maximum
+	}
+}
+
+// WithFlushOnReconnect delivers an All invalidation every time the
+// subscription is re-established after a disconnect.
+func WithFlushOnReconnect(enabled bool) Option {
+	return func(b *Bus) {
+		b.flushOnReconnect = enabled
+	}
+}
+
+// WithLogger logs broker failures and undecodable messages.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Bus) {
+		b.log = logger
+	}
+}
+
+// Subject returns the invalidation subject for namespace ns.
+func Subject(ns string) string {
+	return "tempuscache." + ns + ".invalidate"
+}
+
+/*
+Bus implements tempuscache.Broadcaster and tempuscache.Subscriber
+on top of a NATS server.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+addr             -> NATS server address
+subject          -> Invalidation subject (see Subject)
+auth             -> Credentials sent in CONNECT
+dialTimeout      -> Connection establishment bound
+backoffMin/Max   -> Subscription reconnect backoff bounds
+flushOnReconnect -> Deliver an All invalidation after resubscribing
+log              -> Logger (never nil)
+mu               -> Protects pub, subs, and closed
+pub              -> Publishing connection (nil until first use or after a failure)
+subs             -> Stop functions of running subscriptions
+closed           -> Set by Close
+*/
+
+type Bus struct {
+	addr             string
+	subject          string
+	auth             connectOptions
+	dialTimeout      time.Duration
+	backoffMin       time.Duration
+	backoffMax       time.Duration
+	flushOnReconnect bool
+	log              *slog.Logger
+
+	mu     sync.Mutex
+	pub    *conn
+	subs   map[int]func()
+	nextID int
+	closed bool
+}
+
+// New creates a Bus for the NATS server at addr. No connection is
+// made until the first Broadcast or Subscribe.
+func New(addr string, opts ...Option) *Bus {
+	b := &Bus{
+		addr:        addr,
+		subject:     Subject(DefaultNamespace),
+		dialTimeout: 5 * time.Second,
+		backoffMin:  100 * time.Millisecond,
+		backoffMax:  10 * time.Second,
+		log:         slog.New(slog.DiscardHandler),
+		subs:        make(map[int]func()),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+type message struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	All    bool     `json:"all,omitempty"`
+}
+
+type connectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Token    string `json:"auth_token,omitempty"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+/*
+Broadcast publishes msg on the subject.
+
+The message is followed by a PING, and Broadcast waits for the PONG,
+so a nil error means the server has accepted the message.
+*/
+
+func (b *Bus) Broadcast(ctx context.Context, msg tempuscache.Invalidation) error {
+	payload, err := json.Marshal(message{Origin: msg.Origin, Keys: msg.Keys, All: msg.All})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if b.pub == nil {
+		if b.pub, err = b.dial(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline, _ := ctx.Deadline()
+	b.pub.nc.SetDeadline(deadline)
+
+	b.pub.w.WriteString("PUB " + b.subject + " " + strconv.Itoa(len(payload)) + "\r\n")
+	b.pub.w.Write(payload)
+	b.pub.w.WriteString("\r\nPING\r\n")
+	if err = b.pub.w.Flush(); err == nil {
+		err = b.pub.awaitPong()
+	}
+	if err != nil {
+		b.pub.nc.Close()
+		b.pub = nil
+	}
+	return err
+}
+
+/*
+Subscribe starts a background subscription delivering every
+message published on the subject to handle, reconnecting as needed.
+*/
+
+func (b *Bus) Subscribe(handle func(tempuscache.Invalidation)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.subscribeLoop(ctx, handle)
+	}()
+
+	id := b.nextID
+	b.nextID++
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+	b.subs[id] = stop
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+		stop()
+	}, nil
+}
+
+/*
+Close stops all subscriptions and closes the publishing connection.
+*/
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	if b.pub != nil {
+		b.pub.nc.Close()
+		b.pub = nil
+	}
+	b.mu.Unlock()
+
+	for _, stop := range subs {
+		stop()
+	}
+	return nil
+}
+
+// subscribeLoop keeps one subscription alive until ctx is cancelled.
+func (b *Bus) subscribeLoop(ctx context.Context, handle func(tempuscache.Invalidation)) {
+	backoff := b.backoffMin
+	subscribed := false
+
+	for ctx.Err() == nil {
+		err := b.subscribeOnce(ctx, func() {
+			if subscribed && b.flushOnReconnect {
+				handle(tempuscache.Invalidation{Origin: "tempusnats", All: true})
+			}
+			subscribed = true
+			backoff = b.backoffMin
+		}, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		b.log.Warn("tempusnats: subscription lost, serving local cache only until reconnected",
+			"addr", b.addr, "subject", b.subject, "retry_in", backoff, "err", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, b.backoffMax)
+	}
+}
+
+/*
+subscribeOnce dials, subscribes, and delivers messages until the
+connection fails or ctx is cancelled. ready is called once the
+subscription is confirmed.
+*/
+
+func (b *Bus) subscribeOnce(ctx context.Context, ready func(), handle func(tempuscache.Invalidation)) error {
+	cn, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer cn.nc.Close()
+
+	stop := context.AfterFunc(ctx, func() { cn.nc.Close() })
+	defer stop()
+
+	cn.w.WriteString("SUB " + b.subject + " 1\r\nPING\r\n")
+	if err := cn.w.Flush(); err != nil {
+		return err
+	}
+	if err := cn.awaitPong(); err != nil {
+		return err
+	}
+	ready()
+
+	for {
+		line, err := cn.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PING":
+			cn.w.WriteString("PONG\r\n")
+			if err := cn.w.Flush(); err != nil {
+				return err
+			}
+
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("tempusnats: " + line)
+
+		case strings.HasPrefix(line, "MSG "):
+			payload, err := cn.readPayload(line)
+			if err != nil {
+				return err
+			}
+			var m message
+			if err := json.Unmarshal(payload, &m); err != nil {
+				b.log.Warn("tempusnats: ignoring undecodable message", "subject", b.subject, "err", err)
+				continue
+			}
+			handle(tempuscache.Invalidation{Origin: m.Origin, Keys: m.Keys, All: m.All})
+		}
+	}
+}
+
+type conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+}
+
+/*
+dial connects, reads the server INFO, and sends CONNECT.
+*/
+
+func (b *Bus) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: b.dialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{nc: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	nc.SetDeadline(time.Now().Add(b.dialTimeout))
+	line, err := cn.readLine()
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		nc.Close()
+		return nil, errors.New("tempusnats: expected INFO, got " + line)
+	}
+
+	opts := b.auth
+	opts.Name, opts.Lang, opts.Version = "tempuscache", "go", "2"
+	connect, _ := json.Marshal(opts)
+	cn.w.WriteString("CONNECT " + string(connect) + "\r\n")
+	if err := cn.w.Flush(); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	nc.SetDeadline(time.Time{})
+	return cn, nil
+}
+
+/*
+awaitPong reads until the PONG answering our PING, answering server
+PINGs and failing on -ERR along the way.
+*/
+
+func (cn *conn) awaitPong() error {
+	for {
+		line, err := cn.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			cn.w.WriteString("PONG\r\n")
+			if err := cn.w.Flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("tempusnats: " + line)
+		}
+	}
+}
+
+// readLine reads one protocol line without its CRLF.
+func (cn *conn) readLine() (string, error) {
+	line, err := cn.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPayload reads the payload announced by a "MSG subject sid [reply] size" line.
+func (cn *conn) readPayload(header string) ([]byte, error) {
+	fields := strings.Fields(header)
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || size < 0 || size > maxPayload {
+		return nil, errors.New("tempusnats: invalid MSG header " + header)
+	}
+	buf := make([]byte, size+2)
+	if _, err := io.ReadFull(cn.r, buf); err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
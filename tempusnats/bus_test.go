@@ -0,0 +1,212 @@
+package tempusnats
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// fakeNATS implements just enough of the NATS protocol for the tests.
+type fakeNATS struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	subs map[net.Conn]string
+}
+
+func startFakeNATS(t *testing.T) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeNATS{ln: ln, subs: make(map[net.Conn]string)}
+	t.Cleanup(func() { ln.Close(); f.dropSubscribers() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(nc)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNATS) serve(nc net.Conn) {
+	defer nc.Close()
+	nc.Write([]byte("INFO {\"server_id\":\"fake\"}\r\n"))
+
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			nc.Write([]byte("PONG\r\n"))
+		case "SUB":
+			f.mu.Lock()
+			f.subs[nc] = fields[1]
+			f.mu.Unlock()
+		case "PUB":
+			size, _ := strconv.Atoi(fields[2])
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			f.publish(fields[1], buf[:size])
+		}
+	}
+}
+
+func (f *fakeNATS) publish(subject string, payload []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for nc, s := range f.subs {
+		if s == subject {
+			nc.Write([]byte("MSG " + subject + " 1 " + strconv.Itoa(len(payload)) + "\r\n" + string(payload) + "\r\n"))
+		}
+	}
+}
+
+func (f *fakeNATS) subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+func (f *fakeNATS) dropSubscribers() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for nc := range f.subs {
+		nc.Close()
+		delete(f.subs, nc)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBusInvalidatesPeers(t *testing.T) {
+	nats := startFakeNATS(t)
+	addr := nats.ln.Addr().String()
+
+	busA := New(addr, WithNamespace("users"))
+	busB := New(addr, WithNamespace("users"))
+	other := New(addr, WithNamespace("sessions"))
+	defer busA.Close()
+	defer busB.Close()
+	defer other.Close()
+
+	a := tempuscache.New(tempuscache.WithInvalidation(busA, nil))
+	b := tempuscache.New(tempuscache.WithInvalidation(nil, busB))
+	c := tempuscache.New(tempuscache.WithInvalidation(nil, other))
+	defer a.Stop()
+	defer b.Stop()
+	defer c.Stop()
+
+	waitFor(t, func() bool { return nats.subscribers() == 2 })
+
+	b.Set("k", "stale", 0)
+	c.Set("k", "unrelated", 0)
+	a.Set("k", "fresh", 0)
+	waitFor(t, func() bool { _, found := b.Get("k"); return !found })
+
+	if _, found := c.Get("k"); !found {
+		t.Fatal("expected other namespaces to be unaffected")
+	}
+}
+
+func TestBusDegradesGracefully(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	bus := New(addr, WithBackoff(time.Millisecond, 5*time.Millisecond))
+	defer bus.Close()
+
+	cache := tempuscache.New(tempuscache.WithInvalidation(bus, bus))
+	cache.Set("k", 1, 0)
+	if v, found := cache.Get("k"); !found || v != 1 {
+		t.Fatal("expected the cache to keep working without a broker")
+	}
+	cache.Stop()
+
+	if err := bus.Broadcast(context.Background(), tempuscache.Invalidation{Keys: []string{"k"}}); err == nil {
+		t.Fatal("expected Broadcast to report the unavailable broker")
+	}
+}
+
+func TestBusReconnect(t *testing.T) {
+	nats := startFakeNATS(t)
+
+	var mu sync.Mutex
+	var got []tempuscache.Invalidation
+	bus := New(nats.ln.Addr().String(),
+		WithBackoff(time.Millisecond, 10*time.Millisecond),
+		WithFlushOnReconnect(true))
+	defer bus.Close()
+
+	unsubscribe, err := bus.Subscribe(func(msg tempuscache.Invalidation) {
+		mu.Lock()
+		got = append(got, msg)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	waitFor(t, func() bool { return nats.subscribers() == 1 })
+	nats.dropSubscribers()
+	waitFor(t, func() bool { return nats.subscribers() == 1 })
+
+	if err := bus.Broadcast(context.Background(), tempuscache.Invalidation{Origin: "x", Keys: []string{"k"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(got) == 2 })
+	mu.Lock()
+	defer mu.Unlock()
+	if !got[0].All || got[1].Origin != "x" {
+		t.Fatalf("expected a flush on reconnect, then the message; got %+v", got)
+	}
+}
+
+func TestBusClosed(t *testing.T) {
+	bus := New("127.0.0.1:1")
+	bus.Close()
+
+	if err := bus.Broadcast(context.Background(), tempuscache.Invalidation{}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, err := bus.Subscribe(func(tempuscache.Invalidation) {}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
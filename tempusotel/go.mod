@@ -0,0 +1,12 @@
+module github.com/Krishna8167/tempuscache/v2/tempusotel
+
+go 1.24.3
+
+require (
+	github.com/Krishna8167/tempuscache/v2 v2.0.0
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/metric v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+replace github.com/Krishna8167/tempuscache/v2 => ../
@@ -0,0 +1,197 @@
+/*
+Package tempusotel integrates TempusCache with OpenTelemetry.
+
+================================================================================
+USAGE
+================================================================================
+
+	cache := tempuscache.New(tempuscache.WithMaxEntries(10000))
+
+	instrumented, err := tempusotel.Instrument(cache, meterProvider,
+	    tempusotel.WithCacheName("sessions"))
+	if err != nil {
+	    return err
+	}
+
+	instrumented.Set("k", v, time.Minute) // latency recorded
+	instrumented.Get("k")                 // latency recorded
+
+================================================================================
+RECORDED INSTRUMENTS
+================================================================================
+
+Asynchronous (read from Stats() at collection time):
+
+	tempuscache.hits          (counter)
+	tempuscache.misses        (counter)
+	tempuscache.evictions     (counter)
+	tempuscache.expirations   (counter)
+	tempuscache.entries       (gauge)
+
+Synchronous (recorded by the Cache wrapper):
+
+	tempuscache.operation.duration (histogram, seconds, "operation" attribute)
+
+Every instrument carries a "cache.name" attribute.
+
+================================================================================
+DESIGN
+================================================================================
+
+Counters are observed from the cache's own Stats() snapshot rather
+than being incremented alongside it, so they can never disagree.
+Latency can only be measured around calls, which is why Instrument
+returns a thin wrapper embedding the original *tempuscache.Cache:
+every method remains available, and Get/Set/Delete are timed.
+
+Snapshot and append-only log operations are I/O-bound and can be
+slow; Trace wraps them in spans instead (see Tracer).
+*/
+package tempusotel
+
+import (
+	"context"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "github.com/Krishna8167/tempuscache/v2/tempusotel"
+
+/*
+Option configures Instrument.
+*/
+
+type Option func(*config)
+
+type config struct {
+	cacheName string
+}
+
+// WithCacheName sets the "cache.name" attribute attached to every instrument.
+func WithCacheName(name string) Option {
+	return func(cfg *config) {
+		cfg.cacheName = name
+	}
+}
+
+/*
+Cache wraps a *tempuscache.Cache and records operation latency.
+
+All methods of the embedded cache remain available; Get, Set,
+and Delete are overridden to record their duration.
+*/
+
+type Cache struct {
+	*tempuscache.Cache
+
+	duration metric.Float64Histogram
+	getAttrs metric.MeasurementOption
+	setAttrs metric.MeasurementOption
+	delAttrs metric.MeasurementOption
+}
+
+/*
+Instrument registers cache metrics with the given MeterProvider and
+returns a latency-recording wrapper.
+
+================================================================================
+ERRORS
+================================================================================
+
+Returns an error if any instrument or the observation callback
+cannot be created by the MeterProvider.
+*/
+
+func Instrument(cache *tempuscache.Cache, mp metric.MeterProvider, opts ...Option) (*Cache, error) {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := mp.Meter(instrumentationName)
+	name := attribute.String("cache.name", cfg.cacheName)
+	observeAttrs := metric.WithAttributes(name)
+
+	hits, err := meter.Int64ObservableCounter("tempuscache.hits",
+		metric.WithDescription("Number of successful cache lookups."))
+	if err != nil {
+		return nil, err
+	}
+	misses, err := meter.Int64ObservableCounter("tempuscache.misses",
+		metric.WithDescription("Number of cache lookups for missing or expired keys."))
+	if err != nil {
+		return nil, err
+	}
+	evictions, err := meter.Int64ObservableCounter("tempuscache.evictions",
+		metric.WithDescription("Number of entries evicted due to capacity limits."))
+	if err != nil {
+		return nil, err
+	}
+	expirations, err := meter.Int64ObservableCounter("tempuscache.expirations",
+		metric.WithDescription("Number of entries removed because their TTL elapsed."))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := meter.Int64ObservableGauge("tempuscache.entries",
+		metric.WithDescription("Number of entries currently stored."))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := cache.Stats()
+		o.ObserveInt64(hits, int64(s.Hits), observeAttrs)
+		o.ObserveInt64(misses, int64(s.Misses), observeAttrs)
+		o.ObserveInt64(evictions, int64(s.Evictions), observeAttrs)
+		o.ObserveInt64(expirations, int64(s.Expirations), observeAttrs)
+		o.ObserveInt64(entries, int64(cache.Len()), observeAttrs)
+		return nil
+	}, hits, misses, evictions, expirations, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram("tempuscache.operation.duration",
+		metric.WithDescription("Duration of cache operations."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	opAttrs := func(op string) metric.MeasurementOption {
+		return metric.WithAttributeSet(attribute.NewSet(name, attribute.String("operation", op)))
+	}
+
+	return &Cache{
+		Cache:    cache,
+		duration: duration,
+		getAttrs: opAttrs("get"),
+		setAttrs: opAttrs("set"),
+		delAttrs: opAttrs("delete"),
+	}, nil
+}
+
+// Get calls the embedded cache's Get and records its duration.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	start := time.Now()
+	val, found := c.Cache.Get(key)
+	c.duration.Record(context.Background(), time.Since(start).Seconds(), c.getAttrs)
+	return val, found
+}
+
+// Set calls the embedded cache's Set and records its duration.
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	start := time.Now()
+	c.Cache.Set(key, value, ttl)
+	c.duration.Record(context.Background(), time.Since(start).Seconds(), c.setAttrs)
+}
+
+// Delete calls the embedded cache's Delete and records its duration.
+func (c *Cache) Delete(key string) {
+	start := time.Now()
+	c.Cache.Delete(key)
+	c.duration.Record(context.Background(), time.Since(start).Seconds(), c.delAttrs)
+}
@@ -0,0 +1,101 @@
+package tempusotel
+
+import (
+	"context"
+	"testing"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recordingMeter is a minimal metric.Meter that remembers what the
+// instrumentation registers, so the test needs no SDK.
+type recordingMeter struct {
+	noop.Meter
+	callback   metric.Callback
+	histograms map[string]int
+}
+
+type namedCounter struct {
+	noop.Int64ObservableCounter
+	name string
+}
+
+type namedGauge struct {
+	noop.Int64ObservableGauge
+	name string
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	meter *recordingMeter
+	name  string
+}
+
+func (h recordingHistogram) Record(context.Context, float64, ...metric.RecordOption) {
+	h.meter.histograms[h.name]++
+}
+
+func (m *recordingMeter) Int64ObservableCounter(name string, _ ...metric.Int64ObservableCounterOption) (metric.Int64ObservableCounter, error) {
+	return namedCounter{name: name}, nil
+}
+
+func (m *recordingMeter) Int64ObservableGauge(name string, _ ...metric.Int64ObservableGaugeOption) (metric.Int64ObservableGauge, error) {
+	return namedGauge{name: name}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return recordingHistogram{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) RegisterCallback(cb metric.Callback, _ ...metric.Observable) (metric.Registration, error) {
+	m.callback = cb
+	return noop.Registration{}, nil
+}
+
+type recordingProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func (p recordingProvider) Meter(string, ...metric.MeterOption) metric.Meter { return p.meter }
+
+type recordingObserver struct {
+	noop.Observer
+	values map[string]int64
+}
+
+func (o recordingObserver) ObserveInt64(inst metric.Int64Observable, v int64, _ ...metric.ObserveOption) {
+	switch i := inst.(type) {
+	case namedCounter:
+		o.values[i.name] = v
+	case namedGauge:
+		o.values[i.name] = v
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	meter := &recordingMeter{histograms: map[string]int{}}
+
+	cache, err := Instrument(tempuscache.New(), recordingProvider{meter: meter}, WithCacheName("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	cache.Get("b")
+
+	if n := meter.histograms["tempuscache.operation.duration"]; n != 3 {
+		t.Fatalf("expected 3 recorded durations, got %d", n)
+	}
+
+	obs := recordingObserver{values: map[string]int64{}}
+	if err := meter.callback(context.Background(), obs); err != nil {
+		t.Fatal(err)
+	}
+	if obs.values["tempuscache.hits"] != 1 || obs.values["tempuscache.misses"] != 1 || obs.values["tempuscache.entries"] != 1 {
+		t.Fatalf("unexpected observed values: %v", obs.values)
+	}
+}
@@ -0,0 +1,117 @@
+package tempusotel
+
+import (
+	"context"
+	"io"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+Tracer wraps the slow, I/O-bound operations of a *tempuscache.Cache
+in OpenTelemetry spans.
+
+================================================================================
+USAGE
+================================================================================
+
+	tracer := tempusotel.Trace(cache, tracerProvider,
+	    tempusotel.WithCacheName("sessions"))
+
+	if err := tracer.SaveFile(ctx, "/var/lib/app/cache.snap"); err != nil {
+	    return err
+	}
+
+================================================================================
+RECORDED SPANS
+================================================================================
+
+	tempuscache.save        (Save)
+	tempuscache.save_file   (SaveFile, "file.path" attribute)
+	tempuscache.load        (Load)
+	tempuscache.load_file   (LoadFile, "file.path" attribute)
+	tempuscache.aof.compact (CompactNow)
+
+Each span is a child of the span in the context passed by the caller,
+carries the "cache.name" attribute and, after loads, the resulting
+"cache.entries" count. Failures are recorded on the span and mark it
+with an error status.
+
+Fast in-memory operations (Get/Set/Delete) are intentionally not
+traced: a span per lookup would cost far more than the lookup itself.
+Their latency is covered by the histogram recorded by Instrument.
+*/
+
+type Tracer struct {
+	cache  *tempuscache.Cache
+	tracer trace.Tracer
+	name   attribute.KeyValue
+}
+
+// Trace returns a Tracer creating spans through the given TracerProvider.
+func Trace(cache *tempuscache.Cache, tp trace.TracerProvider, opts ...Option) *Tracer {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Tracer{
+		cache:  cache,
+		tracer: tp.Tracer(instrumentationName),
+		name:   attribute.String("cache.name", cfg.cacheName),
+	}
+}
+
+// Save calls the cache's Save inside a "tempuscache.save" span.
+func (t *Tracer) Save(ctx context.Context, w io.Writer) error {
+	_, span := t.start(ctx, "tempuscache.save")
+	return t.end(span, t.cache.Save(w), false)
+}
+
+// SaveFile calls the cache's SaveFile inside a "tempuscache.save_file" span.
+func (t *Tracer) SaveFile(ctx context.Context, path string) error {
+	_, span := t.start(ctx, "tempuscache.save_file", attribute.String("file.path", path))
+	return t.end(span, t.cache.SaveFile(path), false)
+}
+
+// Load calls the cache's Load inside a "tempuscache.load" span.
+func (t *Tracer) Load(ctx context.Context, r io.Reader) error {
+	_, span := t.start(ctx, "tempuscache.load")
+	return t.end(span, t.cache.Load(r), true)
+}
+
+// LoadFile calls the cache's LoadFile inside a "tempuscache.load_file" span.
+func (t *Tracer) LoadFile(ctx context.Context, path string) error {
+	_, span := t.start(ctx, "tempuscache.load_file", attribute.String("file.path", path))
+	return t.end(span, t.cache.LoadFile(path), true)
+}
+
+// CompactNow calls the cache's CompactNow inside a "tempuscache.aof.compact" span.
+func (t *Tracer) CompactNow(ctx context.Context) error {
+	_, span := t.start(ctx, "tempuscache.aof.compact")
+	return t.end(span, t.cache.CompactNow(), false)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, trace.WithAttributes(append(attrs, t.name)...))
+}
+
+/*
+end records err on span (if any), optionally attaches the resulting
+entry count, ends the span, and returns err unchanged.
+*/
+
+func (t *Tracer) end(span trace.Span, err error, entries bool) error {
+	if entries {
+		span.SetAttributes(attribute.Int("cache.entries", t.cache.Len()))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return err
+}
@@ -0,0 +1,86 @@
+package tempusotel
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingTracer remembers every span it starts, so the test needs no SDK.
+type recordingTracer struct {
+	noop.Tracer
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	noop.Span
+	name   string
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+func (s *recordingSpan) End(...trace.SpanEndOption)          { s.ended = true }
+
+func (t *recordingTracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordingSpan{name: name}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type recordingTracerProvider struct {
+	noop.TracerProvider
+	tracer *recordingTracer
+}
+
+func (p recordingTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return p.tracer
+}
+
+func TestTrace(t *testing.T) {
+	cache := tempuscache.New()
+	cache.Set("a", 1, 0)
+
+	rec := &recordingTracer{}
+	tracer := Trace(cache, recordingTracerProvider{tracer: rec}, WithCacheName("test"))
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := tracer.Save(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracer.Load(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracer.LoadFile(ctx, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected loading a missing file to fail")
+	}
+	if err := tracer.CompactNow(ctx); err != tempuscache.ErrAOFDisabled {
+		t.Fatalf("expected ErrAOFDisabled, got %v", err)
+	}
+
+	want := []struct {
+		name   string
+		status codes.Code
+	}{
+		{"tempuscache.save", codes.Unset},
+		{"tempuscache.load", codes.Unset},
+		{"tempuscache.load_file", codes.Error},
+		{"tempuscache.aof.compact", codes.Error},
+	}
+	if len(rec.spans) != len(want) {
+		t.Fatalf("expected %d spans, got %d", len(want), len(rec.spans))
+	}
+	for i, w := range want {
+		span := rec.spans[i]
+		if span.name != w.name || span.status != w.status || !span.ended {
+			t.Fatalf("span %d: expected %s (status %v), got %+v", i, w.name, w.status, span)
+		}
+	}
+}
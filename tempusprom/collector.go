@@ -0,0 +1,129 @@
+/*
+Package tempusprom exposes TempusCache statistics to Prometheus.
+
+================================================================================
+USAGE
+================================================================================
+
+	cache := tempuscache.New(tempuscache.WithMaxEntries(10000))
+
+	prometheus.MustRegister(tempusprom.NewCollector(cache, tempusprom.Opts{
+	    Namespace: "myapp",
+	    CacheName: "sessions",
+	}))
+
+================================================================================
+EXPORTED METRICS
+================================================================================
+
+All metrics carry a "cache" label with Opts.CacheName.
+
+<ns>_tempuscache_hits_total        (counter)
+<ns>_tempuscache_misses_total      (counter)
+<ns>_tempuscache_evictions_total   (counter)
+<ns>_tempuscache_expirations_total (counter)
+<ns>_tempuscache_entries           (gauge)
+<ns>_tempuscache_capacity          (gauge, 0 = unbounded)
+
+================================================================================
+DESIGN
+================================================================================
+
+The collector holds no state of its own. Every scrape takes a
+fresh Stats() snapshot and emits const metrics from it, so values
+can never drift from what the cache reports.
+*/
+package tempusprom
+
+import (
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/*
+Opts configures a Collector.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Namespace -> Metric name prefix (may be empty)
+CacheName -> Value of the "cache" label, distinguishing multiple
+             caches registered in the same process
+*/
+
+type Opts struct {
+	Namespace string
+	CacheName string
+}
+
+/*
+Collector implements prometheus.Collector for a single Cache.
+*/
+
+type Collector struct {
+	cache *tempuscache.Cache
+
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	evictions   *prometheus.Desc
+	expirations *prometheus.Desc
+	entries     *prometheus.Desc
+	capacity    *prometheus.Desc
+}
+
+/*
+NewCollector creates a Collector reporting on cache.
+
+The result must be registered with a prometheus.Registerer.
+Registering two collectors with the same Namespace and CacheName
+fails with a duplicate-metrics error.
+*/
+
+func NewCollector(cache *tempuscache.Cache, opts Opts) *Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, "tempuscache", name),
+			help,
+			nil,
+			prometheus.Labels{"cache": opts.CacheName},
+		)
+	}
+
+	return &Collector{
+		cache:       cache,
+		hits:        desc("hits_total", "Number of successful cache lookups."),
+		misses:      desc("misses_total", "Number of cache lookups for missing or expired keys."),
+		evictions:   desc("evictions_total", "Number of entries evicted due to capacity limits."),
+		expirations: desc("expirations_total", "Number of entries removed because their TTL elapsed."),
+		entries:     desc("entries", "Number of entries currently stored."),
+		capacity:    desc("capacity", "Maximum number of entries (0 means unbounded)."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.expirations
+	ch <- c.entries
+	ch <- c.capacity
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.cache.Stats()
+
+	capacity := c.cache.Capacity()
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(s.Expirations))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(capacity))
+}
@@ -0,0 +1,35 @@
+package tempusprom
+
+import (
+	"strings"
+	"testing"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	cache := tempuscache.New(tempuscache.WithMaxEntries(100))
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	cache.Get("b")
+
+	collector := NewCollector(cache, Opts{Namespace: "app", CacheName: "users"})
+
+	expected := `
+# HELP app_tempuscache_hits_total Number of successful cache lookups.
+# TYPE app_tempuscache_hits_total counter
+app_tempuscache_hits_total{cache="users"} 1
+# HELP app_tempuscache_entries Number of entries currently stored.
+# TYPE app_tempuscache_entries gauge
+app_tempuscache_entries{cache="users"} 1
+# HELP app_tempuscache_capacity Maximum number of entries (0 means unbounded).
+# TYPE app_tempuscache_capacity gauge
+app_tempuscache_capacity{cache="users"} 100
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"app_tempuscache_hits_total", "app_tempuscache_entries", "app_tempuscache_capacity")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,403 @@
+/*
+Package tempusredis carries TempusCache invalidations over Redis
+pub/sub.
+
+================================================================================
+USAGE
+================================================================================
+
+	bus := tempusredis.New("redis:6379",
+	    tempusredis.WithChannel("myapp:cache-invalidation"))
+	defer bus.Close()
+
+	cache := tempuscache.New(tempuscache.WithInvalidation(bus, bus))
+
+Every replica configured with the same Redis server and channel
+drops its local copy of a key when another replica writes it.
+
+================================================================================
+WIRE FORMAT
+================================================================================
+
+Each invalidation is one PUBLISH of a JSON document:
+
+	{"origin": "<node id>", "keys": ["a", "b"], "all": false}
+
+The package speaks RESP directly and has no dependency on a Redis
+client library.
+
+================================================================================
+RECONNECTS
+================================================================================
+
+  - Publishing uses one connection, redialed on the next Broadcast
+    after a failure. A failed Broadcast is retried once on a fresh
+    connection.
+  - Subscribing runs in a background goroutine that redials with
+    exponential backoff (WithBackoff) until Close or unsubscribe.
+  - Messages published while the subscription is down are lost.
+    WithFlushOnReconnect makes every resubscription deliver an
+    All invalidation, trading a cold cache for never serving entries
+    whose invalidation was missed.
+*/
+package tempusredis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/Krishna8167/tempuscache/v2/internal/resp"
+)
+
+// DefaultChannel is the pub/sub channel used when WithChannel is not given.
+const DefaultChannel = "tempuscache:invalidation"
+
+// ErrClosed is returned by Broadcast and Subscribe after Close.
+var ErrClosed = errors.New("tempusredis: bus closed")
+
+/*
+Option configures a Bus.
+*/
+
+type Option func(*Bus)
+
+// WithChannel sets the pub/sub channel (default DefaultChannel).
+func WithChannel(channel string) Option {
+	return func(b *Bus) {
+		b.channel = channel
+	}
+}
+
+// WithPassword authenticates every connection with AUTH.
+func WithPassword(password string) Option {
+	return func(b *Bus) {
+		b.password = password
+	}
+}
+
+// WithDialTimeout bounds connection establishment (default 5s).
+func WithDialTimeout(d time.Duration) Option {
+	return func(b *Bus) {
+		b.dialTimeout = d
+	}
+}
+
+// WithBackoff sets the initial and maximum delay between subscription
+// reconnect attempts (default 100ms and 10s).
+func WithBackoff(initial, maximum time.Duration) Option {
+	return func(b *Bus) {
+		b.backoffMin, b.backoffMax = initial, maximum
+	}
+}
+
+// WithFlushOnReconnect delivers an All invalidation every time the
+// subscription is re-established after a disconnect.
+func WithFlushOnReconnect(enabled bool) Option {
+	return func(b *Bus) {
+		b.flushOnReconnect = enabled
+	}
+}
+
+// WithLogger logs connection failures and undecodable messages.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Bus) {
+		b.log = logger
+	}
+}
+
+/*
+Bus implements tempuscache.Broadcaster and tempuscache.Subscriber
+on top of a Redis server.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+addr             -> Redis server address
+channel          -> Pub/sub channel
+password         -> AUTH password (empty = no AUTH)
+dialTimeout      -> Connection establishment bound
+backoffMin/Max   -> Subscription reconnect backoff bounds
+flushOnReconnect -> Deliver an All invalidation after resubscribing
+log              -> Logger (never nil)
+mu               -> Protects pub, subs, and closed
+pub              -> Publishing connection (nil until first use or after a failure)
+subs             -> Stop functions of running subscriptions
+closed           -> Set by Close
+*/
+
+type Bus struct {
+	addr             string
+	channel          string
+	password         string
+	dialTimeout      time.Duration
+	backoffMin       time.Duration
+	backoffMax       time.Duration
+	flushOnReconnect bool
+	log              *slog.Logger
+
+	mu     sync.Mutex
+	pub    *conn
+	subs   map[int]func()
+	nextID int
+	closed bool
+}
+
+// New creates a Bus for the Redis server at addr. No connection is
+// made until the first Broadcast or Subscribe.
+func New(addr string, opts ...Option) *Bus {
+	b := &Bus{
+		addr:        addr,
+		channel:     DefaultChannel,
+		dialTimeout: 5 * time.Second,
+		backoffMin:  100 * time.Millisecond,
+		backoffMax:  10 * time.Second,
+		log:         slog.New(slog.DiscardHandler),
+		subs:        make(map[int]func()),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+type message struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	All    bool     `json:"all,omitempty"`
+}
+
+/*
+Broadcast publishes msg on the channel.
+
+The mutex serializes publishers on the shared connection; the cache
+calls Broadcast from a single worker, so it is never contended there.
+*/
+
+func (b *Bus) Broadcast(ctx context.Context, msg tempuscache.Invalidation) error {
+	payload, err := json.Marshal(message{Origin: msg.Origin, Keys: msg.Keys, All: msg.All})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	for attempt := 0; ; attempt++ {
+		if b.pub == nil {
+			if b.pub, err = b.dial(ctx); err != nil {
+				return err
+			}
+		}
+
+		deadline, _ := ctx.Deadline()
+		b.pub.nc.SetDeadline(deadline)
+		_, err = b.pub.do("PUBLISH", b.channel, string(payload))
+		if err == nil {
+			return nil
+		}
+
+		var rerr redisError
+		if errors.As(err, &rerr) {
+			return err
+		}
+		b.pub.nc.Close()
+		b.pub = nil
+		if attempt == 1 || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+/*
+Subscribe starts a background subscription delivering every
+message published on the channel to handle, reconnecting as needed.
+*/
+
+func (b *Bus) Subscribe(handle func(tempuscache.Invalidation)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.subscribeLoop(ctx, handle)
+	}()
+
+	id := b.nextID
+	b.nextID++
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+	b.subs[id] = stop
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+		stop()
+	}, nil
+}
+
+/*
+Close stops all subscriptions and closes the publishing connection.
+*/
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	if b.pub != nil {
+		b.pub.nc.Close()
+		b.pub = nil
+	}
+	b.mu.Unlock()
+
+	for _, stop := range subs {
+		stop()
+	}
+	return nil
+}
+
+// subscribeLoop keeps one subscription alive until ctx is cancelled.
+func (b *Bus) subscribeLoop(ctx context.Context, handle func(tempuscache.Invalidation)) {
+	backoff := b.backoffMin
+	subscribed := false
+
+	for ctx.Err() == nil {
+		err := b.subscribeOnce(ctx, func() {
+			if subscribed && b.flushOnReconnect {
+				handle(tempuscache.Invalidation{Origin: "tempusredis", All: true})
+			}
+			subscribed = true
+			backoff = b.backoffMin
+		}, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		b.log.Warn("tempusredis: subscription lost, reconnecting",
+			"addr", b.addr, "channel", b.channel, "retry_in", backoff, "err", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, b.backoffMax)
+	}
+}
+
+/*
+subscribeOnce dials, subscribes, and delivers messages until the
+connection fails or ctx is cancelled. ready is called once the
+subscription is confirmed.
+*/
+
+func (b *Bus) subscribeOnce(ctx context.Context, ready func(), handle func(tempuscache.Invalidation)) error {
+	cn, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer cn.nc.Close()
+
+	stop := context.AfterFunc(ctx, func() { cn.nc.Close() })
+	defer stop()
+
+	if _, err := cn.do("SUBSCRIBE", b.channel); err != nil {
+		return err
+	}
+	ready()
+
+	for {
+		reply, err := readReply(cn.r)
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 || parts[0] != "message" {
+			continue
+		}
+		payload, _ := parts[2].(string)
+
+		var m message
+		if err := json.Unmarshal([]byte(payload), &m); err != nil {
+			b.log.Warn("tempusredis: ignoring undecodable message", "channel", b.channel, "err", err)
+			continue
+		}
+		handle(tempuscache.Invalidation{Origin: m.Origin, Keys: m.Keys, All: m.All})
+	}
+}
+
+type conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+}
+
+// dial connects and authenticates a new connection.
+func (b *Bus) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: b.dialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{nc: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	if b.password != "" {
+		nc.SetDeadline(time.Now().Add(b.dialTimeout))
+		if _, err := cn.do("AUTH", b.password); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc.SetDeadline(time.Time{})
+	}
+	return cn, nil
+}
+
+// do sends one command and reads one reply.
+func (cn *conn) do(args ...string) (interface{}, error) {
+	resp.WriteCommand(cn.w, args)
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+/*
+redisError is an error reply sent by the server. Unlike I/O errors,
+it leaves the connection usable.
+*/
+
+type redisError string
+
+func (e redisError) Error() string { return "tempusredis: " + string(e) }
+
+// readReply is resp.ReadReply with error replies as redisError.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	reply, err := resp.ReadReply(r)
+	if rerr, ok := err.(resp.Error); ok {
+		return nil, redisError(rerr)
+	}
+	return reply, err
+}
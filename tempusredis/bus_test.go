@@ -0,0 +1,183 @@
+package tempusredis
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// fakeRedis implements just enough of PUBLISH/SUBSCRIBE for the tests.
+type fakeRedis struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	subs map[net.Conn]string
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{ln: ln, subs: make(map[net.Conn]string)}
+	t.Cleanup(func() { ln.Close(); f.dropSubscribers() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(nc)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		args, _ := reply.([]interface{})
+		if len(args) == 0 {
+			return
+		}
+		switch args[0] {
+		case "SUBSCRIBE":
+			f.mu.Lock()
+			f.subs[nc] = args[1].(string)
+			f.mu.Unlock()
+			nc.Write([]byte("*3\r\n$9\r\nsubscribe\r\n" + bulk(args[1].(string)) + ":1\r\n"))
+		case "PUBLISH":
+			n := f.publish(args[1].(string), args[2].(string))
+			nc.Write([]byte(":" + strconv.Itoa(n) + "\r\n"))
+		default:
+			nc.Write([]byte("-ERR unknown command\r\n"))
+		}
+	}
+}
+
+func (f *fakeRedis) publish(channel, payload string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for nc, ch := range f.subs {
+		if ch == channel {
+			nc.Write([]byte("*3\r\n$7\r\nmessage\r\n" + bulk(channel) + bulk(payload)))
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeRedis) subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+func (f *fakeRedis) dropSubscribers() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for nc := range f.subs {
+		nc.Close()
+		delete(f.subs, nc)
+	}
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBusInvalidatesPeers(t *testing.T) {
+	redis := startFakeRedis(t)
+
+	busA := New(redis.ln.Addr().String(), WithChannel("test"))
+	busB := New(redis.ln.Addr().String(), WithChannel("test"))
+	defer busA.Close()
+	defer busB.Close()
+
+	a := tempuscache.New(tempuscache.WithInvalidation(busA, busA))
+	b := tempuscache.New(tempuscache.WithInvalidation(nil, busB))
+	defer a.Stop()
+	defer b.Stop()
+
+	waitFor(t, func() bool { return redis.subscribers() == 2 })
+
+	b.Set("k", "stale", 0)
+	a.Set("k", "fresh", 0)
+	waitFor(t, func() bool { _, found := b.Get("k"); return !found })
+
+	if _, found := a.Get("k"); !found {
+		t.Fatal("expected the writer to ignore its own invalidation")
+	}
+}
+
+func TestBusReconnect(t *testing.T) {
+	redis := startFakeRedis(t)
+
+	var mu sync.Mutex
+	var got []tempuscache.Invalidation
+	bus := New(redis.ln.Addr().String(),
+		WithBackoff(time.Millisecond, 10*time.Millisecond),
+		WithFlushOnReconnect(true))
+	defer bus.Close()
+
+	unsubscribe, err := bus.Subscribe(func(msg tempuscache.Invalidation) {
+		mu.Lock()
+		got = append(got, msg)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	waitFor(t, func() bool { return redis.subscribers() == 1 })
+	redis.dropSubscribers()
+	waitFor(t, func() bool { return redis.subscribers() == 1 })
+
+	if err := bus.Broadcast(context.Background(), tempuscache.Invalidation{Origin: "x", Keys: []string{"k"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(got) == 2 })
+	mu.Lock()
+	defer mu.Unlock()
+	if !got[0].All || got[1].Origin != "x" || len(got[1].Keys) != 1 {
+		t.Fatalf("expected a flush on reconnect, then the message; got %+v", got)
+	}
+}
+
+func TestBusClosed(t *testing.T) {
+	bus := New("127.0.0.1:1")
+	bus.Close()
+
+	if err := bus.Broadcast(context.Background(), tempuscache.Invalidation{}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, err := bus.Subscribe(func(tempuscache.Invalidation) {}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
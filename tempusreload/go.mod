@@ -0,0 +1,10 @@
+module github.com/Krishna8167/tempuscache/v2/tempusreload
+
+go 1.24.3
+
+require (
+	github.com/Krishna8167/tempuscache/v2 v2.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/Krishna8167/tempuscache/v2 => ../
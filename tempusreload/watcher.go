@@ -0,0 +1,312 @@
+/*
+Package tempusreload applies configuration changes from a file to a
+running TempusCache, without restarting the process.
+
+================================================================================
+USAGE
+================================================================================
+
+	w, err := tempusreload.Watch(cache, "/etc/myapp/cache.yaml",
+	    tempusreload.WithLogger(logger))
+	if err != nil {
+	    return err
+	}
+	defer w.Stop()
+
+The file is read once by Watch and then polled every interval; each
+time its modification time or size changes, it is read again and the
+settings it contains are applied. A change is only read once two
+consecutive polls see the same modification time and size, so a file
+still being written is not applied half-way.
+
+================================================================================
+FILE FORMAT
+================================================================================
+
+YAML or JSON (a JSON document is valid YAML):
+
+	max_entries: 100000
+	cleanup_interval: 1m
+	default_ttl: 10m
+
+	{"max_entries": 100000, "cleanup_interval": "1m"}
+
+Supported settings:
+
+	max_entries       Applied with Cache.Resize (0 = unbounded)
+	cleanup_interval  Applied with Cache.SetCleanupInterval (0 = off)
+	default_ttl       Applied with Cache.SetDefaultTTL (0 = off)
+
+Missing settings leave the cache unchanged, and other keys are
+ignored, so the watched file can be a larger application config
+such as the tempusd configuration file.
+
+================================================================================
+FAILURES
+================================================================================
+
+A file that cannot be read or parsed, is empty, or holds invalid
+settings, is logged and reported to the WithOnReload callback; nothing is applied
+and the cache keeps its current configuration until the next change.
+Only the first read, in Watch, returns its error.
+*/
+package tempusreload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Settings are the reloadable settings read from the file. Nil fields
+were missing from the file and are left unchanged.
+*/
+
+type Settings struct {
+	MaxEntries      *int           `yaml:"max_entries"`
+	CleanupInterval *time.Duration `yaml:"cleanup_interval"`
+	DefaultTTL      *time.Duration `yaml:"default_ttl"`
+}
+
+/*
+Option configures a Watcher.
+*/
+
+type Option func(*Watcher)
+
+// WithInterval sets how often the file is checked for changes
+// (default 5s).
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithLogger logs every reload and every failed reload (default
+// slog.Default()).
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Watcher) {
+		w.log = logger
+	}
+}
+
+// WithOnReload calls fn after every reload attempt with the settings
+// read and the error, if any, that prevented applying them.
+func WithOnReload(fn func(Settings, error)) Option {
+	return func(w *Watcher) {
+		w.onReload = fn
+	}
+}
+
+/*
+Watcher applies a configuration file to a cache whenever it changes.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cache    -> Cache being configured
+path     -> Watched file
+interval -> Polling frequency
+log      -> Reload logger
+onReload -> Optional callback after each reload attempt
+mu       -> Serializes Reload() between the ticker and callers
+modTime  -> Modification time of the file at the last reload
+size     -> Size of the file at the last reload
+seenTime -> Modification time of the file at the previous poll
+seenSize -> Size of the file at the previous poll
+stopChan -> Graceful shutdown signal for the polling goroutine
+done     -> Closed when the polling goroutine has exited
+*/
+
+type Watcher struct {
+	cache    *tempuscache.Cache
+	path     string
+	interval time.Duration
+	log      *slog.Logger
+	onReload func(Settings, error)
+	mu       sync.Mutex
+	modTime  time.Time
+	size     int64
+	seenTime time.Time
+	seenSize int64
+	stopChan chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+Watch applies the file at path to cache and starts watching it for
+changes.
+
+Returns the error of the first read, in which case nothing is
+watched.
+*/
+
+func Watch(cache *tempuscache.Cache, path string, opts ...Option) (*Watcher, error) {
+	w := &Watcher{
+		cache:    cache,
+		path:     path,
+		interval: 5 * time.Second,
+		log:      slog.Default(),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if w.changed() {
+				w.Reload()
+			}
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+/*
+changed reports whether the file differs from the last reload and is
+unchanged since the previous poll: a file that is still being
+written is read once it has settled.
+*/
+
+func (w *Watcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		// Reload reports the error once; retry when the file is back.
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	modTime, size := info.ModTime(), info.Size()
+	if modTime.Equal(w.modTime) && size == w.size {
+		return false
+	}
+	settled := modTime.Equal(w.seenTime) && size == w.seenSize
+	w.seenTime, w.seenSize = modTime, size
+	return settled
+}
+
+/*
+Reload reads the file and applies its settings immediately, e.g. on
+SIGHUP. Only settings that differ from the cache's current ones are
+applied.
+*/
+
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s, err := w.read()
+	if err == nil {
+		w.apply(s)
+	} else {
+		w.log.Error("tempusreload: configuration not applied", "path", w.path, "err", err)
+	}
+	if w.onReload != nil {
+		w.onReload(s, err)
+	}
+	return err
+}
+
+// read parses and validates the file, recording its version.
+func (w *Watcher) read() (Settings, error) {
+	var s Settings
+
+	f, err := os.Open(w.path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return s, err
+	}
+	// The version is recorded even if the contents are invalid, so a
+	// broken file is reported once rather than on every poll.
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return s, err
+	}
+	// An empty document is more likely a file caught mid-write
+	// than a request to change nothing.
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&s); errors.Is(err, io.EOF) {
+		return Settings{}, fmt.Errorf("%s: empty configuration", w.path)
+	} else if err != nil {
+		return Settings{}, fmt.Errorf("%s: %w", w.path, err)
+	}
+
+	if s.MaxEntries != nil && *s.MaxEntries < 0 {
+		return s, errors.New("max_entries must not be negative")
+	}
+	if s.CleanupInterval != nil && *s.CleanupInterval < 0 {
+		return s, errors.New("cleanup_interval must not be negative")
+	}
+	if s.DefaultTTL != nil && *s.DefaultTTL < 0 {
+		return s, errors.New("default_ttl must not be negative")
+	}
+	return s, nil
+}
+
+// apply changes the cache settings that differ from s.
+func (w *Watcher) apply(s Settings) {
+	attrs := []any{"path", w.path}
+
+	if s.MaxEntries != nil && *s.MaxEntries != w.cache.Capacity() {
+		evicted := w.cache.Resize(*s.MaxEntries)
+		attrs = append(attrs, "max_entries", *s.MaxEntries, "evicted", evicted)
+	}
+	if s.CleanupInterval != nil && *s.CleanupInterval != w.cache.CleanupInterval() {
+		w.cache.SetCleanupInterval(*s.CleanupInterval)
+		attrs = append(attrs, "cleanup_interval", *s.CleanupInterval)
+	}
+	if s.DefaultTTL != nil && *s.DefaultTTL != w.cache.DefaultTTL() {
+		w.cache.SetDefaultTTL(*s.DefaultTTL)
+		attrs = append(attrs, "default_ttl", *s.DefaultTTL)
+	}
+
+	w.log.Info("tempusreload: configuration reloaded", attrs...)
+}
+
+/*
+Stop terminates the polling goroutine. The cache keeps its current
+configuration. It is safe to call more than once.
+*/
+
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+		<-w.done
+	})
+}
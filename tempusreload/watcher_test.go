@@ -0,0 +1,130 @@
+package tempusreload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func writeFile(t *testing.T, path, data string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Pin the modification time so that changes within the file
+	// system's timestamp granularity are still detected.
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchAppliesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.yaml")
+	start := time.Now()
+	writeFile(t, path, "max_entries: 10\ncleanup_interval: 1m\nunrelated: true\n", start)
+
+	cache := tempuscache.New()
+	defer cache.Stop()
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+
+	// Reloads are explicit: the poller never fires during the test.
+	var reloads int
+	w, err := Watch(cache, path, WithInterval(time.Hour),
+		WithOnReload(func(Settings, error) { reloads++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if cache.Capacity() != 10 || cache.CleanupInterval() != time.Minute {
+		t.Fatalf("expected initial settings, got %d and %v", cache.Capacity(), cache.CleanupInterval())
+	}
+
+	// JSON works too; missing settings are left unchanged.
+	writeFile(t, path, `{"max_entries": 4, "default_ttl": "10m"}`, start.Add(time.Second))
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Capacity() != 4 || cache.Len() != 4 || cache.CleanupInterval() != time.Minute {
+		t.Fatalf("expected resize to 4, got capacity %d, len %d, interval %v",
+			cache.Capacity(), cache.Len(), cache.CleanupInterval())
+	}
+	if cache.DefaultTTL() != 10*time.Minute {
+		t.Fatalf("expected default TTL 10m, got %v", cache.DefaultTTL())
+	}
+
+	// Invalid and empty files are reported and change nothing.
+	for _, data := range []string{"max_entries: -1\n", "max_entries: [\n", "", "# comment only\n"} {
+		writeFile(t, path, data, start.Add(2*time.Second))
+		if err := w.Reload(); err == nil {
+			t.Fatalf("expected %q to be rejected", data)
+		}
+	}
+	if cache.Capacity() != 4 {
+		t.Fatalf("expected capacity to stay 4, got %d", cache.Capacity())
+	}
+
+	writeFile(t, path, "cleanup_interval: 0s\n", start.Add(3*time.Second))
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cache.CleanupInterval() != 0 {
+		t.Fatalf("expected the janitor to be disabled, got %v", cache.CleanupInterval())
+	}
+	if reloads != 7 {
+		t.Fatalf("expected every reload to be reported, got %d", reloads)
+	}
+}
+
+func TestWatchWaitsForSettledFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.yaml")
+	start := time.Now()
+	writeFile(t, path, "max_entries: 10\n", start)
+
+	cache := tempuscache.New()
+	defer cache.Stop()
+	w, err := Watch(cache, path, WithInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if w.changed() {
+		t.Fatal("expected no change right after the first read")
+	}
+
+	// A write in progress: each poll sees a different version.
+	writeFile(t, path, "max_entries: 1", start.Add(time.Second))
+	if w.changed() {
+		t.Fatal("expected a file seen for the first time to wait for the next poll")
+	}
+	writeFile(t, path, "max_entries: 1000\n", start.Add(2*time.Second))
+	if w.changed() {
+		t.Fatal("expected a file still changing to wait for the next poll")
+	}
+
+	if !w.changed() {
+		t.Fatal("expected a settled file to be reloaded")
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Capacity() != 1000 || w.changed() {
+		t.Fatalf("expected the settled version to be applied once, got capacity %d", cache.Capacity())
+	}
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	cache := tempuscache.New()
+	defer cache.Stop()
+
+	if _, err := Watch(cache, filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
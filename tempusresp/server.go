@@ -0,0 +1,662 @@
+/*
+Package tempusresp serves a TempusCache over a subset of the Redis
+serialization protocol (RESP), so existing Redis clients can talk to
+an embedded cache.
+
+================================================================================
+USAGE
+================================================================================
+
+	srv := tempusresp.New(cache, tempusresp.WithAddr("127.0.0.1:6380"))
+	go srv.ListenAndServe()
+	...
+	srv.Shutdown(ctx)
+
+	$ redis-cli -p 6380 SET greeting hello EX 60
+
+================================================================================
+SUPPORTED COMMANDS
+================================================================================
+
+	GET key
+	SET key value [EX seconds | PX milliseconds]
+	DEL key [key ...]
+	EXISTS key [key ...]
+	TTL key / PTTL key
+	EXPIRE key seconds / PEXPIRE key milliseconds
+	FLUSHALL / FLUSHDB
+	INFO
+	PING [message], ECHO message, SELECT 0, QUIT, COMMAND
+
+Anything else returns "-ERR unknown command". Both RESP arrays and
+inline commands (as typed into telnet) are accepted, and pipelined
+commands are answered in a single write.
+
+================================================================================
+SEMANTICS
+================================================================================
+
+  - Values written over RESP are stored as Go strings. Values written
+    by Go code in the same process are returned as-is when they are
+    strings or []byte, and formatted with fmt otherwise.
+  - TTL returns -2 for missing keys and -1 for keys without a deadline.
+  - SET without EX or PX clears any previous deadline, as in Redis.
+  - EXPIRE with a non-positive timeout deletes the key, as in Redis.
+    Timeouts too large for a time.Duration are rejected.
+  - There is a single database; SELECT only accepts 0.
+
+================================================================================
+SECURITY
+================================================================================
+
+There is no authentication, and the server binds to 127.0.0.1:6380
+by default. Do not expose it to untrusted networks.
+
+Bulk strings are capped by WithMaxBulkLen (1 MiB by default) and
+inline commands at 64 KiB; larger requests close the connection.
+*/
+package tempusresp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultAddr is the listen address used when WithAddr is not given.
+const DefaultAddr = "127.0.0.1:6380"
+
+// ErrServerClosed is returned by Serve after Shutdown has been called.
+var ErrServerClosed = errors.New("tempusresp: server closed")
+
+// DefaultMaxBulkLen is the largest bulk string accepted when
+// WithMaxBulkLen is not given.
+const DefaultMaxBulkLen = 1 << 20
+
+const (
+	maxArgs       = 1024
+	maxInlineSize = 64 << 10
+)
+
+/*
+Option configures a Server.
+*/
+
+type Option func(*Server)
+
+// WithAddr sets the TCP listen address (default DefaultAddr).
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithIdleTimeout closes connections that send no command for d
+// (default 0 = never).
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.idleTimeout = d
+	}
+}
+
+// WithMaxBulkLen sets the largest bulk string a client may send, like
+// Redis' proto-max-bulk-len (default DefaultMaxBulkLen). Longer ones
+// are a protocol error and close the connection.
+func WithMaxBulkLen(n int) Option {
+	return func(s *Server) {
+		s.maxBulkLen = n
+	}
+}
+
+/*
+Server serves the RESP protocol for a single Cache.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cache       -> Cache being served
+addr        -> Listen address used by ListenAndServe
+idleTimeout -> Per-command read deadline (0 = none)
+maxBulkLen  -> Largest accepted bulk string, in bytes
+mu          -> Protects listeners, conns, and closed
+listeners   -> Listeners being served
+conns       -> Open client connections
+closed      -> Set by Shutdown; rejects further Serve calls
+wg          -> Tracks connection handlers so Shutdown can wait for them
+*/
+
+type Server struct {
+	cache       *tempuscache.Cache
+	addr        string
+	idleTimeout time.Duration
+	maxBulkLen  int
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// New creates a Server for cache. It does not start listening.
+func New(cache *tempuscache.Cache, opts ...Option) *Server {
+	s := &Server{
+		cache:      cache,
+		addr:       DefaultAddr,
+		maxBulkLen: DefaultMaxBulkLen,
+		listeners:  make(map[net.Listener]struct{}),
+		conns:      make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+/*
+ListenAndServe listens on the configured address and serves clients
+until Shutdown is called, in which case it returns nil.
+*/
+
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+/*
+Serve accepts connections on ln until Shutdown is called, in which
+case it returns nil. ln is closed on return.
+*/
+
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+/*
+Shutdown stops accepting connections, closes client connections,
+and waits for their handlers to return or for ctx to be done.
+
+A command already being executed completes before its connection
+closes. The cache itself is not stopped.
+*/
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	for conn := range s.conns {
+		// Unblock handlers waiting for the next command; a command
+		// that is mid-execution still gets to write its reply.
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+/*
+handle reads and executes commands from one client until it
+disconnects, sends QUIT, or the server shuts down.
+*/
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		if s.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+		// Checked after arming the deadline so it cannot overwrite
+		// the one set by a concurrent Shutdown.
+		if s.isClosed() {
+			return
+		}
+
+		args, err := readCommand(r, s.maxBulkLen)
+		if err != nil {
+			var perr protocolError
+			if errors.As(err, &perr) {
+				writeError(w, "ERR Protocol error: "+string(perr))
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := s.exec(w, args)
+
+		// Pipelined commands are answered with a single write.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+/*
+exec runs one command and writes its reply.
+
+RETURNS:
+true if the client asked to close the connection.
+*/
+
+func (s *Server) exec(w *bufio.Writer, args []string) bool {
+	cmd := strings.ToUpper(args[0])
+	args = args[1:]
+
+	switch cmd {
+	case "PING":
+		switch len(args) {
+		case 0:
+			writeSimple(w, "PONG")
+		case 1:
+			writeBulk(w, args[0])
+		default:
+			writeArity(w, cmd)
+		}
+
+	case "ECHO":
+		if len(args) != 1 {
+			writeArity(w, cmd)
+			break
+		}
+		writeBulk(w, args[0])
+
+	case "QUIT":
+		writeSimple(w, "OK")
+		return true
+
+	case "SELECT":
+		if len(args) != 1 {
+			writeArity(w, cmd)
+		} else if args[0] != "0" {
+			writeError(w, "ERR DB index is out of range")
+		} else {
+			writeSimple(w, "OK")
+		}
+
+	case "COMMAND":
+		writeArrayLen(w, 0)
+
+	case "GET":
+		if len(args) != 1 {
+			writeArity(w, cmd)
+			break
+		}
+		val, found := s.cache.Get(args[0])
+		if !found {
+			writeNil(w)
+			break
+		}
+		writeBulk(w, format(val))
+
+	case "SET":
+		s.set(w, args)
+
+	case "DEL":
+		if len(args) == 0 {
+			writeArity(w, cmd)
+			break
+		}
+		writeInt(w, int64(s.cache.DeleteMany(args...)))
+
+	case "EXISTS":
+		if len(args) == 0 {
+			writeArity(w, cmd)
+			break
+		}
+		n := 0
+		for _, key := range args {
+			if _, found := s.cache.TTL(key); found {
+				n++
+			}
+		}
+		writeInt(w, int64(n))
+
+	case "TTL", "PTTL":
+		if len(args) != 1 {
+			writeArity(w, cmd)
+			break
+		}
+		ttl, found := s.cache.TTL(args[0])
+		switch {
+		case !found:
+			writeInt(w, -2)
+		case ttl == 0:
+			writeInt(w, -1)
+		case cmd == "TTL":
+			writeInt(w, int64((ttl+time.Second-1)/time.Second))
+		default:
+			writeInt(w, ttl.Milliseconds())
+		}
+
+	case "EXPIRE", "PEXPIRE":
+		if len(args) != 2 {
+			writeArity(w, cmd)
+			break
+		}
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			break
+		}
+		unit := time.Second
+		if cmd == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		if n <= 0 {
+			writeInt(w, int64(s.cache.DeleteMany(args[0])))
+			break
+		}
+		ttl, ok := duration(n, unit)
+		if !ok {
+			writeError(w, "ERR invalid expire time in '"+strings.ToLower(cmd)+"' command")
+			break
+		}
+		if s.cache.Expire(args[0], ttl) {
+			writeInt(w, 1)
+		} else {
+			writeInt(w, 0)
+		}
+
+	case "FLUSHALL", "FLUSHDB":
+		s.cache.Flush()
+		writeSimple(w, "OK")
+
+	case "INFO":
+		writeBulk(w, s.info())
+
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", truncate(cmd)))
+	}
+	return false
+}
+
+// set implements SET key value [EX seconds | PX milliseconds].
+func (s *Server) set(w *bufio.Writer, args []string) {
+	if len(args) != 2 && len(args) != 4 {
+		writeError(w, "ERR syntax error")
+		return
+	}
+
+	// A plain SET clears any previous deadline.
+	ttl := tempuscache.NoExpiration
+	if len(args) == 4 {
+		n, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		var unit time.Duration
+		switch strings.ToUpper(args[2]) {
+		case "EX":
+			unit = time.Second
+		case "PX":
+			unit = time.Millisecond
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+		var ok bool
+		if ttl, ok = duration(n, unit); !ok || n <= 0 {
+			writeError(w, "ERR invalid expire time in 'set' command")
+			return
+		}
+	}
+
+	s.cache.Set(args[0], args[1], ttl)
+	writeSimple(w, "OK")
+}
+
+// duration returns n units, and false if that overflows a time.Duration.
+func duration(n int64, unit time.Duration) (time.Duration, bool) {
+	if n > math.MaxInt64/int64(unit) {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// info renders a Redis-style INFO report from Stats().
+func (s *Server) info() string {
+	st := s.cache.Stats()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Server\r\n")
+	fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", int64(st.Uptime.Seconds()))
+	fmt.Fprintf(&b, "\r\n# Memory\r\n")
+	fmt.Fprintf(&b, "used_memory:%d\r\n", st.EstimatedBytes)
+	fmt.Fprintf(&b, "maxmemory_policy:allkeys-lru\r\n")
+	fmt.Fprintf(&b, "\r\n# Stats\r\n")
+	fmt.Fprintf(&b, "keyspace_hits:%d\r\n", st.Hits)
+	fmt.Fprintf(&b, "keyspace_misses:%d\r\n", st.Misses)
+	fmt.Fprintf(&b, "evicted_keys:%d\r\n", st.Evictions)
+	fmt.Fprintf(&b, "expired_keys:%d\r\n", st.Expirations)
+	fmt.Fprintf(&b, "\r\n# Keyspace\r\n")
+	fmt.Fprintf(&b, "db0:keys=%d\r\n", st.Entries)
+	return b.String()
+}
+
+// truncate shortens a command name for echoing in an error message.
+func truncate(cmd string) string {
+	if len(cmd) > 64 {
+		return cmd[:64]
+	}
+	return cmd
+}
+
+// format converts a cached value to its RESP bulk representation.
+func format(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(val)
+}
+
+/*
+protocolError reports malformed client input. The client receives
+an error reply and is disconnected, as Redis does.
+*/
+
+type protocolError string
+
+func (e protocolError) Error() string { return string(e) }
+
+/*
+readCommand reads one command: either a RESP array of bulk strings
+or a single inline line of space-separated words.
+
+Bulk strings longer than maxBulk are a protocol error. Shorter ones
+are read in chunks rather than allocated up front, so memory grows
+with the bytes a client actually sends, not the length it claims.
+*/
+
+func readCommand(r *bufio.Reader, maxBulk int) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArgs {
+		return nil, protocolError("invalid multibulk length")
+	}
+
+	args := make([]string, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, protocolError("expected '$'")
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulk {
+			return nil, protocolError("invalid bulk length")
+		}
+
+		var b strings.Builder
+		if _, err := io.CopyN(&b, r, int64(size)); err != nil {
+			return nil, err
+		}
+		var crlf [2]byte
+		if _, err := io.ReadFull(r, crlf[:]); err != nil {
+			return nil, err
+		}
+		if crlf != [2]byte{'\r', '\n'} {
+			return nil, protocolError("expected CRLF after bulk string")
+		}
+		args = append(args, b.String())
+	}
+	return args, nil
+}
+
+/*
+readLine reads a line terminated by "\n", stripping an optional "\r".
+Lines longer than maxInlineSize are a protocol error, so a client
+cannot grow the buffer without bound.
+*/
+
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxInlineSize {
+			return "", protocolError("too big inline request")
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		break
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return string(bytes.TrimSuffix(line, []byte("\r"))), nil
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func writeError(w *bufio.Writer, s string) {
+	w.WriteString("-" + s + "\r\n")
+}
+
+func writeArity(w *bufio.Writer, cmd string) {
+	writeError(w, "ERR wrong number of arguments for '"+strings.ToLower(cmd)+"' command")
+}
+
+func writeInt(w *bufio.Writer, n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func writeNil(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+func writeArrayLen(w *bufio.Writer, n int) {
+	w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
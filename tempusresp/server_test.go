@@ -0,0 +1,177 @@
+package tempusresp
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func startServer(t *testing.T, cache *tempuscache.Cache, opts ...Option) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(cache, opts...)
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, bufio.NewReader(conn)
+}
+
+// command sends args as a RESP array.
+func command(conn net.Conn, args ...string) {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+	}
+	conn.Write([]byte(b.String()))
+}
+
+func expect(t *testing.T, r *bufio.Reader, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != w+"\r\n" {
+			t.Fatalf("expected %q, got %q", w, line)
+		}
+	}
+}
+
+func TestRESPCommands(t *testing.T) {
+	cache := tempuscache.New()
+	conn, r := startServer(t, cache)
+
+	command(conn, "PING")
+	expect(t, r, "+PONG")
+
+	command(conn, "SET", "k", "hello")
+	expect(t, r, "+OK")
+	command(conn, "GET", "k")
+	expect(t, r, "$5", "hello")
+	command(conn, "GET", "missing")
+	expect(t, r, "$-1")
+
+	command(conn, "TTL", "k")
+	expect(t, r, ":-1")
+	command(conn, "TTL", "missing")
+	expect(t, r, ":-2")
+
+	command(conn, "SET", "e", "v", "EX", "100")
+	expect(t, r, "+OK")
+	command(conn, "TTL", "e")
+	expect(t, r, ":100")
+	command(conn, "EXPIRE", "k", "50")
+	expect(t, r, ":1")
+	command(conn, "PTTL", "k")
+	if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, ":49") && !strings.HasPrefix(line, ":50") {
+		t.Fatalf("unexpected PTTL reply %q", line)
+	}
+
+	command(conn, "EXISTS", "k", "e", "missing")
+	expect(t, r, ":2")
+	command(conn, "DEL", "k", "missing")
+	expect(t, r, ":1")
+
+	command(conn, "SET", "x", "1", "PX", "nope")
+	expect(t, r, "-ERR value is not an integer or out of range")
+	command(conn, "SET", "x", "1", "EX", "9223372036854775807")
+	expect(t, r, "-ERR invalid expire time in 'set' command")
+	command(conn, "EXPIRE", "e", "9223372036854775807")
+	expect(t, r, "-ERR invalid expire time in 'expire' command")
+
+	// A plain SET clears the deadline of the value it replaces.
+	command(conn, "SET", "e", "w")
+	expect(t, r, "+OK")
+	command(conn, "TTL", "e")
+	expect(t, r, ":-1")
+	command(conn, "NOPE")
+	expect(t, r, "-ERR unknown command 'NOPE'")
+
+	command(conn, "FLUSHALL")
+	expect(t, r, "+OK")
+	if cache.Len() != 0 {
+		t.Fatal("expected FLUSHALL to empty the cache")
+	}
+
+	cache.Set("native", 42, 0)
+	command(conn, "GET", "native")
+	expect(t, r, "$2", "42")
+}
+
+func TestRESPInlineAndPipelining(t *testing.T) {
+	conn, r := startServer(t, tempuscache.New())
+
+	conn.Write([]byte("SET a 1\r\nSET b 2\r\nGET b\r\n"))
+	expect(t, r, "+OK", "+OK", "$1", "2")
+
+	command(conn, "QUIT")
+	expect(t, r, "+OK")
+	if _, err := r.ReadByte(); err == nil {
+		t.Fatal("expected connection to be closed after QUIT")
+	}
+}
+
+func TestRESPProtocolError(t *testing.T) {
+	conn, r := startServer(t, tempuscache.New())
+
+	conn.Write([]byte("*1\r\n+GET\r\n"))
+	expect(t, r, "-ERR Protocol error: expected '$'")
+
+	conn, r = startServer(t, tempuscache.New())
+	conn.Write([]byte(strings.Repeat("x", 2*maxInlineSize)))
+	expect(t, r, "-ERR Protocol error: too big inline request")
+
+	conn, r = startServer(t, tempuscache.New(), WithMaxBulkLen(4))
+	command(conn, "SET", "a", "1234")
+	expect(t, r, "+OK")
+	command(conn, "SET", "a", "12345")
+	expect(t, r, "-ERR Protocol error: invalid bulk length")
+}
+
+func TestRESPShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tempuscache.New())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	command(conn, "PING")
+	expect(t, bufio.NewReader(conn), "+PONG")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected Serve to return nil after Shutdown, got %v", err)
+	}
+}
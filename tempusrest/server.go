@@ -0,0 +1,295 @@
+/*
+Package tempusrest serves a TempusCache over HTTP/JSON so that
+non-Go processes on the same host can share it.
+
+================================================================================
+USAGE
+================================================================================
+
+	srv := tempusrest.New(cache, tempusrest.WithAddr("127.0.0.1:7070"))
+	go srv.ListenAndServe()
+	...
+	srv.Shutdown(ctx)
+
+================================================================================
+API
+================================================================================
+
+Single keys:
+
+	GET    /v1/keys/{key}            -> {"key": K, "value": V, "ttl_ms": N}
+	PUT    /v1/keys/{key}?ttl=30s    body: any JSON value
+	DELETE /v1/keys/{key}
+	GET    /v1/keys/{key}/ttl        -> {"ttl_ms": N}
+	PUT    /v1/keys/{key}/ttl?ttl=1m (ttl omitted or 0 = never expire)
+
+Batches:
+
+	POST /v1/batch/get     {"keys": [K, ...]}             -> {"values": {K: V}}
+	POST /v1/batch/set     {"entries": {K: {"value": V, "ttl": "30s"}}}
+	POST /v1/batch/delete  {"keys": [K, ...]}             -> {"deleted": N}
+
+ttl_ms is 0 for entries that never expire. Missing keys yield 404;
+malformed requests yield 400.
+
+================================================================================
+VALUE REPRESENTATION
+================================================================================
+
+Values written over HTTP are stored as json.RawMessage, so they are
+returned byte-for-byte as written. Values written by Go code in the
+same process are encoded with encoding/json when read over HTTP.
+
+================================================================================
+SECURITY
+================================================================================
+
+The API is unauthenticated and binds to 127.0.0.1:7070 by default.
+Do not expose it to untrusted networks.
+*/
+package tempusrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// DefaultAddr is the listen address used when WithAddr is not given.
+const DefaultAddr = "127.0.0.1:7070"
+
+// maxBodyBytes bounds request bodies to protect the cache from
+// accidental or hostile oversized writes.
+const maxBodyBytes = 8 << 20
+
+/*
+Option configures a Server.
+*/
+
+type Option func(*Server)
+
+// WithAddr sets the TCP listen address (default DefaultAddr).
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.http.Addr = addr
+	}
+}
+
+// WithTimeouts sets the HTTP read and write timeouts (default 10s each).
+func WithTimeouts(read, write time.Duration) Option {
+	return func(s *Server) {
+		s.http.ReadTimeout = read
+		s.http.WriteTimeout = write
+	}
+}
+
+/*
+Server serves the REST API for a single Cache.
+*/
+
+type Server struct {
+	cache *tempuscache.Cache
+	http  *http.Server
+}
+
+// New creates a Server for cache. It does not start listening.
+func New(cache *tempuscache.Cache, opts ...Option) *Server {
+	s := &Server{cache: cache}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/keys/{key}", s.get)
+	mux.HandleFunc("PUT /v1/keys/{key}", s.set)
+	mux.HandleFunc("DELETE /v1/keys/{key}", s.delete)
+	mux.HandleFunc("GET /v1/keys/{key}/ttl", s.ttl)
+	mux.HandleFunc("PUT /v1/keys/{key}/ttl", s.expire)
+	mux.HandleFunc("POST /v1/batch/get", s.batchGet)
+	mux.HandleFunc("POST /v1/batch/set", s.batchSet)
+	mux.HandleFunc("POST /v1/batch/delete", s.batchDelete)
+
+	s.http = &http.Server{
+		Addr:         DefaultAddr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handler returns the API handler, for mounting on an existing mux.
+func (s *Server) Handler() http.Handler {
+	return s.http.Handler
+}
+
+/*
+ListenAndServe listens on the configured address and serves
+requests until Shutdown is called, in which case it returns nil.
+*/
+
+func (s *Server) ListenAndServe() error {
+	return ignoreClosed(s.http.ListenAndServe())
+}
+
+// Serve serves requests on ln until Shutdown is called.
+func (s *Server) Serve(ln net.Listener) error {
+	return ignoreClosed(s.http.Serve(ln))
+}
+
+/*
+Shutdown gracefully stops the server: it stops accepting new
+connections and waits for in-flight requests until ctx is done.
+
+The cache itself is not stopped.
+*/
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+func ignoreClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+type entryResponse struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	TTLMs int64       `json:"ttl_ms"`
+}
+
+type ttlResponse struct {
+	TTLMs int64 `json:"ttl_ms"`
+}
+
+type keysRequest struct {
+	Keys []string `json:"keys"`
+}
+
+type batchEntry struct {
+	Value json.RawMessage `json:"value"`
+	TTL   string          `json:"ttl"`
+}
+
+type batchSetRequest struct {
+	Entries map[string]batchEntry `json:"entries"`
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	val, found := s.cache.Get(key)
+	if !found {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	ttl, _ := s.cache.TTL(key)
+	writeJSON(w, entryResponse{Key: key, Value: val, TTLMs: ttl.Milliseconds()})
+}
+
+func (s *Server) set(w http.ResponseWriter, r *http.Request) {
+	ttl, err := parseTTL(r.URL.Query().Get("ttl"))
+	if err != nil {
+		http.Error(w, "invalid ttl", http.StatusBadRequest)
+		return
+	}
+
+	var val json.RawMessage
+	if err := decode(w, r, &val); err != nil {
+		http.Error(w, "invalid JSON value", http.StatusBadRequest)
+		return
+	}
+
+	s.cache.Set(r.PathValue("key"), val, ttl)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.cache.Delete(r.PathValue("key"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) ttl(w http.ResponseWriter, r *http.Request) {
+	ttl, found := s.cache.TTL(r.PathValue("key"))
+	if !found {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, ttlResponse{TTLMs: ttl.Milliseconds()})
+}
+
+func (s *Server) expire(w http.ResponseWriter, r *http.Request) {
+	ttl, err := parseTTL(r.URL.Query().Get("ttl"))
+	if err != nil {
+		http.Error(w, "invalid ttl", http.StatusBadRequest)
+		return
+	}
+	if !s.cache.Expire(r.PathValue("key"), ttl) {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) batchGet(w http.ResponseWriter, r *http.Request) {
+	var req keysRequest
+	if err := decode(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"values": s.cache.GetMany(req.Keys)})
+}
+
+func (s *Server) batchSet(w http.ResponseWriter, r *http.Request) {
+	var req batchSetRequest
+	if err := decode(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entries := make(map[string]tempuscache.Entry, len(req.Entries))
+	for key, e := range req.Entries {
+		ttl, err := parseTTL(e.TTL)
+		if err != nil {
+			http.Error(w, "invalid ttl for key "+key, http.StatusBadRequest)
+			return
+		}
+		entries[key] = tempuscache.Entry{Value: e.Value, TTL: ttl}
+	}
+
+	s.cache.SetMany(entries)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) batchDelete(w http.ResponseWriter, r *http.Request) {
+	var req keysRequest
+	if err := decode(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]int{"deleted": s.cache.DeleteMany(req.Keys...)})
+}
+
+// parseTTL parses a Go duration string; empty means no expiration.
+func parseTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,120 @@
+package tempusrest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func do(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServerKeys(t *testing.T) {
+	cache := tempuscache.New()
+	cache.Set("native", "from go", 0)
+	h := New(cache).Handler()
+
+	if rec := do(h, http.MethodPut, "/v1/keys/a?ttl=1h", `{"n":1}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := do(h, http.MethodGet, "/v1/keys/a", "")
+	var entry struct {
+		Key   string
+		Value json.RawMessage
+		TTLMs int64 `json:"ttl_ms"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if string(entry.Value) != `{"n":1}` || entry.TTLMs <= 0 {
+		t.Fatalf("unexpected entry: %s", rec.Body)
+	}
+
+	rec = do(h, http.MethodGet, "/v1/keys/native", "")
+	if !strings.Contains(rec.Body.String(), `"value":"from go"`) {
+		t.Fatalf("expected Go value to be JSON-encoded, got %s", rec.Body)
+	}
+
+	if rec := do(h, http.MethodPut, "/v1/keys/a/ttl", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec := do(h, http.MethodGet, "/v1/keys/a/ttl", ""); rec.Body.String() != "{\"ttl_ms\":0}\n" {
+		t.Fatalf("expected TTL to be cleared, got %s", rec.Body)
+	}
+
+	if rec := do(h, http.MethodPut, "/v1/keys/a?ttl=soon", "1"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid ttl, got %d", rec.Code)
+	}
+	if rec := do(h, http.MethodPut, "/v1/keys/a", "{"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid JSON, got %d", rec.Code)
+	}
+
+	do(h, http.MethodDelete, "/v1/keys/a", "")
+	if rec := do(h, http.MethodGet, "/v1/keys/a", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	cache := tempuscache.New()
+	h := New(cache).Handler()
+
+	rec := do(h, http.MethodPost, "/v1/batch/set",
+		`{"entries": {"a": {"value": 1}, "b": {"value": "x", "ttl": "1m"}}}`)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(h, http.MethodPost, "/v1/batch/get", `{"keys": ["a", "b", "c"]}`)
+	if rec.Body.String() != "{\"values\":{\"a\":1,\"b\":\"x\"}}\n" {
+		t.Fatalf("unexpected batch get response: %s", rec.Body)
+	}
+
+	rec = do(h, http.MethodPost, "/v1/batch/delete", `{"keys": ["a", "c"]}`)
+	if rec.Body.String() != "{\"deleted\":1}\n" {
+		t.Fatalf("unexpected batch delete response: %s", rec.Body)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(tempuscache.New())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/v1/keys/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected Serve to return nil after Shutdown, got %v", err)
+	}
+}
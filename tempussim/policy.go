@@ -0,0 +1,309 @@
+package tempussim
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+/*
+policy tracks the resident keys of a simulated cache and decides
+which to evict.
+
+get reports whether key is resident, recording the access. set makes
+key resident and returns the keys evicted to make room; the returned
+slice is only valid until the next call.
+*/
+
+type policy interface {
+	get(key uint64) bool
+	set(key uint64) []uint64
+	remove(key uint64)
+	clear()
+}
+
+// lru evicts the least recently used key (capacity 0 = unbounded).
+type lru struct {
+	capacity int
+	order    *list.List // front = most recently used
+	keys     map[uint64]*list.Element
+	evicted  []uint64
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{capacity: capacity, order: list.New(), keys: make(map[uint64]*list.Element)}
+}
+
+func (l *lru) get(key uint64) bool {
+	elem, ok := l.keys[key]
+	if ok {
+		l.order.MoveToFront(elem)
+	}
+	return ok
+}
+
+func (l *lru) set(key uint64) []uint64 {
+	l.evicted = l.evicted[:0]
+	if elem, ok := l.keys[key]; ok {
+		l.order.MoveToFront(elem)
+		return nil
+	}
+	if l.capacity > 0 && l.order.Len() >= l.capacity {
+		oldest := l.order.Back()
+		victim := l.order.Remove(oldest).(uint64)
+		delete(l.keys, victim)
+		l.evicted = append(l.evicted, victim)
+	}
+	l.keys[key] = l.order.PushFront(key)
+	return l.evicted
+}
+
+func (l *lru) remove(key uint64) {
+	if elem, ok := l.keys[key]; ok {
+		l.order.Remove(elem)
+		delete(l.keys, key)
+	}
+}
+
+func (l *lru) clear() {
+	l.order.Init()
+	clear(l.keys)
+}
+
+// lfu evicts the least frequently used key, the least recently used
+// among equally frequent ones.
+type lfu struct {
+	capacity int
+	tick     uint64
+	heap     lfuHeap
+	keys     map[uint64]*lfuEntry
+	evicted  []uint64
+}
+
+type lfuEntry struct {
+	key   uint64
+	freq  uint64
+	tick  uint64 // time of the last access
+	index int    // position in the heap
+}
+
+func newLFU(capacity int) *lfu {
+	return &lfu{capacity: capacity, keys: make(map[uint64]*lfuEntry)}
+}
+
+func (l *lfu) touch(e *lfuEntry) {
+	l.tick++
+	e.freq++
+	e.tick = l.tick
+	heap.Fix(&l.heap, e.index)
+}
+
+func (l *lfu) get(key uint64) bool {
+	e, ok := l.keys[key]
+	if ok {
+		l.touch(e)
+	}
+	return ok
+}
+
+func (l *lfu) set(key uint64) []uint64 {
+	l.evicted = l.evicted[:0]
+	if e, ok := l.keys[key]; ok {
+		l.touch(e)
+		return nil
+	}
+	if len(l.heap) >= l.capacity {
+		victim := heap.Pop(&l.heap).(*lfuEntry)
+		delete(l.keys, victim.key)
+		l.evicted = append(l.evicted, victim.key)
+	}
+	l.tick++
+	e := &lfuEntry{key: key, freq: 1, tick: l.tick}
+	heap.Push(&l.heap, e)
+	l.keys[key] = e
+	return l.evicted
+}
+
+func (l *lfu) remove(key uint64) {
+	if e, ok := l.keys[key]; ok {
+		heap.Remove(&l.heap, e.index)
+		delete(l.keys, key)
+	}
+}
+
+func (l *lfu) clear() {
+	l.heap = l.heap[:0]
+	clear(l.keys)
+}
+
+// lfuHeap is a min-heap of entries by (freq, tick).
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x any) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+/*
+arc is the Adaptive Replacement Cache: resident keys are split
+between t1 (seen once recently) and t2 (seen at least twice), and
+the ghost lists b1 and b2 remember keys recently evicted from each.
+A ghost hit shifts the target size p of t1 towards the list that
+would have kept the key.
+*/
+
+type arc struct {
+	capacity       int
+	p              int
+	t1, t2, b1, b2 *list.List // front = most recently used
+	keys           map[uint64]*arcEntry
+	evicted        []uint64
+}
+
+type arcEntry struct {
+	elem *list.Element
+	in   *list.List
+}
+
+func newARC(capacity int) *arc {
+	return &arc{
+		capacity: capacity,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		keys:     make(map[uint64]*arcEntry),
+	}
+}
+
+func (a *arc) resident(e *arcEntry) bool {
+	return e.in == a.t1 || e.in == a.t2
+}
+
+// move puts key at the front of to, creating its entry if needed.
+func (a *arc) move(key uint64, to *list.List) {
+	e, ok := a.keys[key]
+	if ok {
+		e.in.Remove(e.elem)
+	} else {
+		e = &arcEntry{}
+		a.keys[key] = e
+	}
+	e.elem, e.in = to.PushFront(key), to
+}
+
+// drop forgets the least recently used key of l entirely.
+func (a *arc) drop(l *list.List) {
+	key := l.Remove(l.Back()).(uint64)
+	delete(a.keys, key)
+}
+
+// replace evicts a resident key into the matching ghost list.
+func (a *arc) replace(inB2 bool) {
+	from, to := a.t2, a.b2
+	if n := a.t1.Len(); n > 0 && (n > a.p || inB2 && n == a.p) {
+		from, to = a.t1, a.b1
+	}
+	key := from.Back().Value.(uint64)
+	a.move(key, to)
+	a.evicted = append(a.evicted, key)
+}
+
+func (a *arc) full() bool {
+	return a.t1.Len()+a.t2.Len() >= a.capacity
+}
+
+func (a *arc) get(key uint64) bool {
+	e, ok := a.keys[key]
+	if !ok || !a.resident(e) {
+		return false
+	}
+	a.move(key, a.t2)
+	return true
+}
+
+func (a *arc) set(key uint64) []uint64 {
+	a.evicted = a.evicted[:0]
+	e, ok := a.keys[key]
+	switch {
+	case ok && a.resident(e):
+		a.move(key, a.t2)
+
+	case ok && e.in == a.b1:
+		a.p = min(a.capacity, a.p+max(a.b2.Len()/a.b1.Len(), 1))
+		if a.full() {
+			a.replace(false)
+		}
+		a.move(key, a.t2)
+
+	case ok && e.in == a.b2:
+		a.p = max(0, a.p-max(a.b1.Len()/a.b2.Len(), 1))
+		if a.full() {
+			a.replace(true)
+		}
+		a.move(key, a.t2)
+
+	default:
+		l1 := a.t1.Len() + a.b1.Len()
+		total := l1 + a.t2.Len() + a.b2.Len()
+		if l1 >= a.capacity {
+			if a.t1.Len() < a.capacity {
+				a.drop(a.b1)
+				if a.full() {
+					a.replace(false)
+				}
+			} else {
+				victim := a.t1.Back().Value.(uint64)
+				a.drop(a.t1)
+				a.evicted = append(a.evicted, victim)
+			}
+		} else if total >= a.capacity {
+			if total >= 2*a.capacity {
+				a.drop(a.b2)
+			}
+			if a.full() {
+				a.replace(false)
+			}
+		}
+		a.move(key, a.t1)
+	}
+	return a.evicted
+}
+
+func (a *arc) remove(key uint64) {
+	if e, ok := a.keys[key]; ok {
+		e.in.Remove(e.elem)
+		delete(a.keys, key)
+	}
+}
+
+func (a *arc) clear() {
+	a.p = 0
+	a.t1.Init()
+	a.t2.Init()
+	a.b1.Init()
+	a.b2.Init()
+	clear(a.keys)
+}
@@ -0,0 +1,96 @@
+package tempussim
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func policies(capacity int) map[Policy]policy {
+	return map[Policy]policy{
+		LRU: newLRU(capacity),
+		LFU: newLFU(capacity),
+		ARC: newARC(capacity),
+	}
+}
+
+// resident counts how many of the keys 0..keys-1 p holds.
+func resident(p policy, keys int) int {
+	n := 0
+	for k := 0; k < keys; k++ {
+		if p.get(uint64(k)) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPoliciesRespectCapacity(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for name, p := range policies(50) {
+		evictions := 0
+		for i := 0; i < 20000; i++ {
+			key := uint64(rng.IntN(200))
+			switch rng.IntN(10) {
+			case 0:
+				p.remove(key)
+			case 1, 2, 3:
+				p.get(key)
+			default:
+				evictions += len(p.set(key))
+			}
+		}
+		if n := resident(p, 200); n > 50 {
+			t.Fatalf("%s: %d resident keys exceed the capacity of 50", name, n)
+		}
+		if evictions == 0 {
+			t.Fatalf("%s: expected evictions", name)
+		}
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	p := newLRU(2)
+	p.set(1)
+	p.set(2)
+	p.get(1)
+	if evicted := p.set(3); len(evicted) != 1 || evicted[0] != 2 {
+		t.Fatalf("expected key 2 to be evicted, got %v", evicted)
+	}
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	p := newLFU(2)
+	p.set(1)
+	p.set(2)
+	p.get(1)
+	p.get(2)
+	p.get(2)
+	if evicted := p.set(3); len(evicted) != 1 || evicted[0] != 1 {
+		t.Fatalf("expected key 1 to be evicted, got %v", evicted)
+	}
+}
+
+func TestARCResistsScans(t *testing.T) {
+	hits := map[Policy]int{}
+	for name, p := range map[Policy]policy{LRU: newLRU(100), ARC: newARC(100)} {
+		for round := 0; round < 50; round++ {
+			// A hot set of 50 keys, read twice per round...
+			for pass := 0; pass < 2; pass++ {
+				for k := uint64(0); k < 50; k++ {
+					if p.get(k) {
+						hits[name]++
+					} else {
+						p.set(k)
+					}
+				}
+			}
+			// ...interleaved with a scan of 100 keys never read again.
+			for k := uint64(0); k < 100; k++ {
+				p.set(1_000_000 + uint64(round)*100 + k)
+			}
+		}
+	}
+	if hits[ARC] <= hits[LRU] {
+		t.Fatalf("expected ARC to keep the hot set through scans better than LRU, got %d vs %d hits", hits[ARC], hits[LRU])
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Package tempussim replays operation traces against simulated cache
+configurations, to compare eviction policies, capacities, and TTLs
+on a real workload before deploying them.
+
+================================================================================
+USAGE
+================================================================================
+
+Record a trace in production with tempuscache.WithTrace, then:
+
+	f, _ := os.Open("cache.trace")
+	results, err := tempussim.Run(f,
+	    tempussim.Config{Policy: tempussim.LRU, Capacity: 10000},
+	    tempussim.Config{Policy: tempussim.LFU, Capacity: 10000},
+	    tempussim.Config{Policy: tempussim.ARC, Capacity: 10000},
+	    tempussim.Config{Policy: tempussim.ARC, Capacity: 10000, TTL: time.Minute},
+	)
+	for _, r := range results {
+	    fmt.Printf("%-20s hit ratio %.3f, %d evictions\n", r.Config, r.HitRatio(), r.Evictions)
+	}
+
+================================================================================
+REPLAY MODEL
+================================================================================
+
+The trace is read once and fed to every configuration. Time is
+taken from the record timestamps, so replay runs as fast as the
+trace can be read, whatever period it covers.
+
+  - Hit and miss records are reads, looked up in the simulated cache.
+  - A read that misses in the simulation but hit in the recorded
+    cache is filled, as the application would have loaded the value.
+    Reads that missed in the recorded cache too are filled by the
+    set record that follows them, if the application wrote one.
+  - Set records write the key; deleted and flushed records remove
+    keys. Evicted and expired records are ignored: they reflect the
+    recorded cache's configuration, not the simulated one.
+
+Keys are identified by the hash stored in the trace; values are not
+needed, so only entry counts are simulated.
+*/
+package tempussim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// Policy selects the eviction policy of a simulated cache.
+type Policy string
+
+const (
+	LRU Policy = "lru" // Least recently used, as tempuscache itself
+	LFU Policy = "lfu" // Least frequently used, ties broken by recency
+	ARC Policy = "arc" // Adaptive replacement cache (Megiddo & Modha)
+)
+
+/*
+Config describes one simulated cache.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Policy   -> Eviction policy (default LRU)
+Capacity -> Maximum number of entries (0 = unbounded, nothing is evicted)
+TTL      -> Lifetime given to every written entry (0 = never expires)
+*/
+
+type Config struct {
+	Policy   Policy
+	Capacity int
+	TTL      time.Duration
+}
+
+// String describes c compactly, e.g. "arc/10000/ttl=1m0s".
+func (c Config) String() string {
+	policy := c.Policy
+	if policy == "" {
+		policy = LRU
+	}
+	s := fmt.Sprintf("%s/%d", policy, c.Capacity)
+	if c.TTL > 0 {
+		s += "/ttl=" + c.TTL.String()
+	}
+	return s
+}
+
+/*
+Result reports how a simulated cache performed on a trace.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Config      -> The simulated configuration
+Reads       -> Hit and miss records replayed
+Hits        -> Reads that found a live entry
+Misses      -> Reads that did not
+Writes      -> Entries written (set records and fills)
+Evictions   -> Entries removed by the policy to make room
+Expirations -> Entries found expired by a read
+*/
+
+type Result struct {
+	Config      Config
+	Reads       uint64
+	Hits        uint64
+	Misses      uint64
+	Writes      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// HitRatio returns Hits / Reads, or 0 for a trace without reads.
+func (r Result) HitRatio() float64 {
+	if r.Reads == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Reads)
+}
+
+/*
+Run replays the trace read from r against every configuration and
+returns one Result per configuration, in the same order.
+*/
+
+func Run(r io.Reader, configs ...Config) ([]Result, error) {
+	sims := make([]*simulator, len(configs))
+	for i, cfg := range configs {
+		sim, err := newSimulator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sims[i] = sim
+	}
+
+	tr, err := tempuscache.NewTraceReader(r)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, sim := range sims {
+			sim.apply(rec)
+		}
+	}
+
+	results := make([]Result, len(sims))
+	for i, sim := range sims {
+		results[i] = sim.result
+	}
+	return results, nil
+}
+
+/*
+simulator replays records against one configuration.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+policy  -> Resident keys and eviction decisions
+expires -> Deadline of every resident key with a TTL
+result  -> Counters reported by Run
+*/
+
+type simulator struct {
+	policy  policy
+	expires map[uint64]time.Time
+	result  Result
+}
+
+func newSimulator(cfg Config) (*simulator, error) {
+	var p policy
+	switch {
+	case cfg.Capacity < 0:
+		return nil, fmt.Errorf("tempussim: negative capacity %d", cfg.Capacity)
+	case cfg.Capacity == 0:
+		p = newLRU(0)
+	case cfg.Policy == "" || cfg.Policy == LRU:
+		p = newLRU(cfg.Capacity)
+	case cfg.Policy == LFU:
+		p = newLFU(cfg.Capacity)
+	case cfg.Policy == ARC:
+		p = newARC(cfg.Capacity)
+	default:
+		return nil, fmt.Errorf("tempussim: unknown policy %q", cfg.Policy)
+	}
+	return &simulator{
+		policy:  p,
+		expires: make(map[uint64]time.Time),
+		result:  Result{Config: cfg},
+	}, nil
+}
+
+func (s *simulator) apply(rec tempuscache.TraceRecord) {
+	switch rec.Op {
+	case tempuscache.EventHit, tempuscache.EventMiss:
+		s.result.Reads++
+		if s.read(rec.KeyHash, rec.Time) {
+			s.result.Hits++
+			return
+		}
+		s.result.Misses++
+		if rec.Op == tempuscache.EventHit {
+			s.write(rec.KeyHash, rec.Time)
+		}
+	case tempuscache.EventSet:
+		s.write(rec.KeyHash, rec.Time)
+	case tempuscache.EventDeleted:
+		s.remove(rec.KeyHash)
+	case tempuscache.EventFlushed:
+		s.policy.clear()
+		clear(s.expires)
+	}
+}
+
+// read looks key up at now, removing it if it has expired.
+func (s *simulator) read(key uint64, now time.Time) bool {
+	if deadline, ok := s.expires[key]; ok && now.After(deadline) {
+		s.remove(key)
+		s.result.Expirations++
+		return false
+	}
+	return s.policy.get(key)
+}
+
+// write stores key at now, evicting as the policy decides.
+func (s *simulator) write(key uint64, now time.Time) {
+	s.result.Writes++
+	for _, evicted := range s.policy.set(key) {
+		delete(s.expires, evicted)
+		s.result.Evictions++
+	}
+	if ttl := s.result.Config.TTL; ttl > 0 {
+		s.expires[key] = now.Add(ttl)
+	}
+}
+
+func (s *simulator) remove(key uint64) {
+	s.policy.remove(key)
+	delete(s.expires, key)
+}
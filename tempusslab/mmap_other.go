@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package tempusslab
+
+// mmapSlab falls back to a heap slab where anonymous mappings are not
+// supported; WithMmap then has no effect.
+func mmapSlab(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func munmapSlab(b []byte) error {
+	return nil
+}
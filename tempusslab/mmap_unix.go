@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package tempusslab
+
+import "syscall"
+
+/*
+mmapSlab maps size bytes of anonymous, private memory outside the Go
+heap. The kernel backs pages lazily, so an untouched slab costs only
+address space.
+*/
+
+func mmapSlab(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// munmapSlab releases a slab returned by mmapSlab.
+func munmapSlab(b []byte) error {
+	return syscall.Munmap(b)
+}
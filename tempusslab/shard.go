@@ -0,0 +1,322 @@
+package tempusslab
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+shard.go implements one shard of the slab cache: a byte ring holding
+serialized entries plus a pointer-free index into it.
+
+================================================================================
+ENTRY LAYOUT
+================================================================================
+
+Each entry is stored contiguously in the ring and never wraps around
+its end:
+
+    [0:4]   total entry length (uint32, little-endian)
+    [4:12]  expiration in UnixNano (0 = never expires)
+    [12:20] key hash
+    [20:22] key length (uint16)
+    [22:]   key bytes, then value bytes
+
+================================================================================
+RING
+================================================================================
+
+Entries are appended at tail and evicted from head, oldest first:
+
+    not wrapped:  [ free | head ... tail | free ]
+    wrapped:      [ ... tail | free | head ... end | unused ]
+
+When an entry does not fit between tail and the end of the slab,
+the ring wraps: end remembers where the data stops and writing
+resumes at offset 0, evicting entries at head until the new entry
+fits. Overwritten and deleted entries are not reclaimed early; their
+bytes are recovered when head passes over them.
+
+The index maps a key hash to the offset of its latest entry. An entry
+at head is only dropped from the index if the index still points at
+it, so garbage left by overwrites and deletes never removes a live key.
+*/
+
+const headerSize = 22
+
+/*
+shard is one independently locked partition of the cache.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+mu      -> Guards every field below
+index   -> Key hash -> offset of the entry in buf
+buf     -> The slab; allocated on first write
+size    -> Capacity of buf in bytes
+mmap    -> Whether buf is mapped outside the Go heap (see WithMmap)
+head    -> Offset of the oldest entry
+tail    -> Offset where the next entry is written
+end     -> End of the data before the wrap (valid while wrapped)
+wrapped -> Whether tail has wrapped around behind head
+queued  -> Entries in the ring, including overwritten and deleted ones
+
+hits, misses, collisions, evictions -> Lifetime counters (see Stats),
+updated without the lock
+*/
+
+type shard struct {
+	mu      sync.RWMutex
+	index   map[uint64]uint32
+	buf     []byte
+	size    int
+	mmap    bool
+	head    int
+	tail    int
+	end     int
+	wrapped bool
+	queued  int
+
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	collisions atomic.Uint64
+	evictions  atomic.Uint64
+}
+
+func newShard(size int, mmap bool) *shard {
+	return &shard{index: make(map[uint64]uint32), size: size, mmap: mmap}
+}
+
+// lookup outcomes reported by get.
+const (
+	found = iota
+	missing
+	collision
+	expired
+)
+
+/*
+get returns a copy of the value stored for key, and the outcome of
+the lookup. An expired entry is dropped from the index.
+*/
+
+func (s *shard) get(key string, hash uint64, now int64) ([]byte, int) {
+	s.mu.RLock()
+	off, ok := s.index[hash]
+	if !ok {
+		s.mu.RUnlock()
+		return nil, missing
+	}
+
+	entry := s.entry(int(off))
+	if !hasKey(entry, key) {
+		s.mu.RUnlock()
+		return nil, collision
+	}
+	if exp := int64(binary.LittleEndian.Uint64(entry[4:])); exp != 0 && now > exp {
+		s.mu.RUnlock()
+		s.drop(hash, off)
+		return nil, expired
+	}
+
+	value := append([]byte(nil), entry[headerSize+len(key):]...)
+	s.mu.RUnlock()
+	return value, found
+}
+
+/*
+set appends a new entry for key and points the index at it, evicting
+the oldest entries as needed.
+
+RETURNS:
+The number of live entries evicted to make room, or an error if
+the slab could not be mapped.
+*/
+
+func (s *shard) set(key string, hash uint64, value []byte, exp int64) (int, error) {
+	n := headerSize + len(key) + len(value)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buf == nil {
+		if err := s.allocate(); err != nil {
+			return 0, err
+		}
+	}
+
+	off, evicted := s.reserve(n)
+	entry := s.buf[off : off+n]
+	binary.LittleEndian.PutUint32(entry[0:], uint32(n))
+	binary.LittleEndian.PutUint64(entry[4:], uint64(exp))
+	binary.LittleEndian.PutUint64(entry[12:], hash)
+	binary.LittleEndian.PutUint16(entry[20:], uint16(len(key)))
+	copy(entry[headerSize:], key)
+	copy(entry[headerSize+len(key):], value)
+
+	s.index[hash] = uint32(off)
+	return evicted, nil
+}
+
+/*
+allocate creates the slab, on the heap or as an anonymous mapping.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (s *shard) allocate() error {
+	if !s.mmap {
+		s.buf = make([]byte, s.size)
+		return nil
+	}
+	buf, err := mmapSlab(s.size)
+	if err != nil {
+		return err
+	}
+	s.buf = buf
+	return nil
+}
+
+/*
+reserve claims n bytes at tail, wrapping and evicting from head as
+needed, and returns their offset. n must not exceed s.size.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (s *shard) reserve(n int) (off, evicted int) {
+	for {
+		if s.queued == 0 {
+			s.head, s.tail, s.wrapped = 0, 0, false
+		}
+
+		if !s.wrapped {
+			if s.size-s.tail >= n {
+				break
+			}
+			s.end, s.tail, s.wrapped = s.tail, 0, true
+			continue
+		}
+
+		if s.head-s.tail >= n {
+			break
+		}
+		if s.evictHead() {
+			evicted++
+		}
+	}
+
+	off = s.tail
+	s.tail += n
+	s.queued++
+	return off, evicted
+}
+
+/*
+evictHead removes the oldest entry from the ring, and from the index
+if it is still the key's latest entry.
+
+RETURNS:
+true if a live entry was evicted.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (s *shard) evictHead() bool {
+	entry := s.entry(s.head)
+	hash := binary.LittleEndian.Uint64(entry[12:])
+
+	live := false
+	if off, ok := s.index[hash]; ok && int(off) == s.head {
+		delete(s.index, hash)
+		live = true
+	}
+
+	s.head += len(entry)
+	s.queued--
+	if s.wrapped && s.head == s.end {
+		s.head, s.wrapped = 0, false
+	}
+	return live
+}
+
+/*
+delete removes key from the index; its bytes are reclaimed when the
+ring wraps over them.
+
+RETURNS:
+true if key was present.
+*/
+
+func (s *shard) delete(key string, hash uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	off, ok := s.index[hash]
+	if !ok || !hasKey(s.entry(int(off)), key) {
+		return false
+	}
+	delete(s.index, hash)
+	return true
+}
+
+// drop removes hash from the index if it still points at off.
+func (s *shard) drop(hash uint64, off uint32) {
+	s.mu.Lock()
+	if cur, ok := s.index[hash]; ok && cur == off {
+		delete(s.index, hash)
+	}
+	s.mu.Unlock()
+}
+
+// len returns the number of keys in the index.
+func (s *shard) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.index)
+}
+
+// reset drops every entry but keeps the slab for reuse.
+func (s *shard) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.index)
+	s.head, s.tail, s.end, s.wrapped, s.queued = 0, 0, 0, false, 0
+}
+
+/*
+release drops every entry and frees the slab. A mapped slab is
+unmapped; a heap slab is left to the garbage collector.
+*/
+
+func (s *shard) release() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.index)
+	s.head, s.tail, s.end, s.wrapped, s.queued = 0, 0, 0, false, 0
+	buf := s.buf
+	s.buf = nil
+	if s.mmap && buf != nil {
+		return munmapSlab(buf)
+	}
+	return nil
+}
+
+// entry returns the entry stored at off.
+func (s *shard) entry(off int) []byte {
+	n := binary.LittleEndian.Uint32(s.buf[off:])
+	return s.buf[off : off+int(n)]
+}
+
+// hasKey reports whether entry belongs to key. The string conversion
+// in a comparison does not allocate.
+func hasKey(entry []byte, key string) bool {
+	n := int(binary.LittleEndian.Uint16(entry[20:]))
+	return string(entry[headerSize:headerSize+n]) == key
+}
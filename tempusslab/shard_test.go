@@ -0,0 +1,69 @@
+package tempusslab
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardWrapsAndEvictsOldest(t *testing.T) {
+	// Room for exactly four 32-byte entries.
+	s := newShard(4*(headerSize+10), false)
+	value := make([]byte, 8)
+
+	evicted := 0
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("k%d", i) // 2 bytes
+		n, _ := s.set(key, hashKey(key), value, 0)
+		evicted += n
+	}
+
+	if evicted != 6 {
+		t.Fatalf("expected 6 evictions, got %d", evicted)
+	}
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("k%d", i)
+		_, outcome := s.get(key, hashKey(key), 0)
+		if want := i >= 6; (outcome == found) != want {
+			t.Fatalf("%s: expected present=%v, got outcome %d", key, want, outcome)
+		}
+	}
+}
+
+func TestShardGarbageDoesNotEvictLiveKey(t *testing.T) {
+	s := newShard(3*(headerSize+2), false)
+
+	s.set("a", hashKey("a"), []byte("1"), 0)
+	s.set("a", hashKey("a"), []byte("2"), 0) // the first entry is now garbage
+	s.set("b", hashKey("b"), []byte("3"), 0)
+
+	// Wrapping reclaims the garbage entry of "a" first.
+	if n, _ := s.set("c", hashKey("c"), []byte("4"), 0); n != 0 {
+		t.Fatalf("expected reclaiming garbage to evict nothing, got %d", n)
+	}
+	if v, outcome := s.get("a", hashKey("a"), 0); outcome != found || string(v) != "2" {
+		t.Fatalf("expected the latest value of 'a', got %q (outcome %d)", v, outcome)
+	}
+	if s.len() != 3 {
+		t.Fatalf("expected 3 keys, got %d", s.len())
+	}
+}
+
+func TestShardVariableSizes(t *testing.T) {
+	s := newShard(1000, false)
+	latest := map[string]string{}
+
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("key-%d", i%37)
+		value := fmt.Sprintf("%0*d", i%90, i)
+		s.set(key, hashKey(key), []byte(value), 0)
+		latest[key] = value
+
+		// Whatever survived must be the latest write.
+		for k, want := range latest {
+			got, outcome := s.get(k, hashKey(k), 0)
+			if outcome == found && string(got) != want {
+				t.Fatalf("step %d: %s = %q, want %q", i, k, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,418 @@
+/*
+Package tempusslab is a GC-friendly cache for very large numbers of
+entries, storing serialized values in large byte slabs.
+
+================================================================================
+WHY?
+================================================================================
+
+A tempuscache.Cache keeps every entry as an Item, a list node, and an
+interface value: several pointers per entry that the garbage
+collector must scan on every cycle. At tens of millions of entries
+that scanning shows up directly in GC pauses, however little the
+cache changes.
+
+This package follows the design popularised by bigcache: values are
+copied into a few large []byte slabs, and each key is found through a
+map[uint64]uint32 from key hash to slab offset. Neither contains
+pointers, so the collector skips them entirely, and the per-entry
+cost is a map slot plus the bytes of the entry itself.
+
+================================================================================
+USAGE
+================================================================================
+
+	cache := tempusslab.New(tempusslab.WithCapacity(4 << 30))
+
+	data, _ := json.Marshal(profile)
+	cache.Set("user:42", data, 10*time.Minute)
+
+	if data, ok := cache.Get("user:42"); ok {
+		json.Unmarshal(data, &profile)
+	}
+
+Callers serialize values themselves; Get returns a copy of the bytes.
+
+================================================================================
+TRADE-OFFS
+================================================================================
+
+Compared with tempuscache.Cache:
+
+  - Eviction is FIFO per shard, not LRU: when a shard's slab is full,
+    its oldest entries are dropped regardless of how often they are
+    read. Reads never write, so they only take a shard read lock.
+  - Memory is bounded in bytes (WithCapacity), split evenly across
+    shards. Overwritten and deleted entries occupy their slab space
+    until the ring wraps over them.
+  - Expired entries are dropped when read or when the ring wraps
+    over them; there is no janitor.
+  - Keys are indexed by a 64-bit hash. If two keys collide, the later
+    write wins and reads of the other key miss (counted as Collisions).
+  - None of the read-through, persistence, or event features of
+    tempuscache.Cache are available.
+
+================================================================================
+OFF-HEAP STORAGE
+================================================================================
+
+With WithMmap, slabs are anonymous memory mappings rather than Go
+allocations: only the index and the Cache itself live on the Go
+heap. This suits large values such as multi-megabyte rendered blobs,
+which then never count towards GOGC's heap target. Size shards for
+the largest value (WithShards with a small count, WithCapacity
+large enough), and call Close to unmap the slabs.
+
+Tier adapts a Cache to tempuscache.Tier, so an off-heap slab cache
+can sit under a small in-heap tempuscache.Cache:
+
+	blobs := tempusslab.New(tempusslab.WithMmap(), tempusslab.WithShards(16),
+		tempusslab.WithCapacity(8<<30))
+	defer blobs.Close()
+	tiered := tempuscache.NewTiered(tempuscache.New(tempuscache.WithMaxEntries(100)),
+		tempusslab.NewTier(blobs))
+*/
+package tempusslab
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// ErrEntryTooLarge is returned by Set when an entry cannot fit in a
+// single shard's slab, or its key is longer than 65535 bytes. It
+// wraps tempuscache.ErrTooLarge.
+var ErrEntryTooLarge = fmt.Errorf("tempusslab: entry does not fit: %w", tempuscache.ErrTooLarge)
+
+const (
+	// DefaultShards is the number of shards used unless WithShards is given.
+	DefaultShards = 256
+
+	// DefaultCapacity is the total slab size used unless WithCapacity is given.
+	DefaultCapacity = 256 << 20
+)
+
+/*
+Option configures a Cache.
+*/
+
+type Option func(*Cache)
+
+// WithShards sets the number of shards, rounded up to a power of two
+// (default DefaultShards). More shards reduce lock contention but
+// shrink the largest entry a shard can hold.
+func WithShards(n int) Option {
+	return func(c *Cache) {
+		c.shardCount = n
+	}
+}
+
+// WithCapacity sets the total size of all slabs in bytes (default
+// DefaultCapacity). Each shard's slab is allocated on its first write.
+func WithCapacity(bytes int64) Option {
+	return func(c *Cache) {
+		c.capacity = bytes
+	}
+}
+
+// WithMmap stores slabs in anonymous memory mappings outside the Go
+// heap (see OFF-HEAP STORAGE). On platforms without mmap support,
+// slabs stay on the heap.
+func WithMmap() Option {
+	return func(c *Cache) {
+		c.mmap = true
+	}
+}
+
+// WithHasher replaces the key hash used for shard selection and the
+// index (default: 64-bit FNV-1a), e.g. with tempuscache.MapHash. h
+// must be deterministic and safe for concurrent use. Its quality
+// matters more here than in tempuscache.Cache: keys whose hashes
+// collide evict each other (see Collisions), and shards are chosen
+// by the hash's low bits.
+func WithHasher(h func(key string) uint64) Option {
+	return func(c *Cache) {
+		c.hash = h
+	}
+}
+
+// WithClock sets the time source for expiration (default
+// tempuscache.SystemClock).
+func WithClock(clock tempuscache.Clock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+/*
+Stats is a point-in-time view of cache activity, for the whole cache
+(Stats) or one shard (ShardStats).
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Hits       -> Successful Get calls
+Misses     -> Get calls for missing or expired keys
+Collisions -> Misses caused by another key holding the same hash
+Evictions  -> Live entries dropped to make room in a full slab
+Entries    -> Current number of indexed keys
+*/
+
+type Stats struct {
+	Hits       uint64
+	Misses     uint64
+	Collisions uint64
+	Evictions  uint64
+	Entries    int
+}
+
+/*
+Cache is a sharded byte-slab cache. It is safe for concurrent use.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+shards     -> Independently locked partitions, selected by key hash
+mask       -> len(shards) - 1
+shardCount -> Requested shard count (set by options)
+capacity   -> Requested total slab size (set by options)
+mmap       -> Whether slabs are mapped off-heap (set by WithMmap)
+clock      -> Time source for expiration
+hash       -> Key hash for shard selection and the index (see WithHasher)
+
+Lifetime counters are kept per shard (see ShardStats).
+*/
+
+type Cache struct {
+	shards     []*shard
+	mask       uint64
+	shardCount int
+	capacity   int64
+	mmap       bool
+	clock      tempuscache.Clock
+	hash       func(key string) uint64
+}
+
+/*
+New creates an empty Cache.
+
+A shard's slab is capped at 4 GiB, since offsets are stored as
+uint32; larger capacities need more shards.
+*/
+
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		shardCount: DefaultShards,
+		capacity:   DefaultCapacity,
+		clock:      tempuscache.SystemClock,
+		hash:       hashKey,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.hash == nil {
+		c.hash = hashKey
+	}
+
+	n := 1
+	for n < c.shardCount {
+		n <<= 1
+	}
+	size := c.capacity / int64(n)
+	size = max(headerSize, min(size, math.MaxUint32))
+
+	c.shards = make([]*shard, n)
+	for i := range c.shards {
+		c.shards[i] = newShard(int(size), c.mmap)
+	}
+	c.mask = uint64(n - 1)
+	return c
+}
+
+/*
+Get returns a copy of the value stored for key.
+
+RETURNS:
+- (value, true) -> Key exists and is unexpired
+- (nil, false)  -> Key is missing, expired, evicted, or collided
+*/
+
+func (c *Cache) Get(key string) ([]byte, bool) {
+	hash := c.hash(key)
+	s := c.shard(hash)
+	value, outcome := s.get(key, hash, c.clock.Now().UnixNano())
+	switch outcome {
+	case found:
+		s.hits.Add(1)
+		return value, true
+	case collision:
+		s.collisions.Add(1)
+	}
+	s.misses.Add(1)
+	return nil, false
+}
+
+/*
+Set stores a copy of value under key.
+
+PARAMETERS:
+- ttl > 0  -> The entry expires ttl from now
+- ttl <= 0 -> The entry never expires
+
+If the key's shard is full, its oldest entries are evicted first.
+
+ERRORS:
+Returns ErrEntryTooLarge if the entry cannot fit in one shard's slab
+or the key is longer than 65535 bytes, or the mmap error if an
+off-heap slab could not be mapped; nothing is stored.
+*/
+
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) error {
+	hash := c.hash(key)
+	s := c.shard(hash)
+	if len(key) > math.MaxUint16 || headerSize+len(key)+len(value) > s.size {
+		return ErrEntryTooLarge
+	}
+
+	var exp int64
+	if ttl > 0 {
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	}
+	n, err := s.set(key, hash, value, exp)
+	if n > 0 {
+		s.evictions.Add(uint64(n))
+	}
+	return err
+}
+
+/*
+Delete removes key.
+
+RETURNS:
+true if the key was present.
+*/
+
+func (c *Cache) Delete(key string) bool {
+	hash := c.hash(key)
+	return c.shard(hash).delete(key, hash)
+}
+
+/*
+Len returns the number of indexed keys. Expired entries that have
+not yet been read or evicted are included.
+*/
+
+func (c *Cache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.len()
+	}
+	return n
+}
+
+// Reset removes every entry. Slabs are kept for reuse.
+func (c *Cache) Reset() {
+	for _, s := range c.shards {
+		s.reset()
+	}
+}
+
+/*
+Close removes every entry and frees the slabs, unmapping them when
+WithMmap is used. The cache stays usable: later writes allocate new
+slabs, which need another Close.
+
+Values returned by Get are copies, so they remain valid after Close.
+*/
+
+func (c *Cache) Close() error {
+	var first error
+	for _, s := range c.shards {
+		if err := s.release(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Stats returns the lifetime counters and the current entry count,
+// summed over all shards.
+func (c *Cache) Stats() Stats {
+	var total Stats
+	for _, s := range c.ShardStats() {
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		total.Collisions += s.Collisions
+		total.Evictions += s.Evictions
+		total.Entries += s.Entries
+	}
+	return total
+}
+
+/*
+ShardStats returns the Stats of each shard, indexed by shard number.
+
+================================================================================
+WHY?
+================================================================================
+
+Keys are spread over shards by hash. A poor spread (many keys with
+a long common prefix, or a few very hot keys) overloads some shards:
+their slabs churn, evicting entries early, while others sit idle.
+Aggregate Stats hide this; comparing shards exposes it:
+
+- Entries or Evictions far above the mean → keys hash unevenly;
+  more shards spread them, or the key scheme needs changing.
+- Hits far above the mean → hot keys; more shards do not help.
+- HitRatio far below the mean → the shard's slab is too small for
+  its share of keys: fewer shards give every slab more room.
+
+Counters of different shards are read one after the other, not as
+one atomic snapshot.
+*/
+
+func (c *Cache) ShardStats() []Stats {
+	out := make([]Stats, len(c.shards))
+	for i, s := range c.shards {
+		out[i] = Stats{
+			Hits:       s.hits.Load(),
+			Misses:     s.misses.Load(),
+			Collisions: s.collisions.Load(),
+			Evictions:  s.evictions.Load(),
+			Entries:    s.len(),
+		}
+	}
+	return out
+}
+
+// HitRatio returns Hits / (Hits + Misses), or 0 before any lookup.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// shard returns the shard owning hash.
+func (c *Cache) shard(hash uint64) *shard {
+	return c.shards[hash&c.mask]
+}
+
+// hashKey is 64-bit FNV-1a, inlined to avoid allocating a hash.Hash.
+func hashKey(key string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	return h
+}
@@ -0,0 +1,260 @@
+package tempusslab
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+	"github.com/Krishna8167/tempuscache/v2/tempustest"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	c := New(WithShards(4), WithCapacity(1<<16))
+
+	if err := c.Set("k", []byte("v1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("k", []byte("v2"), 0)
+
+	v, ok := c.Get("k")
+	if !ok || string(v) != "v2" {
+		t.Fatalf("expected v2, got %q, %v", v, ok)
+	}
+	v[0] = 'x'
+	if v, _ := c.Get("k"); string(v) != "v2" {
+		t.Fatal("expected Get to return a copy")
+	}
+
+	if !c.Delete("k") || c.Delete("k") {
+		t.Fatal("expected exactly one successful delete")
+	}
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected a miss after delete")
+	}
+
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 || s.Entries != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	clock := tempustest.NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(WithClock(clock))
+
+	c.Set("short", []byte("a"), time.Second)
+	c.Set("forever", []byte("b"), 0)
+	clock.Advance(2 * time.Second)
+
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("expected the entry to expire")
+	}
+	if _, ok := c.Get("forever"); !ok {
+		t.Fatal("expected the entry without TTL to survive")
+	}
+	if n := c.Len(); n != 1 {
+		t.Fatalf("expected the expired entry to be dropped on read, got %d entries", n)
+	}
+}
+
+func TestEntryTooLarge(t *testing.T) {
+	c := New(WithShards(1), WithCapacity(64))
+
+	if err := c.Set("k", make([]byte, 64), 0); err != ErrEntryTooLarge || !errors.Is(err, tempuscache.ErrTooLarge) {
+		t.Fatalf("expected ErrEntryTooLarge, got %v", err)
+	}
+	if err := c.Set(string(make([]byte, 1<<16)), nil, 0); err != ErrEntryTooLarge {
+		t.Fatalf("expected ErrEntryTooLarge for a long key, got %v", err)
+	}
+	if err := c.Set("k", make([]byte, 64-headerSize-1), 0); err != nil {
+		t.Fatalf("expected an exactly fitting entry to be stored, got %v", err)
+	}
+}
+
+func TestCollisionMisses(t *testing.T) {
+	c := New(WithShards(1))
+	c.Set("a", []byte("1"), 0)
+
+	// Make "b" resolve to the entry of "a", as a hash collision would.
+	s := c.shard(hashKey("b"))
+	s.index[hashKey("b")] = s.index[hashKey("a")]
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected a collision to miss")
+	}
+	if s := c.Stats(); s.Collisions != 1 || s.Misses != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestWithHasher(t *testing.T) {
+	// A constant hash makes every pair of keys collide.
+	c := New(WithShards(4), WithHasher(func(string) uint64 { return 7 }))
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), 0)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected the later write to take over the hash")
+	}
+	if v, ok := c.Get("b"); !ok || string(v) != "2" {
+		t.Fatalf("expected b to be stored, got %q", v)
+	}
+	if s := c.ShardStats(); s[7&3].Entries != 1 || s[7&3].Collisions != 1 {
+		t.Fatalf("expected the hash to select the shard, got %+v", s)
+	}
+
+	mapped := New(WithHasher(tempuscache.MapHash()))
+	mapped.Set("a", []byte("1"), 0)
+	if _, ok := mapped.Get("a"); !ok {
+		t.Fatal("expected MapHash to work as a hasher")
+	}
+}
+
+func TestShardCountRoundsUp(t *testing.T) {
+	if c := New(WithShards(5)); len(c.shards) != 8 {
+		t.Fatalf("expected 8 shards, got %d", len(c.shards))
+	}
+}
+
+func TestShardStats(t *testing.T) {
+	c := New(WithShards(4), WithCapacity(1<<16))
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("k%d", i)
+		c.Set(key, []byte("v"), 0)
+		c.Get(key)
+		c.Get(key + "-missing")
+	}
+
+	shards := c.ShardStats()
+	if len(shards) != 4 {
+		t.Fatalf("expected 4 shards, got %d", len(shards))
+	}
+	var entries int
+	var hits uint64
+	for i, s := range shards {
+		if s.Entries == 0 || s.Entries == 100 {
+			t.Fatalf("shard %d: expected keys to be spread, got %d", i, s.Entries)
+		}
+		if s.HitRatio() <= 0 || s.HitRatio() >= 1 {
+			t.Fatalf("shard %d: unexpected hit ratio %v", i, s.HitRatio())
+		}
+		entries += s.Entries
+		hits += s.Hits
+	}
+	if total := c.Stats(); total.Entries != entries || total.Hits != hits || total.Hits != 100 || total.Misses != 100 {
+		t.Fatalf("expected Stats to sum the shards, got %+v", total)
+	}
+	if r := (Stats{}).HitRatio(); r != 0 {
+		t.Fatalf("expected 0 before any lookup, got %v", r)
+	}
+}
+
+func TestEvictionAndReset(t *testing.T) {
+	c := New(WithShards(1), WithCapacity(10*(headerSize+8)))
+
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("k%03d", i), []byte("valu"), 0)
+	}
+	if s := c.Stats(); s.Evictions != 90 || s.Entries != 10 {
+		t.Fatalf("expected FIFO eviction to keep 10 entries, got %+v", s)
+	}
+	if _, ok := c.Get("k099"); !ok {
+		t.Fatal("expected the newest entry to survive")
+	}
+
+	c.Reset()
+	if c.Len() != 0 {
+		t.Fatal("expected Reset to drop every entry")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	c := New(WithShards(4), WithCapacity(1<<14))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := fmt.Sprintf("k%d", (g+i)%200)
+				want := []byte(key + "-value")
+				switch i % 3 {
+				case 0:
+					c.Set(key, want, time.Minute)
+				case 1:
+					c.Delete(key)
+				default:
+					if v, ok := c.Get(key); ok && !bytes.Equal(v, want) {
+						t.Errorf("%s: got %q", key, v)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkSet(b *testing.B) {
+	c := New()
+	keys := make([]string, 1<<16)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	value := make([]byte, 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(keys[i%len(keys)], value, 0)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c := New()
+	c.Set("key", make([]byte, 100), 0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get("key")
+	}
+}
+
+func TestMmapSlabs(t *testing.T) {
+	c := New(WithMmap(), WithShards(2), WithCapacity(1<<20))
+
+	for i := 0; i < 2000; i++ {
+		if err := c.Set(fmt.Sprintf("k%d", i), bytes.Repeat([]byte{byte(i)}, 1000), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, ok := c.Get("k1999")
+	if !ok || len(v) != 1000 || v[0] != byte(1999%256) {
+		t.Fatalf("unexpected value for k1999: ok=%v", ok)
+	}
+	if c.Stats().Evictions == 0 {
+		t.Fatal("expected the slabs to fill up and evict")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if v[0] != byte(1999%256) {
+		t.Fatal("expected values returned by Get to outlive Close")
+	}
+	if _, ok := c.Get("k1999"); ok {
+		t.Fatal("expected Close to drop every entry")
+	}
+
+	// The cache remains usable after Close.
+	c.Set("again", []byte("x"), 0)
+	if v, _ := c.Get("again"); string(v) != "x" {
+		t.Fatal("expected writes after Close to work")
+	}
+	c.Close()
+}
@@ -0,0 +1,49 @@
+package tempusslab
+
+import (
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+/*
+Tier adapts a Cache to tempuscache.Tier, typically as the off-heap
+second level of a tempuscache.Tiered (see OFF-HEAP STORAGE).
+
+Only []byte values can be stored. Setting any other value removes
+the key instead, so the tier never serves a copy older than the
+write it could not store. Get returns []byte values.
+*/
+
+type Tier struct {
+	cache *Cache
+}
+
+var _ tempuscache.Tier = (*Tier)(nil)
+
+// NewTier returns a Tier backed by cache.
+func NewTier(cache *Cache) *Tier {
+	return &Tier{cache: cache}
+}
+
+// Get returns the []byte stored for key.
+func (t *Tier) Get(key string) (interface{}, bool) {
+	value, ok := t.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value if it is a []byte that fits, and removes key otherwise.
+func (t *Tier) Set(key string, value interface{}, ttl time.Duration) {
+	b, ok := value.([]byte)
+	if !ok || t.cache.Set(key, b, ttl) != nil {
+		t.cache.Delete(key)
+	}
+}
+
+// Delete removes key.
+func (t *Tier) Delete(key string) {
+	t.cache.Delete(key)
+}
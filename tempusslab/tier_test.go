@@ -0,0 +1,45 @@
+package tempusslab
+
+import (
+	"testing"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func TestTierUnderTiered(t *testing.T) {
+	blobs := New(WithMmap(), WithShards(2), WithCapacity(8<<20))
+	defer blobs.Close()
+
+	l1 := tempuscache.New(tempuscache.WithMaxEntries(1))
+	defer l1.Stop()
+	tiered := tempuscache.NewTiered(l1, NewTier(blobs))
+
+	blob := make([]byte, 1<<20)
+	blob[len(blob)-1] = 7
+	tiered.Set("a", blob, 0)
+	tiered.Set("b", []byte("small"), 0) // evicts "a" from L1
+
+	v, ok := tiered.Get("a")
+	if !ok {
+		t.Fatal("expected 'a' to be served from the slab tier")
+	}
+	if got := v.([]byte); len(got) != len(blob) || got[len(got)-1] != 7 {
+		t.Fatal("expected the blob to round-trip intact")
+	}
+}
+
+func TestTierDropsUnstorableValues(t *testing.T) {
+	tier := NewTier(New(WithShards(1), WithCapacity(1024)))
+
+	tier.Set("k", []byte("v"), 0)
+	tier.Set("k", "not bytes", 0)
+	if _, ok := tier.Get("k"); ok {
+		t.Fatal("expected a non-[]byte write to remove the old copy")
+	}
+
+	tier.Set("k", []byte("v"), 0)
+	tier.Set("k", make([]byte, 2048), 0)
+	if _, ok := tier.Get("k"); ok {
+		t.Fatal("expected an oversized write to remove the old copy")
+	}
+}
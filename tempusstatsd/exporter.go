@@ -0,0 +1,209 @@
+/*
+Package tempusstatsd pushes TempusCache statistics to a StatsD
+(or DogStatsD) endpoint.
+
+================================================================================
+USAGE
+================================================================================
+
+	exp, err := tempusstatsd.New(cache, "127.0.0.1:8125",
+	    tempusstatsd.WithPrefix("myapp.sessions."),
+	    tempusstatsd.WithTags("env:prod", "region:eu"),
+	    tempusstatsd.WithInterval(10*time.Second))
+	if err != nil {
+	    return err
+	}
+	defer exp.Stop()
+
+================================================================================
+WIRE FORMAT
+================================================================================
+
+Every interval, one UDP datagram is sent containing:
+
+	<prefix>hits:<delta>|c|#tags
+	<prefix>misses:<delta>|c|#tags
+	<prefix>evictions:<delta>|c|#tags
+	<prefix>expirations:<delta>|c|#tags
+	<prefix>entries:<n>|g|#tags
+
+Counters are sent as DELTAS since the previous push, which is what
+StatsD counters expect. The "|#tags" suffix is the DogStatsD tag
+extension and is omitted when no tags are configured.
+
+================================================================================
+DELIVERY
+================================================================================
+
+StatsD is fire-and-forget UDP. Send errors are ignored; deltas of a
+lost datagram are not re-sent, matching ordinary StatsD clients.
+*/
+package tempusstatsd
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+/*
+Option configures an Exporter.
+*/
+
+type Option func(*Exporter)
+
+// WithPrefix sets the metric name prefix, e.g. "myapp.cache.".
+func WithPrefix(prefix string) Option {
+	return func(e *Exporter) {
+		e.prefix = prefix
+	}
+}
+
+// WithTags attaches DogStatsD tags ("key:value") to every metric.
+func WithTags(tags ...string) Option {
+	return func(e *Exporter) {
+		e.tags = append(e.tags, tags...)
+	}
+}
+
+// WithInterval sets the push interval (default 10s).
+func WithInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = d
+	}
+}
+
+/*
+Exporter periodically pushes cache statistics to StatsD.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cache    -> Cache being reported on
+conn     -> UDP connection to the StatsD endpoint
+prefix   -> Metric name prefix
+tags     -> DogStatsD tags
+interval -> Push frequency
+mu       -> Serializes Flush() between the ticker and callers
+last     -> Stats at the previous push, used to compute deltas
+stopChan -> Graceful shutdown signal for the push goroutine
+done     -> Closed when the push goroutine has exited
+*/
+
+type Exporter struct {
+	cache    *tempuscache.Cache
+	conn     net.Conn
+	prefix   string
+	tags     []string
+	interval time.Duration
+	mu       sync.Mutex
+	last     tempuscache.Stats
+	stopChan chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+New dials addr over UDP and starts pushing statistics.
+
+The first push reports everything accumulated since the cache was
+created; subsequent pushes report deltas.
+
+Returns an error if addr cannot be resolved.
+*/
+
+func New(cache *tempuscache.Cache, addr string, opts ...Option) (*Exporter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{
+		cache:    cache,
+		conn:     conn,
+		interval: 10 * time.Second,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	go e.run()
+	return e, nil
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.Flush()
+		case <-e.stopChan:
+			return
+		}
+	}
+}
+
+/*
+Flush pushes the current deltas immediately.
+
+It is called on every tick and once more by Stop(), so nothing
+accumulated before shutdown is lost.
+*/
+
+func (e *Exporter) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cur := e.cache.Stats()
+	prev := e.last
+	e.last = cur
+
+	var buf bytes.Buffer
+	e.write(&buf, "hits", cur.Hits-prev.Hits, "c")
+	e.write(&buf, "misses", cur.Misses-prev.Misses, "c")
+	e.write(&buf, "evictions", cur.Evictions-prev.Evictions, "c")
+	e.write(&buf, "expirations", cur.Expirations-prev.Expirations, "c")
+	e.write(&buf, "entries", uint64(e.cache.Len()), "g")
+
+	e.conn.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func (e *Exporter) write(buf *bytes.Buffer, name string, value uint64, kind string) {
+	buf.WriteString(e.prefix)
+	buf.WriteString(name)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.FormatUint(value, 10))
+	buf.WriteByte('|')
+	buf.WriteString(kind)
+	if len(e.tags) > 0 {
+		buf.WriteString("|#")
+		buf.WriteString(strings.Join(e.tags, ","))
+	}
+	buf.WriteByte('\n')
+}
+
+/*
+Stop performs a final Flush, terminates the push goroutine,
+and closes the UDP connection. It is safe to call more than once.
+*/
+
+func (e *Exporter) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopChan)
+		<-e.done
+		e.Flush()
+		e.conn.Close()
+	})
+}
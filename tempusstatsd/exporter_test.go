@@ -0,0 +1,50 @@
+package tempusstatsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func TestExporterSendsDeltas(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	cache := tempuscache.New()
+	exp, err := New(cache, pc.LocalAddr().String(),
+		WithPrefix("app."), WithTags("env:test"), WithInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Stop()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	exp.Flush()
+	if got := read(); !strings.Contains(got, "app.hits:1|c|#env:test") || !strings.Contains(got, "app.entries:1|g|#env:test") {
+		t.Fatalf("unexpected first push: %q", got)
+	}
+
+	cache.Get("a")
+	cache.Get("a")
+	exp.Flush()
+	if got := read(); !strings.Contains(got, "app.hits:2|c") {
+		t.Fatalf("expected delta of 2 hits, got %q", got)
+	}
+}
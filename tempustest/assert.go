@@ -0,0 +1,92 @@
+package tempustest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+// epoch is the start time of clocks created by NewCache.
+var epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+/*
+NewCache returns a cache running on a new FakeClock, with opts
+applied, that is stopped when the test ends.
+*/
+
+func NewCache(t testing.TB, opts ...tempuscache.Option) (*tempuscache.Cache, *FakeClock) {
+	t.Helper()
+
+	clock := NewFakeClock(epoch)
+	cache := tempuscache.New(append([]tempuscache.Option{tempuscache.WithClock(clock)}, opts...)...)
+	t.Cleanup(cache.Stop)
+	return cache, clock
+}
+
+/*
+RunJanitor runs one janitor sweep synchronously and returns the
+number of entries it removed. See Cache.DeleteExpired.
+*/
+
+func RunJanitor(cache *tempuscache.Cache) int {
+	return cache.DeleteExpired()
+}
+
+/*
+AssertHit fails the test unless Get(key) finds a value deeply equal
+to want. Like any Get, it counts as a lookup.
+*/
+
+func AssertHit(t testing.TB, cache *tempuscache.Cache, key string, want interface{}) {
+	t.Helper()
+
+	got, found := cache.Get(key)
+	if !found {
+		t.Fatalf("tempustest: expected %q to be cached, got a miss", key)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tempustest: expected %q to hold %#v, got %#v", key, want, got)
+	}
+}
+
+/*
+AssertMiss fails the test if Get(key) finds a value. Like any Get,
+it counts as a lookup.
+*/
+
+func AssertMiss(t testing.TB, cache *tempuscache.Cache, key string) {
+	t.Helper()
+
+	if got, found := cache.Get(key); found {
+		t.Fatalf("tempustest: expected a miss for %q, got %#v", key, got)
+	}
+}
+
+/*
+AssertTTL fails the test unless key is cached with exactly want
+remaining (0 for an entry without expiration). With a FakeClock the
+remaining lifetime is exact, so no tolerance is applied.
+*/
+
+func AssertTTL(t testing.TB, cache *tempuscache.Cache, key string, want time.Duration) {
+	t.Helper()
+
+	got, found := cache.TTL(key)
+	if !found {
+		t.Fatalf("tempustest: expected %q to be cached, got a miss", key)
+	}
+	if got != want {
+		t.Fatalf("tempustest: expected %v left on %q, got %v", want, key, got)
+	}
+}
+
+// AssertLen fails the test unless the cache holds exactly want entries.
+func AssertLen(t testing.TB, cache *tempuscache.Cache, want int) {
+	t.Helper()
+
+	if got := cache.Len(); got != want {
+		t.Fatalf("tempustest: expected %d entries, got %d", want, got)
+	}
+}
@@ -0,0 +1,29 @@
+package tempustest
+
+import (
+	"testing"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+func TestNewCacheExpiresOnFakeTime(t *testing.T) {
+	cache, clock := NewCache(t, tempuscache.WithCleanupInterval(time.Hour))
+
+	cache.Set("session", "alice", time.Minute)
+	cache.Set("config", "v1", 0)
+	AssertTTL(t, cache, "session", time.Minute)
+	AssertTTL(t, cache, "config", 0)
+
+	clock.Advance(59 * time.Second)
+	AssertHit(t, cache, "session", "alice")
+
+	clock.Advance(time.Second + 1)
+	AssertLen(t, cache, 2)
+	if removed := RunJanitor(cache); removed != 1 {
+		t.Fatalf("expected the sweep to remove 1 entry, got %d", removed)
+	}
+	AssertLen(t, cache, 1)
+	AssertMiss(t, cache, "session")
+	AssertHit(t, cache, "config", "v1")
+}
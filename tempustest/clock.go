@@ -0,0 +1,204 @@
+/*
+Package tempustest helps test code that uses TempusCache without
+sleeping: a FakeClock to drive expiration, and assertion helpers.
+
+================================================================================
+USAGE
+================================================================================
+
+	func TestSessionExpiry(t *testing.T) {
+	    cache, clock := tempustest.NewCache(t)
+
+	    cache.Set("session", "alice", time.Minute)
+	    tempustest.AssertTTL(t, cache, "session", time.Minute)
+
+	    clock.Advance(2 * time.Minute)
+	    tempustest.AssertMiss(t, cache, "session")
+	}
+
+================================================================================
+BACKGROUND WORKERS
+================================================================================
+
+Workers (the janitor, auto-snapshots, write-behind) run on tickers
+of the FakeClock, so they only act when the test advances it. As
+they run in their own goroutines, a tick is handled some time after
+Advance returns. Tests that need a sweep to have completed call
+RunJanitor instead, which runs it synchronously.
+
+BlockUntil waits for workers to be waiting on the clock, e.g. for a
+write-behind retry to have started its backoff before advancing
+past it.
+*/
+package tempustest
+
+import (
+	"sync"
+	"time"
+
+	tempuscache "github.com/Krishna8167/tempuscache/v2"
+)
+
+var _ tempuscache.Clock = (*FakeClock)(nil)
+
+/*
+FakeClock is a tempuscache.Clock that only moves when Advance is
+called. It is safe for concurrent use.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+mu      -> Protects now and waiters
+changed -> Signalled whenever waiters changes (for BlockUntil)
+now     -> Current fake time
+waiters -> Pending timers (After) and running tickers
+*/
+
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+/*
+waiter is a pending After timer (period 0) or a running ticker. Like
+their time package counterparts, both buffer one tick and drop ticks
+the receiver is too slow for.
+*/
+
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the current fake time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After returns a channel that receives the fake time once d has
+// been advanced past.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &waiter{at: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+	f.add(w)
+	return w.ch
+}
+
+// NewTicker returns a ticker firing every d of fake time. It panics
+// if d <= 0, as time.NewTicker does.
+func (f *FakeClock) NewTicker(d time.Duration) tempuscache.Ticker {
+	if d <= 0 {
+		panic("tempustest: non-positive interval for NewTicker")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &waiter{at: f.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	f.add(w)
+	return &fakeTicker{clock: f, w: w}
+}
+
+/*
+Advance moves the clock forward by d, firing in order every timer
+and ticker that falls due on the way.
+*/
+
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	target := f.now.Add(d)
+	for {
+		w := f.next()
+		if w == nil || w.at.After(target) {
+			break
+		}
+		f.now = w.at
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+	}
+	f.now = target
+}
+
+/*
+BlockUntil blocks until at least n timers and tickers are waiting
+on the clock, so that a following Advance is sure to fire them.
+*/
+
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
+
+// next returns the earliest due waiter, or nil if there is none.
+func (f *FakeClock) next() *waiter {
+	var first *waiter
+	for _, w := range f.waiters {
+		if first == nil || w.at.Before(first.at) {
+			first = w
+		}
+	}
+	return first
+}
+
+func (f *FakeClock) add(w *waiter) {
+	f.waiters = append(f.waiters, w)
+	f.changed.Broadcast()
+}
+
+func (f *FakeClock) remove(w *waiter) {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.changed.Broadcast()
+			return
+		}
+	}
+}
+
+// fakeTicker is a ticker of a FakeClock.
+type fakeTicker struct {
+	clock *FakeClock
+	w     *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.remove(t.w)
+}
@@ -0,0 +1,57 @@
+package tempustest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	after := clock.After(time.Minute)
+	ticker := clock.NewTicker(20 * time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(59 * time.Second)
+	select {
+	case <-after:
+		t.Fatal("expected After not to fire before its deadline")
+	default:
+	}
+	if got := <-ticker.C(); !got.Equal(epoch.Add(20 * time.Second)) {
+		t.Fatalf("expected the first tick at +20s, got %v", got.Sub(epoch))
+	}
+
+	clock.Advance(time.Second)
+	if got := <-after; !got.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("expected After to fire at +1m, got %v", got.Sub(epoch))
+	}
+	if got := clock.Now(); !got.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("expected the clock at +1m, got %v", got.Sub(epoch))
+	}
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	done := make(chan struct{})
+	go func() {
+		<-clock.After(time.Second)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the waiting goroutine to be released")
+	}
+
+	ticker := clock.NewTicker(time.Second)
+	ticker.Stop()
+	clock.BlockUntil(0)
+	if len(clock.waiters) != 0 {
+		t.Fatalf("expected stopped tickers and fired timers to be dropped, got %d", len(clock.waiters))
+	}
+}
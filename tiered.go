@@ -0,0 +1,401 @@
+package tempuscache
+
+import (
+	"context"
+	"time"
+)
+
+/*
+tiered.go implements a two-level cache: a local Cache (L1) in front
+of a shared second level (L2).
+
+================================================================================
+WHY?
+================================================================================
+
+An in-process cache is fast but private to each replica; a shared
+cache (a tempus server, Redis, ...) is slower but shared by all of
+them. Services commonly combine both by hand: check local, then
+shared, then copy shared hits into local. Tiered does exactly that.
+
+================================================================================
+FLOW
+================================================================================
+
+Get:
+    → L1 hit: returned directly, L2 is not contacted.
+    → L1 miss, L2 hit: the value is copied into L1 (backfill) and
+      returned.
+    → Miss on both: reported as a miss.
+
+Set / Delete:
+    → Written through to L2 first, then to L1, so that L2 is never
+      older than the local copy a write leaves behind.
+
+A backfill can race with a write: a reader that fetched the old
+value from L2 may only get to copy it into L1 after the writer has
+updated both levels. Backfills are therefore versioned like Store
+loads: a backfill is dropped if the key was written, deleted, purged,
+or flushed in L1 since the L1 miss that started it.
+
+================================================================================
+NEAR-CACHE MODE
+================================================================================
+
+By default an L1 copy lives as long as the L2 entry, so a write made
+through another replica can be hidden by a stale local copy for the
+whole TTL. WithNearCache(d) caps the lifetime of every L1 copy at d,
+independently of the authoritative L2 TTL: a local copy is never
+more than d old.
+
+Staleness can be cut further by purging L1 copies as soon as another
+replica writes the key:
+
+  - Automatically, by creating L1 with WithInvalidation. Writes made
+    through Tiered then publish the key, and invalidations from
+    other replicas drop their local copy.
+  - Manually, with Purge, e.g. from an application-level event.
+
+Backfills and purges are local bookkeeping, not writes: they are
+never published, so one replica's L1 miss does not purge the copies
+held by all the others.
+
+================================================================================
+CONTEXTS
+================================================================================
+
+GetContext, SetContext, and DeleteContext pass their context down
+to L1 (and its Store, if any) and to L2 when it implements
+ContextTier, and return their errors instead of treating them as
+misses. A slow L2 call is then abandoned once the caller has gone.
+*/
+
+/*
+Tier is the second level of a Tiered cache.
+
+*Cache, tempusclient.Client, and tempusclient.Ring implement it, as
+can any adapter over another store. Implementations handle their own
+errors: failed reads are reported as misses, failed writes dropped.
+*/
+
+type Tier interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+}
+
+/*
+TTLTier is implemented by tiers that can report the remaining
+lifetime of a key. Tiered uses it to give backfilled L1 copies the
+same deadline as the L2 entry.
+*/
+
+type TTLTier interface {
+	Tier
+	TTL(key string) (time.Duration, bool)
+}
+
+/*
+ContextTier is implemented by tiers with context-aware operations,
+such as *Cache, tempusclient.Client, and tempusclient.Ring. Tiered's
+context variants use them to propagate cancellation to L2.
+*/
+
+type ContextTier interface {
+	Tier
+	GetContext(ctx context.Context, key string) (interface{}, bool, error)
+	SetContext(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	DeleteContext(ctx context.Context, key string) error
+}
+
+/*
+TieredOption configures a Tiered cache.
+*/
+
+type TieredOption func(*Tiered)
+
+/*
+WithBackfillTTL sets the lifetime of L1 copies backfilled from an
+L2 that does not implement TTLTier (default 1 minute).
+
+L2 entries without a deadline are also copied with this TTL, so
+the local copy is eventually re-read from L2.
+*/
+
+func WithBackfillTTL(d time.Duration) TieredOption {
+	return func(t *Tiered) {
+		t.backfillTTL = d
+	}
+}
+
+/*
+WithNearCache caps the lifetime of L1 copies at ttl, whatever the
+TTL of the L2 entry (0 = no cap). It bounds how stale a local copy
+can get when another replica writes the key.
+*/
+
+func WithNearCache(ttl time.Duration) TieredOption {
+	return func(t *Tiered) {
+		t.nearTTL = ttl
+	}
+}
+
+/*
+Tiered is a two-level cache. See tiered.go for the read and write
+flow.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+l1          -> Local cache, checked first
+l2          -> Shared second level
+backfillTTL -> Lifetime of L1 copies whose L2 deadline is unknown
+nearTTL     -> Upper bound on the lifetime of L1 copies (0 = none)
+*/
+
+type Tiered struct {
+	l1          *Cache
+	l2          Tier
+	backfillTTL time.Duration
+	nearTTL     time.Duration
+}
+
+// NewTiered puts l1 in front of l2.
+func NewTiered(l1 *Cache, l2 Tier, opts ...TieredOption) *Tiered {
+	t := &Tiered{
+		l1:          l1,
+		l2:          l2,
+		backfillTTL: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// L1 returns the local cache.
+func (t *Tiered) L1() *Cache {
+	return t.l1
+}
+
+/*
+Get returns key from L1, falling back to L2 and backfilling L1 on
+an L2 hit.
+*/
+
+func (t *Tiered) Get(key string) (interface{}, bool) {
+	if value, found := t.l1.Get(key); found {
+		return value, true
+	}
+
+	b := t.l1.beginBackfill(key)
+	value, found := t.l2.Get(key)
+	if !found {
+		t.l1.endBackfill(key, b, nil, 0, false)
+		return nil, false
+	}
+	t.l1.endBackfill(key, b, value, t.localTTL(t.backfillTTLFor(key)), true)
+	return value, true
+}
+
+/*
+GetContext is Get that passes ctx to L1 and L2 and returns their
+errors. With an L2 that is not a ContextTier, ctx is only checked
+before L2 is contacted.
+*/
+
+func (t *Tiered) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	value, found, err := t.l1.GetContext(ctx, key)
+	if found || err != nil {
+		return value, found, err
+	}
+
+	b := t.l1.beginBackfill(key)
+	if ct, ok := t.l2.(ContextTier); ok {
+		value, found, err = ct.GetContext(ctx, key)
+	} else if err = ctx.Err(); err == nil {
+		value, found = t.l2.Get(key)
+	}
+	if !found || err != nil {
+		t.l1.endBackfill(key, b, nil, 0, false)
+		return nil, false, err
+	}
+
+	t.l1.endBackfill(key, b, value, t.localTTL(t.backfillTTLFor(key)), true)
+	return value, true, nil
+}
+
+/*
+backfillTTLFor returns the lifetime of an L1 copy of key: the
+remaining L2 lifetime when known, backfillTTL otherwise.
+*/
+
+func (t *Tiered) backfillTTLFor(key string) time.Duration {
+	tt, ok := t.l2.(TTLTier)
+	if !ok {
+		return t.backfillTTL
+	}
+	remaining, found := tt.TTL(key)
+	if !found || remaining == 0 {
+		return t.backfillTTL
+	}
+	return remaining
+}
+
+// localTTL caps ttl at the near-cache lifetime, if any.
+func (t *Tiered) localTTL(ttl time.Duration) time.Duration {
+	if t.nearTTL > 0 && (ttl <= 0 || ttl > t.nearTTL) {
+		return t.nearTTL
+	}
+	return ttl
+}
+
+// TTL returns the remaining lifetime of key in L1, or in L2 if it
+// is not held locally and L2 implements TTLTier.
+func (t *Tiered) TTL(key string) (time.Duration, bool) {
+	if remaining, found := t.l1.TTL(key); found {
+		return remaining, true
+	}
+	if tt, ok := t.l2.(TTLTier); ok {
+		return tt.TTL(key)
+	}
+	return 0, false
+}
+
+// Set writes value through to L2, then L1.
+func (t *Tiered) Set(key string, value interface{}, ttl time.Duration) {
+	t.l2.Set(key, value, ttl)
+	t.l1.Set(key, value, t.localTTL(ttl))
+}
+
+// Delete removes key from L2, then L1.
+func (t *Tiered) Delete(key string) {
+	t.l2.Delete(key)
+	t.l1.Delete(key)
+}
+
+/*
+SetContext is Set that passes ctx to L2 and L1. If L2 fails, L1 is
+left untouched and the error returned.
+*/
+
+func (t *Tiered) SetContext(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ct, ok := t.l2.(ContextTier); ok {
+		if err := ct.SetContext(ctx, key, value, ttl); err != nil {
+			return err
+		}
+	} else if err := ctx.Err(); err != nil {
+		return err
+	} else {
+		t.l2.Set(key, value, ttl)
+	}
+	return t.l1.SetContext(ctx, key, value, t.localTTL(ttl))
+}
+
+/*
+DeleteContext is Delete that passes ctx to L2. The local copy is
+removed even if L2 fails, so L1 never outlives an attempted delete.
+*/
+
+func (t *Tiered) DeleteContext(ctx context.Context, key string) error {
+	var err error
+	if ct, ok := t.l2.(ContextTier); ok {
+		err = ct.DeleteContext(ctx, key)
+	} else if err = ctx.Err(); err == nil {
+		t.l2.Delete(key)
+	}
+	t.l1.Delete(key)
+	return err
+}
+
+/*
+Purge drops the local copies of keys, leaving L2 untouched. The next
+Get re-reads them from L2.
+*/
+
+func (t *Tiered) Purge(keys ...string) {
+	t.l1.unpublished(func() {
+		for _, key := range keys {
+			t.l1.delete(key)
+		}
+	})
+}
+
+/*
+backfill is an L2 read whose result may be copied into L1. written
+is the write stamp of the L1 entry (0 if missing) when the read
+started; superseded (guarded by the L1 lock) is set when the key is
+deleted, invalidated, purged, or flushed meanwhile. Either change
+means L1 may already be newer than the L2 value.
+*/
+
+type backfill struct {
+	written    int64
+	superseded bool
+}
+
+// beginBackfill registers a backfill of key before L2 is read.
+func (c *Cache) beginBackfill(key string) *backfill {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := &backfill{}
+	if elem, found := c.data[key]; found {
+		b.written = elem.Value.(*Item).written
+	}
+	if c.backfills == nil {
+		c.backfills = make(map[string][]*backfill)
+	}
+	c.backfills[key] = append(c.backfills[key], b)
+	return b
+}
+
+/*
+endBackfill unregisters b and, if store is set and key has not been
+written or removed since beginBackfill, copies value into the cache
+with ttl. The copy is not published as an invalidation.
+*/
+
+func (c *Cache) endBackfill(key string, b *backfill, value interface{}, ttl time.Duration, store bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fills := c.backfills[key]
+	for i, f := range fills {
+		if f == b {
+			fills = append(fills[:i], fills[i+1:]...)
+			break
+		}
+	}
+	if len(fills) == 0 {
+		delete(c.backfills, key)
+	} else {
+		c.backfills[key] = fills
+	}
+
+	if !store || b.superseded {
+		return
+	}
+	if elem, found := c.data[key]; found && elem.Value.(*Item).written != b.written {
+		return
+	}
+
+	c.restoring = true
+	defer func() { c.restoring = false }()
+	c.set(key, value, ttl)
+}
+
+/*
+unpublished runs fn under the exclusive lock without publishing the
+changes it makes as invalidations.
+*/
+
+func (c *Cache) unpublished(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.restoring = true
+	defer func() { c.restoring = false }()
+	fn()
+}
@@ -0,0 +1,252 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapTier is a Tier without TTL support that counts reads.
+type mapTier struct {
+	data  map[string]interface{}
+	reads int
+}
+
+func (m *mapTier) Get(key string) (interface{}, bool) {
+	m.reads++
+	v, ok := m.data[key]
+	return v, ok
+}
+
+func (m *mapTier) Set(key string, value interface{}, _ time.Duration) { m.data[key] = value }
+func (m *mapTier) Delete(key string)                                  { delete(m.data, key) }
+
+var (
+	_ TTLTier     = (*Cache)(nil)
+	_ ContextTier = (*Cache)(nil)
+)
+
+// failingTier is a ContextTier whose context operations all fail.
+type failingTier struct {
+	mapTier
+	err error
+}
+
+func (f *failingTier) GetContext(context.Context, string) (interface{}, bool, error) {
+	return nil, false, f.err
+}
+
+func (f *failingTier) SetContext(context.Context, string, interface{}, time.Duration) error {
+	return f.err
+}
+
+func (f *failingTier) DeleteContext(context.Context, string) error { return f.err }
+
+func TestTiered(t *testing.T) {
+	l1, l2 := New(), New()
+	defer l1.Stop()
+	defer l2.Stop()
+	tiered := NewTiered(l1, l2)
+
+	tiered.Set("written", 1, time.Hour)
+	if _, found := l2.Get("written"); !found {
+		t.Fatal("expected Set to write through to L2")
+	}
+
+	l2.Set("shared", "v", 30*time.Second)
+	if v, found := tiered.Get("shared"); !found || v != "v" {
+		t.Fatalf("expected L2 hit, got %v (%v)", v, found)
+	}
+	if ttl, found := l1.TTL("shared"); !found || ttl <= 0 || ttl > 30*time.Second {
+		t.Fatalf("expected L1 backfill with the L2 deadline, got %v (%v)", ttl, found)
+	}
+
+	tiered.Delete("shared")
+	if _, found := tiered.Get("shared"); found {
+		t.Fatal("expected Delete to remove both levels")
+	}
+}
+
+func TestTieredBackfillTTL(t *testing.T) {
+	l1 := New()
+	defer l1.Stop()
+	l2 := &mapTier{data: map[string]interface{}{"k": "v"}}
+	tiered := NewTiered(l1, l2, WithBackfillTTL(time.Second))
+
+	for i := 0; i < 3; i++ {
+		if v, found := tiered.Get("k"); !found || v != "v" {
+			t.Fatalf("expected hit, got %v (%v)", v, found)
+		}
+	}
+	if l2.reads != 1 {
+		t.Fatalf("expected L1 to absorb repeated reads, L2 was read %d times", l2.reads)
+	}
+	if ttl, _ := l1.TTL("k"); ttl <= 0 || ttl > time.Second {
+		t.Fatalf("expected backfill TTL of 1s, got %v", ttl)
+	}
+	if _, found := tiered.TTL("missing"); found {
+		t.Fatal("expected miss")
+	}
+}
+
+// countingBus counts broadcasts once every subscriber has handled them.
+type countingBus struct {
+	*MemoryBus
+	published atomic.Int32
+}
+
+func (b *countingBus) Broadcast(ctx context.Context, msg Invalidation) error {
+	err := b.MemoryBus.Broadcast(ctx, msg)
+	b.published.Add(1)
+	return err
+}
+
+func TestTieredNearCache(t *testing.T) {
+	bus := &countingBus{MemoryBus: NewMemoryBus()}
+	shared := New()
+	defer shared.Stop()
+
+	newReplica := func() *Tiered {
+		l1 := New(WithInvalidation(bus, bus))
+		t.Cleanup(l1.Stop)
+		return NewTiered(l1, shared, WithNearCache(time.Second))
+	}
+	a, b := newReplica(), newReplica()
+
+	a.Set("k", "v1", time.Hour)
+	if ttl, _ := a.L1().TTL("k"); ttl <= 0 || ttl > time.Second {
+		t.Fatalf("expected the L1 copy to be capped at 1s, got %v", ttl)
+	}
+	if ttl, _ := shared.TTL("k"); ttl <= time.Second {
+		t.Fatalf("expected L2 to keep the authoritative TTL, got %v", ttl)
+	}
+
+	waitFor(t, func() bool { return bus.published.Load() == 1 }) // a's Set
+
+	// b's backfill is not published, so a keeps its copy.
+	if v, _ := b.Get("k"); v != "v1" {
+		t.Fatalf("expected v1, got %v", v)
+	}
+	if ttl, _ := b.L1().TTL("k"); ttl <= 0 || ttl > time.Second {
+		t.Fatalf("expected the backfilled copy to be capped at 1s, got %v", ttl)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if bus.published.Load() != 1 {
+		t.Fatal("expected the backfill not to be published")
+	}
+	if _, found := a.L1().Get("k"); !found {
+		t.Fatal("expected a backfill on b not to purge a's copy")
+	}
+
+	// A write on a purges b's copy.
+	a.Set("k", "v2", time.Hour)
+	waitFor(t, func() bool { _, found := b.L1().Get("k"); return !found })
+	if v, _ := b.Get("k"); v != "v2" {
+		t.Fatalf("expected v2 after the purge, got %v", v)
+	}
+
+	a.Purge("k")
+	if _, found := a.L1().Get("k"); found {
+		t.Fatal("expected Purge to drop the local copy")
+	}
+	if _, found := shared.Get("k"); !found {
+		t.Fatal("expected Purge to leave L2 untouched")
+	}
+}
+
+func TestTieredContext(t *testing.T) {
+	l1 := New()
+	defer l1.Stop()
+	l2 := &failingTier{mapTier: mapTier{data: map[string]interface{}{}}, err: errors.New("l2 down")}
+	tiered := NewTiered(l1, l2)
+	ctx := context.Background()
+
+	if err := tiered.SetContext(ctx, "k", "v", 0); err != l2.err {
+		t.Fatalf("expected the L2 error, got %v", err)
+	}
+	if _, found := l1.Get("k"); found {
+		t.Fatal("expected L1 to be left untouched when L2 fails")
+	}
+	if _, _, err := tiered.GetContext(ctx, "k"); err != l2.err {
+		t.Fatalf("expected the L2 error, got %v", err)
+	}
+
+	l1.Set("k", "local", 0)
+	if v, found, err := tiered.GetContext(ctx, "k"); err != nil || !found || v != "local" {
+		t.Fatalf("expected an L1 hit without contacting L2, got %v, %v, %v", v, found, err)
+	}
+	if err := tiered.DeleteContext(ctx, "k"); err != l2.err {
+		t.Fatalf("expected the L2 error, got %v", err)
+	}
+	if _, found := l1.Get("k"); found {
+		t.Fatal("expected the local copy to be removed even when L2 fails")
+	}
+
+	// A plain Tier is not contacted once ctx has ended.
+	plain := &mapTier{data: map[string]interface{}{"k": "v"}}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := NewTiered(l1, plain).GetContext(canceled, "k"); err != context.Canceled || plain.reads != 0 {
+		t.Fatalf("expected Canceled without an L2 read, got %v (%d reads)", err, plain.reads)
+	}
+}
+
+// gatedTier is an L2 whose reads pause until released, so a write
+// can be slipped in between an L1 miss and its backfill.
+type gatedTier struct {
+	*Cache
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedTier) Get(key string) (interface{}, bool) {
+	v, ok := g.Cache.Get(key)
+	g.reading <- struct{}{}
+	<-g.release
+	return v, ok
+}
+
+func TestTieredBackfillRace(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+
+	for _, tc := range []struct {
+		name  string
+		write func(*Tiered)
+		want  interface{}
+	}{
+		{"Set", func(tr *Tiered) { tr.Set("k", "new", 0) }, "new"},
+		{"Delete", func(tr *Tiered) { tr.Delete("k") }, nil},
+		{"Purge", func(tr *Tiered) { tr.Purge("k") }, nil},
+		{"Flush", func(tr *Tiered) { tr.L1().Flush() }, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l1, l2 := New(WithClock(clock)), New(WithClock(clock))
+			defer l1.Stop()
+			defer l2.Stop()
+			l2.Set("k", "old", 0)
+
+			gate := &gatedTier{Cache: l2, reading: make(chan struct{}), release: make(chan struct{})}
+			tiered := NewTiered(l1, gate)
+
+			done := make(chan interface{})
+			go func() {
+				v, _ := tiered.Get("k")
+				done <- v
+			}()
+
+			<-gate.reading
+			tc.write(tiered)
+			close(gate.release)
+			if v := <-done; v != "old" {
+				t.Fatalf("expected the racing read to return the old value, got %v", v)
+			}
+
+			v, _ := l1.Get("k")
+			if v != tc.want {
+				t.Fatalf("expected the backfill to be dropped, L1 holds %v", v)
+			}
+		})
+	}
+}
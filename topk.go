@@ -0,0 +1,148 @@
+package tempuscache
+
+import (
+	"container/heap"
+	"sort"
+)
+
+/*
+topk.go implements hot-key tracking.
+
+================================================================================
+ALGORITHM
+================================================================================
+
+Every lookup is counted in a count-min sketch. A min-heap holds the
+k keys with the highest estimated counts seen so far:
+
+1. Increment the key in the sketch and obtain its new estimate.
+2. If the key is already in the heap → update its count in place.
+3. Else if the heap has fewer than k keys → push it.
+4. Else if its estimate beats the heap minimum → replace the minimum.
+
+================================================================================
+COST
+================================================================================
+
+Per lookup: O(depth) sketch updates + O(log k) heap maintenance.
+Memory: the sketch plus k heap entries, regardless of how many
+distinct keys the cache sees.
+
+Counts are sketch ESTIMATES: they may slightly over-count under
+hash collisions, but a genuinely hot key is never missed.
+*/
+
+/*
+KeyCount is a key with its estimated number of lookups.
+*/
+
+type KeyCount struct {
+	Key   string
+	Count uint64
+}
+
+type topKTracker struct {
+	k      int
+	sketch *countMinSketch
+	heap   keyHeap
+	index  map[string]int
+}
+
+func newTopKTracker(k int) *topKTracker {
+	t := &topKTracker{
+		k:      k,
+		sketch: newCountMinSketch(k * 64),
+		index:  make(map[string]int, k),
+	}
+	t.heap.index = t.index
+	return t
+}
+
+/*
+observe counts one lookup of key, whose hash is h.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (t *topKTracker) observe(key string, h uint64) {
+	count := t.sketch.add(h)
+
+	if i, ok := t.index[key]; ok {
+		t.heap.items[i].Count = count
+		heap.Fix(&t.heap, i)
+		return
+	}
+
+	if len(t.heap.items) < t.k {
+		heap.Push(&t.heap, KeyCount{Key: key, Count: count})
+		return
+	}
+
+	if count > t.heap.items[0].Count {
+		delete(t.index, t.heap.items[0].Key)
+		t.heap.items[0] = KeyCount{Key: key, Count: count}
+		t.index[key] = 0
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+/*
+top returns the tracked keys ordered from hottest to coldest.
+*/
+
+func (t *topKTracker) top() []KeyCount {
+	out := make([]KeyCount, len(t.heap.items))
+	copy(out, t.heap.items)
+	sort.Slice(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	return out
+}
+
+/*
+keyHeap is a min-heap of KeyCount ordered by Count, which keeps
+index (key → heap position) in sync on every swap.
+*/
+
+type keyHeap struct {
+	items []KeyCount
+	index map[string]int
+}
+
+func (h *keyHeap) Len() int           { return len(h.items) }
+func (h *keyHeap) Less(i, j int) bool { return h.items[i].Count < h.items[j].Count }
+
+func (h *keyHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Key] = i
+	h.index[h.items[j].Key] = j
+}
+
+func (h *keyHeap) Push(x interface{}) {
+	kc := x.(KeyCount)
+	h.index[kc.Key] = len(h.items)
+	h.items = append(h.items, kc)
+}
+
+func (h *keyHeap) Pop() interface{} {
+	kc := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, kc.Key)
+	return kc
+}
+
+/*
+HotKeys returns the most frequently looked-up keys, hottest first.
+
+Returns nil unless hot-key tracking was enabled with WithTopK().
+The same list is reported in Stats().HotKeys.
+*/
+
+func (c *Cache) HotKeys() []KeyCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.topK == nil {
+		return nil
+	}
+	return c.topK.top()
+}
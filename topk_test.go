@@ -0,0 +1,36 @@
+package tempuscache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHotKeys(t *testing.T) {
+	cache := New(WithTopK(3))
+
+	for i := 0; i < 100; i++ {
+		cache.Get("hot")
+		if i%2 == 0 {
+			cache.Get("warm")
+		}
+		cache.Get(fmt.Sprintf("cold%d", i))
+	}
+
+	hot := cache.HotKeys()
+	if len(hot) != 3 {
+		t.Fatalf("expected 3 hot keys, got %v", hot)
+	}
+	if hot[0].Key != "hot" || hot[1].Key != "warm" {
+		t.Fatalf("unexpected ranking: %v", hot)
+	}
+	if hot[0].Count < 100 {
+		t.Fatalf("expected at least 100 lookups for 'hot', got %d", hot[0].Count)
+	}
+
+	if got := cache.Stats().HotKeys; len(got) != 3 {
+		t.Fatalf("expected Stats to report hot keys, got %v", got)
+	}
+	if New().HotKeys() != nil {
+		t.Fatal("expected nil hot keys when tracking is disabled")
+	}
+}
@@ -0,0 +1,232 @@
+package tempuscache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+/*
+trace.go records a stream of cache operations for offline analysis.
+
+================================================================================
+WHY?
+================================================================================
+
+Capacity and policy choices are best made against the real access
+pattern of a workload, not a synthetic one. With WithTrace, a
+production cache writes every operation to an io.Writer; the trace
+can later be read back with TraceReader, e.g. to replay it against
+other configurations.
+
+================================================================================
+FORMAT
+================================================================================
+
+A trace starts with the 4-byte magic "TCTR" and a version byte (1),
+followed by one record per operation:
+
+    op      1 byte    EventType (set, hit, miss, deleted, evicted,
+                      expired, flushed)
+    delta   uvarint   Nanoseconds since the previous record (since
+                      the Unix epoch for the first one)
+    hash    8 bytes   FNV-1a 64 hash of the key, little endian
+    size    uvarint   Estimated entry size for set and hit, else 0
+
+Keys are hashed, never stored: traces are compact (about 12 bytes
+per operation) and do not leak key contents. Times come from the
+cache's Clock.
+
+================================================================================
+COST AND FAILURE
+================================================================================
+
+Records are buffered and written while the cache lock is held, so
+the writer should be fast (a file, not a network connection). The
+buffer is flushed by Stop and Close. If a write fails, the error is
+logged and tracing stops; the cache itself is unaffected.
+*/
+
+// traceMagic starts every trace, followed by traceVersion.
+const (
+	traceMagic   = "TCTR"
+	traceVersion = 1
+)
+
+// ErrBadTrace is returned by TraceReader for input that is not a trace.
+var ErrBadTrace = errors.New("tempuscache: not a trace or unsupported trace version")
+
+/*
+TraceRecord is one operation read back from a trace.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+Op      -> What happened (the EventType of the operation)
+Time    -> When it happened, on the recording cache's Clock
+KeyHash -> FNV-1a 64 hash of the key
+Size    -> Estimated entry size for EventSet and EventHit, else 0
+*/
+
+type TraceRecord struct {
+	Op      EventType
+	Time    time.Time
+	KeyHash uint64
+	Size    int64
+}
+
+/*
+tracer writes trace records to a buffered writer.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+w    -> Buffered destination
+last -> Time of the previous record in UnixNano
+err  -> First write error; once set, recording stops
+buf  -> Scratch space for one record (a stack buffer would escape
+        through the writer and allocate on every operation)
+*/
+
+type tracer struct {
+	w    *bufio.Writer
+	last int64
+	err  error
+	buf  [traceRecordMax]byte
+}
+
+// traceRecordMax is the encoded size of the largest trace record.
+const traceRecordMax = 1 + binary.MaxVarintLen64 + 8 + binary.MaxVarintLen64
+
+func newTracer(w io.Writer) *tracer {
+	t := &tracer{w: bufio.NewWriter(w)}
+	t.w.WriteString(traceMagic)
+	t.w.WriteByte(traceVersion)
+	return t
+}
+
+/*
+trace records an operation on key, if tracing is enabled.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) trace(op EventType, key string) {
+	t := c.tracer
+	if t == nil || t.err != nil {
+		return
+	}
+
+	var size int64
+	if op == EventSet || op == EventHit {
+		if elem, found := c.data[key]; found {
+			size = elem.Value.(*Item).size
+		}
+	}
+
+	now := c.now()
+	buf := t.buf[:]
+	buf[0] = byte(op)
+	n := 1 + binary.PutUvarint(buf[1:], uint64(now-t.last))
+	binary.LittleEndian.PutUint64(buf[n:], hashKey(key))
+	n += 8
+	n += binary.PutUvarint(buf[n:], uint64(size))
+	t.last = now
+
+	if _, err := t.w.Write(buf[:n]); err != nil {
+		t.err = err
+		c.logger().Error("tempuscache: trace write failed, tracing stopped", "err", err)
+	}
+}
+
+/*
+flushTrace writes out buffered trace records.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) flushTrace() {
+	t := c.tracer
+	if t == nil || t.err != nil {
+		return
+	}
+	if err := t.w.Flush(); err != nil {
+		t.err = err
+		c.logger().Error("tempuscache: trace flush failed, tracing stopped", "err", err)
+	}
+}
+
+/*
+TraceReader reads the records of a trace written with WithTrace.
+*/
+
+type TraceReader struct {
+	r    *bufio.Reader
+	last int64
+}
+
+// NewTraceReader checks the trace header and returns a reader for
+// its records, or ErrBadTrace.
+func NewTraceReader(r io.Reader) (*TraceReader, error) {
+	br := bufio.NewReader(r)
+	var header [len(traceMagic) + 1]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, ErrBadTrace
+	}
+	if string(header[:len(traceMagic)]) != traceMagic || header[len(traceMagic)] != traceVersion {
+		return nil, ErrBadTrace
+	}
+	return &TraceReader{r: br}, nil
+}
+
+/*
+Next returns the next record, or io.EOF at the end of the trace. A
+trace cut short mid-record (e.g. by a crash) reports
+io.ErrUnexpectedEOF.
+*/
+
+func (tr *TraceReader) Next() (TraceRecord, error) {
+	op, err := tr.r.ReadByte()
+	if err != nil {
+		return TraceRecord{}, err
+	}
+
+	delta, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return TraceRecord{}, unexpected(err)
+	}
+	var hash [8]byte
+	if _, err := io.ReadFull(tr.r, hash[:]); err != nil {
+		return TraceRecord{}, unexpected(err)
+	}
+	size, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return TraceRecord{}, unexpected(err)
+	}
+	if op == 0 || EventType(op) > EventFlushed {
+		return TraceRecord{}, fmt.Errorf("tempuscache: corrupt trace: unknown op %d", op)
+	}
+
+	tr.last += int64(delta)
+	return TraceRecord{
+		Op:      EventType(op),
+		Time:    time.Unix(0, tr.last),
+		KeyHash: binary.LittleEndian.Uint64(hash[:]),
+		Size:    int64(size),
+	}, nil
+}
+
+// unexpected turns io.EOF inside a record into io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package tempuscache
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithTrace(&buf), WithClock(clock), WithMaxEntries(1))
+
+	cache.Set("a", "value", 0)
+	clock.advance(time.Second)
+	cache.Get("a")
+	cache.Get("missing")
+	cache.Set("b", 1, 0) // evicts "a"
+	cache.Delete("b")
+	cache.Stop()
+
+	tr, err := NewTraceReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		op    EventType
+		key   string
+		sized bool
+		at    time.Duration
+	}{
+		{EventSet, "a", true, 0},
+		{EventHit, "a", true, time.Second},
+		{EventMiss, "missing", false, time.Second},
+		{EventEvicted, "a", false, time.Second},
+		{EventSet, "b", true, time.Second},
+		{EventDeleted, "b", false, time.Second},
+	}
+	for i, w := range want {
+		rec, err := tr.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if rec.Op != w.op || rec.KeyHash != hashKey(w.key) || (rec.Size > 0) != w.sized {
+			t.Fatalf("record %d: expected %v %q, got %+v", i, w.op, w.key, rec)
+		}
+		if at := rec.Time.Sub(time.Unix(1_000_000, 0)); at != w.at {
+			t.Fatalf("record %d: expected time +%v, got +%v", i, w.at, at)
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last record, got %v", err)
+	}
+}
+
+func TestTraceReaderErrors(t *testing.T) {
+	if _, err := NewTraceReader(strings.NewReader("not a trace")); err != ErrBadTrace {
+		t.Fatalf("expected ErrBadTrace, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	cache := New(WithTrace(&buf))
+	cache.Set("a", 1, 0)
+	cache.Stop()
+
+	truncated := buf.Bytes()[:buf.Len()-3]
+	tr, err := NewTraceReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a cut-off record, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package tempuscache
+
+import "time"
+
+/*
+trylock.go implements non-blocking variants of Get and Set.
+
+================================================================================
+WHY?
+================================================================================
+
+Get and Set wait for the cache lock. Usually that is a matter of
+nanoseconds, but the lock is also held through a janitor sweep, a
+large batch write, or a burst of evictions. Latency-critical callers
+may prefer to skip the cache (recompute, or serve without caching)
+over queueing behind such work.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+TryGet and TrySet attempt to take the lock once. If it is held,
+they return immediately with ok = false and count the rejection in
+Stats.Busy; nothing is read or written.
+
+Neither ever waits for a Store:
+
+  - TryGet reports a miss without loading (stale and refresh-ahead
+    entries still trigger their background refresh).
+  - TrySet only queues the value with write-behind, failing (ok =
+    false) if the queue is full. With write-through, which saves
+    synchronously, TrySet always fails: use Set or SetContext.
+*/
+
+/*
+TryGet is Get that never blocks.
+
+RETURNS:
+- (value, true, true) -> Hit
+- (nil, false, true)  -> Miss (not loaded from the Store)
+- (nil, false, false) -> The cache was busy; nothing was looked up
+*/
+
+func (c *Cache) TryGet(key string) (value interface{}, found, ok bool) {
+	if !c.mu.TryLock() {
+		c.counters.busy.Add(1)
+		return nil, false, false
+	}
+	value, found, refresh := c.lookup(key)
+	c.mu.Unlock()
+
+	if refresh {
+		c.revalidate(key)
+	}
+	return value, found, true
+}
+
+/*
+TrySet is Set that never blocks. It returns false, leaving the
+cache unchanged, if the cache (or the write-behind queue) was busy
+or write-through is enabled.
+*/
+
+func (c *Cache) TrySet(key string, value interface{}, ttl time.Duration) bool {
+	if c.writeThrough != nil {
+		return false
+	}
+	if !c.mu.TryLock() {
+		c.counters.busy.Add(1)
+		return false
+	}
+	defer c.mu.Unlock()
+
+	// Queue before writing, so the value is either cached and queued,
+	// or neither.
+	if c.writeBehind != nil && !c.writeBehind.tryEnqueue(key, value) {
+		c.counters.busy.Add(1)
+		return false
+	}
+	c.set(key, value, ttl)
+	return true
+}
@@ -0,0 +1,56 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTryGetTrySet(t *testing.T) {
+	cache := New()
+	defer cache.Stop()
+
+	if !cache.TrySet("a", 1, 0) {
+		t.Fatal("expected TrySet to succeed on an idle cache")
+	}
+	if v, found, ok := cache.TryGet("a"); !ok || !found || v != 1 {
+		t.Fatalf("expected a hit, got %v, %v, %v", v, found, ok)
+	}
+
+	// Simulate a long operation holding the lock.
+	cache.mu.Lock()
+	_, _, getOK := cache.TryGet("a")
+	setOK := cache.TrySet("a", 2, 0)
+	cache.mu.Unlock()
+
+	if getOK || setOK {
+		t.Fatal("expected TryGet and TrySet to fail fast while the cache is locked")
+	}
+	if v, _ := cache.Get("a"); v != 1 {
+		t.Fatalf("expected the rejected TrySet to leave the cache unchanged, got %v", v)
+	}
+	if busy := cache.Stats().Busy; busy != 2 {
+		t.Fatalf("expected 2 busy rejections, got %d", busy)
+	}
+}
+
+func TestTrySetWithStores(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{}}
+
+	wt := New(WithStore(store), WithWriteThrough(SaveBeforeCache))
+	defer wt.Stop()
+	if wt.TrySet("a", 1, 0) {
+		t.Fatal("expected TrySet to fail with write-through")
+	}
+
+	wb := New(WithStore(store), WithWriteBehind(WriteBehindConfig{MaxPending: 1, Interval: time.Hour}))
+	defer wb.Stop()
+	if !wb.TrySet("a", 1, 0) || !wb.TrySet("a", 2, 0) {
+		t.Fatal("expected TrySet to queue, and coalesce, with room in the queue")
+	}
+	if wb.TrySet("b", 3, 0) {
+		t.Fatal("expected TrySet to fail with a full write-behind queue")
+	}
+	if _, found := wb.Get("b"); found {
+		t.Fatal("expected a rejected TrySet not to cache the value")
+	}
+}
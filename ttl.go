@@ -0,0 +1,217 @@
+package tempuscache
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+/*
+NoExpiration is a ttl for Set (and the other TTL-taking writes)
+that stores the value without a deadline.
+
+A ttl of 0 keeps the deadline of an existing entry (or applies
+WithDefaultTTL to a new one); NoExpiration clears it, in the same
+locked write as the value itself.
+*/
+
+const NoExpiration time.Duration = -1
+
+/*
+TTL returns the remaining lifetime of key.
+
+RETURNS:
+- (remaining, true) -> Key exists and is unexpired; remaining is 0
+                       if the entry never expires
+- (0, false)        -> Key is missing or expired
+
+TTL does not count as a lookup and does not affect LRU order.
+Expired entries are removed lazily, as Get() would.
+*/
+
+func (c *Cache) TTL(key string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.data[key]
+	if !found {
+		return 0, false
+	}
+
+	item := elem.Value.(*Item)
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		return 0, false
+	}
+	if item.expiration == 0 {
+		return 0, true
+	}
+	return time.Duration(item.expiration - c.now()), true
+}
+
+/*
+Expire changes the TTL of an existing key without touching its value.
+
+PARAMETERS:
+- ttl > 0  -> The key expires ttl from now
+- ttl <= 0 -> The key no longer expires
+
+RETURNS:
+true if the key existed and was unexpired.
+
+BEHAVIOR:
+- The entry is NOT promoted in the LRU list and no hit is counted:
+  changing a deadline is bookkeeping, not a use of the value.
+- The new deadline is recorded in the append-only log (if enabled).
+
+TIME COMPLEXITY:
+O(1) average case
+*/
+
+func (c *Cache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.data[key]
+	if !found {
+		return false
+	}
+
+	item := elem.Value.(*Item)
+	if c.expired(item) {
+		c.expireElement(elem, false)
+		return false
+	}
+	if c.Frozen() {
+		return false
+	}
+
+	now := c.clock.Now()
+	item.expiration = 0
+	if ttl > 0 {
+		item.expiration = now.Add(ttl).UnixNano()
+	}
+	item.written = now.UnixNano()
+	c.publish(item)
+	c.schedule(item)
+	c.aofAppend(aofOpSet, key, c.unpack(item.value), item.expiration)
+	return true
+}
+
+/*
+SetDefaultTTL changes the TTL given to new entries written without
+one (see WithDefaultTTL). Entries already cached keep their
+expiration. d <= 0 turns the default off.
+*/
+
+func (c *Cache) SetDefaultTTL(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = d
+}
+
+// DefaultTTL returns the TTL of new entries written without one; <= 0 means they never expire.
+func (c *Cache) DefaultTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.defaultTTL
+}
+
+// KeyDeadline is an entry's key with its absolute deadline.
+type KeyDeadline struct {
+	Key        string
+	Expiration time.Time
+}
+
+/*
+ExpiringSoon returns up to n unexpired entries with a deadline,
+soonest first. Entries expiring at the same time are ordered by key.
+
+Like TTL, it does not count as a lookup and does not affect LRU
+order. Expired entries are skipped, not removed.
+
+================================================================================
+COST
+================================================================================
+
+O(entries × log n) under the read lock: every entry is visited, and
+the n soonest are kept in a heap. It suits maintenance schedulers
+and admin tooling rather than hot paths.
+*/
+
+func (c *Cache) ExpiringSoon(n int) []KeyDeadline {
+	if n <= 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	now := c.now()
+	var soonest deadlineHeap
+	for key, elem := range c.data {
+		item := elem.Value.(*Item)
+		if item.expiration == 0 || item.expiredAt(now) {
+			continue
+		}
+		d := keyExp{key, item.expiration}
+		if len(soonest) < n {
+			heap.Push(&soonest, d)
+		} else if d.before(soonest[0]) {
+			soonest[0] = d
+			heap.Fix(&soonest, 0)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(soonest, func(i, j int) bool { return soonest[i].before(soonest[j]) })
+	out := make([]KeyDeadline, len(soonest))
+	for i, d := range soonest {
+		out[i] = KeyDeadline{Key: d.key, Expiration: time.Unix(0, d.exp)}
+	}
+	return out
+}
+
+/*
+NextExpiration returns the deadline of the unexpired entry that
+expires first, or false if no unexpired entry has one. Its cost is
+that of ExpiringSoon(1).
+*/
+
+func (c *Cache) NextExpiration() (time.Time, bool) {
+	next := c.ExpiringSoon(1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+	return next[0].Expiration, true
+}
+
+// keyExp is a key with its deadline (UnixNano).
+type keyExp struct {
+	key string
+	exp int64
+}
+
+func (d keyExp) before(o keyExp) bool {
+	if d.exp != o.exp {
+		return d.exp < o.exp
+	}
+	return d.key < o.key
+}
+
+/*
+deadlineHeap is a max-heap of keyExp, so that the latest of the kept
+deadlines is the one replaced by a sooner one.
+*/
+
+type deadlineHeap []keyExp
+
+func (h deadlineHeap) Len() int            { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool  { return h[j].before(h[i]) }
+func (h deadlineHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x interface{}) { *h = append(*h, x.(keyExp)) }
+
+func (h *deadlineHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
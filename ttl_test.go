@@ -0,0 +1,92 @@
+package tempuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	cache := New()
+
+	cache.Set("forever", 1, 0)
+	cache.Set("short", 2, time.Hour)
+
+	if ttl, found := cache.TTL("forever"); !found || ttl != 0 {
+		t.Fatalf("expected (0, true) for non-expiring key, got (%v, %v)", ttl, found)
+	}
+	if ttl, found := cache.TTL("short"); !found || ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("unexpected TTL %v", ttl)
+	}
+	if _, found := cache.TTL("missing"); found {
+		t.Fatal("expected missing key to report not found")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	if !cache.Expire("a", time.Millisecond) {
+		t.Fatal("expected Expire to succeed on existing key")
+	}
+	if info, _ := cache.Inspect("a"); info.Position != 1 {
+		t.Fatal("expected Expire not to promote the entry")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if _, found := cache.Get("a"); found {
+		t.Fatal("expected 'a' to expire after Expire")
+	}
+	if cache.Expire("a", time.Hour) {
+		t.Fatal("expected Expire to fail on expired key")
+	}
+
+	cache.Set("c", 3, time.Millisecond)
+	cache.Expire("c", 0)
+	time.Sleep(2 * time.Millisecond)
+	if v, found := cache.Get("c"); !found || v != 3 {
+		t.Fatal("expected Expire(0) to remove the deadline")
+	}
+}
+
+func TestExpiringSoon(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock))
+	defer cache.Stop()
+
+	if _, ok := cache.NextExpiration(); ok {
+		t.Fatal("expected no next expiration in an empty cache")
+	}
+
+	cache.Set("forever", 1, 0)
+	cache.Set("gone", 1, time.Second)
+	cache.Set("c", 1, 3*time.Hour)
+	cache.Set("b", 1, 2*time.Hour)
+	cache.Set("a", 1, 2*time.Hour)
+	cache.Set("d", 1, time.Hour)
+	clock.advance(2 * time.Second)
+
+	got := cache.ExpiringSoon(3)
+	want := []string{"d", "a", "b"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), got)
+	}
+	for i, d := range got {
+		if d.Key != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+	if !got[0].Expiration.Equal(time.Unix(1_000_000, 0).Add(time.Hour)) {
+		t.Fatalf("unexpected deadline %v", got[0].Expiration)
+	}
+	if n := len(cache.ExpiringSoon(10)); n != 4 {
+		t.Fatalf("expected the 4 live entries with a deadline, got %d", n)
+	}
+
+	next, ok := cache.NextExpiration()
+	if !ok || !next.Equal(got[0].Expiration) {
+		t.Fatalf("expected next expiration %v, got %v", got[0].Expiration, next)
+	}
+}
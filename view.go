@@ -0,0 +1,172 @@
+package tempuscache
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+view.go implements immutable point-in-time views of the cache.
+
+================================================================================
+WHY?
+================================================================================
+
+Exports, persistence, and iteration need a consistent picture of
+the whole cache. Walking the live cache under its lock blocks every
+writer for the duration of the walk, however slow the consumer is;
+walking it without the lock sees a mix of old and new state.
+
+================================================================================
+DESIGN
+================================================================================
+
+Entries are already copy-on-write: every write publishes a new,
+immutable itemView (value and expiration, see readpath.go) instead
+of modifying the old one. A View is therefore just the list of
+itemView pointers current at one instant:
+
+- Taking a View holds the lock only to copy one pointer per entry.
+- Values are shared with the cache, not copied; later writes
+  replace the cache's views and leave the View untouched.
+- Reading a View never takes the cache lock.
+
+A View keeps the values it references reachable, so long-lived
+views hold on to memory that the cache itself has already released.
+
+================================================================================
+SEMANTICS
+================================================================================
+
+A View is the state at the moment it was taken: it contains the
+entries live at that instant, in LRU order, and keeps them even
+after they expire or are removed from the cache. Values are read
+through the cache's read path, so WithSerializer, WithCompression,
+and WithCopyOnRead apply as they do to Get.
+*/
+
+/*
+View is an immutable point-in-time view of a Cache, returned by
+SnapshotView. It is safe for concurrent use.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+c       -> Cache the view was taken from, for decoding values
+taken   -> Time the view was taken
+entries -> Entry views, least recently used first
+index   -> Key -> position in entries, built on first Get
+once    -> Guards building index
+*/
+
+type View struct {
+	c       *Cache
+	taken   time.Time
+	entries []*itemView
+	index   map[string]int
+	once    sync.Once
+}
+
+/*
+SnapshotView returns an immutable view of the current contents.
+
+================================================================================
+BEHAVIOR
+================================================================================
+
+- Entries expired at the time of the call are excluded.
+- Writers are blocked only while one pointer per entry is copied;
+  reading the view afterwards never blocks the cache.
+- Reads of the view are not counted in Stats and do not affect
+  LRU order.
+
+TIME COMPLEXITY:
+O(n) pointer copies under the lock.
+*/
+
+func (c *Cache) SnapshotView() *View {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.view()
+}
+
+/*
+view captures the live entries, least recently used first.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) view() *View {
+	c.applyPromotions()
+
+	now := c.clock.Now()
+	v := &View{c: c, taken: now, entries: make([]*itemView, 0, c.lru.Len())}
+	for elem := c.lru.Back(); elem != nil; elem = elem.Prev() {
+		item := elem.Value.(*Item)
+		if item.expiredAt(now.UnixNano()) {
+			continue
+		}
+		v.entries = append(v.entries, item.view.Load())
+	}
+	return v
+}
+
+// Time returns when the view was taken.
+func (v *View) Time() time.Time {
+	return v.taken
+}
+
+// Len returns the number of entries in the view.
+func (v *View) Len() int {
+	return len(v.entries)
+}
+
+/*
+Get returns the value key had when the view was taken.
+
+The first call builds a key index in O(n); later calls are O(1).
+*/
+
+func (v *View) Get(key string) (interface{}, bool) {
+	v.once.Do(func() {
+		v.index = make(map[string]int, len(v.entries))
+		for i, e := range v.entries {
+			v.index[e.key] = i
+		}
+	})
+
+	i, ok := v.index[key]
+	if !ok {
+		return nil, false
+	}
+	return v.c.read(v.entries[i].value), true
+}
+
+/*
+Range calls fn for each entry, least recently used first, until fn
+returns false. expiration is the zero time for entries that never
+expire.
+*/
+
+func (v *View) Range(fn func(key string, value interface{}, expiration time.Time) bool) {
+	for _, e := range v.entries {
+		var exp time.Time
+		if e.expiration != 0 {
+			exp = time.Unix(0, e.expiration)
+		}
+		if !fn(e.key, v.c.read(e.value), exp) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys in the view, least recently used first.
+func (v *View) Keys() []string {
+	keys := make([]string, len(v.entries))
+	for i, e := range v.entries {
+		keys[i] = e.key
+	}
+	return keys
+}
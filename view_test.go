@@ -0,0 +1,120 @@
+package tempuscache
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotView(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1_000_000, 0)}
+	cache := New(WithClock(clock))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, time.Minute)
+	cache.Set("c", 3, time.Second)
+	cache.Get("a")
+
+	clock.advance(2 * time.Second)
+	v := cache.SnapshotView()
+
+	// Expired entries are excluded; order is least recently used first.
+	if got, want := v.Keys(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected keys %v, got %v", want, got)
+	}
+	if !v.Time().Equal(clock.Now()) {
+		t.Fatalf("expected view time %v, got %v", clock.Now(), v.Time())
+	}
+
+	// Later writes do not show through.
+	cache.Set("a", 100, 0)
+	cache.Delete("b")
+	cache.Set("d", 4, 0)
+
+	if val, ok := v.Get("a"); !ok || val != 1 {
+		t.Fatalf("expected a=1 in view, got %v %v", val, ok)
+	}
+	if val, ok := v.Get("b"); !ok || val != 2 {
+		t.Fatalf("expected deleted b=2 in view, got %v %v", val, ok)
+	}
+	if _, ok := v.Get("d"); ok {
+		t.Fatal("expected d to be absent from view")
+	}
+	if v.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", v.Len())
+	}
+
+	// Range reports expirations and stops early.
+	var seen []string
+	v.Range(func(key string, value interface{}, exp time.Time) bool {
+		seen = append(seen, key)
+		if want := clock.Now().Add(-2 * time.Second).Add(time.Minute); !exp.Equal(want) {
+			t.Fatalf("expected b to expire at %v, got %v", want, exp)
+		}
+		return false
+	})
+	if len(seen) != 1 || seen[0] != "b" {
+		t.Fatalf("expected Range to stop after b, got %v", seen)
+	}
+}
+
+func TestSnapshotViewReadPath(t *testing.T) {
+	cache := New(WithSerializer(GobSerializer()), WithCopyOnRead(nil))
+	defer cache.Stop()
+
+	cache.Set("m", map[string]int{"x": 1}, 0)
+	v := cache.SnapshotView()
+
+	got, _ := v.Get("m")
+	got.(map[string]int)["x"] = 2
+
+	v.Range(func(_ string, value interface{}, exp time.Time) bool {
+		if value.(map[string]int)["x"] != 1 || !exp.IsZero() {
+			t.Fatalf("expected a fresh decoded copy without expiry, got %v %v", value, exp)
+		}
+		return true
+	})
+}
+
+func TestSnapshotViewConcurrentWrites(t *testing.T) {
+	cache := New(WithMaxEntries(100))
+	defer cache.Stop()
+
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprint(i), i, 0)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cache.Set(fmt.Sprint(i%200), -i, 0)
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		v := cache.SnapshotView()
+		before := v.Keys()
+		v.Range(func(key string, value interface{}, _ time.Time) bool {
+			if _, ok := value.(int); !ok {
+				t.Errorf("unexpected value %v for %s", value, key)
+			}
+			return true
+		})
+		if !reflect.DeepEqual(before, v.Keys()) {
+			t.Fatal("expected the view to stay unchanged while the cache is written")
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
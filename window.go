@@ -0,0 +1,155 @@
+package tempuscache
+
+import "sync/atomic"
+
+/*
+window.go implements rolling-window hit ratio tracking.
+
+================================================================================
+WHY WINDOWS?
+================================================================================
+
+Lifetime counters hide regressions: after a week of uptime, a sudden
+drop in hit rate barely moves Hits / (Hits + Misses). Rolling windows
+over the last 1, 5, and 15 minutes surface such changes immediately,
+in the spirit of Unix load averages.
+
+================================================================================
+DATA STRUCTURE
+================================================================================
+
+A ring of per-second buckets covering the longest window:
+
+    bucket index = unix_second % windowSeconds
+
+Each bucket remembers which second it currently represents. A bucket
+holding an older second is reset before being reused, so no separate
+rotation goroutine is needed.
+
+Memory cost is fixed: windowSeconds * 16 bytes (~14 KB per cache).
+
+================================================================================
+CONCURRENCY
+================================================================================
+
+Buckets are atomic: lookups record into them from the lock-free hit
+path (see readpath.go) as well as under the cache lock, and
+HitRatios reads them without the lock. When a bucket is recycled for
+a new second, a lookup recorded concurrently by another goroutine
+may be lost; windows are approximate by a few counts per second.
+*/
+
+const windowSeconds = 15 * 60
+
+type hitWindow struct {
+	second [windowSeconds]atomic.Int64
+	hits   [windowSeconds]atomic.Uint32
+	misses [windowSeconds]atomic.Uint32
+}
+
+/*
+record counts one lookup in the bucket for the given unix second.
+*/
+
+func (w *hitWindow) record(sec int64, hit bool) {
+	i := sec % windowSeconds
+	if old := w.second[i].Load(); old != sec && w.second[i].CompareAndSwap(old, sec) {
+		w.hits[i].Store(0)
+		w.misses[i].Store(0)
+	}
+	if hit {
+		w.hits[i].Add(1)
+	} else {
+		w.misses[i].Add(1)
+	}
+}
+
+/*
+sum totals the buckets for the span seconds ending at sec (inclusive).
+Buckets that hold a different second are stale and skipped.
+*/
+
+func (w *hitWindow) sum(sec int64, span int64) HitRatio {
+	var r HitRatio
+	for s := sec - span + 1; s <= sec; s++ {
+		i := s % windowSeconds
+		if w.second[i].Load() == s {
+			r.Hits += uint64(w.hits[i].Load())
+			r.Misses += uint64(w.misses[i].Load())
+		}
+	}
+	return r
+}
+
+/*
+HitRatio holds lookup counts for one time window.
+*/
+
+type HitRatio struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Ratio returns Hits / (Hits + Misses), or 0 when there were no lookups.
+func (r HitRatio) Ratio() float64 {
+	total := r.Hits + r.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(total)
+}
+
+/*
+HitRatios reports lookup outcomes over rolling windows.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+OneMinute      -> Lookups during the last 60 seconds
+FiveMinutes    -> Lookups during the last 5 minutes
+FifteenMinutes -> Lookups during the last 15 minutes
+*/
+
+type HitRatios struct {
+	OneMinute      HitRatio
+	FiveMinutes    HitRatio
+	FifteenMinutes HitRatio
+}
+
+/*
+recordLookup counts a lookup outcome in both the lifetime counters
+and the rolling windows.
+
+NOTE:
+The caller must hold the exclusive lock.
+*/
+
+func (c *Cache) recordLookup(hit bool) {
+	if hit {
+		c.counters.hits.Add(1)
+	} else {
+		c.counters.misses.Add(1)
+	}
+	c.window.record(c.clock.Now().Unix(), hit)
+}
+
+/*
+HitRatios returns hit/miss counts over the last 1, 5, and 15 minutes.
+
+Windows have one-second granularity and include the current,
+partially elapsed second. They are independent of ResetStats().
+
+TIME COMPLEXITY:
+O(windowSeconds) — a fixed ~1,200 bucket reads.
+*/
+
+func (c *Cache) HitRatios() HitRatios {
+	now := c.clock.Now().Unix()
+
+	return HitRatios{
+		OneMinute:      c.window.sum(now, 60),
+		FiveMinutes:    c.window.sum(now, 5*60),
+		FifteenMinutes: c.window.sum(now, 15*60),
+	}
+}
@@ -0,0 +1,42 @@
+package tempuscache
+
+import "testing"
+
+func TestHitRatios(t *testing.T) {
+	cache := New()
+
+	cache.Set("a", 1, 0)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+
+	r := cache.HitRatios()
+	if r.OneMinute.Hits != 3 || r.OneMinute.Misses != 1 {
+		t.Fatalf("unexpected 1m window: %+v", r.OneMinute)
+	}
+	if got := r.FifteenMinutes.Ratio(); got != 0.75 {
+		t.Fatalf("expected 15m ratio 0.75, got %v", got)
+	}
+}
+
+func TestHitWindowExpiresOldBuckets(t *testing.T) {
+	var w hitWindow
+
+	w.record(1000, true)
+	w.record(1000, false)
+	w.record(1050, true)
+
+	if r := w.sum(1050, 60); r.Hits != 2 || r.Misses != 1 {
+		t.Fatalf("expected both seconds in 1m window, got %+v", r)
+	}
+	if r := w.sum(1070, 60); r.Hits != 1 || r.Misses != 0 {
+		t.Fatalf("expected second 1000 to fall out of 1m window, got %+v", r)
+	}
+
+	// Same ring slot, one full rotation later.
+	w.record(1000+windowSeconds, false)
+	if r := w.sum(1000+windowSeconds, 1); r.Hits != 0 || r.Misses != 1 {
+		t.Fatalf("expected reused bucket to be reset, got %+v", r)
+	}
+}
@@ -0,0 +1,385 @@
+package tempuscache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+writebehind.go persists Set values to the Store asynchronously.
+
+================================================================================
+WHY?
+================================================================================
+
+Write-through makes every Set wait for the Store. For high-write
+data such as counters that is far too slow, and mostly wasted: a
+counter bumped a thousand times a second only needs its latest value
+persisted every so often. With write-behind, Set updates the cache
+immediately and the value is saved in the background.
+
+================================================================================
+FLOW
+================================================================================
+
+Set:
+    → The cache is updated.
+    → The key is queued with its value. A key already queued only has
+      its value replaced (coalescing), so a hot key costs one Save
+      per flush, not one per Set.
+    → If MaxPending keys are already queued, Set blocks until the
+      worker makes room (backpressure instead of unbounded memory).
+
+Background worker:
+    → Saves queued keys in batches of BatchSize, as soon as a full
+      batch is queued and at least every Interval.
+    → Uses Store.SaveMany when the Store implements BatchStore,
+      Store.Save per key otherwise.
+    → Retries failed saves MaxRetries times with exponential backoff,
+      then drops them, logging an error and counting
+      Stats.WriteBehindDropped.
+
+FlushWrites, Stop, and Close save everything still queued before
+returning. Successive values of one key are saved in Set order.
+
+================================================================================
+DURABILITY
+================================================================================
+
+Values accepted by Set but not yet saved are lost if the process
+crashes. Use write-through for data that must never be lost.
+*/
+
+/*
+BatchStore is implemented by Stores that can persist several values
+in one call. Write-behind uses it to save whole batches at once.
+*/
+
+type BatchStore interface {
+	WritableStore
+	SaveMany(ctx context.Context, values map[string]interface{}) error
+}
+
+/*
+WriteBehindConfig configures write-behind persistence.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+BatchSize    -> Keys saved per batch (default 100)
+Interval     -> Longest time a write stays queued (default 1s)
+MaxPending   -> Queued keys after which Set blocks (default 10000)
+MaxRetries   -> Extra attempts for a failed save (default 3,
+                negative = none)
+RetryBackoff -> Delay before the first retry, doubled for each
+                later one (default 100ms)
+*/
+
+type WriteBehindConfig struct {
+	BatchSize    int
+	Interval     time.Duration
+	MaxPending   int
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+/*
+writeBehind holds write-behind state.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+cfg     -> Configuration, with defaults applied
+mu      -> Protects pending, order, and stopped
+space   -> Signalled when queued keys are taken by the worker
+pending -> Latest queued value per key
+order   -> Queued keys, oldest first
+stopped -> Set once the worker has exited; later writes are saved
+           synchronously
+wake    -> Signals the worker that a full batch is queued
+flush   -> FlushWrites requests, closed by the worker once drained
+*/
+
+type writeBehind struct {
+	cfg WriteBehindConfig
+
+	mu      sync.Mutex
+	space   *sync.Cond
+	pending map[string]interface{}
+	order   []string
+	stopped bool
+	wake    chan struct{}
+	flush   chan chan struct{}
+}
+
+func newWriteBehind(cfg WriteBehindConfig) *writeBehind {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 10000
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+
+	wb := &writeBehind{
+		cfg:     cfg,
+		pending: make(map[string]interface{}),
+		wake:    make(chan struct{}, 1),
+		flush:   make(chan chan struct{}),
+	}
+	wb.space = sync.NewCond(&wb.mu)
+	return wb
+}
+
+/*
+enqueue queues key for saving, blocking while the queue is full.
+It returns false once the worker has stopped, and ctx.Err() if ctx
+ends while waiting for room.
+*/
+
+func (wb *writeBehind) enqueue(ctx context.Context, key string, value interface{}) (bool, error) {
+	// Wake the wait below when ctx ends.
+	stop := context.AfterFunc(ctx, func() {
+		wb.mu.Lock()
+		wb.space.Broadcast()
+		wb.mu.Unlock()
+	})
+	defer stop()
+
+	wb.mu.Lock()
+	for !wb.stopped && len(wb.order) >= wb.cfg.MaxPending {
+		if _, queued := wb.pending[key]; queued {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			wb.mu.Unlock()
+			return false, err
+		}
+		wb.space.Wait()
+	}
+	if wb.stopped {
+		wb.mu.Unlock()
+		return false, nil
+	}
+
+	wb.push(key, value)
+	return true, nil
+}
+
+/*
+push queues key, unlocks the queue, and wakes the worker once a
+full batch is queued.
+
+NOTE:
+The caller must hold wb.mu, which push releases.
+*/
+
+func (wb *writeBehind) push(key string, value interface{}) {
+	if _, queued := wb.pending[key]; !queued {
+		wb.order = append(wb.order, key)
+	}
+	wb.pending[key] = value
+	full := len(wb.order) >= wb.cfg.BatchSize
+	wb.mu.Unlock()
+
+	if full {
+		select {
+		case wb.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+/*
+tryEnqueue is enqueue that fails instead of blocking: when the queue
+is locked or full, or the worker has stopped.
+*/
+
+func (wb *writeBehind) tryEnqueue(key string, value interface{}) bool {
+	if !wb.mu.TryLock() {
+		return false
+	}
+	_, queued := wb.pending[key]
+	if wb.stopped || !queued && len(wb.order) >= wb.cfg.MaxPending {
+		wb.mu.Unlock()
+		return false
+	}
+
+	wb.push(key, value)
+	return true
+}
+
+// take removes up to BatchSize queued keys, oldest first.
+func (wb *writeBehind) take(fullOnly bool) map[string]interface{} {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	n := min(len(wb.order), wb.cfg.BatchSize)
+	if n == 0 || fullOnly && n < wb.cfg.BatchSize {
+		return nil
+	}
+
+	batch := make(map[string]interface{}, n)
+	for _, key := range wb.order[:n] {
+		batch[key] = wb.pending[key]
+		delete(wb.pending, key)
+	}
+	wb.order = wb.order[n:]
+	wb.space.Broadcast()
+	return batch
+}
+
+// queued returns the number of keys waiting to be saved.
+func (wb *writeBehind) queued() int {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	return len(wb.order)
+}
+
+/*
+startWriteBehind launches the write-behind worker configured by
+WithWriteBehind().
+
+The worker shares stopChan with the janitor; on Stop() it saves
+everything still queued before returning.
+*/
+
+func (c *Cache) startWriteBehind() {
+	wb := c.writeBehind
+	store, ok := c.store.(WritableStore)
+	if wb == nil || !ok || c.writeThrough != nil {
+		c.writeBehind = nil
+		return
+	}
+
+	ticker := c.clock.NewTicker(wb.cfg.Interval)
+
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-wb.wake:
+				c.saveQueued(store, true)
+			case <-ticker.C():
+				c.saveQueued(store, false)
+			case done := <-wb.flush:
+				c.saveQueued(store, false)
+				close(done)
+			case <-c.stopChan:
+				// Stop queueing first, so no write slips in after the
+				// final drain.
+				wb.mu.Lock()
+				wb.stopped = true
+				wb.space.Broadcast()
+				wb.mu.Unlock()
+				c.saveQueued(store, false)
+				return
+			}
+		}
+	}()
+}
+
+/*
+saveQueued saves queued keys batch by batch: only full batches when
+fullOnly is set, everything otherwise.
+*/
+
+func (c *Cache) saveQueued(store WritableStore, fullOnly bool) {
+	for {
+		batch := c.writeBehind.take(fullOnly)
+		if batch == nil {
+			return
+		}
+		c.saveBatch(store, batch)
+	}
+}
+
+// saveBatch saves batch, retrying failed keys with backoff.
+func (c *Cache) saveBatch(store WritableStore, batch map[string]interface{}) {
+	cfg := c.writeBehind.cfg
+	backoff := cfg.RetryBackoff
+
+	failed, err := trySave(store, batch)
+	for attempt := 0; len(failed) > 0 && attempt < cfg.MaxRetries; attempt++ {
+		<-c.clock.After(backoff)
+		backoff *= 2
+		failed, err = trySave(store, failed)
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	c.logger().Error("tempuscache: write-behind save failed, writes dropped", "keys", len(failed), "err", err)
+	c.counters.writeBehindDropped.Add(uint64(len(failed)))
+}
+
+// trySave saves batch once and returns the values that failed.
+func trySave(store WritableStore, batch map[string]interface{}) (map[string]interface{}, error) {
+	ctx := context.Background()
+
+	if bs, ok := store.(BatchStore); ok {
+		if err := bs.SaveMany(ctx, batch); err != nil {
+			return batch, err
+		}
+		return nil, nil
+	}
+
+	var failed map[string]interface{}
+	var lastErr error
+	for key, value := range batch {
+		if err := store.Save(ctx, key, value); err != nil {
+			if failed == nil {
+				failed = make(map[string]interface{})
+			}
+			failed[key], lastErr = value, err
+		}
+	}
+	return failed, lastErr
+}
+
+/*
+FlushWrites saves every queued write-behind value and waits for it.
+
+It returns ctx.Err() if ctx ends first; the flush itself carries on
+in the background. Without write-behind it returns immediately.
+*/
+
+func (c *Cache) FlushWrites(ctx context.Context) error {
+	wb := c.writeBehind
+	if wb == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	select {
+	case wb.flush <- done:
+	case <-c.stopChan:
+		// Stop drains the queue itself.
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
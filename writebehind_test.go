@@ -0,0 +1,152 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchStore is a BatchStore recording its SaveMany calls.
+type batchStore struct {
+	memStore
+	batches  int
+	failures int // number of SaveMany calls left to fail
+}
+
+func (s *batchStore) SaveMany(_ context.Context, values map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("temporarily unavailable")
+	}
+	for k, v := range values {
+		s.data[k] = v
+	}
+	return nil
+}
+
+func (s *batchStore) get(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key]
+}
+
+func TestWriteBehindCoalescesAndBatches(t *testing.T) {
+	store := &batchStore{memStore: memStore{data: map[string]interface{}{}}}
+	cache := New(WithStore(store), WithWriteBehind(WriteBehindConfig{BatchSize: 10, Interval: time.Hour}))
+	defer cache.Stop()
+
+	for i := 0; i < 1000; i++ {
+		cache.Set("counter", i, 0)
+	}
+	cache.Set("other", "x", 0)
+	if v, _ := cache.Get("counter"); v != 999 {
+		t.Fatalf("expected the cache to be updated immediately, got %v", v)
+	}
+	if n := cache.Stats().WriteBehindPending; n != 2 {
+		t.Fatalf("expected 2 coalesced keys queued, got %d", n)
+	}
+
+	if err := cache.FlushWrites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if store.get("counter") != 999 || store.get("other") != "x" || store.batches != 1 {
+		t.Fatalf("expected one batch with the latest values, got %d batches and %v", store.batches, store.data)
+	}
+}
+
+func TestWriteBehindRetries(t *testing.T) {
+	store := &batchStore{memStore: memStore{data: map[string]interface{}{}}, failures: 2}
+	cache := New(WithStore(store), WithWriteBehind(WriteBehindConfig{Interval: time.Hour, RetryBackoff: time.Millisecond}))
+	defer cache.Stop()
+
+	cache.Set("k", "v", 0)
+	cache.FlushWrites(context.Background())
+	if store.get("k") != "v" || store.batches != 3 {
+		t.Fatalf("expected success on the third attempt, got %d attempts", store.batches)
+	}
+
+	store.failures = 10
+	cache.Set("lost", "v", 0)
+	cache.FlushWrites(context.Background())
+	if n := cache.Stats().WriteBehindDropped; n != 1 {
+		t.Fatalf("expected 1 dropped write, got %d", n)
+	}
+}
+
+func TestWriteBehindBackpressure(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{}}
+	cache := New(WithStore(store), WithWriteBehind(WriteBehindConfig{MaxPending: 2, Interval: time.Hour}))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Set("a", 3, 0) // already queued: never blocks
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	returned := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		cache.Set("c", 4, 0)
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+		t.Fatal("expected Set to block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cache.FlushWrites(context.Background())
+	wg.Wait()
+	cache.FlushWrites(context.Background())
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.data["a"] != 3 || store.data["b"] != 2 || store.data["c"] != 4 {
+		t.Fatalf("unexpected store contents %v", store.data)
+	}
+}
+
+func TestWriteBehindBackpressureContext(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{}}
+	cache := New(WithStore(store), WithWriteBehind(WriteBehindConfig{MaxPending: 1, Interval: time.Hour}))
+	defer cache.Stop()
+
+	cache.Set("a", 1, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cache.SetContext(ctx, "b", 2, 0); err != context.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded while the queue is full, got %v", err)
+	}
+	if v, _ := cache.Get("b"); v != 2 {
+		t.Fatalf("expected the value to be cached anyway, got %v", v)
+	}
+}
+
+func TestWriteBehindStopDrains(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{}}
+	cache := New(WithStore(store), WithWriteBehind(WriteBehindConfig{Interval: time.Hour}))
+
+	cache.Set("k", "queued", 0)
+	cache.Stop()
+	if store.data["k"] != "queued" {
+		t.Fatal("expected Stop to save queued writes")
+	}
+
+	if err := cache.SetContext(context.Background(), "k", "late", 0); err != nil {
+		t.Fatal(err)
+	}
+	if store.data["k"] != "late" {
+		t.Fatal("expected writes after Stop to be saved synchronously")
+	}
+	if err := cache.FlushWrites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,154 @@
+package tempuscache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+writethrough.go makes Set persist to the backing Store.
+
+================================================================================
+WHY?
+================================================================================
+
+For data such as configuration, the cache can be the single write
+path: writers call Set, and the Store is updated as part of the
+same call. Readers keep reading from the cache (and, with WithStore,
+load from the Store on a miss).
+
+================================================================================
+ORDERING
+================================================================================
+
+SaveBeforeCache (safe default):
+    → Store.Save runs first.
+    → On failure the cache is left untouched and the error returned,
+      so the cache never holds a value the Store rejected.
+
+SaveAfterCache:
+    → The cache is updated first, so readers see the new value
+      without waiting for the Store.
+    → On failure the entry is removed again, so the next read loads
+      the Store's value instead of serving one it never accepted.
+
+Write-throughs of the same key are serialized, so the Store and the
+cache always agree on which of two concurrent Sets came last.
+
+================================================================================
+SCOPE
+================================================================================
+
+Only Set and SetContext write through. Batch writes, the atomic
+operations, and entries loaded from snapshots or the append-only log
+stay cache-only.
+*/
+
+// writeStripes is the number of locks serializing write-throughs.
+const writeStripes = 64
+
+/*
+WritableStore is a Store that can also persist values, required by
+WithWriteThrough.
+*/
+
+type WritableStore interface {
+	Store
+	Save(ctx context.Context, key string, value interface{}) error
+}
+
+// WriteOrder selects when Set persists to the Store.
+type WriteOrder int
+
+const (
+	// SaveBeforeCache persists first and caches only on success.
+	SaveBeforeCache WriteOrder = iota + 1
+
+	// SaveAfterCache caches first and rolls back on failure.
+	SaveAfterCache
+)
+
+/*
+writeThrough holds write-through state.
+
+================================================================================
+STRUCTURE FIELDS
+================================================================================
+
+order -> SaveBeforeCache or SaveAfterCache
+locks -> Striped per-key locks serializing write-throughs
+*/
+
+type writeThrough struct {
+	order WriteOrder
+	locks [writeStripes]sync.Mutex
+}
+
+/*
+SetContext stores value under key like Set and, with
+WithWriteThrough, persists it to the Store, returning the Store's
+error. With WithWriteBehind it queues the value for saving (see
+writebehind.go); if ctx ends while the queue is full, the value is
+cached but not queued, and ctx.Err() is returned. A key refused by
+WithKeyValidator is neither cached nor saved, and its error is
+returned. Otherwise it never fails.
+
+ctx is passed to Store.Save, so a caller that has gone away (an
+abandoned HTTP request, ...) does not keep waiting for the Store.
+*/
+
+func (c *Cache) SetContext(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	store, ok := c.store.(WritableStore)
+	if wb := c.writeBehind; wb != nil {
+		if err := c.setLocal(key, value, ttl); err != nil {
+			return err
+		}
+		queued, err := wb.enqueue(ctx, key, value)
+		if err != nil {
+			return err
+		}
+		if !queued {
+			// The worker has stopped: save synchronously instead.
+			return store.Save(ctx, key, value)
+		}
+		return nil
+	}
+
+	wt := c.writeThrough
+	if wt == nil || !ok {
+		return c.setLocal(key, value, ttl)
+	}
+
+	mu := &wt.locks[c.hash(key)%writeStripes]
+	mu.Lock()
+	defer mu.Unlock()
+
+	if wt.order != SaveAfterCache {
+		// Invalid keys must not reach the Store either.
+		if err := c.admit(key); err != nil {
+			return err
+		}
+		if err := store.Save(ctx, key, value); err != nil {
+			return err
+		}
+		return c.setLocal(key, value, ttl)
+	}
+
+	if err := c.setLocal(key, value, ttl); err != nil {
+		return err
+	}
+	if err := store.Save(ctx, key, value); err != nil {
+		c.Delete(key)
+		return err
+	}
+	return nil
+}
+
+// setLocal is Set without write-through.
+func (c *Cache) setLocal(key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.set(key, value, ttl)
+}
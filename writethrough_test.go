@@ -0,0 +1,86 @@
+package tempuscache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a WritableStore backed by a map.
+type memStore struct {
+	mu      sync.Mutex
+	data    map[string]interface{}
+	saveErr error
+}
+
+func (s *memStore) Load(_ context.Context, key string) (interface{}, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	return v, 0, nil
+}
+
+func (s *memStore) Save(_ context.Context, key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.data[key] = value
+	return nil
+}
+
+func TestWriteThroughBeforeCache(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{}}
+	cache := New(WithStore(store), WithWriteThrough(SaveBeforeCache))
+	defer cache.Stop()
+
+	cache.Set("k", "v1", 0)
+	if store.data["k"] != "v1" {
+		t.Fatalf("expected Set to persist, store has %v", store.data["k"])
+	}
+
+	store.saveErr = errors.New("read-only")
+	if err := cache.SetContext(context.Background(), "k", "v2", 0); !errors.Is(err, store.saveErr) {
+		t.Fatalf("expected the save error, got %v", err)
+	}
+	if v, _ := cache.Get("k"); v != "v1" {
+		t.Fatalf("expected a failed save to leave the cache untouched, got %v", v)
+	}
+}
+
+func TestWriteThroughAfterCache(t *testing.T) {
+	store := &memStore{data: map[string]interface{}{"k": "stored"}, saveErr: errors.New("down")}
+	cache := New(WithStore(store), WithWriteThrough(SaveAfterCache))
+	defer cache.Stop()
+
+	if err := cache.SetContext(context.Background(), "k", "rejected", 0); err == nil {
+		t.Fatal("expected the save error")
+	}
+	if v, _ := cache.Get("k"); v != "stored" {
+		t.Fatalf("expected the rolled-back key to reload from the store, got %v", v)
+	}
+
+	store.saveErr = nil
+	cache.Set("k", "accepted", 0)
+	if v, _ := cache.Get("k"); v != "accepted" || store.data["k"] != "accepted" {
+		t.Fatalf("expected both levels to hold the new value, got %v and %v", v, store.data["k"])
+	}
+}
+
+func TestWriteThroughWithoutWritableStore(t *testing.T) {
+	cache := New(WithWriteThrough(SaveBeforeCache))
+	defer cache.Stop()
+
+	if err := cache.SetContext(context.Background(), "k", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := cache.Get("k"); !found {
+		t.Fatal("expected a plain cache write")
+	}
+}
@@ -0,0 +1,50 @@
+package tempuscache
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+/*
+xfetch.go implements probabilistic early expiration ("X-Fetch", from
+"Optimal Probabilistic Cache Stampede Prevention", Vattani et al.).
+
+================================================================================
+WHY?
+================================================================================
+
+Loads of a key are deduplicated (see store.go), but that only helps
+within one process. A fleet of caches holding the same hot key still
+expires it at the same deadline, and every one of them hits the
+backing source at once.
+
+With early expiration, each Get of a live entry may treat it as a
+miss and reload it, with a probability that grows as expiry
+approaches. One reader, picked at random, reloads shortly before the
+deadline; the rest keep hitting the refreshed entry.
+
+================================================================================
+ALGORITHM
+================================================================================
+
+An entry is treated as expired when
+
+    now - delta * beta * ln(rand()) >= expiration
+
+where rand() is uniform in (0, 1], delta is how long the Store.Load
+that produced the value took, and beta is the WithEarlyExpiration
+setting. Expensive values are thus reloaded earlier than cheap ones.
+
+Entries that were never loaded from the Store (delta = 0) and
+entries without a TTL never expire early. Unlike refresh-ahead (see
+refresh.go), the picked reader waits for the reload, as on any miss.
+*/
+
+// expiresEarly reports whether item is picked for early expiration.
+func (c *Cache) expiresEarly(item *Item) bool {
+	if item.expiration == 0 || item.delta == 0 {
+		return false
+	}
+	gap := float64(item.delta) * c.earlyBeta * -math.Log(1-rand.Float64())
+	return float64(c.now())+gap >= float64(item.expiration)
+}
@@ -0,0 +1,52 @@
+package tempuscache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEarlyExpiration(t *testing.T) {
+	var loads atomic.Int32
+	store := funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+		time.Sleep(time.Millisecond)
+		return loads.Add(1), time.Hour, nil
+	})
+
+	// With an enormous beta, every read of a loaded entry is early.
+	eager := New(WithStore(store), WithEarlyExpiration(1e12))
+	defer eager.Stop()
+
+	eager.Get("k")
+	if v, found := eager.Get("k"); !found || v != int32(2) {
+		t.Fatalf("expected the entry to be reloaded early, got %v, %v", v, found)
+	}
+	if s := eager.Stats(); s.EarlyExpirations != 1 || s.Misses != 2 {
+		t.Fatalf("expected 1 early expiration among 2 misses, got %+v", s)
+	}
+
+	// Entries written with Set have no load cost and never expire early.
+	eager.Set("set", "v", time.Hour)
+	if v, _ := eager.Get("set"); v != "v" {
+		t.Fatalf("expected a Set entry to be served, got %v", v)
+	}
+}
+
+func TestEarlyExpirationFarFromExpiry(t *testing.T) {
+	var loads atomic.Int32
+	cache := New(
+		WithStore(funcStore(func(context.Context, string) (interface{}, time.Duration, error) {
+			return loads.Add(1), time.Hour, nil
+		})),
+		WithEarlyExpiration(1),
+	)
+	defer cache.Stop()
+
+	for i := 0; i < 1000; i++ {
+		cache.Get("k")
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected no early reloads an hour before expiry, got %d loads", n)
+	}
+}